@@ -0,0 +1,390 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var errUnknownSnapshot = errors.New("unknown snapshot")
+
+// SetBalance sets the balance of an account in the pending state.
+func (b *SimulatedBackend) SetBalance(account common.Address, balance *big.Int) {
+	balance = new(big.Int).Set(balance)
+	b.modifyPending(func(block *core.BlockGen) {
+		block.SetBalance(account, balance)
+	})
+}
+
+// SetNonce sets the nonce of an account in the pending state.
+func (b *SimulatedBackend) SetNonce(account common.Address, nonce uint64) {
+	b.modifyPending(func(block *core.BlockGen) {
+		block.SetAccountNonce(account, nonce)
+	})
+}
+
+// SetCode sets the code of an account in the pending state.
+func (b *SimulatedBackend) SetCode(account common.Address, code []byte) {
+	code = common.CopyBytes(code)
+	b.modifyPending(func(block *core.BlockGen) {
+		block.SetCode(account, code)
+	})
+}
+
+// SetStorageAt sets the value of a storage slot of an account in the pending state.
+func (b *SimulatedBackend) SetStorageAt(account common.Address, key, value common.Hash) {
+	b.modifyPending(func(block *core.BlockGen) {
+		block.SetState(account, key, value)
+	})
+}
+
+// modifyPending applies a state change on top of the pending transactions.
+func (b *SimulatedBackend) modifyPending(action func(*core.BlockGen)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pendingActions = append(b.pendingActions, action)
+	b.pendingUnchecked = true
+	b.generatePending(b.blockchain.GetBlockByHash(b.pendingBlock.ParentHash()), 0)
+}
+
+// SendImpersonatedTransaction adds a transaction to the pending block, executing
+// it as if it was sent by the given account, irrespective of its signature. The
+// transaction does not need to be signed, its signature being replaced by one
+// unique to the impersonated account. The transaction actually included in the
+// block is returned.
+//
+// Contracts may be impersonated as well, but their transactions cannot be
+// retrieved from the chain with their real sender.
+func (b *SimulatedBackend) SendImpersonatedTransaction(ctx context.Context, from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Replace the signature to ensure identical transactions of different senders
+	// have different hashes
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig[common.HashLength-common.AddressLength:], from[:])
+	sig[2*common.HashLength-1] = 1
+
+	tx, err := tx.WithSignature(types.LatestSigner(b.config), sig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction: %v", err)
+	}
+	// Check transaction validity, failures would abort the block generation
+	if nonce := b.pendingState.GetNonce(from); tx.Nonce() != nonce {
+		return nil, fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce)
+	}
+	if tx.Gas() > b.pendingBlock.GasLimit()-b.pendingBlock.GasUsed() {
+		return nil, fmt.Errorf("%w: have %d, want %d", core.ErrGasLimitReached, b.pendingBlock.GasLimit()-b.pendingBlock.GasUsed(), tx.Gas())
+	}
+	if cost, balance := tx.Cost(), b.pendingState.GetBalance(from); balance.Cmp(cost) < 0 {
+		return nil, fmt.Errorf("%w: address %v have %v want %v", core.ErrInsufficientFunds, from, balance, cost)
+	}
+	if baseFee := b.pendingBlock.BaseFee(); baseFee != nil && tx.GasFeeCap().Cmp(baseFee) < 0 {
+		return nil, fmt.Errorf("%w: address %v, maxFeePerGas: %v baseFee: %v", core.ErrFeeCapTooLow, from, tx.GasFeeCap(), baseFee)
+	}
+	if tx.To() == nil {
+		b.impersonatedCreations[tx.Hash()] = crypto.CreateAddress(from, tx.Nonce())
+	}
+	b.pendingActions = append(b.pendingActions, func(block *core.BlockGen) {
+		block.AddImpersonatedTx(b.blockchain, from, tx)
+	})
+	b.pendingUnchecked = true
+	b.generatePending(b.blockchain.GetBlockByHash(b.pendingBlock.ParentHash()), 0)
+	return tx, nil
+}
+
+// Mine commits the given number of blocks, the first one containing all the
+// pending transactions and state changes, the rest of them being empty. The
+// hash of the last block is returned.
+func (b *SimulatedBackend) Mine(blocks uint64) common.Hash {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hash := b.pendingBlock.ParentHash()
+	for i := uint64(0); i < blocks; i++ {
+		hash = b.commit()
+	}
+	return hash
+}
+
+// SetNextBlockTimestamp sets the timestamp of the pending block, which must be
+// later than the one of its parent.
+func (b *SimulatedBackend) SetNextBlockTimestamp(timestamp uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	parent := b.blockchain.GetBlockByHash(b.pendingBlock.ParentHash())
+	if timestamp <= parent.Time() {
+		return fmt.Errorf("timestamp %d not after parent timestamp %d", timestamp, parent.Time())
+	}
+	b.pendingTime = timestamp
+	b.pendingUnchecked = true
+	b.generatePending(parent, 0)
+	return nil
+}
+
+// SetNextBlockBaseFee sets the base fee of the pending block. The pending
+// transactions are re-executed with the new base fee, the base fee being left
+// unchanged if any of them cannot be executed anymore (e.g. its sender cannot
+// afford the higher fees).
+func (b *SimulatedBackend) SetNextBlockBaseFee(baseFee *big.Int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.config.IsLondon(b.pendingBlock.Number()) {
+		return errors.New("base fee not supported before london")
+	}
+	for _, tx := range b.pendingBlock.Transactions() {
+		if tx.GasFeeCap().Cmp(baseFee) < 0 {
+			return fmt.Errorf("%w: transaction %x, maxFeePerGas: %v baseFee: %v", core.ErrFeeCapTooLow, tx.Hash(), tx.GasFeeCap(), baseFee)
+		}
+	}
+	prev := b.pendingBaseFee
+	b.pendingBaseFee = new(big.Int).Set(baseFee)
+	if err := b.tryGeneratePending(b.blockchain.GetBlockByHash(b.pendingBlock.ParentHash())); err != nil {
+		b.pendingBaseFee = prev
+		return err
+	}
+	b.pendingUnchecked = true
+	return nil
+}
+
+// tryGeneratePending regenerates the pending block like generatePending, but
+// fails instead of panicking if the pending transactions cannot be executed
+// anymore. The pending block is left untouched on failure.
+func (b *SimulatedBackend) tryGeneratePending(parent *types.Block) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			// Block generation panics with the transaction execution error,
+			// anything else is a bug in the simulator
+			failure, ok := r.(error)
+			if !ok {
+				panic(r)
+			}
+			err = failure
+		}
+	}()
+	b.generatePending(parent, 0)
+	return nil
+}
+
+// Snapshot records the current head of the chain, returning an identifier which
+// can be used to revert to it later. The pending block is not part of snapshots.
+func (b *SimulatedBackend) Snapshot() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextSnapshot
+	b.nextSnapshot++
+	b.snapshots[id] = b.blockchain.CurrentBlock().Hash()
+	return id
+}
+
+// Revert rewinds the chain to the head recorded by the given snapshot, dropping
+// all the pending changes. The snapshot and all the ones taken after it are
+// discarded.
+func (b *SimulatedBackend) Revert(id uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hash, ok := b.snapshots[id]
+	if !ok {
+		return errUnknownSnapshot
+	}
+	for snap := range b.snapshots {
+		if snap >= id {
+			delete(b.snapshots, snap)
+		}
+	}
+	block := b.blockchain.GetBlockByHash(hash)
+	if block == nil {
+		return errBlockDoesNotExist
+	}
+	if err := b.blockchain.SetHead(block.NumberU64()); err != nil {
+		return err
+	}
+	b.rollback(b.blockchain.CurrentBlock())
+	return nil
+}
+
+// APIs returns the RPC services exposing the state manipulation methods of the
+// simulated backend under the "sim" namespace, for use by non-Go tooling.
+func (b *SimulatedBackend) APIs() []rpc.API {
+	return []rpc.API{{
+		Namespace: "sim",
+		Service:   &SimulatedAPI{b: b},
+	}}
+}
+
+// SimulatedAPI exposes the state manipulation methods of a simulated backend
+// over RPC.
+type SimulatedAPI struct {
+	b *SimulatedBackend
+}
+
+// SetBalance sets the balance of an account in the pending state.
+func (api *SimulatedAPI) SetBalance(account common.Address, balance hexutil.Big) {
+	api.b.SetBalance(account, (*big.Int)(&balance))
+}
+
+// SetNonce sets the nonce of an account in the pending state.
+func (api *SimulatedAPI) SetNonce(account common.Address, nonce hexutil.Uint64) {
+	api.b.SetNonce(account, uint64(nonce))
+}
+
+// SetCode sets the code of an account in the pending state.
+func (api *SimulatedAPI) SetCode(account common.Address, code hexutil.Bytes) {
+	api.b.SetCode(account, code)
+}
+
+// SetStorageAt sets the value of a storage slot of an account in the pending state.
+func (api *SimulatedAPI) SetStorageAt(account common.Address, key, value common.Hash) {
+	api.b.SetStorageAt(account, key, value)
+}
+
+// ImpersonatedTransactionArgs are the arguments of a transaction to send as an
+// arbitrary account. Missing fields are filled with sensible defaults.
+type ImpersonatedTransactionArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  *hexutil.Uint64 `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                *hexutil.Uint64 `json:"nonce"`
+	Data                 *hexutil.Bytes  `json:"data"`
+	Input                *hexutil.Bytes  `json:"input"`
+}
+
+// SendTransaction adds a transaction sent by an arbitrary account to the pending
+// block, without requiring a signature.
+func (api *SimulatedAPI) SendTransaction(ctx context.Context, args ImpersonatedTransactionArgs) (common.Hash, error) {
+	var (
+		data  []byte
+		value = new(big.Int)
+	)
+	if args.Input != nil {
+		data = *args.Input
+	} else if args.Data != nil {
+		data = *args.Data
+	}
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+	var nonce uint64
+	if args.Nonce != nil {
+		nonce = uint64(*args.Nonce)
+	} else {
+		pending, err := api.b.PendingNonceAt(ctx, args.From)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		nonce = pending
+	}
+	var gas uint64
+	if args.Gas != nil {
+		gas = uint64(*args.Gas)
+	} else {
+		estimate, err := api.b.EstimateGas(ctx, ethereum.CallMsg{From: args.From, To: args.To, Value: value, Data: data})
+		if err != nil {
+			return common.Hash{}, err
+		}
+		gas = estimate
+	}
+	var tx *types.Transaction
+	if args.GasPrice != nil {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: args.GasPrice.ToInt(),
+			Gas:      gas,
+			To:       args.To,
+			Value:    value,
+			Data:     data,
+		})
+	} else {
+		api.b.mu.Lock()
+		baseFee := api.b.pendingBlock.BaseFee()
+		api.b.mu.Unlock()
+
+		tip, _ := api.b.SuggestGasTipCap(ctx)
+		if args.MaxPriorityFeePerGas != nil {
+			tip = args.MaxPriorityFeePerGas.ToInt()
+		}
+		feeCap := new(big.Int).Add(tip, new(big.Int).Mul(baseFee, big.NewInt(2)))
+		if args.MaxFeePerGas != nil {
+			feeCap = args.MaxFeePerGas.ToInt()
+		}
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   api.b.config.ChainID,
+			Nonce:     nonce,
+			GasTipCap: tip,
+			GasFeeCap: feeCap,
+			Gas:       gas,
+			To:        args.To,
+			Value:     value,
+			Data:      data,
+		})
+	}
+	sent, err := api.b.SendImpersonatedTransaction(ctx, args.From, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return sent.Hash(), nil
+}
+
+// Mine commits the given number of blocks (1 if omitted), the first one holding
+// all the pending transactions and state changes.
+func (api *SimulatedAPI) Mine(blocks *hexutil.Uint64) common.Hash {
+	n := uint64(1)
+	if blocks != nil {
+		n = uint64(*blocks)
+	}
+	return api.b.Mine(n)
+}
+
+// SetNextBlockTimestamp sets the timestamp of the pending block.
+func (api *SimulatedAPI) SetNextBlockTimestamp(timestamp hexutil.Uint64) error {
+	return api.b.SetNextBlockTimestamp(uint64(timestamp))
+}
+
+// SetNextBlockBaseFee sets the base fee of the pending block.
+func (api *SimulatedAPI) SetNextBlockBaseFee(baseFee hexutil.Big) error {
+	return api.b.SetNextBlockBaseFee((*big.Int)(&baseFee))
+}
+
+// Snapshot records the current head of the chain, returning its identifier.
+func (api *SimulatedAPI) Snapshot() hexutil.Uint64 {
+	return hexutil.Uint64(api.b.Snapshot())
+}
+
+// Revert rewinds the chain to the head recorded by the given snapshot.
+func (api *SimulatedAPI) Revert(id hexutil.Uint64) error {
+	return api.b.Revert(uint64(id))
+}
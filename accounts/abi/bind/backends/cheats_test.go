@@ -0,0 +1,334 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backends
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestSetState(t *testing.T) {
+	sim := NewSimulatedBackend(core.GenesisAlloc{}, 10000000)
+	defer sim.Close()
+
+	var (
+		ctx      = context.Background()
+		testAddr = crypto.PubkeyToAddress(testKey.PublicKey)
+		contract = common.Address{0x01}
+		slot     = common.HexToHash("0x2a")
+		value    = common.HexToHash("0xdeadbeef")
+	)
+	sim.SetBalance(testAddr, big.NewInt(params.Ether))
+	sim.SetNonce(testAddr, 5)
+	sim.SetCode(contract, forkTestCode)
+	sim.SetStorageAt(contract, common.Hash{}, value)
+	sim.SetStorageAt(contract, slot, value)
+
+	if nonce, _ := sim.PendingNonceAt(ctx, testAddr); nonce != 5 {
+		t.Errorf("pending nonce mismatch: have %d, want %d", nonce, 5)
+	}
+	if code, _ := sim.PendingCodeAt(ctx, contract); !bytes.Equal(code, forkTestCode) {
+		t.Errorf("pending code mismatch: have %x, want %x", code, forkTestCode)
+	}
+	// Ensure state changes can be combined with regular transactions
+	head, _ := sim.HeaderByNumber(ctx, nil)
+	gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(1))
+
+	tx, _ := types.SignTx(types.NewTransaction(5, contract, new(big.Int), 100000, gasPrice, nil), types.HomesteadSigner{}, testKey)
+	if err := sim.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	sim.SetNonce(testAddr, 10)
+	sim.Commit()
+
+	if receipt, err := sim.TransactionReceipt(ctx, tx.Hash()); err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction not executed successfully: %v", err)
+	}
+	if nonce, _ := sim.NonceAt(ctx, testAddr, nil); nonce != 10 {
+		t.Errorf("nonce mismatch: have %d, want %d", nonce, 10)
+	}
+	if balance, _ := sim.BalanceAt(ctx, testAddr, nil); balance.Cmp(big.NewInt(params.Ether)) >= 0 || balance.Sign() == 0 {
+		t.Errorf("balance mismatch: have %v, want less than %v", balance, params.Ether)
+	}
+	if stored, _ := sim.StorageAt(ctx, contract, slot, nil); !bytes.Equal(stored, value[:]) {
+		t.Errorf("storage mismatch: have %x, want %x", stored, value)
+	}
+	out, err := sim.CallContract(ctx, ethereum.CallMsg{To: &contract}, nil)
+	if err != nil {
+		t.Fatalf("failed to call contract: %v", err)
+	}
+	if !bytes.Equal(out, value[:]) {
+		t.Errorf("call result mismatch: have %x, want %x", out, value)
+	}
+}
+
+func TestSendImpersonatedTransaction(t *testing.T) {
+	sim := NewSimulatedBackend(core.GenesisAlloc{}, 10000000)
+	defer sim.Close()
+
+	var (
+		ctx       = context.Background()
+		sender    = common.Address{0xaa}
+		recipient = common.Address{0xbb}
+	)
+	head, _ := sim.HeaderByNumber(ctx, nil)
+	gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(1))
+
+	// Ensure unfunded senders are rejected instead of aborting the block
+	transfer := types.NewTransaction(0, recipient, big.NewInt(1000), params.TxGas, gasPrice, nil)
+	if _, err := sim.SendImpersonatedTransaction(ctx, sender, transfer); err == nil {
+		t.Fatalf("unfunded transaction accepted")
+	}
+	sim.SetBalance(sender, big.NewInt(params.Ether))
+	sim.SetCode(sender, []byte{0x00}) // contracts may be impersonated too
+
+	sent, err := sim.SendImpersonatedTransaction(ctx, sender, transfer)
+	if err != nil {
+		t.Fatalf("failed to send impersonated transaction: %v", err)
+	}
+	create := types.NewContractCreation(1, new(big.Int), 100000, gasPrice, append(common.FromHex("6018600c60003960186000f3"), forkTestCode...))
+	created, err := sim.SendImpersonatedTransaction(ctx, sender, create)
+	if err != nil {
+		t.Fatalf("failed to send impersonated creation: %v", err)
+	}
+	// Ensure the same transaction of another sender is distinguishable
+	other := common.Address{0xcc}
+	sim.SetBalance(other, big.NewInt(params.Ether))
+
+	duplicate, err := sim.SendImpersonatedTransaction(ctx, other, transfer)
+	if err != nil {
+		t.Fatalf("failed to send impersonated transaction: %v", err)
+	}
+	if duplicate.Hash() == sent.Hash() {
+		t.Errorf("impersonated transactions of different senders have the same hash")
+	}
+	sim.Commit()
+
+	if balance, _ := sim.BalanceAt(ctx, recipient, nil); balance.Cmp(big.NewInt(2000)) != 0 {
+		t.Errorf("recipient balance mismatch: have %v, want %v", balance, 2000)
+	}
+	if nonce, _ := sim.NonceAt(ctx, sender, nil); nonce != 2 {
+		t.Errorf("sender nonce mismatch: have %d, want %d", nonce, 2)
+	}
+	receipt, err := sim.TransactionReceipt(ctx, created.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve creation receipt: %v", err)
+	}
+	if want := crypto.CreateAddress(sender, 1); receipt.ContractAddress != want {
+		t.Errorf("contract address mismatch: have %x, want %x", receipt.ContractAddress, want)
+	}
+	if code, _ := sim.CodeAt(ctx, receipt.ContractAddress, nil); !bytes.Equal(code, forkTestCode) {
+		t.Errorf("created code mismatch: have %x, want %x", code, forkTestCode)
+	}
+	if _, pending, err := sim.TransactionByHash(ctx, sent.Hash()); err != nil || pending {
+		t.Errorf("impersonated transaction not mined: pending %v, err %v", pending, err)
+	}
+}
+
+func TestMineAndRevert(t *testing.T) {
+	sim := NewSimulatedBackend(core.GenesisAlloc{}, 10000000)
+	defer sim.Close()
+
+	var (
+		ctx     = context.Background()
+		account = common.Address{0xaa}
+	)
+	snap := sim.Snapshot()
+
+	sim.SetBalance(account, big.NewInt(1))
+	sim.Mine(3)
+	if head := sim.Blockchain().CurrentBlock().NumberU64(); head != 3 {
+		t.Fatalf("head mismatch after mining: have %d, want %d", head, 3)
+	}
+	inner := sim.Snapshot()
+
+	sim.SetBalance(account, big.NewInt(2))
+	sim.Commit()
+	if balance, _ := sim.BalanceAt(ctx, account, nil); balance.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("balance mismatch: have %v, want %v", balance, 2)
+	}
+	if err := sim.Revert(inner); err != nil {
+		t.Fatalf("failed to revert to inner snapshot: %v", err)
+	}
+	if balance, _ := sim.BalanceAt(ctx, account, nil); balance.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("balance mismatch after revert: have %v, want %v", balance, 1)
+	}
+	if err := sim.Revert(inner); err != errUnknownSnapshot {
+		t.Errorf("reverted to discarded snapshot: %v", err)
+	}
+	sim.SetBalance(account, big.NewInt(3)) // pending changes are dropped on revert
+	if err := sim.Revert(snap); err != nil {
+		t.Fatalf("failed to revert to outer snapshot: %v", err)
+	}
+	if head := sim.Blockchain().CurrentBlock().NumberU64(); head != 0 {
+		t.Errorf("head mismatch after revert: have %d, want %d", head, 0)
+	}
+	if balance := sim.pendingState.GetBalance(account); balance.Sign() != 0 {
+		t.Errorf("pending balance mismatch after revert: have %v, want %v", balance, 0)
+	}
+	// Ensure the chain can be extended after reverting
+	sim.Mine(2)
+	if head := sim.Blockchain().CurrentBlock().NumberU64(); head != 2 {
+		t.Errorf("head mismatch after mining: have %d, want %d", head, 2)
+	}
+}
+
+func TestSetNextBlockHeader(t *testing.T) {
+	sim := NewSimulatedBackend(core.GenesisAlloc{}, 10000000)
+	defer sim.Close()
+
+	parent := sim.Blockchain().CurrentBlock()
+	if err := sim.SetNextBlockTimestamp(parent.Time()); err == nil {
+		t.Fatalf("timestamp of parent accepted")
+	}
+	timestamp := parent.Time() + 3600
+	if err := sim.SetNextBlockTimestamp(timestamp); err != nil {
+		t.Fatalf("failed to set timestamp: %v", err)
+	}
+	baseFee := big.NewInt(params.GWei)
+	if err := sim.SetNextBlockBaseFee(baseFee); err != nil {
+		t.Fatalf("failed to set base fee: %v", err)
+	}
+	sim.Commit()
+
+	head := sim.Blockchain().CurrentBlock()
+	if head.Time() != timestamp {
+		t.Errorf("timestamp mismatch: have %d, want %d", head.Time(), timestamp)
+	}
+	if head.BaseFee().Cmp(baseFee) != 0 {
+		t.Errorf("base fee mismatch: have %v, want %v", head.BaseFee(), baseFee)
+	}
+	// Ensure overrides only apply to the next block
+	sim.Commit()
+	if head := sim.Blockchain().CurrentBlock(); head.Time() != timestamp+10 {
+		t.Errorf("timestamp mismatch: have %d, want %d", head.Time(), timestamp+10)
+	}
+}
+
+func TestSetNextBlockBaseFeeUnaffordable(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		testAddr = crypto.PubkeyToAddress(testKey.PublicKey)
+	)
+	sim := NewSimulatedBackend(core.GenesisAlloc{}, 10000000)
+	defer sim.Close()
+
+	// Fund the sender with just enough for two transfers at the current base fee,
+	// the first one becoming more expensive with a higher base fee
+	head, _ := sim.HeaderByNumber(ctx, nil)
+	feeCap := new(big.Int).Mul(head.BaseFee, big.NewInt(10))
+	balance := new(big.Int).Add(head.BaseFee, feeCap)
+	sim.SetBalance(testAddr, balance.Mul(balance, new(big.Int).SetUint64(params.TxGas)))
+
+	var txs []*types.Transaction
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   sim.config.ChainID,
+			Nonce:     nonce,
+			To:        &common.Address{0x01},
+			Gas:       params.TxGas,
+			GasFeeCap: feeCap,
+			GasTipCap: new(big.Int),
+		})
+		tx, err := types.SignTx(tx, types.LatestSigner(sim.config), testKey)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		if err := sim.SendTransaction(ctx, tx); err != nil {
+			t.Fatalf("failed to send transaction: %v", err)
+		}
+		txs = append(txs, tx)
+	}
+	// Raising the base fee beyond the sender's means must fail without crashing
+	pending := sim.pendingBlock.Hash()
+	if err := sim.SetNextBlockBaseFee(new(big.Int).Mul(head.BaseFee, big.NewInt(2))); !errors.Is(err, core.ErrInsufficientFunds) {
+		t.Fatalf("unaffordable base fee error mismatch: have %v, want %v", err, core.ErrInsufficientFunds)
+	}
+	if sim.pendingBlock.Hash() != pending {
+		t.Errorf("pending block modified by failed base fee change")
+	}
+	// Ensure the pending block can still be mined with the original base fee
+	sim.Commit()
+	for i, tx := range txs {
+		if receipt, err := sim.TransactionReceipt(ctx, tx.Hash()); err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+			t.Errorf("transaction %d not mined: %v", i, err)
+		}
+	}
+}
+
+func TestSimulatedAPI(t *testing.T) {
+	sim := NewSimulatedBackend(core.GenesisAlloc{}, 10000000)
+	defer sim.Close()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	for _, api := range sim.APIs() {
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			t.Fatalf("failed to register API: %v", err)
+		}
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var (
+		ctx       = context.Background()
+		sender    = common.Address{0xaa}
+		recipient = common.Address{0xbb}
+	)
+	if err := client.CallContext(ctx, nil, "sim_setBalance", sender, (*hexutil.Big)(big.NewInt(params.Ether))); err != nil {
+		t.Fatalf("failed to set balance: %v", err)
+	}
+	var snap hexutil.Uint64
+	if err := client.CallContext(ctx, &snap, "sim_snapshot"); err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+	var hash common.Hash
+	args := map[string]interface{}{"from": sender, "to": recipient, "value": (*hexutil.Big)(big.NewInt(1000))}
+	if err := client.CallContext(ctx, &hash, "sim_sendTransaction", args); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if err := client.CallContext(ctx, nil, "sim_mine", hexutil.Uint64(2)); err != nil {
+		t.Fatalf("failed to mine blocks: %v", err)
+	}
+	if head := sim.Blockchain().CurrentBlock().NumberU64(); head != 2 {
+		t.Errorf("head mismatch: have %d, want %d", head, 2)
+	}
+	if receipt, err := sim.TransactionReceipt(ctx, hash); err != nil || receipt.BlockNumber.Uint64() != 1 {
+		t.Fatalf("transaction not mined in first block: %v", err)
+	}
+	if balance, _ := sim.BalanceAt(ctx, recipient, nil); balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("recipient balance mismatch: have %v, want %v", balance, 1000)
+	}
+	if err := client.CallContext(ctx, nil, "sim_revert", snap); err != nil {
+		t.Fatalf("failed to revert: %v", err)
+	}
+	if balance, _ := sim.BalanceAt(ctx, recipient, nil); balance.Sign() != 0 {
+		t.Errorf("recipient balance mismatch after revert: have %v, want %v", balance, 0)
+	}
+}
@@ -0,0 +1,503 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ForkSource is the upstream state a forked simulated backend lazily retrieves
+// accounts, code and storage from. It is implemented by *ethclient.Client and by
+// recorded fork fixtures.
+type ForkSource interface {
+	ethereum.ChainStateReader
+
+	ChainID(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// ForkFixture is a recording of all the upstream state accessed by a forked
+// simulated backend. It can be used as the fork source of later runs to replay
+// them offline, without access to the upstream node.
+type ForkFixture struct {
+	Chain    *hexutil.Big                    `json:"chainId"` // Chain ID of the forked chain
+	Header   *types.Header                   `json:"header"`
+	Accounts map[common.Address]*ForkAccount `json:"accounts"`
+}
+
+// ForkAccount is the upstream state of a single account. Storage only contains
+// the slots accessed during the recording.
+type ForkAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   hexutil.Uint64              `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// exists reports whether the account is present in the upstream state.
+func (acc *ForkAccount) exists() bool {
+	return acc.Nonce != 0 || acc.Balance.ToInt().Sign() != 0 || len(acc.Code) != 0
+}
+
+// copy creates a deep copy of the account.
+func (acc *ForkAccount) copy() *ForkAccount {
+	cpy := &ForkAccount{
+		Balance: (*hexutil.Big)(new(big.Int).Set(acc.Balance.ToInt())),
+		Nonce:   acc.Nonce,
+		Code:    common.CopyBytes(acc.Code),
+	}
+	if len(acc.Storage) > 0 {
+		cpy.Storage = make(map[common.Hash]common.Hash, len(acc.Storage))
+		for key, val := range acc.Storage {
+			cpy.Storage[key] = val
+		}
+	}
+	return cpy
+}
+
+// ChainID returns the recorded chain ID of the forked chain.
+func (f *ForkFixture) ChainID(ctx context.Context) (*big.Int, error) {
+	if f.Chain == nil {
+		return nil, errors.New("chain ID not recorded in fork fixture")
+	}
+	return new(big.Int).Set(f.Chain.ToInt()), nil
+}
+
+// HeaderByNumber returns the recorded header of the forked block.
+func (f *ForkFixture) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number != nil && number.Cmp(f.Header.Number) != 0 {
+		return nil, fmt.Errorf("block %d not recorded in fork fixture of block %d", number, f.Header.Number)
+	}
+	return f.Header, nil
+}
+
+// BalanceAt returns the recorded balance of an account.
+func (f *ForkFixture) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	acc, err := f.account(account)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(acc.Balance.ToInt()), nil
+}
+
+// NonceAt returns the recorded nonce of an account.
+func (f *ForkFixture) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	acc, err := f.account(account)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(acc.Nonce), nil
+}
+
+// CodeAt returns the recorded code of an account.
+func (f *ForkFixture) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	acc, err := f.account(account)
+	if err != nil {
+		return nil, err
+	}
+	return common.CopyBytes(acc.Code), nil
+}
+
+// StorageAt returns the recorded value of a storage slot.
+func (f *ForkFixture) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	acc, err := f.account(account)
+	if err != nil {
+		return nil, err
+	}
+	val, ok := acc.Storage[key]
+	if !ok {
+		return nil, fmt.Errorf("storage slot %x of account %x not recorded in fork fixture", key, account)
+	}
+	return val.Bytes(), nil
+}
+
+// account retrieves a recorded account, failing if the account was never accessed
+// during the recording (i.e. the fixture went stale).
+func (f *ForkFixture) account(addr common.Address) (*ForkAccount, error) {
+	acc, ok := f.Accounts[addr]
+	if !ok {
+		return nil, fmt.Errorf("account %x not recorded in fork fixture", addr)
+	}
+	return acc, nil
+}
+
+// forkState is the cache of the upstream state accessed by a forked simulated
+// backend, shared by all the local states built on top of the fork.
+type forkState struct {
+	source  ForkSource
+	chainID *big.Int             // Chain ID of the upstream chain
+	header  *types.Header        // Header of the upstream block the state is pinned to
+	db      ethdb.KeyValueWriter // Database to store the upstream contract code into
+
+	lock       sync.Mutex
+	accounts   map[common.Address]*ForkAccount // Upstream accounts fetched so far
+	addresses  map[common.Hash]common.Address  // Address preimages of the existing upstream accounts
+	destructed map[common.Address]bool         // Upstream accounts whose storage was wiped locally
+}
+
+// newForkState creates the upstream state cache pinned to the given block.
+func newForkState(source ForkSource, chainID *big.Int, header *types.Header, db ethdb.KeyValueWriter) *forkState {
+	return &forkState{
+		source:     source,
+		chainID:    chainID,
+		header:     header,
+		db:         db,
+		accounts:   make(map[common.Address]*ForkAccount),
+		addresses:  make(map[common.Hash]common.Address),
+		destructed: make(map[common.Address]bool),
+	}
+}
+
+// account retrieves an upstream account, fetching it from the source if it was
+// not accessed yet. The lock must be held by the caller.
+func (f *forkState) account(addr common.Address) (*ForkAccount, error) {
+	if acc, ok := f.accounts[addr]; ok {
+		return acc, nil
+	}
+	var (
+		ctx    = context.Background()
+		number = f.header.Number
+	)
+	balance, err := f.source.BalanceAt(ctx, addr, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch forked balance of %x: %v", addr, err)
+	}
+	nonce, err := f.source.NonceAt(ctx, addr, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch forked nonce of %x: %v", addr, err)
+	}
+	code, err := f.source.CodeAt(ctx, addr, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch forked code of %x: %v", addr, err)
+	}
+	acc := &ForkAccount{
+		Balance: (*hexutil.Big)(balance),
+		Nonce:   hexutil.Uint64(nonce),
+		Code:    code,
+	}
+	if len(code) > 0 {
+		rawdb.WriteCode(f.db, crypto.Keccak256Hash(code), code)
+	}
+	if acc.exists() {
+		f.addresses[crypto.Keccak256Hash(addr[:])] = addr
+	}
+	f.accounts[addr] = acc
+	return acc, nil
+}
+
+// stateAccount retrieves the consensus representation of an upstream account,
+// or nil if the account does not exist upstream. The storage root is that of
+// an empty trie, storage slots being retrieved individually on demand.
+func (f *forkState) stateAccount(addr common.Address) (*types.StateAccount, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	acc, err := f.account(addr)
+	if err != nil || !acc.exists() {
+		return nil, err
+	}
+	return &types.StateAccount{
+		Nonce:    uint64(acc.Nonce),
+		Balance:  new(big.Int).Set(acc.Balance.ToInt()),
+		Root:     types.EmptyRootHash,
+		CodeHash: crypto.Keccak256(acc.Code),
+	}, nil
+}
+
+// storage retrieves an upstream storage slot of an account, fetching it from the
+// source if it was not accessed yet.
+func (f *forkState) storage(addr common.Address, key common.Hash) (common.Hash, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	acc, err := f.account(addr)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if val, ok := acc.Storage[key]; ok {
+		return val, nil
+	}
+	blob, err := f.source.StorageAt(context.Background(), addr, key, f.header.Number)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to fetch forked storage slot %x of %x: %v", key, addr, err)
+	}
+	if acc.Storage == nil {
+		acc.Storage = make(map[common.Hash]common.Hash)
+	}
+	val := common.BytesToHash(blob)
+	acc.Storage[key] = val
+	return val, nil
+}
+
+// upstream reports whether the account exists in the upstream state.
+func (f *forkState) upstream(addr common.Address) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	acc, ok := f.accounts[addr]
+	return ok && acc.exists()
+}
+
+// address resolves the address of an account from its hash, if the account has
+// upstream storage which was not wiped locally.
+func (f *forkState) address(addrHash common.Hash) (common.Address, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	addr, ok := f.addresses[addrHash]
+	if !ok || f.destructed[addr] {
+		return common.Address{}, false
+	}
+	return addr, true
+}
+
+// destruct marks the upstream storage of an account as wiped locally.
+//
+// Note, this is not tracked per state, so a destructed account does not regain
+// its upstream storage even if the chain is reorged to before the destruction.
+func (f *forkState) destruct(addr common.Address) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.destructed[addr] = true
+}
+
+// blockContext modifies the EVM block context of a local block to report the
+// upstream block numbers, the local genesis standing in for the forked block.
+// Hashes of the blocks preceding the forked one are not available.
+func (f *forkState) blockContext(ctx *vm.BlockContext) {
+	var (
+		number  = f.header.Number.Uint64()
+		getHash = ctx.GetHash
+	)
+	ctx.BlockNumber = new(big.Int).Add(ctx.BlockNumber, f.header.Number)
+	ctx.GetHash = func(n uint64) common.Hash {
+		switch {
+		case n > number:
+			return getHash(n - number)
+		case n == number:
+			return f.header.Hash()
+		default:
+			return common.Hash{}
+		}
+	}
+}
+
+// fixture exports all the upstream state accessed so far.
+func (f *forkState) fixture() *ForkFixture {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	fixture := &ForkFixture{
+		Chain:    (*hexutil.Big)(new(big.Int).Set(f.chainID)),
+		Header:   types.CopyHeader(f.header),
+		Accounts: make(map[common.Address]*ForkAccount, len(f.accounts)),
+	}
+	for addr, acc := range f.accounts {
+		fixture.Accounts[addr] = acc.copy()
+	}
+	return fixture
+}
+
+// forkDatabase is a state database which falls back to the upstream state of a
+// fork for all the accounts and storage slots not present locally.
+type forkDatabase struct {
+	state.Database
+	fork *forkState
+}
+
+// newForkDatabase wraps a state database to fall back to the given fork.
+func newForkDatabase(db state.Database, fork *forkState) *forkDatabase {
+	return &forkDatabase{Database: db, fork: fork}
+}
+
+// OpenTrie opens the main account trie, falling back to the upstream accounts.
+func (db *forkDatabase) OpenTrie(root common.Hash) (state.Trie, error) {
+	tr, err := db.Database.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	return &forkAccountTrie{Trie: tr, fork: db.fork}, nil
+}
+
+// OpenStorageTrie opens the storage trie of an account, falling back to the
+// upstream storage slots of the account.
+func (db *forkDatabase) OpenStorageTrie(stateRoot common.Hash, addrHash, root common.Hash) (state.Trie, error) {
+	tr, err := db.Database.OpenStorageTrie(stateRoot, addrHash, root)
+	if err != nil {
+		return nil, err
+	}
+	return &forkStorageTrie{Trie: tr, fork: db.fork, addrHash: addrHash}, nil
+}
+
+// CopyTrie returns an independent copy of the given trie.
+func (db *forkDatabase) CopyTrie(t state.Trie) state.Trie {
+	switch t := t.(type) {
+	case *forkAccountTrie:
+		return &forkAccountTrie{Trie: db.Database.CopyTrie(t.Trie), fork: t.fork}
+	case *forkStorageTrie:
+		return &forkStorageTrie{Trie: db.Database.CopyTrie(t.Trie), fork: t.fork, addrHash: t.addrHash}
+	default:
+		return db.Database.CopyTrie(t)
+	}
+}
+
+// ContractCodeWithPrefix retrieves a particular contract's code, if the wrapped
+// database supports it.
+func (db *forkDatabase) ContractCodeWithPrefix(addrHash, codeHash common.Hash) ([]byte, error) {
+	type codeReader interface {
+		ContractCodeWithPrefix(addrHash, codeHash common.Hash) ([]byte, error)
+	}
+	if reader, ok := db.Database.(codeReader); ok {
+		return reader.ContractCodeWithPrefix(addrHash, codeHash)
+	}
+	return db.Database.ContractCode(addrHash, codeHash)
+}
+
+// forkAccountTrie is an account trie falling back to the upstream accounts.
+type forkAccountTrie struct {
+	state.Trie
+	fork *forkState
+}
+
+// TryGetAccount returns the local account if present, or the upstream one.
+func (t *forkAccountTrie) TryGetAccount(key []byte) (*types.StateAccount, error) {
+	acc, err := t.Trie.TryGetAccount(key)
+	if err != nil || acc != nil {
+		return acc, err
+	}
+	return t.fork.stateAccount(common.BytesToAddress(key))
+}
+
+// TryDeleteAccount removes an account from the trie. Accounts present upstream
+// are overwritten with an empty account instead, preventing the upstream one
+// from being resurrected.
+func (t *forkAccountTrie) TryDeleteAccount(key []byte) error {
+	addr := common.BytesToAddress(key)
+	if !t.fork.upstream(addr) {
+		return t.Trie.TryDeleteAccount(key)
+	}
+	t.fork.destruct(addr)
+	return t.Trie.TryUpdateAccount(key, &types.StateAccount{
+		Balance:  new(big.Int),
+		Root:     types.EmptyRootHash,
+		CodeHash: crypto.Keccak256(nil),
+	})
+}
+
+// forkStorageTrie is a storage trie falling back to the upstream storage slots
+// of its account.
+type forkStorageTrie struct {
+	state.Trie
+	fork     *forkState
+	addrHash common.Hash
+}
+
+// TryGet returns the local value of a storage slot if present, or the upstream one.
+func (t *forkStorageTrie) TryGet(key []byte) ([]byte, error) {
+	enc, err := t.Trie.TryGet(key)
+	if err != nil || enc != nil {
+		return enc, err
+	}
+	addr, ok := t.fork.address(t.addrHash)
+	if !ok {
+		return nil, nil
+	}
+	val, err := t.fork.storage(addr, common.BytesToHash(key))
+	if err != nil || val == (common.Hash{}) {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(common.TrimLeftZeroes(val[:]))
+}
+
+// TryDelete removes a storage slot from the trie. Slots of upstream accounts are
+// overwritten with an empty value instead, preventing the upstream value from
+// being resurrected.
+func (t *forkStorageTrie) TryDelete(key []byte) error {
+	if _, ok := t.fork.address(t.addrHash); !ok {
+		return t.Trie.TryDelete(key)
+	}
+	return t.Trie.TryUpdate(key, []byte{0x80})
+}
+
+// NewForkedSimulatedBackend creates a new binding backend on top of the state of
+// an upstream chain at the given block (latest if nil). Accounts, code and storage
+// are lazily retrieved from the source on first access and cached, while all the
+// local changes are applied on top. The accounts in alloc fully override their
+// upstream counterparts.
+//
+// The upstream state is only read at the pinned block, the local chain starting
+// from its own genesis with the chain ID, timestamp and base fee of the pinned
+// block. Contracts see the local blocks numbered on top of the pinned one, while
+// the backend itself numbers them from zero.
+func NewForkedSimulatedBackend(source ForkSource, block *big.Int, alloc core.GenesisAlloc, gasLimit uint64) (*SimulatedBackend, error) {
+	chainID, err := source.ChainID(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve fork chain ID: %v", err)
+	}
+	header, err := source.HeaderByNumber(context.Background(), block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve fork block: %v", err)
+	}
+	var (
+		database = rawdb.NewMemoryDatabase()
+		fork     = newForkState(source, chainID, header, database)
+		config   = *params.AllEthashProtocolChanges
+	)
+	config.ChainID = chainID
+
+	genesis := core.Genesis{
+		Config:    &config,
+		Timestamp: header.Time,
+		GasLimit:  gasLimit,
+		BaseFee:   header.BaseFee,
+		Alloc:     alloc,
+	}
+	cacheConfig := &core.CacheConfig{
+		TrieCleanLimit: 256,
+		TrieDirtyLimit: 256,
+		TrieTimeLimit:  5 * time.Minute,
+	}
+	return newSimulatedBackend(database, &genesis, cacheConfig, fork), nil
+}
+
+// ForkFixture exports all the upstream state accessed so far by a forked backend,
+// or nil if the backend is not forked.
+func (b *SimulatedBackend) ForkFixture() *ForkFixture {
+	if b.fork == nil {
+		return nil
+	}
+	return b.fork.fixture()
+}
@@ -0,0 +1,295 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backends
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// forkTestCode is a contract which returns its first storage slot when called
+// without input, and stores the first word of the input into it otherwise.
+var forkTestCode = common.FromHex("3615600c57600035600055005b60005460005260206000f3")
+
+var (
+	forkTestContract = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	forkTestAccount  = common.HexToAddress("0x1000000000000000000000000000000000000002")
+)
+
+// testForkSource is an upstream chain serving a predefined state, and counting
+// the requests made to it.
+type testForkSource struct {
+	header   *types.Header
+	accounts map[common.Address]*ForkAccount
+	requests int
+}
+
+func newTestForkSource() *testForkSource {
+	return &testForkSource{
+		header: &types.Header{
+			Number:     big.NewInt(16000000),
+			Time:       1670000000,
+			GasLimit:   30000000,
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+			Difficulty: new(big.Int),
+		},
+		accounts: map[common.Address]*ForkAccount{
+			forkTestContract: {
+				Balance: (*hexutil.Big)(new(big.Int)),
+				Nonce:   1,
+				Code:    forkTestCode,
+				Storage: map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(42))},
+			},
+			forkTestAccount: {
+				Balance: (*hexutil.Big)(big.NewInt(params.Ether)),
+				Nonce:   7,
+			},
+		},
+	}
+}
+
+func (s *testForkSource) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (s *testForkSource) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return s.header, nil
+}
+
+func (s *testForkSource) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	s.requests++
+	if acc, ok := s.accounts[account]; ok {
+		return new(big.Int).Set(acc.Balance.ToInt()), nil
+	}
+	return new(big.Int), nil
+}
+
+func (s *testForkSource) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	s.requests++
+	if acc, ok := s.accounts[account]; ok {
+		return uint64(acc.Nonce), nil
+	}
+	return 0, nil
+}
+
+func (s *testForkSource) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	s.requests++
+	if acc, ok := s.accounts[account]; ok {
+		return common.CopyBytes(acc.Code), nil
+	}
+	return nil, nil
+}
+
+func (s *testForkSource) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	s.requests++
+	if acc, ok := s.accounts[account]; ok {
+		val := acc.Storage[key]
+		return val.Bytes(), nil
+	}
+	return make([]byte, common.HashLength), nil
+}
+
+// checkForkedState verifies that the upstream state and the local changes are
+// both visible through the backend.
+func checkForkedState(t *testing.T, sim *SimulatedBackend, slot int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	balance, err := sim.BalanceAt(ctx, forkTestAccount, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve forked balance: %v", err)
+	}
+	if balance.Cmp(big.NewInt(params.Ether)) != 0 {
+		t.Errorf("forked balance mismatch: have %v, want %v", balance, params.Ether)
+	}
+	nonce, err := sim.NonceAt(ctx, forkTestAccount, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve forked nonce: %v", err)
+	}
+	if nonce != 7 {
+		t.Errorf("forked nonce mismatch: have %d, want %d", nonce, 7)
+	}
+	code, err := sim.CodeAt(ctx, forkTestContract, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve forked code: %v", err)
+	}
+	if !bytes.Equal(code, forkTestCode) {
+		t.Errorf("forked code mismatch: have %x, want %x", code, forkTestCode)
+	}
+	want := common.BigToHash(big.NewInt(slot))
+	stored, err := sim.StorageAt(ctx, forkTestContract, common.Hash{}, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve forked storage: %v", err)
+	}
+	if !bytes.Equal(stored, want[:]) {
+		t.Errorf("forked storage mismatch: have %x, want %x", stored, want)
+	}
+	out, err := sim.CallContract(ctx, ethereum.CallMsg{To: &forkTestContract}, nil)
+	if err != nil {
+		t.Fatalf("failed to call forked contract: %v", err)
+	}
+	if !bytes.Equal(out, want[:]) {
+		t.Errorf("forked call result mismatch: have %x, want %x", out, want)
+	}
+}
+
+// sendForkTestStore sends a transaction storing a value into the forked contract.
+func sendForkTestStore(t *testing.T, sim *SimulatedBackend, nonce uint64, value int64) {
+	t.Helper()
+
+	head, _ := sim.HeaderByNumber(context.Background(), nil)
+	gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(1))
+
+	input := common.BigToHash(big.NewInt(value))
+	tx, err := types.SignTx(types.NewTransaction(nonce, forkTestContract, new(big.Int), 100000, gasPrice, input[:]), types.HomesteadSigner{}, testKey)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	if err := sim.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+}
+
+func TestForkedSimulatedBackend(t *testing.T) {
+	var (
+		source = newTestForkSource()
+		alloc  = core.GenesisAlloc{crypto.PubkeyToAddress(testKey.PublicKey): {Balance: big.NewInt(params.Ether)}}
+	)
+	sim, err := NewForkedSimulatedBackend(source, nil, alloc, 10000000)
+	if err != nil {
+		t.Fatalf("failed to create forked backend: %v", err)
+	}
+	defer sim.Close()
+
+	head, _ := sim.HeaderByNumber(context.Background(), nil)
+	if head.Time != source.header.Time {
+		t.Errorf("genesis time mismatch: have %d, want %d", head.Time, source.header.Time)
+	}
+	checkForkedState(t, sim, 42)
+
+	// Ensure the upstream state is cached after the first access
+	requests := source.requests
+	checkForkedState(t, sim, 42)
+	if source.requests != requests {
+		t.Errorf("upstream state refetched: have %d requests, want %d", source.requests, requests)
+	}
+	// Ensure local changes override the upstream state, even when clearing slots
+	sendForkTestStore(t, sim, 0, 0)
+	sim.Commit()
+	checkForkedState(t, sim, 0)
+
+	sendForkTestStore(t, sim, 1, 3)
+	sim.Rollback()
+	checkForkedState(t, sim, 0)
+
+	sendForkTestStore(t, sim, 1, 3)
+	sim.Commit()
+	checkForkedState(t, sim, 3)
+
+	if err := sim.AdjustTime(time.Hour); err != nil {
+		t.Fatalf("failed to adjust time: %v", err)
+	}
+	sim.Commit()
+	checkForkedState(t, sim, 3)
+
+	// Ensure the accessed upstream state can be replayed offline
+	blob, err := json.Marshal(sim.ForkFixture())
+	if err != nil {
+		t.Fatalf("failed to encode fork fixture: %v", err)
+	}
+	fixture := new(ForkFixture)
+	if err := json.Unmarshal(blob, fixture); err != nil {
+		t.Fatalf("failed to decode fork fixture: %v", err)
+	}
+	replay, err := NewForkedSimulatedBackend(fixture, nil, alloc, 10000000)
+	if err != nil {
+		t.Fatalf("failed to create replayed backend: %v", err)
+	}
+	defer replay.Close()
+
+	checkForkedState(t, replay, 42)
+	if _, err := replay.BalanceAt(context.Background(), common.Address{0xff}, nil); err == nil {
+		t.Errorf("unrecorded account retrieved from fixture")
+	}
+}
+
+// forkTestContextCode is a contract returning the chain ID and the block number
+// it is executed in, and storing the block number when called with any input.
+var forkTestContextCode = common.FromHex("3615600a5743600055005b466000524360205260406000f3")
+
+func TestForkedSimulatedBackendContext(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		source   = newTestForkSource()
+		contract = common.HexToAddress("0x1000000000000000000000000000000000000003")
+		alloc    = core.GenesisAlloc{
+			crypto.PubkeyToAddress(testKey.PublicKey): {Balance: big.NewInt(params.Ether)},
+			contract: {Balance: new(big.Int), Code: forkTestContextCode},
+		}
+	)
+	sim, err := NewForkedSimulatedBackend(source, nil, alloc, 10000000)
+	if err != nil {
+		t.Fatalf("failed to create forked backend: %v", err)
+	}
+	defer sim.Close()
+
+	// Ensure calls see the upstream chain ID and block number
+	out, err := sim.CallContract(ctx, ethereum.CallMsg{To: &contract}, nil)
+	if err != nil {
+		t.Fatalf("failed to call contract: %v", err)
+	}
+	if chainID := new(big.Int).SetBytes(out[:32]); chainID.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("chain ID mismatch: have %v, want %v", chainID, 1)
+	}
+	if number := new(big.Int).SetBytes(out[32:]); number.Cmp(source.header.Number) != 0 {
+		t.Errorf("block number mismatch: have %v, want %v", number, source.header.Number)
+	}
+	// Ensure transactions see the local blocks numbered on top of the fork
+	head, _ := sim.HeaderByNumber(ctx, nil)
+	gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(1))
+
+	tx := types.NewTx(&types.LegacyTx{To: &contract, Gas: 100000, GasPrice: gasPrice, Data: []byte{1}})
+	tx, err = types.SignTx(tx, types.LatestSigner(sim.config), testKey)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	if err := sim.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	sim.Commit()
+
+	want := common.BigToHash(new(big.Int).Add(source.header.Number, common.Big1))
+	stored, err := sim.StorageAt(ctx, contract, common.Hash{}, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve storage: %v", err)
+	}
+	if !bytes.Equal(stored, want[:]) {
+		t.Errorf("stored block number mismatch: have %x, want %x", stored, want)
+	}
+}
@@ -62,12 +62,23 @@ var (
 type SimulatedBackend struct {
 	database   ethdb.Database   // In memory database to store our testing data
 	blockchain *core.BlockChain // Ethereum blockchain to handle the consensus
+	fork       *forkState       // Upstream state the chain is forked from, nil if not forked
+	forkCache  state.Database   // State database falling back to the fork, nil if not forked
 
 	mu              sync.Mutex
 	pendingBlock    *types.Block   // Currently pending block that will be imported on request
 	pendingState    *state.StateDB // Currently pending state that will be the active on request
 	pendingReceipts types.Receipts // Currently receipts for the pending block
 
+	pendingActions   []func(*core.BlockGen) // Transactions and state changes of the pending block, in order
+	pendingTime      uint64                 // Timestamp override of the pending block (0 = default)
+	pendingBaseFee   *big.Int               // Base fee override of the pending block (nil = default)
+	pendingUnchecked bool                   // Whether the pending block cannot be reproduced by block processing
+
+	impersonatedCreations map[common.Hash]common.Address // Contracts created by impersonated transactions
+	snapshots             map[uint64]common.Hash         // Chain heads recorded by snapshot identifiers
+	nextSnapshot          uint64                         // Identifier of the next snapshot to take
+
 	events       *filters.EventSystem  // for filtering log events live
 	filterSystem *filters.FilterSystem // for filtering database logs
 
@@ -83,14 +94,28 @@ func NewSimulatedBackendWithDatabase(database ethdb.Database, alloc core.Genesis
 		GasLimit: gasLimit,
 		Alloc:    alloc,
 	}
-	blockchain, _ := core.NewBlockChain(database, nil, &genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	return newSimulatedBackend(database, &genesis, nil, nil)
+}
+
+// newSimulatedBackend creates a new binding backend with the given genesis and
+// cache configuration, optionally on top of the state of a forked chain.
+func newSimulatedBackend(database ethdb.Database, genesis *core.Genesis, cacheConfig *core.CacheConfig, fork *forkState) *SimulatedBackend {
+	blockchain, _ := core.NewBlockChain(database, cacheConfig, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
 
 	backend := &SimulatedBackend{
 		database:   database,
 		blockchain: blockchain,
+		fork:       fork,
 		config:     genesis.Config,
+
+		impersonatedCreations: make(map[common.Hash]common.Address),
+		snapshots:             make(map[uint64]common.Hash),
+		nextSnapshot:          1,
 	}
 
+	if fork != nil {
+		backend.forkCache = newForkDatabase(blockchain.StateCache(), fork)
+	}
 	filterBackend := &filterBackend{database, blockchain, backend}
 	backend.filterSystem = filters.NewFilterSystem(filterBackend, filters.Config{})
 	backend.events = filters.NewEventSystem(backend.filterSystem, false)
@@ -118,7 +143,13 @@ func (b *SimulatedBackend) Commit() common.Hash {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if _, err := b.blockchain.InsertChain([]*types.Block{b.pendingBlock}); err != nil {
+	return b.commit()
+}
+
+// commit imports the pending block into the chain and starts a fresh new state
+// on top of it. The lock must be held by the caller.
+func (b *SimulatedBackend) commit() common.Hash {
+	if err := b.insertPending(); err != nil {
 		panic(err) // This cannot happen unless the simulator is wrong, fail in that case
 	}
 	blockHash := b.pendingBlock.Hash()
@@ -130,6 +161,34 @@ func (b *SimulatedBackend) Commit() common.Hash {
 	return blockHash
 }
 
+// insertPending imports the pending block into the chain. Blocks with changes
+// which cannot be reproduced by block processing (state overrides, impersonated
+// transactions, forked state, etc) are written directly along with their
+// generated state.
+func (b *SimulatedBackend) insertPending() error {
+	if !b.pendingUnchecked && b.fork == nil {
+		_, err := b.blockchain.InsertChain([]*types.Block{b.pendingBlock})
+		return err
+	}
+	var (
+		hash = b.pendingBlock.Hash()
+		logs []*types.Log
+	)
+	for _, receipt := range b.pendingReceipts {
+		receipt.BlockHash = hash
+		for _, l := range receipt.Logs {
+			l.BlockHash = hash
+		}
+		logs = append(logs, receipt.Logs...)
+	}
+	stateDB, err := b.blockchain.StateAt(b.pendingBlock.Root())
+	if err != nil {
+		return err
+	}
+	_, err = b.blockchain.WriteBlockAndSetHead(b.pendingBlock, b.pendingReceipts, logs, stateDB, true)
+	return err
+}
+
 // Rollback aborts all pending transactions, reverting to the last committed state.
 func (b *SimulatedBackend) Rollback() {
 	b.mu.Lock()
@@ -139,10 +198,56 @@ func (b *SimulatedBackend) Rollback() {
 }
 
 func (b *SimulatedBackend) rollback(parent *types.Block) {
-	blocks, _ := core.GenerateChain(b.config, parent, ethash.NewFaker(), b.database, 1, func(int, *core.BlockGen) {})
+	b.pendingActions = nil
+	b.pendingTime, b.pendingBaseFee = 0, nil
+	b.pendingUnchecked = false
+
+	b.generatePending(parent, 0)
+}
 
+// generatePending regenerates the pending block on top of parent, replaying all
+// the pending transactions and state changes. The block time is shifted by the
+// given number of seconds.
+func (b *SimulatedBackend) generatePending(parent *types.Block, offset int64) {
+	blocks, receipts := b.generateBlock(parent, func(number int, block *core.BlockGen) {
+		if b.pendingTime != 0 && b.pendingTime != block.Timestamp() {
+			block.OffsetTime(int64(b.pendingTime) - int64(block.Timestamp()))
+		}
+		if offset != 0 {
+			block.OffsetTime(offset)
+		}
+		if b.pendingBaseFee != nil {
+			block.SetBaseFee(b.pendingBaseFee)
+		}
+		for _, action := range b.pendingActions {
+			action(block)
+		}
+	})
 	b.pendingBlock = blocks[0]
-	b.pendingState, _ = state.New(b.pendingBlock.Root(), b.blockchain.StateCache(), nil)
+	b.pendingState, _ = b.stateAt(b.pendingBlock.Root())
+	b.pendingReceipts = receipts[0]
+}
+
+// generateBlock creates a new block on top of parent, accessing the state through
+// the fork if the backend is forked.
+func (b *SimulatedBackend) generateBlock(parent *types.Block, gen func(int, *core.BlockGen)) ([]*types.Block, []types.Receipts) {
+	if b.fork == nil {
+		return core.GenerateChain(b.config, parent, ethash.NewFaker(), b.database, 1, gen)
+	}
+	sdb := newForkDatabase(state.NewDatabase(b.database), b.fork)
+	return core.GenerateChainWithStateDatabase(b.config, parent, ethash.NewFaker(), sdb, 1, func(i int, block *core.BlockGen) {
+		block.OverrideBlockContext(b.fork.blockContext)
+		gen(i, block)
+	})
+}
+
+// stateAt returns the state at the given root, accessed through the fork if the
+// backend is forked.
+func (b *SimulatedBackend) stateAt(root common.Hash) (*state.StateDB, error) {
+	if b.fork == nil {
+		return b.blockchain.StateAt(root)
+	}
+	return state.New(root, b.forkCache, nil)
 }
 
 // Fork creates a side-chain that can be used to simulate reorgs.
@@ -175,13 +280,13 @@ func (b *SimulatedBackend) Fork(ctx context.Context, parent common.Hash) error {
 // stateByBlockNumber retrieves a state by a given blocknumber.
 func (b *SimulatedBackend) stateByBlockNumber(ctx context.Context, blockNumber *big.Int) (*state.StateDB, error) {
 	if blockNumber == nil || blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) == 0 {
-		return b.blockchain.State()
+		return b.stateAt(b.blockchain.CurrentBlock().Root())
 	}
 	block, err := b.blockByNumber(ctx, blockNumber)
 	if err != nil {
 		return nil, err
 	}
-	return b.blockchain.StateAt(block.Root())
+	return b.stateAt(block.Root())
 }
 
 // CodeAt returns the code associated with a certain account in the blockchain.
@@ -194,7 +299,8 @@ func (b *SimulatedBackend) CodeAt(ctx context.Context, contract common.Address,
 		return nil, err
 	}
 
-	return stateDB.GetCode(contract), nil
+	// State errors are only set when retrieving a forked upstream state fails
+	return stateDB.GetCode(contract), stateDB.Error()
 }
 
 // BalanceAt returns the wei balance of a certain account in the blockchain.
@@ -207,7 +313,7 @@ func (b *SimulatedBackend) BalanceAt(ctx context.Context, contract common.Addres
 		return nil, err
 	}
 
-	return stateDB.GetBalance(contract), nil
+	return stateDB.GetBalance(contract), stateDB.Error()
 }
 
 // NonceAt returns the nonce of a certain account in the blockchain.
@@ -220,7 +326,7 @@ func (b *SimulatedBackend) NonceAt(ctx context.Context, contract common.Address,
 		return 0, err
 	}
 
-	return stateDB.GetNonce(contract), nil
+	return stateDB.GetNonce(contract), stateDB.Error()
 }
 
 // StorageAt returns the value of key in the storage of an account in the blockchain.
@@ -234,7 +340,7 @@ func (b *SimulatedBackend) StorageAt(ctx context.Context, contract common.Addres
 	}
 
 	val := stateDB.GetState(contract, key)
-	return val[:], nil
+	return val[:], stateDB.Error()
 }
 
 // TransactionReceipt returns the receipt of a transaction.
@@ -246,6 +352,11 @@ func (b *SimulatedBackend) TransactionReceipt(ctx context.Context, txHash common
 	if receipt == nil {
 		return nil, ethereum.NotFound
 	}
+	// Contract addresses are derived from the transaction signatures, which are
+	// meaningless for impersonated transactions
+	if addr, ok := b.impersonatedCreations[txHash]; ok {
+		receipt.ContractAddress = addr
+	}
 	return receipt, nil
 }
 
@@ -434,7 +545,7 @@ func (b *SimulatedBackend) CallContract(ctx context.Context, call ethereum.CallM
 	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
 		return nil, errBlockNumberUnsupported
 	}
-	stateDB, err := b.blockchain.State()
+	stateDB, err := b.stateAt(b.blockchain.CurrentBlock().Root())
 	if err != nil {
 		return nil, err
 	}
@@ -646,12 +757,20 @@ func (b *SimulatedBackend) callContract(ctx context.Context, call ethereum.CallM
 
 	txContext := core.NewEVMTxContext(msg)
 	evmContext := core.NewEVMBlockContext(block.Header(), b.blockchain, nil)
+	if b.fork != nil {
+		b.fork.blockContext(&evmContext)
+	}
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
 	vmEnv := vm.NewEVM(evmContext, txContext, stateDB, b.config, vm.Config{NoBaseFee: true})
 	gasPool := new(core.GasPool).AddGas(math.MaxUint64)
 
-	return core.NewStateTransition(vmEnv, msg, gasPool).TransitionDb()
+	res, err := core.NewStateTransition(vmEnv, msg, gasPool).TransitionDb()
+	if err == nil {
+		// Surface any failure to retrieve a forked upstream state during execution
+		err = stateDB.Error()
+	}
+	return res, err
 }
 
 // SendTransaction updates the pending block to include the given transaction.
@@ -675,17 +794,10 @@ func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transa
 		return fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce)
 	}
 	// Include tx in chain
-	blocks, receipts := core.GenerateChain(b.config, block, ethash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
-		for _, tx := range b.pendingBlock.Transactions() {
-			block.AddTxWithChain(b.blockchain, tx)
-		}
+	b.pendingActions = append(b.pendingActions, func(block *core.BlockGen) {
 		block.AddTxWithChain(b.blockchain, tx)
 	})
-	stateDB, _ := b.blockchain.State()
-
-	b.pendingBlock = blocks[0]
-	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database(), nil)
-	b.pendingReceipts = receipts[0]
+	b.generatePending(block, 0)
 	return nil
 }
 
@@ -799,14 +911,7 @@ func (b *SimulatedBackend) AdjustTime(adjustment time.Duration) error {
 		return fmt.Errorf("could not find parent")
 	}
 
-	blocks, _ := core.GenerateChain(b.config, block, ethash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
-		block.OffsetTime(int64(adjustment.Seconds()))
-	})
-	stateDB, _ := b.blockchain.State()
-
-	b.pendingBlock = blocks[0]
-	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database(), nil)
-
+	b.generatePending(block, int64(adjustment.Seconds()))
 	return nil
 }
 
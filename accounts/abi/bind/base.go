@@ -27,9 +27,11 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 const basefeeWiggleMultiplier = 2
@@ -524,6 +526,50 @@ func (c *BoundContract) UnpackLogIntoMap(out map[string]interface{}, event strin
 	return abi.ParseTopicsIntoMap(out, indexed, log.Topics[1:])
 }
 
+// UnpackError decodes the revert data carried by an error returned from a contract
+// call or gas estimation into one of the custom errors defined by the contract ABI.
+// It returns the matching error definition along with its unpacked fields, or nil
+// if the error doesn't carry the revert data of any known custom error.
+func (c *BoundContract) UnpackError(err error) (*abi.Error, []interface{}) {
+	data := revertData(err)
+	if len(data) < 4 {
+		return nil, nil
+	}
+	var id [4]byte
+	copy(id[:], data[:4])
+
+	errABI, err := c.abi.ErrorByID(id)
+	if err != nil {
+		return nil, nil
+	}
+	fields, err := errABI.Unpack(data)
+	if err != nil {
+		return nil, nil
+	}
+	return errABI, fields.([]interface{})
+}
+
+// revertData extracts the raw revert data from an error returned by a backend,
+// if it carries any. RPC backends report it hex encoded, others as plain bytes.
+func revertData(err error) []byte {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil
+	}
+	switch data := dataErr.ErrorData().(type) {
+	case string:
+		blob, err := hexutil.Decode(data)
+		if err != nil {
+			return nil
+		}
+		return blob
+	case []byte:
+		return data
+	default:
+		return nil
+	}
+}
+
 // ensureContext is a helper method to ensure a context is not nil, even if the
 // user specified it as such.
 func ensureContext(ctx context.Context) context.Context {
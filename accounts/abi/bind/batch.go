@@ -0,0 +1,262 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Multicall3Address is the address of the Multicall3 contract, deployed at the
+// same location on most EVM chains.
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// multicall3ABI is the subset of the Multicall3 ABI needed for call batching.
+const multicall3ABI = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+var (
+	// ErrBatchNotExecuted is returned when retrieving the result of a batched
+	// call before its batch has been executed.
+	ErrBatchNotExecuted = errors.New("batch not executed")
+
+	// ErrBatchExecuted is returned when executing a batch a second time.
+	ErrBatchExecuted = errors.New("batch already executed")
+)
+
+// multicall3Call is the Go binding of the Multicall3.Call3 struct.
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result is the Go binding of the Multicall3.Result struct.
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// BatchCaller defines the methods needed to execute a list of JSON-RPC requests
+// in a single round trip. It is implemented by *rpc.Client.
+type BatchCaller interface {
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// CallBatch aggregates many read-only contract calls, possibly across different
+// contracts, and executes them in a single round trip to the backend. Failures
+// of individual calls are reported through their BatchedCall, not the batch.
+type CallBatch struct {
+	calls    []*BatchedCall
+	executed bool
+}
+
+// BatchedCall is a single contract call queued into a CallBatch. Its results
+// become available once the batch has been executed.
+type BatchedCall struct {
+	contract *BoundContract
+	method   string
+	input    []byte
+
+	output []interface{} // Unpacked return values of the call
+	err    error         // Failure of this specific call, if any
+}
+
+// NewCallBatch creates an empty batch of contract calls.
+func NewCallBatch() *CallBatch {
+	return new(CallBatch)
+}
+
+// Add queues a call of the given contract method with params as input values.
+// Packing failures are not returned here, but reported as the result of the call.
+func (b *CallBatch) Add(contract *BoundContract, method string, params ...interface{}) *BatchedCall {
+	call := &BatchedCall{
+		contract: contract,
+		method:   method,
+		err:      ErrBatchNotExecuted,
+	}
+	if input, err := contract.abi.Pack(method, params...); err != nil {
+		call.err = err
+	} else {
+		call.input = input
+		b.calls = append(b.calls, call)
+	}
+	return call
+}
+
+// Len returns the number of calls queued for execution.
+func (b *CallBatch) Len() int {
+	return len(b.calls)
+}
+
+// ExecuteRPC runs all queued calls as a single JSON-RPC batch of eth_call requests.
+// The returned error only reports batch wide failures, errors of individual calls
+// are available through their results.
+func (b *CallBatch) ExecuteRPC(opts *CallOpts, client BatchCaller) error {
+	if b.executed {
+		return ErrBatchExecuted
+	}
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	var (
+		block = callBlockNumber(opts)
+		reqs  = make([]rpc.BatchElem, len(b.calls))
+		outs  = make([]hexutil.Bytes, len(b.calls))
+	)
+	for i, call := range b.calls {
+		to := call.contract.address
+		arg := map[string]interface{}{
+			"from": opts.From,
+			"to":   &to,
+			"data": hexutil.Bytes(call.input),
+		}
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{arg, block},
+			Result: &outs[i],
+		}
+	}
+	if err := client.BatchCallContext(ensureContext(opts.Context), reqs); err != nil {
+		return err
+	}
+	b.executed = true
+	for i, call := range b.calls {
+		if reqs[i].Error != nil {
+			call.err = reqs[i].Error
+			continue
+		}
+		call.unpack(outs[i])
+	}
+	return nil
+}
+
+// ExecuteMulticall runs all queued calls as a single aggregate3 call against the
+// Multicall3 contract deployed at the given address. Individual calls are allowed
+// to fail, their revert data being reported as the error of the call. The returned
+// error only reports batch wide failures.
+func (b *CallBatch) ExecuteMulticall(opts *CallOpts, caller ContractCaller, multicall common.Address) error {
+	if b.executed {
+		return ErrBatchExecuted
+	}
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return err
+	}
+	calls := make([]multicall3Call, len(b.calls))
+	for i, call := range b.calls {
+		calls[i] = multicall3Call{
+			Target:       call.contract.address,
+			AllowFailure: true,
+			CallData:     call.input,
+		}
+	}
+	var (
+		aggregate = NewBoundContract(multicall, parsed, caller, nil, nil)
+		out       []interface{}
+	)
+	if err := aggregate.Call(opts, &out, "aggregate3", calls); err != nil {
+		return err
+	}
+	results := *abi.ConvertType(out[0], new([]multicall3Result)).(*[]multicall3Result)
+	if len(results) != len(b.calls) {
+		return fmt.Errorf("multicall result count mismatch: have %d, want %d", len(results), len(b.calls))
+	}
+	b.executed = true
+	for i, call := range b.calls {
+		if !results[i].Success {
+			call.err = newBatchRevertError(results[i].ReturnData)
+			continue
+		}
+		call.unpack(results[i].ReturnData)
+	}
+	return nil
+}
+
+// Result returns the unpacked return values of the call, or the error the call
+// failed with. Before the batch is executed, ErrBatchNotExecuted is returned.
+func (c *BatchedCall) Result() ([]interface{}, error) {
+	return c.output, c.err
+}
+
+// unpack decodes the raw return data of an executed call into its results.
+func (c *BatchedCall) unpack(output []byte) {
+	// An empty return for a method which has results means there's no contract
+	// to operate on, report it the same way as a single call does.
+	if len(output) == 0 && len(c.contract.abi.Methods[c.method].Outputs) > 0 {
+		c.err = ErrNoCode
+		return
+	}
+	c.output, c.err = c.contract.abi.Unpack(c.method, output)
+}
+
+// callBlockNumber converts the block selection of the call options into the
+// block parameter of an eth_call request.
+func callBlockNumber(opts *CallOpts) rpc.BlockNumber {
+	switch {
+	case opts.Pending:
+		return rpc.PendingBlockNumber
+	case opts.BlockNumber != nil:
+		return rpc.BlockNumber(opts.BlockNumber.Int64())
+	default:
+		return rpc.LatestBlockNumber
+	}
+}
+
+// batchRevertError is an error raised by a reverted call within a multicall
+// batch. It carries the revert data the same way RPC errors do, so that it can
+// be decoded into custom errors.
+type batchRevertError struct {
+	reason string // revert reason, if the call reverted with one
+	data   []byte // raw revert data returned by the call
+}
+
+// newBatchRevertError creates a revert error from the revert data of a call.
+func newBatchRevertError(data []byte) *batchRevertError {
+	reason, _ := abi.UnpackRevert(data)
+	return &batchRevertError{reason: reason, data: data}
+}
+
+// Error implements error, returning the unpacked revert reason if available.
+func (e *batchRevertError) Error() string {
+	if e.reason == "" {
+		return "execution reverted"
+	}
+	return "execution reverted: " + e.reason
+}
+
+// ErrorData returns the hex encoded revert data.
+func (e *batchRevertError) ErrorData() interface{} {
+	return hexutil.Encode(e.data)
+}
+
+var (
+	// Ensure the revert error can be decoded like the ones of RPC backends.
+	_ rpc.DataError = (*batchRevertError)(nil)
+
+	// Ensure *rpc.Client can execute JSON-RPC batches of calls.
+	_ BatchCaller = (*rpc.Client)(nil)
+)
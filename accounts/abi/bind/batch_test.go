@@ -0,0 +1,238 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const batchTestABI = `[
+	{"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"fail","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"available","type":"uint256"}],"name":"InsufficientLiquidity","type":"error"}
+]`
+
+// batchTestBackend answers the calls of the batch test contract: balanceOf returns
+// the contract address' last byte times the owner's last byte and fail reverts
+// with the InsufficientLiquidity custom error.
+type batchTestBackend struct {
+	abi abi.ABI
+}
+
+func newBatchTestBackend(t *testing.T) *batchTestBackend {
+	parsed, err := abi.JSON(strings.NewReader(batchTestABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &batchTestBackend{abi: parsed}
+}
+
+// call executes a single call, returning its output or its revert data.
+func (b *batchTestBackend) call(to common.Address, data []byte) ([]byte, []byte) {
+	method, err := b.abi.MethodById(data)
+	if err != nil {
+		return nil, []byte{}
+	}
+	if method.Name == "fail" {
+		errABI := b.abi.Errors["InsufficientLiquidity"]
+		revert, _ := errABI.Inputs.Pack(big.NewInt(42))
+		return nil, append(common.CopyBytes(errABI.ID[:4]), revert...)
+	}
+	args, _ := method.Inputs.Unpack(data[4:])
+	owner := args[0].(common.Address)
+
+	out, _ := method.Outputs.Pack(big.NewInt(int64(to[19]) * int64(owner[19])))
+	return out, nil
+}
+
+// batchTestError is a JSON-RPC error carrying revert data.
+type batchTestError struct {
+	data []byte
+}
+
+func (e *batchTestError) Error() string          { return "execution reverted" }
+func (e *batchTestError) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+// mockBatchCaller executes JSON-RPC batches of eth_call requests.
+type mockBatchCaller struct {
+	*batchTestBackend
+	block interface{}
+}
+
+func (mc *mockBatchCaller) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	for i := range b {
+		mc.block = b[i].Args[1]
+
+		arg := b[i].Args[0].(map[string]interface{})
+		out, revert := mc.call(*arg["to"].(*common.Address), arg["data"].(hexutil.Bytes))
+		if revert != nil {
+			b[i].Error = &batchTestError{data: revert}
+			continue
+		}
+		*b[i].Result.(*hexutil.Bytes) = out
+	}
+	return nil
+}
+
+// mockMulticaller executes calls against a mock Multicall3 contract.
+type mockMulticaller struct {
+	*batchTestBackend
+	multicall abi.ABI
+	calls     int
+}
+
+func (mc *mockMulticaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (mc *mockMulticaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	mc.calls++
+
+	args, err := mc.multicall.Methods["aggregate3"].Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := *abi.ConvertType(args[0], new([]struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	})).(*[]struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	})
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	results := make([]result, len(calls))
+	for i, call := range calls {
+		out, revert := mc.call(call.Target, call.CallData)
+		if revert != nil {
+			results[i] = result{Success: false, ReturnData: revert}
+		} else {
+			results[i] = result{Success: true, ReturnData: out}
+		}
+	}
+	return mc.multicall.Methods["aggregate3"].Outputs.Pack(results)
+}
+
+// queueBatchTestCalls queues a balance query on two contracts and a failing call.
+func queueBatchTestCalls(t *testing.T, backend *batchTestBackend, caller bind.ContractCaller) (*bind.CallBatch, *bind.BoundContract, []*bind.BatchedCall) {
+	var (
+		batch = bind.NewCallBatch()
+		c1    = bind.NewBoundContract(common.Address{19: 2}, backend.abi, caller, nil, nil)
+		c2    = bind.NewBoundContract(common.Address{19: 3}, backend.abi, caller, nil, nil)
+	)
+	calls := []*bind.BatchedCall{
+		batch.Add(c1, "balanceOf", common.Address{19: 5}),
+		batch.Add(c2, "balanceOf", common.Address{19: 7}),
+		batch.Add(c1, "fail"),
+		batch.Add(c1, "balanceOf", "invalid"),
+	}
+	if batch.Len() != 3 {
+		t.Fatalf("batch length mismatch: have %d, want %d", batch.Len(), 3)
+	}
+	if _, err := calls[0].Result(); err != bind.ErrBatchNotExecuted {
+		t.Fatalf("unexecuted call error mismatch: have %v, want %v", err, bind.ErrBatchNotExecuted)
+	}
+	if _, err := calls[3].Result(); err == nil {
+		t.Fatalf("invalid call packed successfully")
+	}
+	return batch, c1, calls
+}
+
+// checkBatchTestCalls verifies the results of the calls queued by queueBatchTestCalls.
+func checkBatchTestCalls(t *testing.T, contract *bind.BoundContract, calls []*bind.BatchedCall) {
+	for i, want := range []int64{10, 21} {
+		out, err := calls[i].Result()
+		if err != nil {
+			t.Fatalf("call %d: failed to execute: %v", i, err)
+		}
+		if have := out[0].(*big.Int); have.Int64() != want {
+			t.Errorf("call %d: result mismatch: have %v, want %v", i, have, want)
+		}
+	}
+	_, err := calls[2].Result()
+	if err == nil {
+		t.Fatalf("failing call succeeded")
+	}
+	errABI, fields := contract.UnpackError(err)
+	if errABI == nil || errABI.Name != "InsufficientLiquidity" {
+		t.Fatalf("failed to unpack custom error from %v", err)
+	}
+	if fields[0].(*big.Int).Int64() != 42 {
+		t.Errorf("custom error field mismatch: have %v, want %v", fields[0], 42)
+	}
+}
+
+func TestCallBatchRPC(t *testing.T) {
+	backend := newBatchTestBackend(t)
+	batch, contract, calls := queueBatchTestCalls(t, backend, nil)
+
+	caller := &mockBatchCaller{batchTestBackend: backend}
+	if err := batch.ExecuteRPC(&bind.CallOpts{BlockNumber: big.NewInt(12)}, caller); err != nil {
+		t.Fatalf("failed to execute batch: %v", err)
+	}
+	if caller.block != rpc.BlockNumber(12) {
+		t.Errorf("block number mismatch: have %v, want %v", caller.block, 12)
+	}
+	checkBatchTestCalls(t, contract, calls)
+
+	if err := batch.ExecuteRPC(nil, caller); err != bind.ErrBatchExecuted {
+		t.Fatalf("re-execution error mismatch: have %v, want %v", err, bind.ErrBatchExecuted)
+	}
+}
+
+func TestCallBatchMulticall(t *testing.T) {
+	backend := newBatchTestBackend(t)
+	multicall, err := abi.JSON(strings.NewReader(`[{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller := &mockMulticaller{batchTestBackend: backend, multicall: multicall}
+	batch, contract, calls := queueBatchTestCalls(t, backend, caller)
+
+	if err := batch.ExecuteMulticall(nil, caller, bind.Multicall3Address); err != nil {
+		t.Fatalf("failed to execute batch: %v", err)
+	}
+	if caller.calls != 1 {
+		t.Errorf("backend call count mismatch: have %d, want %d", caller.calls, 1)
+	}
+	checkBatchTestCalls(t, contract, calls)
+
+	// Ensure batch wide failures are reported by the batch itself
+	failing := bind.NewCallBatch()
+	call := failing.Add(contract, "balanceOf", common.Address{})
+	if err := failing.ExecuteMulticall(nil, &mockCaller{callContractErr: errors.New("boom")}, bind.Multicall3Address); err == nil {
+		t.Fatalf("failing multicall succeeded")
+	}
+	if _, err := call.Result(); err != bind.ErrBatchNotExecuted {
+		t.Fatalf("call error mismatch: have %v, want %v", err, bind.ErrBatchNotExecuted)
+	}
+}
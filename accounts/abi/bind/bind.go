@@ -41,6 +41,7 @@ const (
 	LangGo Lang = iota
 	LangJava
 	LangObjC
+	LangTS
 )
 
 func isKeyWord(arg string) bool {
@@ -80,6 +81,21 @@ func isKeyWord(arg string) bool {
 	return true
 }
 
+// isKeyWordTS reports whether the given name is a reserved word in TypeScript,
+// which can't be used as a parameter name.
+func isKeyWordTS(arg string) bool {
+	switch arg {
+	case "break", "case", "catch", "class", "const", "continue", "debugger", "default",
+		"delete", "do", "else", "enum", "export", "extends", "false", "finally", "for",
+		"function", "if", "import", "in", "instanceof", "new", "null", "return", "super",
+		"switch", "this", "throw", "true", "try", "typeof", "var", "void", "while", "with",
+		"let", "static", "yield", "await", "implements", "interface", "package", "private",
+		"protected", "public", "arguments", "eval", "overrides":
+		return true
+	}
+	return false
+}
+
 // Bind generates a Go wrapper around a contract ABI. This wrapper isn't meant
 // to be used as is in client code, but rather as an intermediate struct which
 // enforces compile time type safety and naming convention opposed to having to
@@ -114,6 +130,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			calls     = make(map[string]*tmplMethod)
 			transacts = make(map[string]*tmplMethod)
 			events    = make(map[string]*tmplEvent)
+			errs      = make(map[string]*tmplError)
 			fallback  *tmplMethod
 			receive   *tmplMethod
 
@@ -124,6 +141,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			callIdentifiers     = make(map[string]bool)
 			transactIdentifiers = make(map[string]bool)
 			eventIdentifiers    = make(map[string]bool)
+			errorIdentifiers    = make(map[string]bool)
 		)
 
 		for _, input := range evmABI.Constructor.Inputs {
@@ -151,7 +169,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			normalized.Inputs = make([]abi.Argument, len(original.Inputs))
 			copy(normalized.Inputs, original.Inputs)
 			for j, input := range normalized.Inputs {
-				if input.Name == "" || isKeyWord(input.Name) {
+				if input.Name == "" || isKeyWord(input.Name) || (lang == LangTS && isKeyWordTS(input.Name)) {
 					normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
 				}
 				if hasStruct(input.Type) {
@@ -175,6 +193,15 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 				transacts[original.Name] = &tmplMethod{Original: original, Normalized: normalized, Structured: structured(original.Outputs)}
 			}
 		}
+		// Ensure the batched call bindings generated in Go don't collide with the
+		// binding of another call
+		if lang == LangGo {
+			for _, call := range calls {
+				if callIdentifiers["Batch"+call.Normalized.Name] {
+					return "", fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", call.Original.Name, "Batch"+call.Normalized.Name)
+				}
+			}
+		}
 		for _, original := range evmABI.Events {
 			// Skip anonymous events as they don't support explicit filtering
 			if original.Anonymous {
@@ -195,7 +222,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			normalized.Inputs = make([]abi.Argument, len(original.Inputs))
 			copy(normalized.Inputs, original.Inputs)
 			for j, input := range normalized.Inputs {
-				if input.Name == "" || isKeyWord(input.Name) {
+				if input.Name == "" || isKeyWord(input.Name) || (lang == LangTS && isKeyWordTS(input.Name)) {
 					normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
 				}
 				// Event is a bit special, we need to define event struct in binding,
@@ -214,6 +241,45 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			// Append the event to the accumulator list
 			events[original.Name] = &tmplEvent{Original: original, Normalized: normalized}
 		}
+		for _, original := range evmABI.Errors {
+			// Normalize the error for capital cases and non-anonymous inputs
+			normalized := original
+			normalized.Name = capitalise(alias(aliases, original.Name))
+
+			// Errors are bound to types named after the contract, same as events,
+			// ensure there is no collision with any of the generated types.
+			if eventIdentifiers[methodNormalizer[lang](alias(aliases, original.Name))] || errorIdentifiers[normalized.Name] || errorNameReserved(lang, normalized.Name, eventIdentifiers, callIdentifiers) {
+				return "", fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", original.Name, normalized.Name)
+			}
+			errorIdentifiers[normalized.Name] = true
+
+			// The generated error types have members of their own, ensure the
+			// fields don't collide with them.
+			used := make(map[string]bool)
+			for _, member := range reservedErrorFields[lang] {
+				used[member] = true
+			}
+			normalized.Inputs = make([]abi.Argument, len(original.Inputs))
+			copy(normalized.Inputs, original.Inputs)
+			for j, input := range normalized.Inputs {
+				if input.Name == "" || isKeyWord(input.Name) || (lang == LangTS && isKeyWordTS(input.Name)) {
+					normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
+				}
+				// Errors are bound to structs, ensure there is no camel-case-style
+				// name conflict among the fields.
+				for index := 0; ; index++ {
+					if !used[capitalise(normalized.Inputs[j].Name)] {
+						used[capitalise(normalized.Inputs[j].Name)] = true
+						break
+					}
+					normalized.Inputs[j].Name = fmt.Sprintf("%s%d", normalized.Inputs[j].Name, index)
+				}
+				if hasStruct(input.Type) {
+					bindStructType[lang](input.Type, structs)
+				}
+			}
+			errs[original.Name] = &tmplError{Original: original, Normalized: normalized}
+		}
 		// Add two special fallback functions if they exist
 		if evmABI.HasFallback() {
 			fallback = &tmplMethod{Original: evmABI.Fallback}
@@ -226,9 +292,14 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			return "", errors.New("java binding for tuple arguments is not supported yet")
 		}
 
+		// TypeScript embeds the ABI as a JSON literal, all others as a quoted string
+		inputABI := strings.ReplaceAll(strippedABI, "\"", "\\\"")
+		if lang == LangTS {
+			inputABI = strippedABI
+		}
 		contracts[types[i]] = &tmplContract{
 			Type:        capitalise(types[i]),
-			InputABI:    strings.ReplaceAll(strippedABI, "\"", "\\\""),
+			InputABI:    inputABI,
 			InputBin:    strings.TrimPrefix(strings.TrimSpace(bytecodes[i]), "0x"),
 			Constructor: evmABI.Constructor,
 			Calls:       calls,
@@ -236,6 +307,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			Fallback:    fallback,
 			Receive:     receive,
 			Events:      events,
+			Errors:      errs,
 			Libraries:   make(map[string]string),
 		}
 		// Function 4-byte signatures are stored in the same sequence
@@ -291,15 +363,88 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		}
 		return string(code), nil
 	}
+	// For TypeScript bindings squash the blank lines left behind by the template
+	if lang == LangTS {
+		return tidyTS(buffer.String()), nil
+	}
 	// For all others just return as is for now
 	return buffer.String(), nil
 }
 
+// tidyTS cleans up a generated TypeScript binding by dropping the blank lines
+// at block boundaries and collapsing consecutive blank lines into one.
+func tidyTS(code string) string {
+	var (
+		lines = strings.Split(strings.TrimSpace(code), "\n")
+		out   = make([]string, 0, len(lines))
+	)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			prev := out[len(out)-1]
+			if prev == "" || strings.HasSuffix(prev, "{") {
+				continue
+			}
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next < len(lines) && strings.TrimSpace(lines[next]) == "}" {
+				continue
+			}
+			line = ""
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// reservedErrorNames are the suffixes of the identifiers generated for a contract,
+// which custom errors can't be named after, for each language. Error types are
+// named after the contract, followed by the name of the error.
+var reservedErrorNames = map[Lang][]string{
+	LangGo: {"Caller", "Transactor", "Filterer", "Session", "CallerSession", "TransactorSession", "Raw", "CallerRaw", "TransactorRaw", "MetaData", "ABI", "Bin", "FuncSigs"},
+	LangTS: {"Error", "ABI", "Bin", "FuncSigs"},
+}
+
+// errorNameReserved reports whether the type bound to a custom error would collide
+// with any of the other identifiers generated for the contract: the fixed ones,
+// the ones derived from events in Go and from call outputs in TypeScript.
+func errorNameReserved(lang Lang, name string, events map[string]bool, calls map[string]bool) bool {
+	for _, reserved := range reservedErrorNames[lang] {
+		if name == reserved {
+			return true
+		}
+	}
+	switch lang {
+	case LangGo:
+		for event := range events {
+			if name == event+"Iterator" {
+				return true
+			}
+		}
+	case LangTS:
+		for call := range calls {
+			if name == capitalise(call)+"Output" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reservedErrorFields are the members of the generated error types, which the
+// fields of custom errors can't be named after, for each language.
+var reservedErrorFields = map[Lang][]string{
+	LangGo: {"Error"},
+	LangTS: {"Name"},
+}
+
 // bindType is a set of type binders that convert Solidity types to some supported
 // programming language types.
 var bindType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
 	LangGo:   bindTypeGo,
 	LangJava: bindTypeJava,
+	LangTS:   bindTypeTS,
 }
 
 // bindBasicTypeGo converts basic solidity types(except array, slice and tuple) to Go ones.
@@ -417,11 +562,39 @@ func bindTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 	}
 }
 
+// bindBasicTypeTS converts basic solidity types(except array, slice and tuple) to
+// TypeScript ones, as decoded by ethers.
+func bindBasicTypeTS(kind abi.Type) string {
+	switch kind.T {
+	case abi.IntTy, abi.UintTy:
+		return "bigint"
+	case abi.BoolTy:
+		return "boolean"
+	default:
+		// address, string, bytes and function types are all hex or utf8 strings
+		return "string"
+	}
+}
+
+// bindTypeTS converts solidity types to TypeScript ones. Fixed size arrays are
+// mapped to plain arrays, as TypeScript has no practical fixed length type.
+func bindTypeTS(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return structs[kind.TupleRawName+kind.String()].Name
+	case abi.ArrayTy, abi.SliceTy:
+		return bindTypeTS(*kind.Elem, structs) + "[]"
+	default:
+		return bindBasicTypeTS(kind)
+	}
+}
+
 // bindTopicType is a set of type binders that convert Solidity types to some
 // supported programming language topic types.
 var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
 	LangGo:   bindTopicTypeGo,
 	LangJava: bindTopicTypeJava,
+	LangTS:   bindTopicTypeTS,
 }
 
 // bindTopicTypeGo converts a Solidity topic type to a Go one. It is almost the same
@@ -458,11 +631,24 @@ func bindTopicTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 	return bound
 }
 
+// bindTopicTypeTS converts a Solidity topic type to a TypeScript one. It is almost
+// the same functionality as for simple types, but all non-value types (strings,
+// bytes, arrays and structs) are stored as hashes, which are hex strings.
+func bindTopicTypeTS(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.ArrayTy, abi.SliceTy, abi.TupleTy:
+		return "string"
+	default:
+		return bindTypeTS(kind, structs)
+	}
+}
+
 // bindStructType is a set of type binders that convert Solidity tuple types to some supported
 // programming language struct definition.
 var bindStructType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
 	LangGo:   bindStructTypeGo,
 	LangJava: bindStructTypeJava,
+	LangTS:   bindStructTypeTS,
 }
 
 // bindStructTypeGo converts a Solidity tuple type to a Go one and records the mapping
@@ -548,11 +734,43 @@ func bindStructTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 	}
 }
 
+// bindStructTypeTS converts a Solidity tuple type to a TypeScript interface and
+// records the mapping in the given map. The fields keep their raw names, as the
+// decoded ethers results are accessed through them.
+func bindStructTypeTS(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		id := kind.TupleRawName + kind.String()
+		if s, exist := structs[id]; exist {
+			return s.Name
+		}
+		var fields []*tmplField
+		for i, elem := range kind.TupleElems {
+			field := bindStructTypeTS(*elem, structs)
+			fields = append(fields, &tmplField{Type: field, Name: kind.TupleRawNames[i], SolKind: *elem})
+		}
+		name := kind.TupleRawName
+		if name == "" {
+			name = fmt.Sprintf("Struct%d", len(structs))
+		}
+		structs[id] = &tmplStruct{
+			Name:   capitalise(name),
+			Fields: fields,
+		}
+		return capitalise(name)
+	case abi.ArrayTy, abi.SliceTy:
+		return bindStructTypeTS(*kind.Elem, structs) + "[]"
+	default:
+		return bindBasicTypeTS(kind)
+	}
+}
+
 // namedType is a set of functions that transform language specific types to
 // named versions that may be used inside method names.
 var namedType = map[Lang]func(string, abi.Type) string{
 	LangGo:   func(string, abi.Type) string { panic("this shouldn't be needed") },
 	LangJava: namedTypeJava,
+	LangTS:   func(string, abi.Type) string { panic("this shouldn't be needed") },
 }
 
 // namedTypeJava converts some primitive data types to named variants that can
@@ -595,6 +813,7 @@ func alias(aliases map[string]string, n string) string {
 var methodNormalizer = map[Lang]func(string) string{
 	LangGo:   abi.ToCamelCase,
 	LangJava: decapitalise,
+	LangTS:   decapitalise,
 }
 
 // capitalise makes a camel-case string which starts with an upper case character.
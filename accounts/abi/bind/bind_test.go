@@ -367,6 +367,15 @@ var bindTests = []struct {
 			} else if str != "Hi" || num.Cmp(big.NewInt(1)) != 0 {
 				t.Fatalf("Retrieved value mismatch: have %v/%v, want %v/%v", str, num, "Hi", 1)
 			}
+			// Queue the same call into a batch, results are only available after execution
+			batch := bind.NewCallBatch()
+			result := getter.BatchGetter(batch)
+			if batch.Len() != 1 {
+				t.Fatalf("Batched call count mismatch: have %d, want %d", batch.Len(), 1)
+			}
+			if _, _, _, err := result(); err != bind.ErrBatchNotExecuted {
+				t.Fatalf("Unexecuted batch error mismatch: have %v, want %v", err, bind.ErrBatchNotExecuted)
+			}
 		`,
 		nil,
 		nil,
@@ -1874,6 +1883,7 @@ var bindTests = []struct {
 		[]string{"0x6080604052348015600f57600080fd5b5060998061001e6000396000f3fe6080604052348015600f57600080fd5b506004361060285760003560e01c8063726c638214602d575b600080fd5b60336035565b005b60405163024876cd60e61b815260016004820152600260248201526003604482015260640160405180910390fdfea264697066735822122093f786a1bc60216540cd999fbb4a6109e0fef20abcff6e9107fb2817ca968f3c64736f6c63430008070033"},
 		[]string{`[{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"MyError","type":"error"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"MyError1","type":"error"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"},{"internalType":"uint256","name":"","type":"uint256"}],"name":"MyError2","type":"error"},{"inputs":[{"internalType":"uint256","name":"a","type":"uint256"},{"internalType":"uint256","name":"b","type":"uint256"},{"internalType":"uint256","name":"c","type":"uint256"}],"name":"MyError3","type":"error"},{"inputs":[],"name":"Error","outputs":[],"stateMutability":"pure","type":"function"}]`},
 		`
+			"errors"
 			"math/big"
 	
 			"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -1899,17 +1909,51 @@ var bindTests = []struct {
 			if err != nil {
 				t.Error(err)
 			}
-			if err := contract.Error(new(bind.CallOpts)); err == nil {
+			err = contract.Error(new(bind.CallOpts))
+			if err == nil {
 				t.Fatalf("expected contract to throw error")
 			}
-			// TODO (MariusVanDerWijden unpack error using abigen
-			// once that is implemented
+			var custom *NewErrorsMyError3
+			if !errors.As(contract.UnpackError(err), &custom) {
+				t.Fatalf("failed to unpack custom error: %v", err)
+			}
+			if custom.A.Cmp(big.NewInt(1)) != 0 || custom.B.Cmp(big.NewInt(2)) != 0 || custom.C.Cmp(big.NewInt(3)) != 0 {
+				t.Fatalf("custom error fields mismatch: have %v, want MyError3(a=1, b=2, c=3)", custom)
+			}
+			if have, want := custom.Error(), "MyError3(a=1, b=2, c=3)"; have != want {
+				t.Fatalf("custom error message mismatch: have %q, want %q", have, want)
+			}
+			// Errors not carrying any revert data are left untouched
+			plain := errors.New("plain")
+			if contract.UnpackError(plain) != plain {
+				t.Fatalf("plain error modified by unpacking")
+			}
 	   `,
 		nil,
 		nil,
 		nil,
 		nil,
 	},
+	// Test that custom error fields don't collide with the Error method
+	{
+		name: `ErrorFields`,
+		contract: `
+		pragma solidity >=0.8.4 <0.9.0;
+
+		contract ErrorFields {
+			error Failure(string error, uint256 code);
+		}
+		`,
+		bytecode: []string{``},
+		abi:      []string{`[{"inputs":[{"internalType":"string","name":"error","type":"string"},{"internalType":"uint256","name":"code","type":"uint256"}],"name":"Failure","type":"error"}]`},
+		imports:  `"math/big"`,
+		tester: `
+			failure := &ErrorFieldsFailure{Error0: "oops", Code: big.NewInt(1)}
+			if have, want := failure.Error(), "Failure(error0=oops, code=1)"; have != want {
+				t.Fatalf("custom error message mismatch: have %q, want %q", have, want)
+			}
+		`,
+	},
 	{
 		name: `ConstructorWithStructParam`,
 		contract: `
@@ -2116,6 +2160,35 @@ func TestGolangBindings(t *testing.T) {
 	}
 }
 
+// Tests that custom errors colliding with other identifiers generated for the
+// contract are rejected.
+func TestBindErrorCollisions(t *testing.T) {
+	tests := []struct {
+		lang Lang
+		abi  string
+		fail bool
+	}{
+		{LangGo, `[{"inputs":[],"name":"Failure","type":"error"}]`, false},
+		{LangGo, `[{"inputs":[],"name":"Session","type":"error"}]`, true},
+		{LangGo, `[{"inputs":[],"name":"callerRaw","type":"error"}]`, true},
+		{LangGo, `[{"anonymous":false,"inputs":[],"name":"Transfer","type":"event"},{"inputs":[],"name":"TransferIterator","type":"error"}]`, true},
+		{LangGo, `[{"inputs":[],"name":"Error","type":"error"}]`, false},
+		{LangTS, `[{"inputs":[],"name":"Error","type":"error"}]`, true},
+		{LangTS, `[{"inputs":[],"name":"balance","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"BalanceOutput","type":"error"}]`, true},
+		{LangGo, `[{"inputs":[],"name":"getter","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"batchGetter","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`, true},
+		{LangGo, `[{"inputs":[],"name":"getter","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"batchGetter","outputs":[],"stateMutability":"nonpayable","type":"function"}]`, false},
+	}
+	for i, tt := range tests {
+		_, err := Bind([]string{"Token"}, []string{tt.abi}, []string{""}, nil, "bindtest", tt.lang, nil, nil)
+		if tt.fail && err == nil {
+			t.Errorf("test %d: colliding identifier accepted", i)
+		}
+		if !tt.fail && err != nil {
+			t.Errorf("test %d: failed to generate binding: %v", i, err)
+		}
+	}
+}
+
 // Tests that java binding generated by the binder is exactly matched.
 func TestJavaBindings(t *testing.T) {
 	var cases = []struct {
@@ -2520,3 +2593,53 @@ public class Test {
 		}
 	}
 }
+
+// Tests that the TypeScript bindings generated by the binder match the golden
+// files in testdata/ts. The contracts are shared with the Go binding tests.
+func TestTypeScriptBindings(t *testing.T) {
+	var cases = []struct {
+		name     string
+		abi      []string
+		bytecode []string
+		libs     map[string]string
+		types    []string
+	}{
+		{
+			name:     "Overloaded",
+			abi:      []string{`[{"inputs":[{"internalType":"uint256","name":"i","type":"uint256"}],"name":"foo","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"i","type":"uint256"},{"internalType":"uint256","name":"j","type":"uint256"}],"name":"foo","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"in","type":"address"}],"name":"balance","outputs":[{"internalType":"uint256","name":"","type":"uint256"},{"internalType":"bool","name":"ok","type":"bool"}],"stateMutability":"view","type":"function"}]`},
+			bytecode: []string{``},
+		},
+	}
+	for _, name := range []string{"Token", "Structs", "Eventer", "UseLibrary", "NewFallbacks", "NewErrors"} {
+		for _, tt := range bindTests {
+			if tt.name == name {
+				cases = append(cases, struct {
+					name     string
+					abi      []string
+					bytecode []string
+					libs     map[string]string
+					types    []string
+				}{tt.name, tt.abi, tt.bytecode, tt.libs, tt.types})
+			}
+		}
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			types := tt.types
+			if types == nil {
+				types = []string{tt.name}
+			}
+			binding, err := Bind(types, tt.abi, tt.bytecode, nil, "bindtest", LangTS, tt.libs, nil)
+			if err != nil {
+				t.Fatalf("failed to generate binding: %v", err)
+			}
+			want, err := os.ReadFile(filepath.Join("testdata", "ts", strings.ToLower(tt.name)+".ts"))
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if binding != string(want) {
+				t.Fatalf("generated binding mismatch, has %s, want %s", binding, want)
+			}
+		})
+	}
+}
@@ -38,6 +38,7 @@ type tmplContract struct {
 	Fallback    *tmplMethod            // Additional special fallback function
 	Receive     *tmplMethod            // Additional special receive function
 	Events      map[string]*tmplEvent  // Contract events accessors
+	Errors      map[string]*tmplError  // Contract custom errors
 	Libraries   map[string]string      // Same as tmplData, but filtered to only keep what the contract needs
 	Library     bool                   // Indicator whether the contract is a library
 }
@@ -57,6 +58,13 @@ type tmplEvent struct {
 	Normalized abi.Event // Normalized version of the parsed fields
 }
 
+// tmplError is a wrapper around an abi.Error that contains a few preprocessed
+// and cached data fields.
+type tmplError struct {
+	Original   abi.Error // Original error as parsed by the abi package
+	Normalized abi.Error // Normalized version of the parsed fields
+}
+
 // tmplField is a wrapper around a struct field with binding language
 // struct type definition and relative filed name.
 type tmplField struct {
@@ -77,6 +85,7 @@ type tmplStruct struct {
 var tmplSource = map[Lang]string{
 	LangGo:   tmplSourceGo,
 	LangJava: tmplSourceJava,
+	LangTS:   tmplSourceTS,
 }
 
 // tmplSourceGo is the Go source template that the generated Go contract binding
@@ -88,9 +97,10 @@ const tmplSourceGo = `
 package {{.Package}}
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 	"strings"
-	"errors"
 
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -103,6 +113,7 @@ import (
 // Reference imports to suppress errors if they are not otherwise used.
 var (
 	_ = errors.New
+	_ = fmt.Sprintf
 	_ = big.NewInt
 	_ = strings.NewReader
 	_ = ethereum.NotFound
@@ -354,6 +365,35 @@ var (
 		func (_{{$contract.Type}} *{{$contract.Type}}CallerSession) {{.Normalized.Name}}({{range $i, $_ := .Normalized.Inputs}}{{if ne $i 0}},{{end}} {{.Name}} {{bindtype .Type $structs}} {{end}}) ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type $structs}};{{end}} }, {{else}} {{range .Normalized.Outputs}}{{bindtype .Type $structs}},{{end}} {{end}} error) {
 		  return _{{$contract.Type}}.Contract.{{.Normalized.Name}}(&_{{$contract.Type}}.CallOpts {{range .Normalized.Inputs}}, {{.Name}}{{end}})
 		}
+
+		// Batch{{.Normalized.Name}} queues a free data retrieval call binding the contract method 0x{{printf "%x" .Original.ID}}
+		// into the given batch. The returned function yields the results once the batch has been executed.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}Caller) Batch{{.Normalized.Name}}(batch *bind.CallBatch {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type $structs}} {{end}}) func() ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type $structs}};{{end}} },{{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}},{{end}}{{end}} error) {
+			call := batch.Add(_{{$contract.Type}}.contract, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+			return func() ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type $structs}};{{end}} },{{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}},{{end}}{{end}} error) {
+				{{if .Normalized.Outputs}}out{{else}}_{{end}}, err := call.Result()
+				{{if .Structured}}
+				outstruct := new(struct{ {{range .Normalized.Outputs}} {{.Name}} {{bindtype .Type $structs}}; {{end}} })
+				if err != nil {
+					return *outstruct, err
+				}
+				{{range $i, $t := .Normalized.Outputs}} 
+				outstruct.{{.Name}} = *abi.ConvertType(out[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+
+				return *outstruct, err
+				{{else}}
+				if err != nil {
+					return {{range $i, $_ := .Normalized.Outputs}}*new({{bindtype .Type $structs}}), {{end}} err
+				}
+				{{range $i, $t := .Normalized.Outputs}}
+				out{{$i}} := *abi.ConvertType(out[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+
+				return {{range $i, $t := .Normalized.Outputs}}out{{$i}}, {{end}} err
+				{{end}}
+			}
+		}
 	{{end}}
 
 	{{range .Transacts}}
@@ -568,6 +608,61 @@ var (
 		}
 
  	{{end}}
+
+	{{range .Errors}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Normalized.Name}} error raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct { {{range .Normalized.Inputs}}
+			{{capitalise .Name}} {{bindtype .Type $structs}}; {{end}}
+		}
+
+		// Error implements the error interface, formatting the custom error 0x{{printf "%x" (slice .Original.ID.Bytes 0 4)}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (e *{{$contract.Type}}{{.Normalized.Name}}) Error() string {
+			return fmt.Sprintf("{{.Original.Name}}({{range $index, $item := .Normalized.Inputs}}{{if $index}}, {{end}}{{.Name}}=%v{{end}})"{{range .Normalized.Inputs}}, e.{{capitalise .Name}}{{end}})
+		}
+	{{end}}
+
+	{{if .Errors}}
+		// UnpackError decodes the revert data carried by an error returned from a {{.Type}}
+		// call or gas estimation into the matching typed custom error. Errors which don't
+		// carry the revert data of any known custom error are returned as is.
+		func (_{{$contract.Type}} *{{$contract.Type}}) UnpackError(err error) error {
+			return _{{$contract.Type}}.{{$contract.Type}}Caller.UnpackError(err)
+		}
+
+		// UnpackError decodes the revert data carried by an error returned from a {{.Type}}
+		// call into the matching typed custom error. Errors which don't carry the revert
+		// data of any known custom error are returned as is.
+		func (_{{$contract.Type}} *{{$contract.Type}}Caller) UnpackError(err error) error {
+			return unpack{{$contract.Type}}Error(_{{$contract.Type}}.contract, err)
+		}
+
+		// UnpackError decodes the revert data carried by an error returned from a {{.Type}}
+		// gas estimation into the matching typed custom error. Errors which don't carry the
+		// revert data of any known custom error are returned as is.
+		func (_{{$contract.Type}} *{{$contract.Type}}Transactor) UnpackError(err error) error {
+			return unpack{{$contract.Type}}Error(_{{$contract.Type}}.contract, err)
+		}
+
+		// unpack{{.Type}}Error converts the revert data carried by err into one of the
+		// typed custom errors of the {{.Type}} contract.
+		func unpack{{.Type}}Error(contract *bind.BoundContract, err error) error {
+			errABI, fields := contract.UnpackError(err)
+			if errABI == nil {
+				return err
+			}
+			switch errABI.Name {
+			{{range .Errors}}
+			case "{{.Original.Name}}":
+				return &{{$contract.Type}}{{.Normalized.Name}}{ {{range $index, $item := .Normalized.Inputs}}
+					{{capitalise .Name}}: *abi.ConvertType(fields[{{$index}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}),{{end}}
+				}
+			{{end}}
+			}
+			return err
+		}
+	{{end}}
 {{end}}
 `
 
@@ -707,3 +802,204 @@ import java.util.*;
 }
 {{end}}
 `
+
+// tmplSourceTS is the TypeScript source template that the generated TypeScript
+// contract binding is based on. The bindings are built on top of ethers v6.
+const tmplSourceTS = `
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+import {
+	BytesLike,
+	Contract,
+	ContractEventPayload,
+	ContractFactory,
+	ContractRunner,
+	ContractTransactionResponse,
+	Indexed,
+	Interface,
+	Log,
+	Overrides,
+	hexlify,
+} from "ethers";
+
+// topic unwraps an indexed event parameter. Non-value types are not stored in
+// the log directly, only their hash, which is returned instead.
+function topic(value: any): any {
+	return value instanceof Indexed ? value.hash : value;
+}
+
+{{$structs := .Structs}}
+{{range $structs}}
+// {{.Name}} is an auto generated low-level TypeScript binding around an user-defined struct.
+export interface {{.Name}} { {{- range $field := .Fields}}
+	{{$field.Name}}: {{$field.Type}};{{end}}
+}
+{{end}}
+
+{{range $contract := .Contracts}}
+// {{.Type}}ABI is the input ABI used to generate the binding from.
+export const {{.Type}}ABI = {{.InputABI}};
+{{if $contract.FuncSigs}}
+// {{.Type}}FuncSigs maps the 4-byte function signature to its string representation.
+export const {{.Type}}FuncSigs: Record<string, string> = { {{- range $strsig, $binsig := .FuncSigs}}
+	"{{$binsig}}": "{{$strsig}}",{{end}}
+};
+{{end}}
+{{if .InputBin}}
+// {{.Type}}Bin is the compiled bytecode used for deploying new contracts.
+export const {{.Type}}Bin = "0x{{.InputBin}}";
+{{end}}
+{{range .Calls}}{{if gt (len .Normalized.Outputs) 1}}
+// {{$contract.Type}}{{capitalise .Normalized.Name}}Output is the output of a call to {{.Normalized.Name}}.
+export interface {{$contract.Type}}{{capitalise .Normalized.Name}}Output { {{- range $index, $item := .Normalized.Outputs}}
+	{{if .Name}}{{decapitalise .Name}}{{else}}ret{{$index}}{{end}}: {{bindtype .Type $structs}};{{end}}
+}
+{{end}}{{end}}
+{{range .Events}}
+// {{$contract.Type}}{{capitalise .Normalized.Name}} represents a {{capitalise .Normalized.Name}} event raised by the {{$contract.Type}} contract.
+export interface {{$contract.Type}}{{capitalise .Normalized.Name}} { {{- range .Normalized.Inputs}}
+	{{.Name}}: {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}};{{end}}
+	raw: Log; // Blockchain specific contextual infos
+}
+{{end}}
+{{range .Errors}}
+// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Normalized.Name}} error raised by the {{$contract.Type}} contract.
+export interface {{$contract.Type}}{{.Normalized.Name}} {
+	name: "{{.Original.Name}}";{{range .Normalized.Inputs}}
+	{{.Name}}: {{bindtype .Type $structs}};{{end}}
+}
+{{end}}
+{{if .Errors}}
+// {{.Type}}Error is the union of all custom errors defined by the {{.Type}} contract.
+export type {{.Type}}Error ={{range .Errors}}
+	| {{$contract.Type}}{{.Normalized.Name}}{{end}};
+{{end}}
+
+// {{.Type}} is an auto generated TypeScript binding around an Ethereum contract.
+export class {{.Type}} {
+	readonly address: string;
+	readonly contract: Contract;
+
+	// Creates a new instance of {{.Type}}, bound to a specific deployed contract.
+	constructor(address: string, runner: ContractRunner | null) {
+		this.address = address;
+		this.contract = new Contract(address, {{.Type}}ABI, runner);
+	}
+{{if .InputBin}}
+	// deploy deploys a new Ethereum contract, binding an instance of {{.Type}} to it.
+	static async deploy(runner: ContractRunner{{range .Constructor.Inputs}}, {{.Name}}: {{bindtype .Type $structs}}{{end}}, overrides: Overrides = {}): Promise<{{.Type}}> {
+		let bytecode = {{.Type}}Bin;
+{{- range $pattern, $name := .Libraries}}
+
+		// "link" contract to dependent libraries by deploying them first.
+		const {{decapitalise $name}}Inst = await {{capitalise $name}}.deploy(runner);
+		bytecode = bytecode.split("__${{$pattern}}$__").join({{decapitalise $name}}Inst.address.slice(2).toLowerCase());
+{{end}}
+		const factory = new ContractFactory({{.Type}}ABI, bytecode, runner);
+		const contract = await factory.deploy({{range .Constructor.Inputs}}{{.Name}}, {{end}}overrides);
+		await contract.waitForDeployment();
+		return new {{.Type}}(await contract.getAddress(), runner);
+	}
+{{end}}
+{{range .Calls}}
+	// {{.Normalized.Name}} is a free data retrieval call binding the contract method 0x{{printf "%x" .Original.ID}}.
+	//
+	// Solidity: {{.Original.String}}
+	async {{.Normalized.Name}}({{range .Normalized.Inputs}}{{.Name}}: {{bindtype .Type $structs}}, {{end}}overrides: Overrides = {}): Promise<{{if gt (len .Normalized.Outputs) 1}}{{$contract.Type}}{{capitalise .Normalized.Name}}Output{{else if eq (len .Normalized.Outputs) 0}}void{{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}}{{end}}{{end}}> {
+{{- if gt (len .Normalized.Outputs) 1}}
+		const result = await this.contract.getFunction("{{.Original.Sig}}").staticCall({{range .Normalized.Inputs}}{{.Name}}, {{end}}overrides);
+		return { {{- range $index, $item := .Normalized.Outputs}}
+			{{if .Name}}{{decapitalise .Name}}{{else}}ret{{$index}}{{end}}: result[{{$index}}],{{end}}
+		};
+{{- else if eq (len .Normalized.Outputs) 1}}
+		return await this.contract.getFunction("{{.Original.Sig}}").staticCall({{range .Normalized.Inputs}}{{.Name}}, {{end}}overrides);
+{{- else}}
+		await this.contract.getFunction("{{.Original.Sig}}").staticCall({{range .Normalized.Inputs}}{{.Name}}, {{end}}overrides);
+{{- end}}
+	}
+{{end}}
+{{range .Transacts}}
+	// {{.Normalized.Name}} is a paid mutator transaction binding the contract method 0x{{printf "%x" .Original.ID}}.
+	//
+	// Solidity: {{.Original.String}}
+	async {{.Normalized.Name}}({{range .Normalized.Inputs}}{{.Name}}: {{bindtype .Type $structs}}, {{end}}overrides: Overrides = {}): Promise<ContractTransactionResponse> {
+		return await this.contract.getFunction("{{.Original.Sig}}").send({{range .Normalized.Inputs}}{{.Name}}, {{end}}overrides);
+	}
+{{end}}
+{{if .Fallback}}
+	// fallback is a paid mutator transaction binding the contract fallback function.
+	//
+	// Solidity: {{.Fallback.Original.String}}
+	async fallback(calldata: BytesLike, overrides: Overrides = {}): Promise<ContractTransactionResponse> {
+		return await this.contract.fallback!.send({ ...overrides, data: hexlify(calldata) });
+	}
+{{end}}
+{{if .Receive}}
+	// receive is a paid mutator transaction binding the contract receive function.
+	//
+	// Solidity: {{.Receive.Original.String}}
+	async receive(overrides: Overrides = {}): Promise<ContractTransactionResponse> {
+		return await this.contract.fallback!.send({ ...overrides });
+	}
+{{end}}
+{{range .Events}}
+	// filter{{capitalise .Normalized.Name}} is a free log retrieval operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+	//
+	// Solidity: {{.Original.String}}
+	async filter{{capitalise .Normalized.Name}}(fromBlock?: number | string, toBlock?: number | string): Promise<{{$contract.Type}}{{capitalise .Normalized.Name}}[]> {
+		const logs = await this.contract.queryFilter("{{.Original.Sig}}", fromBlock, toBlock);
+		return logs.map((log) => this.parse{{capitalise .Normalized.Name}}(log));
+	}
+
+	// watch{{capitalise .Normalized.Name}} is a free log subscription operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+	// The returned function unsubscribes the listener.
+	//
+	// Solidity: {{.Original.String}}
+	async watch{{capitalise .Normalized.Name}}(listener: (event: {{$contract.Type}}{{capitalise .Normalized.Name}}) => void): Promise<() => Promise<void>> {
+		const handler = (...args: any[]) => {
+			const payload = args[args.length - 1] as ContractEventPayload;
+			listener(this.parse{{capitalise .Normalized.Name}}(payload.log));
+		};
+		await this.contract.on("{{.Original.Sig}}", handler);
+		return async () => {
+			await this.contract.off("{{.Original.Sig}}", handler);
+		};
+	}
+
+	// parse{{capitalise .Normalized.Name}} is a log parse operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+	//
+	// Solidity: {{.Original.String}}
+	parse{{capitalise .Normalized.Name}}(log: Log): {{$contract.Type}}{{capitalise .Normalized.Name}} {
+		const parsed = this.contract.interface.parseLog(log);
+		if (parsed === null || parsed.signature !== "{{.Original.Sig}}") {
+			throw new Error("log is not a {{.Original.Name}} event");
+		}
+		return { {{- range $index, $item := .Normalized.Inputs}}
+			{{.Name}}: {{if .Indexed}}topic(parsed.args[{{$index}}]){{else}}parsed.args[{{$index}}]{{end}},{{end}}
+			raw: log,
+		};
+	}
+{{end}}
+{{if .Errors}}
+	// decodeError decodes the revert data of a failed call or transaction into
+	// one of the custom errors defined by the contract, or null if the data
+	// doesn't match any of them.
+	static decodeError(data: BytesLike): {{.Type}}Error | null {
+		const parsed = Interface.from({{.Type}}ABI).parseError(data);
+		if (parsed === null) {
+			return null;
+		}
+		switch (parsed.signature) { {{- range .Errors}}
+		case "{{.Original.Sig}}":
+			return {
+				name: "{{.Original.Name}}",{{range $index, $item := .Normalized.Inputs}}
+				{{.Name}}: parsed.args[{{$index}}],{{end}}
+			};{{end}}
+		}
+		return null;
+	}
+{{end}}
+}
+{{end}}
+`
@@ -146,6 +146,24 @@ func (ac *accountCache) deleteByFile(path string) {
 	}
 }
 
+// updateByFile replaces the account referenced by the given path with the one
+// freshly read from it. Key files rewritten in place (e.g. re-encrypted) keep
+// their address, so their cache entry is left alone and never goes missing.
+func (ac *accountCache) updateByFile(path string, a *accounts.Account) {
+	ac.mu.Lock()
+	i := sort.Search(len(ac.all), func(i int) bool { return ac.all[i].URL.Path >= path })
+	unchanged := a != nil && i < len(ac.all) && ac.all[i] == *a
+	ac.mu.Unlock()
+
+	if unchanged {
+		return
+	}
+	ac.deleteByFile(path)
+	if a != nil {
+		ac.add(*a)
+	}
+}
+
 // watcherStarted returns true if the watcher loop started running (even if it
 // has since also ended).
 func (ac *accountCache) watcherStarted() bool {
@@ -292,10 +310,7 @@ func (ac *accountCache) scanAccounts() error {
 		ac.deleteByFile(path)
 	}
 	for _, path := range updates.ToSlice() {
-		ac.deleteByFile(path)
-		if a := readAccount(path); a != nil {
-			ac.add(*a)
-		}
+		ac.updateByFile(path, readAccount(path))
 	}
 	end := time.Now()
 
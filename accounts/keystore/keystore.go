@@ -29,6 +29,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -66,6 +67,7 @@ type KeyStore struct {
 	unlocked map[common.Address]*unlocked // Currently unlocked account (decrypted private keys)
 
 	wallets     []accounts.Wallet       // Wallet wrappers around the individual key files
+	seeds       []*seedWallet           // Wallets deriving accounts from the stored mnemonics
 	updateFeed  event.Feed              // Event feed to notify wallet additions/removals
 	updateScope event.SubscriptionScope // Subscription scope tracking current live listeners
 	updating    bool                    // Whether the event notification loop is running
@@ -117,10 +119,11 @@ func (ks *KeyStore) init(keydir string) {
 	for i := 0; i < len(accs); i++ {
 		ks.wallets[i] = &keystoreWallet{account: accs[i], keystore: ks}
 	}
+	ks.seeds = loadSeedWallets(ks, keydir)
 }
 
-// Wallets implements accounts.Backend, returning all single-key wallets from the
-// keystore directory.
+// Wallets implements accounts.Backend, returning all single-key and seed wallets
+// from the keystore directory.
 func (ks *KeyStore) Wallets() []accounts.Wallet {
 	// Make sure the list of wallets is in sync with the account cache
 	ks.refreshWallets()
@@ -128,8 +131,14 @@ func (ks *KeyStore) Wallets() []accounts.Wallet {
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
 
-	cpy := make([]accounts.Wallet, len(ks.wallets))
+	cpy := make([]accounts.Wallet, len(ks.wallets), len(ks.wallets)+len(ks.seeds))
 	copy(cpy, ks.wallets)
+	if len(ks.seeds) > 0 {
+		for _, seed := range ks.seeds {
+			cpy = append(cpy, seed)
+		}
+		sort.Slice(cpy, func(i, j int) bool { return cpy[i].URL().Cmp(cpy[j].URL()) < 0 })
+	}
 	return cpy
 }
 
@@ -424,13 +433,17 @@ func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string)
 	if err != nil {
 		return nil, err
 	}
-	var N, P int
+	N, P := ks.scryptParams()
+	return EncryptKey(key, newPassphrase, N, P)
+}
+
+// scryptParams returns the scrypt parameters to encrypt exported keys and new
+// seeds with, falling back to the standard ones for plaintext keystores.
+func (ks *KeyStore) scryptParams() (int, int) {
 	if store, ok := ks.storage.(*keyStorePassphrase); ok {
-		N, P = store.scryptN, store.scryptP
-	} else {
-		N, P = StandardScryptN, StandardScryptP
+		return store.scryptN, store.scryptP
 	}
-	return EncryptKey(key, newPassphrase, N, P)
+	return StandardScryptN, StandardScryptP
 }
 
 // Import stores the given encrypted JSON key into the key directory.
@@ -486,6 +499,28 @@ func (ks *KeyStore) Update(a accounts.Account, passphrase, newPassphrase string)
 	return ks.storage.StoreKey(a.URL.Path, key, newPassphrase)
 }
 
+// UpgradeKDF re-encrypts the key file of an existing account in place, deriving
+// the encryption key from the unchanged passphrase with Argon2id using the given
+// parameters. The file is replaced atomically, so the account stays available
+// throughout the rewrite.
+func (ks *KeyStore) UpgradeKDF(a accounts.Account, passphrase string, time, memory uint32, threads uint8) error {
+	store, ok := ks.storage.(*keyStorePassphrase)
+	if !ok {
+		return errors.New("keystore is not encrypted")
+	}
+	a, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	keyjson, err := EncryptKeyArgon2id(key, passphrase, time, memory, threads)
+	if err != nil {
+		return err
+	}
+	return store.storeKeyJSON(a.URL.Path, key, keyjson, passphrase)
+}
+
 // ImportPreSaleKey decrypts the given Ethereum presale wallet and stores
 // a key file in the key directory. The key file is encrypted with the same passphrase.
 func (ks *KeyStore) ImportPreSaleKey(keyJSON []byte, passphrase string) (accounts.Account, error) {
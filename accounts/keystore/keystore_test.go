@@ -458,3 +458,40 @@ func tmpKeyStore(t *testing.T, encrypted bool) (string, *KeyStore) {
 	}
 	return d, newKs(d)
 }
+
+// Tests that upgrading the KDF of a key file keeps the account and its
+// passphrase intact.
+func TestUpgradeKDF(t *testing.T) {
+	_, ks := tmpKeyStore(t, true)
+	a, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.UpgradeKDF(a, "bar", 1, 64, 1); err != ErrDecrypt {
+		t.Fatalf("upgrade with wrong passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := ks.UpgradeKDF(a, "foo", 1, 64, 1); err != nil {
+		t.Fatalf("failed to upgrade KDF: %v", err)
+	}
+	keyjson, err := os.ReadFile(a.URL.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(keyjson), `"kdf":"argon2id"`) {
+		t.Errorf("key file not re-encrypted with argon2id: %s", keyjson)
+	}
+	// Rescan the key directory, the rewritten file must not be mistaken for
+	// a different account.
+	if err := ks.cache.scanAccounts(); err != nil {
+		t.Fatal(err)
+	}
+	if accs := ks.Accounts(); len(accs) != 1 || accs[0] != a {
+		t.Errorf("account changed by upgrade: have %v, want %v", accs, a)
+	}
+	if err := ks.Unlock(a, "foo"); err != nil {
+		t.Errorf("failed to unlock upgraded account: %v", err)
+	}
+	if matches, _ := os.ReadDir(ks.cache.keydir); len(matches) != 1 {
+		t.Errorf("temporary files left behind: %d files in keystore", len(matches))
+	}
+}
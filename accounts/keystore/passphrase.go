@@ -42,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 	"golang.org/x/crypto/scrypt"
 )
@@ -67,6 +68,44 @@ const (
 
 	scryptR     = 8
 	scryptDKLen = 32
+
+	keyHeaderKDFArgon2id = "argon2id"
+
+	// StandardArgon2idT is the time parameter of Argon2id encryption algorithm,
+	// using 256MB memory and taking approximately 1s CPU time on a modern processor.
+	StandardArgon2idT = 3
+
+	// StandardArgon2idM is the memory parameter (in KiB) of Argon2id encryption
+	// algorithm, using 256MB memory and taking approximately 1s CPU time on a
+	// modern processor.
+	StandardArgon2idM = 256 * 1024
+
+	// StandardArgon2idP is the parallelism parameter of Argon2id encryption
+	// algorithm, using 256MB memory and taking approximately 1s CPU time on a
+	// modern processor.
+	StandardArgon2idP = 4
+
+	// LightArgon2idT is the time parameter of Argon2id encryption algorithm,
+	// using 4MB memory and taking approximately 100ms CPU time on a modern processor.
+	LightArgon2idT = 3
+
+	// LightArgon2idM is the memory parameter (in KiB) of Argon2id encryption
+	// algorithm, using 4MB memory and taking approximately 100ms CPU time on a
+	// modern processor.
+	LightArgon2idM = 4 * 1024
+
+	// LightArgon2idP is the parallelism parameter of Argon2id encryption
+	// algorithm, using 4MB memory and taking approximately 100ms CPU time on a
+	// modern processor.
+	LightArgon2idP = 1
+
+	argon2idDKLen = 32
+
+	// maxArgon2idT and maxArgon2idM (in KiB, 4GB) bound the Argon2id parameters
+	// accepted from key files, preventing crafted ones from exhausting the CPU
+	// or memory of the node.
+	maxArgon2idT = 64
+	maxArgon2idM = 4 * 1024 * 1024
 )
 
 type keyStorePassphrase struct {
@@ -107,6 +146,14 @@ func (ks keyStorePassphrase) StoreKey(filename string, key *Key, auth string) er
 	if err != nil {
 		return err
 	}
+	return ks.storeKeyJSON(filename, key, keyjson, auth)
+}
+
+// storeKeyJSON atomically writes an already encrypted key into the given file,
+// verifying beforehand that it can be decrypted with 'auth'. The key is moved
+// into place with a rename from a hidden file, so an existing file is replaced
+// without ever being observed as missing or partially written.
+func (ks keyStorePassphrase) storeKeyJSON(filename string, key *Key, keyjson []byte, auth string) error {
 	// Write into temporary file
 	tmpName, err := writeTemporaryKeyFile(filename, keyjson)
 	if err != nil {
@@ -146,6 +193,41 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 	if err != nil {
 		return CryptoJSON{}, err
 	}
+	scryptParamsJSON := make(map[string]interface{}, 5)
+	scryptParamsJSON["n"] = scryptN
+	scryptParamsJSON["r"] = scryptR
+	scryptParamsJSON["p"] = scryptP
+	scryptParamsJSON["dklen"] = scryptDKLen
+	scryptParamsJSON["salt"] = hex.EncodeToString(salt)
+
+	return encryptDataV3(data, derivedKey, keyHeaderKDF, scryptParamsJSON)
+}
+
+// EncryptDataV3Argon2id encrypts the data given as 'data' with the password
+// 'auth', deriving the encryption key with Argon2id instead of scrypt.
+func EncryptDataV3Argon2id(data, auth []byte, time, memory uint32, threads uint8) (CryptoJSON, error) {
+	if err := validateArgon2idParams(time, memory, threads); err != nil {
+		return CryptoJSON{}, err
+	}
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		panic("reading from crypto/rand failed: " + err.Error())
+	}
+	derivedKey := argon2.IDKey(auth, salt, time, memory, threads, argon2idDKLen)
+
+	argon2idParamsJSON := make(map[string]interface{}, 5)
+	argon2idParamsJSON["t"] = time
+	argon2idParamsJSON["m"] = memory
+	argon2idParamsJSON["p"] = threads
+	argon2idParamsJSON["dklen"] = argon2idDKLen
+	argon2idParamsJSON["salt"] = hex.EncodeToString(salt)
+
+	return encryptDataV3(data, derivedKey, keyHeaderKDFArgon2id, argon2idParamsJSON)
+}
+
+// encryptDataV3 encrypts 'data' with a key derived by the given KDF, and packs
+// it together with the KDF parameters into a crypto section.
+func encryptDataV3(data, derivedKey []byte, kdf string, kdfParams map[string]interface{}) (CryptoJSON, error) {
 	encryptKey := derivedKey[:16]
 
 	iv := make([]byte, aes.BlockSize) // 16
@@ -158,22 +240,15 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 	}
 	mac := crypto.Keccak256(derivedKey[16:32], cipherText)
 
-	scryptParamsJSON := make(map[string]interface{}, 5)
-	scryptParamsJSON["n"] = scryptN
-	scryptParamsJSON["r"] = scryptR
-	scryptParamsJSON["p"] = scryptP
-	scryptParamsJSON["dklen"] = scryptDKLen
-	scryptParamsJSON["salt"] = hex.EncodeToString(salt)
 	cipherParamsJSON := cipherparamsJSON{
 		IV: hex.EncodeToString(iv),
 	}
-
 	cryptoStruct := CryptoJSON{
 		Cipher:       "aes-128-ctr",
 		CipherText:   hex.EncodeToString(cipherText),
 		CipherParams: cipherParamsJSON,
-		KDF:          keyHeaderKDF,
-		KDFParams:    scryptParamsJSON,
+		KDF:          kdf,
+		KDFParams:    kdfParams,
 		MAC:          hex.EncodeToString(mac),
 	}
 	return cryptoStruct, nil
@@ -187,6 +262,21 @@ func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	return marshalKeyV3(key, cryptoStruct)
+}
+
+// EncryptKeyArgon2id encrypts a key using the specified Argon2id parameters into
+// a json blob that can be decrypted later on.
+func EncryptKeyArgon2id(key *Key, auth string, time, memory uint32, threads uint8) ([]byte, error) {
+	keyBytes := math.PaddedBigBytes(key.PrivateKey.D, 32)
+	cryptoStruct, err := EncryptDataV3Argon2id(keyBytes, []byte(auth), time, memory, threads)
+	if err != nil {
+		return nil, err
+	}
+	return marshalKeyV3(key, cryptoStruct)
+}
+
+func marshalKeyV3(key *Key, cryptoStruct CryptoJSON) ([]byte, error) {
 	encryptedKeyJSONV3 := encryptedKeyJSONV3{
 		hex.EncodeToString(key.Address[:]),
 		cryptoStruct,
@@ -348,11 +438,32 @@ func getKDFKey(cryptoJSON CryptoJSON, auth string) ([]byte, error) {
 		}
 		key := pbkdf2.Key(authArray, salt, c, dkLen, sha256.New)
 		return key, nil
+	} else if cryptoJSON.KDF == keyHeaderKDFArgon2id {
+		t := ensureInt(cryptoJSON.KDFParams["t"])
+		m := ensureInt(cryptoJSON.KDFParams["m"])
+		p := ensureInt(cryptoJSON.KDFParams["p"])
+		if t < 0 || t > maxArgon2idT || m < 0 || m > maxArgon2idM || p < 0 || p > math.MaxUint8 || dkLen != argon2idDKLen {
+			return nil, fmt.Errorf("invalid Argon2id parameters: t=%d m=%d p=%d dklen=%d", t, m, p, dkLen)
+		}
+		if err := validateArgon2idParams(uint32(t), uint32(m), uint8(p)); err != nil {
+			return nil, err
+		}
+		return argon2.IDKey(authArray, salt, uint32(t), uint32(m), uint8(p), uint32(dkLen)), nil
 	}
 
 	return nil, fmt.Errorf("unsupported KDF: %s", cryptoJSON.KDF)
 }
 
+// validateArgon2idParams rejects Argon2id parameters the algorithm cannot run
+// with, instead of letting the key derivation panic, along with the ones beyond
+// the supported resource limits.
+func validateArgon2idParams(time, memory uint32, threads uint8) error {
+	if time < 1 || time > maxArgon2idT || threads < 1 || memory < 8*uint32(threads) || memory > maxArgon2idM {
+		return fmt.Errorf("invalid Argon2id parameters: t=%d m=%d p=%d", time, memory, threads)
+	}
+	return nil
+}
+
 // TODO: can we do without this when unmarshalling dynamic JSON?
 // why do integers in KDF params end up as float64 and not int after
 // unmarshal?
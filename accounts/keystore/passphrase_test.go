@@ -18,6 +18,7 @@ package keystore
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -58,3 +59,51 @@ func TestKeyEncryptDecrypt(t *testing.T) {
 		}
 	}
 }
+
+// Tests that a key encrypted with Argon2id can be decrypted, and that broken
+// Argon2id parameters are rejected.
+func TestKeyEncryptDecryptArgon2id(t *testing.T) {
+	keyjson, err := os.ReadFile("testdata/very-light-scrypt.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := DecryptKey(keyjson, "")
+	if err != nil {
+		t.Fatalf("json key failed to decrypt: %v", err)
+	}
+	if keyjson, err = EncryptKeyArgon2id(key, "argon", 1, 64, 1); err != nil {
+		t.Fatalf("failed to encrypt key: %v", err)
+	}
+	if _, err := DecryptKey(keyjson, "bad"); err != ErrDecrypt {
+		t.Errorf("json key decrypted with bad password: %v", err)
+	}
+	decrypted, err := DecryptKey(keyjson, "argon")
+	if err != nil {
+		t.Fatalf("json key failed to decrypt: %v", err)
+	}
+	if decrypted.Address != key.Address {
+		t.Errorf("key address mismatch: have %x, want %x", decrypted.Address, key.Address)
+	}
+	// Ensure invalid parameters fail instead of panicking
+	if _, err := EncryptKeyArgon2id(key, "argon", 0, 64, 1); err == nil {
+		t.Errorf("key encrypted with zero time parameter")
+	}
+	if _, err := EncryptKeyArgon2id(key, "argon", 1, maxArgon2idM+1, 1); err == nil {
+		t.Errorf("key encrypted with oversized memory parameter")
+	}
+	for _, param := range [][2]string{
+		{`"p":1`, `"p":0`},
+		{`"t":1`, `"t":4294967297`},
+		{`"m":64`, `"m":4294967295`},
+		{`"dklen":32`, `"dklen":4294967295`},
+		{`"dklen":32`, `"dklen":64`},
+	} {
+		broken := strings.Replace(string(keyjson), param[0], param[1], 1)
+		if broken == string(keyjson) {
+			t.Fatalf("parameter %s not found in key file", param[0])
+		}
+		if _, err := DecryptKey([]byte(broken), "argon"); err == nil || err == ErrDecrypt {
+			t.Errorf("invalid parameter %s not rejected: %v", param[1], err)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/google/uuid"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// seedDirName is the subdirectory of the keystore holding the encrypted
+	// mnemonics of seed wallets. Being a directory, the account cache skips it.
+	seedDirName = "seeds"
+
+	// seedTypeBIP39 is the type of seed files holding a BIP-39 mnemonic.
+	seedTypeBIP39 = "bip39"
+)
+
+// ErrInvalidMnemonic is returned if a mnemonic to import is not a valid BIP-39
+// mnemonic of the English wordlist.
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// encryptedSeedJSON is the on-disk format of a seed wallet, with the mnemonic
+// encrypted the same way as the private key in version 3 key files.
+type encryptedSeedJSON struct {
+	Type    string     `json:"type"`
+	Crypto  CryptoJSON `json:"crypto"`
+	Id      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+// encryptMnemonic encrypts a mnemonic using the specified scrypt parameters into
+// a json blob that can be decrypted later on.
+func encryptMnemonic(id uuid.UUID, mnemonic, auth string, scryptN, scryptP int) ([]byte, error) {
+	cryptoStruct, err := EncryptDataV3([]byte(mnemonic), []byte(auth), scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedSeedJSON{
+		Type:    seedTypeBIP39,
+		Crypto:  cryptoStruct,
+		Id:      id.String(),
+		Version: version,
+	})
+}
+
+// decryptMnemonic decrypts a mnemonic from a json blob.
+func decryptMnemonic(seedjson []byte, auth string) (string, error) {
+	seed := new(encryptedSeedJSON)
+	if err := json.Unmarshal(seedjson, seed); err != nil {
+		return "", err
+	}
+	if seed.Type != seedTypeBIP39 {
+		return "", fmt.Errorf("seed type not supported: %v", seed.Type)
+	}
+	if seed.Version != version {
+		return "", fmt.Errorf("version not supported: %v", seed.Version)
+	}
+	mnemonic, err := DecryptDataV3(seed.Crypto, auth)
+	if err != nil {
+		return "", err
+	}
+	return string(mnemonic), nil
+}
+
+// seedFileName implements the naming convention for seed files:
+// UTC--<created_at UTC ISO8601>--<wallet id>
+func seedFileName(id uuid.UUID) string {
+	return fmt.Sprintf("UTC--%s--%s", toISO8601(time.Now().UTC()), id)
+}
+
+// deriveKey derives the private key at the given path from a BIP-32 master seed.
+func deriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	var (
+		curve = crypto.S256()
+		mac   = hmac.New(sha512.New, []byte("Bitcoin seed"))
+	)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	if key.Sign() == 0 || key.Cmp(curve.Params().N) >= 0 {
+		return nil, errors.New("invalid master key")
+	}
+	for _, index := range path {
+		// Hardened children commit to the parent private key, normal ones to
+		// the compressed parent public key
+		var data []byte
+		if index >= 0x80000000 {
+			data = append([]byte{0}, math.PaddedBigBytes(key, 32)...)
+		} else {
+			x, y := curve.ScalarBaseMult(math.PaddedBigBytes(key, 32))
+			data = crypto.CompressPubkey(&ecdsa.PublicKey{Curve: curve, X: x, Y: y})
+		}
+		var indexBytes [4]byte
+		binary.BigEndian.PutUint32(indexBytes[:], index)
+		data = append(data, indexBytes[:]...)
+
+		mac = hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum = mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(curve.Params().N) >= 0 {
+			return nil, fmt.Errorf("invalid child key at index %d", index)
+		}
+		key.Add(key, tweak).Mod(key, curve.Params().N)
+		if key.Sign() == 0 {
+			return nil, fmt.Errorf("invalid child key at index %d", index)
+		}
+		chainCode = sum[32:]
+	}
+	return crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+}
+
+// NewMnemonic generates a new random 24 word BIP-39 mnemonic and stores it as
+// a seed wallet into the key directory, encrypting it with the passphrase.
+func (ks *KeyStore) NewMnemonic(passphrase string) (string, accounts.Wallet, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", nil, err
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", nil, err
+	}
+	wallet, err := ks.ImportMnemonic(mnemonic, passphrase)
+	if err != nil {
+		return "", nil, err
+	}
+	return mnemonic, wallet, nil
+}
+
+// ImportMnemonic stores the given BIP-39 mnemonic as a seed wallet into the key
+// directory, encrypting it with the passphrase. Accounts of the wallet are only
+// derived on demand, after opening it with the same passphrase.
+func (ks *KeyStore) ImportMnemonic(mnemonic, passphrase string) (accounts.Wallet, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if _, err := bip39.EntropyFromMnemonic(mnemonic); err != nil {
+		return nil, ErrInvalidMnemonic
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	scryptN, scryptP := ks.scryptParams()
+	seedjson, err := encryptMnemonic(id, mnemonic, passphrase, scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(ks.cache.keydir, seedDirName, seedFileName(id))
+	if err := writeKeyFile(path, seedjson); err != nil {
+		return nil, err
+	}
+	wallet := newSeedWallet(ks, path)
+
+	ks.mu.Lock()
+	ks.seeds = append(ks.seeds, wallet)
+	sortSeedWallets(ks.seeds)
+	ks.mu.Unlock()
+
+	ks.updateFeed.Send(accounts.WalletEvent{Wallet: wallet, Kind: accounts.WalletArrived})
+	return wallet, nil
+}
+
+// IsSeedWallet reports whether the wallet derives its accounts from a mnemonic
+// stored in this keystore.
+func (ks *KeyStore) IsSeedWallet(wallet accounts.Wallet) bool {
+	seed, ok := wallet.(*seedWallet)
+	return ok && seed.keystore == ks
+}
+
+// TimedOpen opens a seed wallet of the keystore with the passphrase, closing it
+// again after the timeout. A timeout of 0 keeps the wallet open until it is
+// explicitly closed.
+func (ks *KeyStore) TimedOpen(wallet accounts.Wallet, passphrase string, timeout time.Duration) error {
+	if !ks.IsSeedWallet(wallet) {
+		return accounts.ErrUnknownWallet
+	}
+	return wallet.(*seedWallet).timedOpen(passphrase, timeout)
+}
+
+// loadSeedWallets creates a wallet for every seed file in the key directory.
+func loadSeedWallets(ks *KeyStore, keydir string) []*seedWallet {
+	files, err := os.ReadDir(filepath.Join(keydir, seedDirName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn("Failed to load seed wallets", "err", err)
+		}
+		return nil
+	}
+	var wallets []*seedWallet
+	for _, fi := range files {
+		if nonKeyFile(fi) {
+			continue
+		}
+		wallets = append(wallets, newSeedWallet(ks, filepath.Join(keydir, seedDirName, fi.Name())))
+	}
+	sortSeedWallets(wallets)
+	return wallets
+}
+
+// readSeed decrypts the mnemonic stored in the given seed file and returns the
+// BIP-39 seed generated from it.
+func readSeed(path, passphrase string) ([]byte, error) {
+	seedjson, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	mnemonic, err := decryptMnemonic(seedjson, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return bip39.NewSeed(mnemonic, ""), nil
+}
+
+func sortSeedWallets(wallets []*seedWallet) {
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].url.Cmp(wallets[j].url) < 0 })
+}
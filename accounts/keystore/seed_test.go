@@ -0,0 +1,231 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// Tests key derivation against the BIP-32 test vector 1.
+func TestDeriveKey(t *testing.T) {
+	seed := common.FromHex("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for i, tt := range tests {
+		var path accounts.DerivationPath
+		if tt.path != "m" {
+			var err error
+			if path, err = accounts.ParseDerivationPath(tt.path); err != nil {
+				t.Fatalf("test %d: failed to parse path: %v", i, err)
+			}
+		}
+		key, err := deriveKey(seed, path)
+		if err != nil {
+			t.Fatalf("test %d: failed to derive key: %v", i, err)
+		}
+		if have := common.Bytes2Hex(crypto.FromECDSA(key)); have != tt.key {
+			t.Errorf("test %d: key mismatch: have %s, want %s", i, have, tt.key)
+		}
+	}
+}
+
+func TestSeedWallet(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	if _, err := ks.ImportMnemonic("abandon abandon", "foo"); err != ErrInvalidMnemonic {
+		t.Fatalf("invalid mnemonic import: have %v, want %v", err, ErrInvalidMnemonic)
+	}
+	wallet, err := ks.ImportMnemonic(testMnemonic, "foo")
+	if err != nil {
+		t.Fatalf("failed to import mnemonic: %v", err)
+	}
+	if wallets := ks.Wallets(); len(wallets) != 1 || wallets[0] != wallet {
+		t.Fatalf("seed wallet not listed: %v", wallets)
+	}
+	if accs := ks.Accounts(); len(accs) != 0 {
+		t.Errorf("seed file mistaken for key file: %v", accs)
+	}
+	// Derivation requires the wallet to be opened with the right passphrase
+	if _, err := wallet.Derive(accounts.DefaultBaseDerivationPath, true); err != accounts.ErrWalletClosed {
+		t.Errorf("derivation from closed wallet: have %v, want %v", err, accounts.ErrWalletClosed)
+	}
+	if err := wallet.Open("bar"); err != ErrDecrypt {
+		t.Fatalf("opening with wrong passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := wallet.Open("foo"); err != nil {
+		t.Fatalf("failed to open wallet: %v", err)
+	}
+	account, err := wallet.Derive(accounts.DefaultBaseDerivationPath, true)
+	if err != nil {
+		t.Fatalf("failed to derive account: %v", err)
+	}
+	if want := common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"); account.Address != want {
+		t.Errorf("derived address mismatch: have %x, want %x", account.Address, want)
+	}
+	if !wallet.Contains(account) {
+		t.Errorf("pinned account not contained")
+	}
+	// Ensure the derived accounts can sign
+	sig, err := wallet.SignText(account, []byte("hello"))
+	if err != nil {
+		t.Fatalf("failed to sign text: %v", err)
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte("hello")), sig)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != account.Address {
+		t.Errorf("signer mismatch: have %x, want %x", signer, account.Address)
+	}
+	tx := types.NewTransaction(0, common.Address{}, new(big.Int), 21000, new(big.Int), nil)
+	if _, err := wallet.SignTxWithPassphrase(account, "bar", tx, big.NewInt(1)); err != ErrDecrypt {
+		t.Errorf("signing with wrong passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	if _, err := wallet.SignTxWithPassphrase(account, "foo", tx, big.NewInt(1)); err != nil {
+		t.Errorf("failed to sign transaction: %v", err)
+	}
+	// Closing the wallet forgets the seed and the derived accounts
+	wallet.Close()
+	if _, err := wallet.SignText(account, []byte("hello")); err != accounts.ErrUnknownAccount {
+		t.Errorf("signing with closed wallet: have %v, want %v", err, accounts.ErrUnknownAccount)
+	}
+	// Ensure the seed wallet is loaded from disk
+	if wallets := NewKeyStore(dir, veryLightScryptN, veryLightScryptP).Wallets(); len(wallets) != 1 || wallets[0].URL() != wallet.URL() {
+		t.Errorf("seed wallet not reloaded: %v", wallets)
+	}
+}
+
+func TestSeedWalletTimedOpen(t *testing.T) {
+	_, ks := tmpKeyStore(t, true)
+	wallet, err := ks.ImportMnemonic(testMnemonic, "foo")
+	if err != nil {
+		t.Fatalf("failed to import mnemonic: %v", err)
+	}
+	if err := ks.TimedOpen(wallet, "foo", 100*time.Millisecond); err != nil {
+		t.Fatalf("failed to open wallet: %v", err)
+	}
+	if status, _ := wallet.Status(); status != "Unlocked" {
+		t.Fatalf("wallet not opened: %s", status)
+	}
+	// The wallet is closed again after the timeout
+	time.Sleep(250 * time.Millisecond)
+	if status, _ := wallet.Status(); status != "Locked" {
+		t.Fatalf("wallet not closed after timeout: %s", status)
+	}
+	// Expiry of an earlier open must not close a reopened wallet
+	if err := ks.TimedOpen(wallet, "foo", 100*time.Millisecond); err != nil {
+		t.Fatalf("failed to open wallet: %v", err)
+	}
+	wallet.Close()
+	if err := wallet.Open("foo"); err != nil {
+		t.Fatalf("failed to reopen wallet: %v", err)
+	}
+	time.Sleep(250 * time.Millisecond)
+	if status, _ := wallet.Status(); status != "Unlocked" {
+		t.Fatalf("reopened wallet closed by stale timeout: %s", status)
+	}
+	// Only seed wallets of the keystore can be opened
+	account, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	plain, err := ks.Find(account)
+	if err != nil {
+		t.Fatalf("failed to find account: %v", err)
+	}
+	if err := ks.TimedOpen(&keystoreWallet{account: plain, keystore: ks}, "foo", time.Second); err != accounts.ErrUnknownWallet {
+		t.Errorf("opening plain wallet: have %v, want %v", err, accounts.ErrUnknownWallet)
+	}
+}
+
+// testChainState is a chain state reader reporting nonces for a set of accounts.
+type testChainState map[common.Address]uint64
+
+func (s testChainState) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return new(big.Int), nil
+}
+
+func (s testChainState) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (s testChainState) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (s testChainState) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return s[account], nil
+}
+
+func TestSeedWalletSelfDerive(t *testing.T) {
+	_, ks := tmpKeyStore(t, true)
+	wallet, err := ks.ImportMnemonic(testMnemonic, "foo")
+	if err != nil {
+		t.Fatalf("failed to import mnemonic: %v", err)
+	}
+	if err := wallet.Open("foo"); err != nil {
+		t.Fatalf("failed to open wallet: %v", err)
+	}
+	// Mark the first two accounts as used
+	var (
+		chain = make(testChainState)
+		want  []common.Address
+	)
+	for i := 0; i < 3; i++ {
+		path := append(accounts.DerivationPath{}, accounts.DefaultBaseDerivationPath...)
+		path[len(path)-1] += uint32(i)
+
+		account, err := wallet.Derive(path, false)
+		if err != nil {
+			t.Fatalf("failed to derive account %d: %v", i, err)
+		}
+		if i < 2 {
+			chain[account.Address] = 1
+		}
+		want = append(want, account.Address)
+	}
+	wallet.SelfDerive([]accounts.DerivationPath{accounts.DefaultBaseDerivationPath}, chain)
+
+	// The used accounts and the first unused one should be discovered
+	accs := wallet.Accounts()
+	if len(accs) != len(want) {
+		t.Fatalf("discovered account count mismatch: have %d, want %d", len(accs), len(want))
+	}
+	for i, account := range accs {
+		if account.Address != want[i] {
+			t.Errorf("account %d: address mismatch: have %x, want %x", i, account.Address, want[i])
+		}
+	}
+}
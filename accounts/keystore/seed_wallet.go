@@ -0,0 +1,425 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Minimum time to wait between self derivation attempts, even it the user is
+// requesting accounts like crazy.
+const selfDeriveThrottling = time.Second
+
+// seedWallet implements the accounts.Wallet interface for a BIP-39 mnemonic
+// stored encrypted in the keystore, deriving its accounts along BIP-32 paths.
+type seedWallet struct {
+	url      accounts.URL // Textual URL uniquely identifying this wallet
+	keystore *KeyStore    // Keystore where the seed file originates from
+
+	seed     []byte                                     // BIP-39 seed, nil while the wallet is closed
+	abort    chan struct{}                              // Cancels the expiry of a timed open, nil if opened indefinitely
+	accounts []accounts.Account                         // List of derived accounts pinned in the wallet
+	paths    map[common.Address]accounts.DerivationPath // Known derivation paths for signing operations
+
+	deriveNextPaths []accounts.DerivationPath // Next derivation paths for account auto-discovery (multiple bases supported)
+	deriveNextAddrs []common.Address          // Next derived account addresses for auto-discovery (multiple bases supported)
+	deriveChain     ethereum.ChainStateReader // Blockchain state reader to discover used account with
+	deriveTime      time.Time                 // Time of the last self-derivation, protected by deriveLock
+
+	stateLock  sync.RWMutex // Protects read and write access to the wallet struct fields
+	deriveLock sync.Mutex   // Serializes self-derivations, skipped when busy
+}
+
+func newSeedWallet(ks *KeyStore, path string) *seedWallet {
+	return &seedWallet{
+		url:      accounts.URL{Scheme: KeyStoreScheme, Path: path},
+		keystore: ks,
+	}
+}
+
+// URL implements accounts.Wallet, returning the URL of the seed file.
+func (w *seedWallet) URL() accounts.URL {
+	return w.url
+}
+
+// Status implements accounts.Wallet, returning whether the seed of the wallet
+// is decrypted or not.
+func (w *seedWallet) Status() (string, error) {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	if w.seed != nil {
+		return "Unlocked", nil
+	}
+	return "Locked", nil
+}
+
+// Open implements accounts.Wallet, decrypting the mnemonic of the wallet with
+// the passphrase, after which accounts can be derived from it.
+func (w *seedWallet) Open(passphrase string) error {
+	return w.timedOpen(passphrase, 0)
+}
+
+// timedOpen decrypts the mnemonic of the wallet with the passphrase, keeping the
+// seed in memory for the given duration. A timeout of 0 keeps the wallet open
+// until it is explicitly closed.
+func (w *seedWallet) timedOpen(passphrase string, timeout time.Duration) error {
+	w.stateLock.Lock()
+	if w.seed != nil {
+		w.stateLock.Unlock()
+		return accounts.ErrWalletAlreadyOpen
+	}
+	seed, err := readSeed(w.url.Path, passphrase)
+	if err != nil {
+		w.stateLock.Unlock()
+		return err
+	}
+	w.seed = seed
+	w.paths = make(map[common.Address]accounts.DerivationPath)
+	if timeout > 0 {
+		w.abort = make(chan struct{})
+		go w.expire(w.abort, timeout)
+	}
+	w.stateLock.Unlock()
+
+	w.keystore.updateFeed.Send(accounts.WalletEvent{Wallet: w, Kind: accounts.WalletOpened})
+	return nil
+}
+
+// expire closes the wallet after the timeout, unless the open it was started
+// for is aborted in the meantime.
+func (w *seedWallet) expire(abort chan struct{}, timeout time.Duration) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-abort:
+		// just quit
+	case <-t.C:
+		w.stateLock.Lock()
+		// only close if the wallet wasn't reopened since, which can be checked
+		// by pointer equality as every timed open creates a new channel
+		if w.abort == abort {
+			w.close()
+		}
+		w.stateLock.Unlock()
+	}
+}
+
+// Close implements accounts.Wallet, dropping the decrypted seed and all the
+// accounts derived from it.
+func (w *seedWallet) Close() error {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	w.close()
+	return nil
+}
+
+// close drops the decrypted seed and all the accounts derived from it. The state
+// lock must be held by the caller.
+func (w *seedWallet) close() {
+	if w.abort != nil {
+		close(w.abort)
+		w.abort = nil
+	}
+	for i := range w.seed {
+		w.seed[i] = 0
+	}
+	w.seed, w.accounts, w.paths = nil, nil, nil
+	w.deriveNextPaths, w.deriveNextAddrs, w.deriveChain = nil, nil, nil
+}
+
+// Accounts implements accounts.Wallet, returning the list of accounts pinned to
+// the wallet. If self-derivation was enabled, the account list is periodically
+// expanded based on current chain state.
+func (w *seedWallet) Accounts() []accounts.Account {
+	w.selfDerive()
+
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	cpy := make([]accounts.Account, len(w.accounts))
+	copy(cpy, w.accounts)
+	return cpy
+}
+
+// selfDerive attempts to find new non-zero accounts along the self-derivation
+// base paths. Derivation runs at most once per throttling period, and is skipped
+// if another one is in progress.
+func (w *seedWallet) selfDerive() {
+	if !w.deriveLock.TryLock() {
+		return
+	}
+	defer w.deriveLock.Unlock()
+
+	if time.Since(w.deriveTime) < selfDeriveThrottling {
+		return
+	}
+	// Derivation needs a chain and an open wallet, skip if either unavailable
+	w.stateLock.RLock()
+	if w.seed == nil || w.deriveChain == nil {
+		w.stateLock.RUnlock()
+		return
+	}
+	var (
+		seed      = common.CopyBytes(w.seed)
+		chain     = w.deriveChain
+		nextPaths = make([]accounts.DerivationPath, len(w.deriveNextPaths))
+		nextAddrs = append([]common.Address{}, w.deriveNextAddrs...)
+	)
+	for i, path := range w.deriveNextPaths {
+		nextPaths[i] = append(accounts.DerivationPath{}, path...)
+	}
+	w.stateLock.RUnlock()
+
+	defer func() {
+		for i := range seed {
+			seed[i] = 0
+		}
+	}()
+	// Derive the next batch of accounts, stopping at the first empty one
+	var (
+		accs  []accounts.Account
+		paths []accounts.DerivationPath
+		err   error
+	)
+	for i := 0; i < len(nextAddrs) && err == nil; i++ {
+		for empty := false; !empty; {
+			// Retrieve the next derived Ethereum account
+			if nextAddrs[i] == (common.Address{}) {
+				if nextAddrs[i], err = deriveAddress(seed, nextPaths[i]); err != nil {
+					log.Warn("Seed wallet account derivation failed", "err", err)
+					break
+				}
+			}
+			// Check the account's status against the current chain state
+			var (
+				balance *big.Int
+				nonce   uint64
+			)
+			balance, err = chain.BalanceAt(context.Background(), nextAddrs[i], nil)
+			if err != nil {
+				log.Warn("Seed wallet balance retrieval failed", "err", err)
+				break
+			}
+			nonce, err = chain.NonceAt(context.Background(), nextAddrs[i], nil)
+			if err != nil {
+				log.Warn("Seed wallet nonce retrieval failed", "err", err)
+				break
+			}
+			// Track the account, even the first empty one to be used next
+			path := append(accounts.DerivationPath{}, nextPaths[i]...)
+			paths = append(paths, path)
+			accs = append(accs, w.account(nextAddrs[i], path))
+
+			if balance.Sign() == 0 && nonce == 0 {
+				empty = true
+				continue
+			}
+			// Fetch the next potential account
+			nextAddrs[i] = common.Address{}
+			nextPaths[i][len(nextPaths[i])-1]++
+		}
+	}
+	w.deriveTime = time.Now()
+
+	// Insert any accounts successfully derived, unless the wallet was closed
+	// in the meantime
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	if w.seed == nil {
+		return
+	}
+	for i := 0; i < len(accs); i++ {
+		if _, ok := w.paths[accs[i].Address]; !ok {
+			log.Info("Seed wallet discovered new account", "address", accs[i].Address, "path", paths[i])
+			w.accounts = append(w.accounts, accs[i])
+			w.paths[accs[i].Address] = paths[i]
+		}
+	}
+	if err == nil {
+		w.deriveNextAddrs = nextAddrs
+		w.deriveNextPaths = nextPaths
+	}
+}
+
+// Contains implements accounts.Wallet, returning whether a particular account is
+// or is not pinned into this wallet instance.
+func (w *seedWallet) Contains(account accounts.Account) bool {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	_, exists := w.paths[account.Address]
+	return exists
+}
+
+// Derive implements accounts.Wallet, deriving a new account at the specific
+// derivation path. If pin is set to true, the account will be added to the list
+// of tracked accounts.
+func (w *seedWallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	if w.seed == nil {
+		return accounts.Account{}, accounts.ErrWalletClosed
+	}
+	address, err := deriveAddress(w.seed, path)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	account := w.account(address, path)
+	if !pin {
+		return account, nil
+	}
+	if _, ok := w.paths[address]; !ok {
+		w.accounts = append(w.accounts, account)
+		w.paths[address] = append(accounts.DerivationPath{}, path...)
+	}
+	return account, nil
+}
+
+// SelfDerive implements accounts.Wallet, trying to discover accounts that the
+// user used previously (based on the chain state), but ones that they did not
+// explicitly pin to the wallet manually. To avoid chain head monitoring, self
+// derivation only runs during account listing (and even then throttled).
+func (w *seedWallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	w.deriveNextPaths = make([]accounts.DerivationPath, len(bases))
+	for i, base := range bases {
+		w.deriveNextPaths[i] = append(accounts.DerivationPath{}, base...)
+	}
+	w.deriveNextAddrs = make([]common.Address, len(bases))
+	w.deriveChain = chain
+}
+
+// account creates the account entry of an address derived along the given path.
+func (w *seedWallet) account(address common.Address, path accounts.DerivationPath) accounts.Account {
+	return accounts.Account{
+		Address: address,
+		URL:     accounts.URL{Scheme: w.url.Scheme, Path: fmt.Sprintf("%s/%s", w.url.Path, path)},
+	}
+}
+
+// privateKey derives the private key of a pinned account. If the passphrase is
+// given, the seed is decrypted from disk instead of using the cached one.
+func (w *seedWallet) privateKey(account accounts.Account, passphrase *string) (*ecdsa.PrivateKey, error) {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	path, ok := w.paths[account.Address]
+	if !ok {
+		return nil, accounts.ErrUnknownAccount
+	}
+	if passphrase == nil {
+		return deriveKey(w.seed, path)
+	}
+	seed, err := readSeed(w.url.Path, *passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range seed {
+			seed[i] = 0
+		}
+	}()
+	return deriveKey(seed, path)
+}
+
+// signHash attempts to sign the given hash with the given account, using either
+// the opened wallet or the passphrase as authentication.
+func (w *seedWallet) signHash(account accounts.Account, passphrase *string, hash []byte) ([]byte, error) {
+	key, err := w.privateKey(account, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+	return crypto.Sign(hash, key)
+}
+
+// signTx attempts to sign the given transaction with the given account, using
+// either the opened wallet or the passphrase as authentication.
+func (w *seedWallet) signTx(account accounts.Account, passphrase *string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	key, err := w.privateKey(account, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+
+	// Depending on the presence of the chain ID, sign with or without replay protection.
+	signer := types.LatestSignerForChainID(chainID)
+	return types.SignTx(tx, signer, key)
+}
+
+// SignData signs keccak256(data). The mimetype parameter describes the type of data being signed.
+func (w *seedWallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, nil, crypto.Keccak256(data))
+}
+
+// SignDataWithPassphrase signs keccak256(data). The mimetype parameter describes the type of data being signed.
+func (w *seedWallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, &passphrase, crypto.Keccak256(data))
+}
+
+// SignText implements accounts.Wallet, attempting to sign the hash of
+// the given text with the given account.
+func (w *seedWallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return w.signHash(account, nil, accounts.TextHash(text))
+}
+
+// SignTextWithPassphrase implements accounts.Wallet, attempting to sign the
+// hash of the given text with the given account using passphrase as extra authentication.
+func (w *seedWallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return w.signHash(account, &passphrase, accounts.TextHash(text))
+}
+
+// SignTx implements accounts.Wallet, attempting to sign the given transaction
+// with the given account. If the wallet does not contain this particular account,
+// an error is returned.
+func (w *seedWallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.signTx(account, nil, tx, chainID)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet, attempting to sign the given
+// transaction with the given account using passphrase as extra authentication.
+func (w *seedWallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.signTx(account, &passphrase, tx, chainID)
+}
+
+// deriveAddress derives the address of the account at the given path.
+func deriveAddress(seed []byte, path accounts.DerivationPath) (common.Address, error) {
+	key, err := deriveKey(seed, path)
+	if err != nil {
+		return common.Address{}, err
+	}
+	defer zeroKey(key)
+	return crypto.PubkeyToAddress(key.PublicKey), nil
+}
@@ -0,0 +1,286 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package kms implements an account backend signing with keys held by an
+// out-of-process key management service, such as an HSM or a cloud KMS.
+//
+// The key service is reached via JSON-RPC over a UNIX socket and needs to
+// implement the following methods:
+//
+//	kms_version()                  -> string
+//	kms_listKeys()                 -> [{"id": string, "publicKey": hex}]
+//	kms_sign(id string, digest hex) -> hex
+//
+// Public keys may be returned compressed, uncompressed or DER encoded. Signatures
+// may be returned as raw [R || S] or DER encoded, as they are normalised to the
+// Ethereum format (low S, with recovery id) locally.
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Scheme is the URL scheme prefix of key service wallets.
+const Scheme = "kms"
+
+// signTimeout is the maximum time to wait for the key service to sign a digest.
+// Hardware backed services may be slow, so be generous.
+const signTimeout = 30 * time.Second
+
+// Key is a signing key held by the key service.
+type Key struct {
+	ID        string        `json:"id"`        // Identifier of the key within the service
+	PublicKey hexutil.Bytes `json:"publicKey"` // Public key in compressed, uncompressed or DER form
+}
+
+// Backend is an account backend exposing the keys held by a key service.
+type Backend struct {
+	wallet *Wallet
+}
+
+// NewBackend connects to the key service listening on the given endpoint.
+func NewBackend(endpoint string) (*Backend, error) {
+	wallet, err := NewWallet(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &Backend{wallet: wallet}, nil
+}
+
+// Wallets implements accounts.Backend, returning the single key service wallet.
+func (b *Backend) Wallets() []accounts.Wallet {
+	return []accounts.Wallet{b.wallet}
+}
+
+// Subscribe implements accounts.Backend. The key service wallet is static, so no
+// events are ever emitted.
+func (b *Backend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// Close terminates the connection to the key service.
+func (b *Backend) Close() {
+	b.wallet.client.Close()
+}
+
+// remoteKey is a key of the service along with its parsed public key.
+type remoteKey struct {
+	id  string
+	pub *ecdsa.PublicKey
+}
+
+// Wallet is an accounts.Wallet signing with the keys of a key service.
+type Wallet struct {
+	client   *rpc.Client
+	endpoint string
+	status   string
+
+	keys map[common.Address]*remoteKey // Cached keys of the service, nil if not yet fetched
+	lock sync.RWMutex                  // Protects the key cache
+}
+
+// NewWallet connects to the key service listening on the given endpoint.
+func NewWallet(endpoint string) (*Wallet, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var version string
+	if err := client.Call(&version, "kms_version"); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &Wallet{
+		client:   client,
+		endpoint: endpoint,
+		status:   fmt.Sprintf("ok [version=%v]", version),
+	}, nil
+}
+
+// URL implements accounts.Wallet, returning the URL of the key service.
+func (w *Wallet) URL() accounts.URL {
+	return accounts.URL{Scheme: Scheme, Path: w.endpoint}
+}
+
+// Status implements accounts.Wallet, returning the version of the key service.
+func (w *Wallet) Status() (string, error) {
+	return w.status, nil
+}
+
+// Open implements accounts.Wallet, but is a noop as key services are always open.
+func (w *Wallet) Open(passphrase string) error { return nil }
+
+// Close implements accounts.Wallet, but is a noop as key services are always open.
+func (w *Wallet) Close() error { return nil }
+
+// Accounts implements accounts.Wallet, refreshing and returning the list of keys
+// held by the key service.
+func (w *Wallet) Accounts() []accounts.Account {
+	keys, err := w.refresh()
+	if err != nil {
+		log.Error("Key service listing failed", "endpoint", w.endpoint, "err", err)
+		return nil
+	}
+	accs := make([]accounts.Account, 0, len(keys))
+	for addr := range keys {
+		accs = append(accs, accounts.Account{Address: addr, URL: w.URL()})
+	}
+	sort.Slice(accs, func(i, j int) bool {
+		return bytes.Compare(accs[i].Address[:], accs[j].Address[:]) < 0
+	})
+	return accs
+}
+
+// refresh fetches the list of keys from the key service and updates the cache.
+func (w *Wallet) refresh() (map[common.Address]*remoteKey, error) {
+	var res []Key
+	if err := w.client.Call(&res, "kms_listKeys"); err != nil {
+		return nil, err
+	}
+	keys := make(map[common.Address]*remoteKey)
+	for _, key := range res {
+		pub, err := ParsePublicKey(key.PublicKey)
+		if err != nil {
+			log.Warn("Skipping invalid key service key", "id", key.ID, "err", err)
+			continue
+		}
+		keys[crypto.PubkeyToAddress(*pub)] = &remoteKey{id: key.ID, pub: pub}
+	}
+	w.lock.Lock()
+	w.keys = keys
+	w.lock.Unlock()
+	return keys, nil
+}
+
+// key returns the service key of the given account, refreshing the cache if the
+// account is not yet known.
+func (w *Wallet) key(account accounts.Account) (*remoteKey, error) {
+	if account.URL != (accounts.URL{}) && account.URL != w.URL() {
+		return nil, accounts.ErrUnknownAccount
+	}
+	w.lock.RLock()
+	key := w.keys[account.Address]
+	w.lock.RUnlock()
+
+	if key == nil {
+		keys, err := w.refresh()
+		if err != nil {
+			return nil, err
+		}
+		if key = keys[account.Address]; key == nil {
+			return nil, accounts.ErrUnknownAccount
+		}
+	}
+	return key, nil
+}
+
+// Contains implements accounts.Wallet, returning whether a particular account is
+// or is not held by the key service.
+func (w *Wallet) Contains(account accounts.Account) bool {
+	_, err := w.key(account)
+	return err == nil
+}
+
+// Derive implements accounts.Wallet, but is a noop as key services don't have a
+// notion of hierarchical account derivation.
+func (w *Wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop as key services don't
+// have a notion of hierarchical account derivation.
+func (w *Wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+}
+
+// signHash requests the key service to sign the given digest with the key of the
+// account, and normalises the returned signature into the [R || S || V] format.
+func (w *Wallet) signHash(account accounts.Account, hash []byte) ([]byte, error) {
+	key, err := w.key(account)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), signTimeout)
+	defer cancel()
+
+	var sig hexutil.Bytes
+	if err := w.client.CallContext(ctx, &sig, "kms_sign", key.id, hexutil.Bytes(hash)); err != nil {
+		return nil, err
+	}
+	return NormalizeSignature(hash, sig, key.pub)
+}
+
+// SignData implements accounts.Wallet, attempting to sign the keccak256 hash of
+// the given data with the given account.
+func (w *Wallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, crypto.Keccak256(data))
+}
+
+// SignDataWithPassphrase implements accounts.Wallet. Key services handle their
+// own authentication, so the passphrase is ignored.
+func (w *Wallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return w.SignData(account, mimeType, data)
+}
+
+// SignText implements accounts.Wallet, attempting to sign the hash of the given
+// text with the given account.
+func (w *Wallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return w.signHash(account, accounts.TextHash(text))
+}
+
+// SignTextWithPassphrase implements accounts.Wallet. Key services handle their
+// own authentication, so the passphrase is ignored.
+func (w *Wallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return w.SignText(account, text)
+}
+
+// SignTx implements accounts.Wallet, attempting to sign the given transaction
+// with the given account.
+func (w *Wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	// Depending on the presence of the chain ID, sign with 2718 or homestead
+	signer := types.LatestSignerForChainID(chainID)
+
+	sig, err := w.signHash(account, signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet. Key services handle their own
+// authentication, so the passphrase is ignored.
+func (w *Wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.SignTx(account, tx, chainID)
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package kms
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestBackend starts a stand-in key service on a UNIX socket holding the given
+// keys and connects a backend to it.
+func newTestBackend(t *testing.T, keys ...*ecdsa.PrivateKey) *Backend {
+	t.Helper()
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("kms", NewKeyService(keys...)); err != nil {
+		t.Fatalf("failed to register key service: %v", err)
+	}
+	endpoint := filepath.Join(t.TempDir(), "kms.ipc")
+	listener, err := net.Listen("unix", endpoint)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", endpoint, err)
+	}
+	go server.ServeListener(listener)
+
+	backend, err := NewBackend(endpoint)
+	if err != nil {
+		t.Fatalf("failed to connect to key service: %v", err)
+	}
+	t.Cleanup(func() {
+		backend.Close()
+		listener.Close()
+		server.Stop()
+	})
+	return backend
+}
+
+func TestBackendAccounts(t *testing.T) {
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	backend := newTestBackend(t, key1, key2)
+
+	wallets := backend.Wallets()
+	if len(wallets) != 1 {
+		t.Fatalf("wallet count mismatch: have %d, want 1", len(wallets))
+	}
+	wallet := wallets[0]
+	if status, err := wallet.Status(); err != nil || status != "ok [version=memory/1.0.0]" {
+		t.Fatalf("status mismatch: have %q, %v", status, err)
+	}
+	accs := wallet.Accounts()
+	if len(accs) != 2 {
+		t.Fatalf("account count mismatch: have %d, want 2", len(accs))
+	}
+	for _, key := range []*ecdsa.PrivateKey{key1, key2} {
+		if !wallet.Contains(accounts.Account{Address: crypto.PubkeyToAddress(key.PublicKey)}) {
+			t.Errorf("account %x not contained", crypto.PubkeyToAddress(key.PublicKey))
+		}
+	}
+	if wallet.Contains(accounts.Account{Address: common.Address{0x01}}) {
+		t.Errorf("unknown account contained")
+	}
+}
+
+func TestBackendSigning(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	wallet := newTestBackend(t, key).Wallets()[0]
+	account := accounts.Account{Address: addr}
+
+	// Sign some text and ensure it recovers to the key
+	text := []byte("hello world")
+	sig, err := wallet.SignText(account, text)
+	if err != nil {
+		t.Fatalf("failed to sign text: %v", err)
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(text), sig)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if have := crypto.PubkeyToAddress(*pub); have != addr {
+		t.Fatalf("text signer mismatch: have %x, want %x", have, addr)
+	}
+	// Sign both a legacy and a dynamic fee transaction and check the sender
+	chainID := big.NewInt(1337)
+	for i, tx := range []*types.Transaction{
+		types.NewTransaction(0, common.Address{0xaa}, big.NewInt(1), 21000, big.NewInt(1), nil),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, To: &common.Address{0xaa}, Gas: 21000, GasFeeCap: big.NewInt(2), GasTipCap: big.NewInt(1)}),
+	} {
+		signed, err := wallet.SignTx(account, tx, chainID)
+		if err != nil {
+			t.Fatalf("tx %d: failed to sign: %v", i, err)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+		if err != nil {
+			t.Fatalf("tx %d: failed to recover sender: %v", i, err)
+		}
+		if sender != addr {
+			t.Fatalf("tx %d: sender mismatch: have %x, want %x", i, sender, addr)
+		}
+	}
+	// Signing with unknown accounts should fail
+	if _, err := wallet.SignData(accounts.Account{Address: common.Address{0x01}}, accounts.MimetypeTextPlain, text); err != accounts.ErrUnknownAccount {
+		t.Fatalf("unknown account error mismatch: have %v, want %v", err, accounts.ErrUnknownAccount)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// KeyService is an in-memory stand-in for a key management service, holding its
+// keys unprotected. It mimics the behaviour of real services by returning DER
+// encoded signatures without a recovery id, and is meant for testing and local
+// development only. Register it on an rpc.Server under the "kms" namespace.
+type KeyService struct {
+	keys []*ecdsa.PrivateKey
+}
+
+// NewKeyService creates a stand-in key service holding the given keys. The keys
+// are identified by their index in the list.
+func NewKeyService(keys ...*ecdsa.PrivateKey) *KeyService {
+	return &KeyService{keys: keys}
+}
+
+// Version returns the version of the key service.
+func (s *KeyService) Version() string {
+	return "memory/1.0.0"
+}
+
+// ListKeys returns the identifiers and DER encoded public keys of all the keys.
+func (s *KeyService) ListKeys() []Key {
+	keys := make([]Key, len(s.keys))
+	for i, key := range s.keys {
+		pub := crypto.FromECDSAPub(&key.PublicKey)
+		blob, _ := asn1.Marshal(subjectPublicKeyInfo{
+			Algorithm: pkix.AlgorithmIdentifier{
+				Algorithm:  oidPublicKeyECDSA,
+				Parameters: asn1.RawValue{FullBytes: oidSecp256k1DER},
+			},
+			PublicKey: asn1.BitString{Bytes: pub, BitLength: 8 * len(pub)},
+		})
+		keys[i] = Key{ID: fmt.Sprint(i), PublicKey: blob}
+	}
+	return keys
+}
+
+// Sign signs the given digest with the identified key, returning the signature
+// in DER encoding.
+func (s *KeyService) Sign(id string, digest hexutil.Bytes) (hexutil.Bytes, error) {
+	var index int
+	if _, err := fmt.Sscan(id, &index); err != nil || index < 0 || index >= len(s.keys) {
+		return nil, errors.New("unknown key")
+	}
+	sig, err := crypto.Sign(digest, s.keys[index])
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ecdsaSignature{
+		R: new(big.Int).SetBytes(sig[:32]),
+		S: new(big.Int).SetBytes(sig[32:64]),
+	})
+}
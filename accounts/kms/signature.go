@@ -0,0 +1,125 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package kms
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// errInvalidSignature is returned if the signature returned by the key service
+	// is neither a 64 byte [R || S] blob nor an ASN.1 DER encoded ECDSA signature.
+	errInvalidSignature = errors.New("invalid signature encoding")
+
+	// errSignatureMismatch is returned if the signature returned by the key service
+	// does not recover to the public key of the signing key.
+	errSignatureMismatch = errors.New("signature does not match public key")
+)
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1halfN = new(big.Int).Div(secp256k1N, big.NewInt(2))
+)
+
+var (
+	oidPublicKeyECDSA  = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1       = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	oidSecp256k1DER, _ = asn1.Marshal(oidSecp256k1)
+)
+
+// ecdsaSignature is the ASN.1 structure of an ECDSA signature, as produced by
+// most HSMs and cloud key management services.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// subjectPublicKeyInfo is the ASN.1 structure of a DER encoded public key, as
+// returned by most cloud key management services.
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// ParsePublicKey parses a secp256k1 public key in either compressed (33 bytes),
+// uncompressed (65 bytes) or ASN.1 DER encoded SubjectPublicKeyInfo form.
+func ParsePublicKey(blob []byte) (*ecdsa.PublicKey, error) {
+	switch len(blob) {
+	case 33:
+		return crypto.DecompressPubkey(blob)
+	case 65:
+		return crypto.UnmarshalPubkey(blob)
+	}
+	var info subjectPublicKeyInfo
+	rest, err := asn1.Unmarshal(blob, &info)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("invalid public key: trailing data")
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) || !bytes.Equal(info.Algorithm.Parameters.FullBytes, oidSecp256k1DER) {
+		return nil, errors.New("invalid public key: not a secp256k1 key")
+	}
+	return crypto.UnmarshalPubkey(info.PublicKey.RightAlign())
+}
+
+// NormalizeSignature converts a signature produced by a key service over the
+// given digest into the [R || S || V] format used by Ethereum, where V is 0 or
+// 1. The signature may either be a raw 64 byte [R || S] blob or ASN.1 DER
+// encoded. High S values are flipped into the lower half of the curve order as
+// required by EIP-2, and the recovery id is found by recovering the public key
+// with both candidates and comparing it to the one of the signing key.
+func NormalizeSignature(digest []byte, sig []byte, pub *ecdsa.PublicKey) ([]byte, error) {
+	var r, s *big.Int
+	if len(sig) == 64 {
+		r, s = new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	} else {
+		var parsed ecdsaSignature
+		rest, err := asn1.Unmarshal(sig, &parsed)
+		if err != nil || len(rest) > 0 || parsed.R == nil || parsed.S == nil {
+			return nil, errInvalidSignature
+		}
+		r, s = parsed.R, parsed.S
+	}
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(secp256k1N) >= 0 || s.Cmp(secp256k1N) >= 0 {
+		return nil, errInvalidSignature
+	}
+	if s.Cmp(secp256k1halfN) > 0 {
+		s = new(big.Int).Sub(secp256k1N, s)
+	}
+	out := make([]byte, crypto.SignatureLength)
+	math.ReadBits(r, out[:32])
+	math.ReadBits(s, out[32:64])
+
+	want := crypto.FromECDSAPub(pub)
+	for v := byte(0); v < 2; v++ {
+		out[crypto.RecoveryIDOffset] = v
+		if have, err := crypto.Ecrecover(digest, out); err == nil && bytes.Equal(have, want) {
+			return out, nil
+		}
+	}
+	return nil, errSignatureMismatch
+}
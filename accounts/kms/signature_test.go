@@ -0,0 +1,97 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package kms
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that signatures in all supported encodings are normalised into the low S
+// Ethereum format with the correct recovery id.
+func TestNormalizeSignature(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+
+	for i := 0; i < 16; i++ {
+		digest := crypto.Keccak256([]byte{byte(i)})
+		want, _ := crypto.Sign(digest, key)
+
+		r := new(big.Int).SetBytes(want[:32])
+		s := new(big.Int).SetBytes(want[32:64])
+		highS := new(big.Int).Sub(secp256k1N, s)
+
+		raw := make([]byte, 64)
+		math.ReadBits(r, raw[:32])
+		math.ReadBits(highS, raw[32:])
+		der, _ := asn1.Marshal(ecdsaSignature{R: r, S: s})
+		derHigh, _ := asn1.Marshal(ecdsaSignature{R: r, S: highS})
+
+		for j, sig := range [][]byte{want[:64], raw, der, derHigh} {
+			have, err := NormalizeSignature(digest, sig, &key.PublicKey)
+			if err != nil {
+				t.Fatalf("digest %d, encoding %d: failed to normalise: %v", i, j, err)
+			}
+			if !bytes.Equal(have, want) {
+				t.Fatalf("digest %d, encoding %d: signature mismatch: have %x, want %x", i, j, have, want)
+			}
+		}
+		if _, err := NormalizeSignature(digest, der, &other.PublicKey); err != errSignatureMismatch {
+			t.Fatalf("digest %d: foreign key error mismatch: have %v, want %v", i, err, errSignatureMismatch)
+		}
+	}
+}
+
+// Tests that malformed signatures are rejected.
+func TestNormalizeSignatureInvalid(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	digest := crypto.Keccak256(nil)
+
+	overflow, _ := asn1.Marshal(ecdsaSignature{R: big.NewInt(1), S: secp256k1N})
+	zero, _ := asn1.Marshal(ecdsaSignature{R: big.NewInt(0), S: big.NewInt(1)})
+
+	for i, sig := range [][]byte{nil, make([]byte, 63), make([]byte, 64), overflow, zero, {0x30, 0x00}} {
+		if _, err := NormalizeSignature(digest, sig, &key.PublicKey); err != errInvalidSignature {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, errInvalidSignature)
+		}
+	}
+}
+
+// Tests that public keys in all supported encodings can be parsed.
+func TestParsePublicKey(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	want := crypto.FromECDSAPub(&key.PublicKey)
+
+	for i, blob := range [][]byte{
+		want,
+		crypto.CompressPubkey(&key.PublicKey),
+		NewKeyService(key).ListKeys()[0].PublicKey,
+	} {
+		pub, err := ParsePublicKey(blob)
+		if err != nil {
+			t.Fatalf("test %d: failed to parse public key: %v", i, err)
+		}
+		if have := crypto.FromECDSAPub(pub); !bytes.Equal(have, want) {
+			t.Fatalf("test %d: public key mismatch: have %x, want %x", i, have, want)
+		}
+	}
+}
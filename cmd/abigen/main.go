@@ -65,7 +65,7 @@ var (
 	}
 	langFlag = &cli.StringFlag{
 		Name:  "lang",
-		Usage: "Destination language for the bindings (go, java, objc)",
+		Usage: "Destination language for the bindings (go, java, objc, ts)",
 		Value: "go",
 	}
 	aliasFlag = &cli.StringFlag{
@@ -95,9 +95,6 @@ func init() {
 func abigen(c *cli.Context) error {
 	utils.CheckExclusive(c, abiFlag, jsonFlag) // Only one source can be selected.
 
-	if c.String(pkgFlag.Name) == "" {
-		utils.Fatalf("No destination package specified (--pkg)")
-	}
 	var lang bind.Lang
 	switch c.String(langFlag.Name) {
 	case "go":
@@ -107,9 +104,15 @@ func abigen(c *cli.Context) error {
 	case "objc":
 		lang = bind.LangObjC
 		utils.Fatalf("Objc binding generation is uncompleted")
+	case "ts":
+		lang = bind.LangTS
 	default:
 		utils.Fatalf("Unsupported destination language \"%s\" (--lang)", c.String(langFlag.Name))
 	}
+	// TypeScript bindings are modules on their own, all others need a package
+	if lang != bind.LangTS && c.String(pkgFlag.Name) == "" {
+		utils.Fatalf("No destination package specified (--pkg)")
+	}
 	// If the entire solidity code was specified, build and bind based on that
 	var (
 		abis    []string
@@ -36,6 +36,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/accounts/kms"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -116,6 +117,10 @@ var (
 		Usage: "File used for writing new 4byte-identifiers submitted via API",
 		Value: "./4byte-custom.json",
 	}
+	kmsFlag = &cli.StringFlag{
+		Name:  "kms",
+		Usage: "Path to the IPC socket of an external key management service to sign with",
+	}
 	auditLogFlag = &cli.StringFlag{
 		Name:  "auditlog",
 		Usage: "File used to emit audit logs. Set to \"\" to disable",
@@ -125,6 +130,24 @@ var (
 		Name:  "rules",
 		Usage: "Path to the rule file to auto-authorize requests with",
 	}
+	policyFlag = &cli.StringFlag{
+		Name:  "policy",
+		Usage: "Path to the declarative (YAML or JSON) policy file to auto-authorize requests with",
+	}
+	quorumOperatorsFlag = &cli.StringFlag{
+		Name:  "quorum.operators",
+		Usage: "Comma separated addresses of the operators required to approve transactions (enables M-of-N approval)",
+	}
+	quorumThresholdFlag = &cli.IntFlag{
+		Name:  "quorum.threshold",
+		Usage: "Number of operator approvals required to sign a transaction",
+		Value: 1,
+	}
+	quorumTimeoutFlag = &cli.DurationFlag{
+		Name:  "quorum.timeout",
+		Usage: "Time after which transactions lacking operator approvals are rejected",
+		Value: 10 * time.Minute,
+	}
 	stdiouiFlag = &cli.BoolFlag{
 		Name: "stdio-ui",
 		Usage: "Use STDIN/STDOUT as a channel for an external UI. " +
@@ -159,8 +182,8 @@ the rule-engine to work.`,
 			signerSecretFlag,
 		},
 		Description: `
-The attest command stores the sha256 of the rule.js-file that you want to use for automatic processing of
-incoming requests.
+The attest command stores the sha256 of the rule.js-file or policy file that you want to use for automatic
+processing of incoming requests.
 
 Whenever you make an edit to the rule file, you need to use attestation to tell
 Clef that the file is 'safe' to execute.`,
@@ -270,6 +293,7 @@ func init() {
 		utils.LightKDFFlag,
 		utils.NoUSBFlag,
 		utils.SmartCardDaemonPathFlag,
+		kmsFlag,
 		utils.HTTPListenAddrFlag,
 		utils.HTTPVirtualHostsFlag,
 		utils.IPCDisabledFlag,
@@ -280,6 +304,10 @@ func init() {
 		customDBFlag,
 		auditLogFlag,
 		ruleFlag,
+		policyFlag,
+		quorumOperatorsFlag,
+		quorumThresholdFlag,
+		quorumTimeoutFlag,
 		stdiouiFlag,
 		testFlag,
 		advancedMode,
@@ -397,6 +425,19 @@ func attestFile(ctx *cli.Context) error {
 	return nil
 }
 
+// attested checks whether the sha256 of a rule or policy file matches the one
+// stored via the attest command.
+func attested(configStorage storage.Storage, data []byte) bool {
+	shasum := sha256.Sum256(data)
+	foundShaSum := hex.EncodeToString(shasum[:])
+	storedShasum, _ := configStorage.Get("ruleset_sha256")
+	if storedShasum != foundShaSum {
+		log.Warn("Rule hash not attested, disabling", "hash", foundShaSum, "attested", storedShasum)
+		return false
+	}
+	return true
+}
+
 func initInternalApi(c *cli.Context) (*core.UIServerAPI, core.UIClientAPI, error) {
 	if err := initialize(c); err != nil {
 		return nil, nil, err
@@ -624,6 +665,9 @@ func signer(c *cli.Context) error {
 	if c.NArg() > 0 {
 		return fmt.Errorf("invalid command: %q", c.Args().First())
 	}
+	if c.IsSet(ruleFlag.Name) && c.IsSet(policyFlag.Name) {
+		return fmt.Errorf("flags --%s and --%s are mutually exclusive", ruleFlag.Name, policyFlag.Name)
+	}
 	if err := initialize(c); err != nil {
 		return err
 	}
@@ -646,6 +690,16 @@ func signer(c *cli.Context) error {
 	embeds, locals := db.Size()
 	log.Info("Loaded 4byte database", "embeds", embeds, "locals", locals, "local", fourByteLocal)
 
+	// Audit logging
+	var auditLog log.Logger
+	if logfile := c.String(auditLogFlag.Name); logfile != "" {
+		auditLog, err = core.NewAuditLog(logfile)
+		if err != nil {
+			utils.Fatalf(err.Error())
+		}
+		log.Info("Audit logs configured", "file", logfile)
+	}
+
 	var (
 		api       core.ExternalAPI
 		pwStorage storage.Storage = &storage.NoStorage{}
@@ -671,24 +725,54 @@ func signer(c *cli.Context) error {
 			ruleJS, err := os.ReadFile(ruleFile)
 			if err != nil {
 				log.Warn("Could not load rules, disabling", "file", ruleFile, "err", err)
-			} else {
-				shasum := sha256.Sum256(ruleJS)
-				foundShaSum := hex.EncodeToString(shasum[:])
-				storedShasum, _ := configStorage.Get("ruleset_sha256")
-				if storedShasum != foundShaSum {
-					log.Warn("Rule hash not attested, disabling", "hash", foundShaSum, "attested", storedShasum)
-				} else {
-					// Initialize rules
-					ruleEngine, err := rules.NewRuleEvaluator(ui, jsStorage)
-					if err != nil {
-						utils.Fatalf(err.Error())
-					}
-					ruleEngine.Init(string(ruleJS))
-					ui = ruleEngine
-					log.Info("Rule engine configured", "file", c.String(ruleFlag.Name))
+			} else if attested(configStorage, ruleJS) {
+				// Initialize rules
+				ruleEngine, err := rules.NewRuleEvaluator(ui, jsStorage)
+				if err != nil {
+					utils.Fatalf(err.Error())
 				}
+				ruleEngine.Init(string(ruleJS))
+				ui = ruleEngine
+				log.Info("Rule engine configured", "file", c.String(ruleFlag.Name))
 			}
 		}
+		// Do we have a policy-file?
+		if policyFile := c.String(policyFlag.Name); policyFile != "" {
+			policy, err := os.ReadFile(policyFile)
+			if err != nil {
+				log.Warn("Could not load policy, disabling", "file", policyFile, "err", err)
+			} else if attested(configStorage, policy) {
+				policykey := crypto.Keccak256([]byte("policystorage"), stretchedKey)
+				policyStorage := storage.NewAESEncryptedStorage(filepath.Join(vaultLocation, "policystorage.json"), policykey)
+
+				policyEngine, err := rules.NewPolicyEvaluator(ui, policyStorage, db)
+				if err != nil {
+					utils.Fatalf(err.Error())
+				}
+				if err := policyEngine.Init(policy); err != nil {
+					utils.Fatalf("Invalid policy: %v", err)
+				}
+				ui = policyEngine
+				log.Info("Policy engine configured", "file", policyFile)
+			}
+		}
+	}
+	// Do we require multiple operators to approve transactions?
+	var quorum *core.QuorumUI
+	if c.IsSet(quorumOperatorsFlag.Name) {
+		var operators []common.Address
+		for _, operator := range utils.SplitAndTrim(c.String(quorumOperatorsFlag.Name)) {
+			if !common.IsHexAddress(operator) {
+				utils.Fatalf("Invalid quorum operator address %q", operator)
+			}
+			operators = append(operators, common.HexToAddress(operator))
+		}
+		quorum, err = core.NewQuorumUI(ui, operators, c.Int(quorumThresholdFlag.Name), c.Duration(quorumTimeoutFlag.Name), auditLog)
+		if err != nil {
+			utils.Fatalf(err.Error())
+		}
+		ui = quorum
+		log.Info("Multi-operator approval configured", "operators", len(operators), "threshold", c.Int(quorumThresholdFlag.Name))
 	}
 	var (
 		chainId  = c.Int64(chainIdFlag.Name)
@@ -701,6 +785,14 @@ func signer(c *cli.Context) error {
 	log.Info("Starting signer", "chainid", chainId, "keystore", ksLoc,
 		"light-kdf", lightKdf, "advanced", advanced)
 	am := core.StartClefAccountManager(ksLoc, nousb, lightKdf, scpath)
+	if endpoint := c.String(kmsFlag.Name); endpoint != "" {
+		backend, err := kms.NewBackend(endpoint)
+		if err != nil {
+			utils.Fatalf("Failed to connect to key management service: %v", err)
+		}
+		am.AddBackend(backend)
+		log.Info("Key management service connected", "endpoint", endpoint)
+	}
 	apiImpl := core.NewSignerAPI(am, chainId, nousb, ui, db, advanced, pwStorage)
 
 	// Establish the bidirectional communication, by creating a new UI backend and registering
@@ -708,13 +800,8 @@ func signer(c *cli.Context) error {
 	ui.RegisterUIServer(core.NewUIServerAPI(apiImpl))
 	api = apiImpl
 
-	// Audit logging
-	if logfile := c.String(auditLogFlag.Name); logfile != "" {
-		api, err = core.NewAuditLogger(logfile, api)
-		if err != nil {
-			utils.Fatalf(err.Error())
-		}
-		log.Info("Audit logs configured", "file", logfile)
+	if auditLog != nil {
+		api = core.NewAuditLoggerWithLog(auditLog, api)
 	}
 	// register signer API with server
 	var (
@@ -727,12 +814,20 @@ func signer(c *cli.Context) error {
 			Service:   api,
 		},
 	}
+	modules := []string{"account"}
+	if quorum != nil {
+		rpcAPI = append(rpcAPI, rpc.API{
+			Namespace: "operator",
+			Service:   core.NewQuorumAPI(quorum),
+		})
+		modules = append(modules, "operator")
+	}
 	if c.Bool(utils.HTTPEnabledFlag.Name) {
 		vhosts := utils.SplitAndTrim(c.String(utils.HTTPVirtualHostsFlag.Name))
 		cors := utils.SplitAndTrim(c.String(utils.HTTPCORSDomainFlag.Name))
 
 		srv := rpc.NewServer()
-		err := node.RegisterApis(rpcAPI, []string{"account"}, srv)
+		err := node.RegisterApis(rpcAPI, modules, srv)
 		if err != nil {
 			utils.Fatalf("Could not register API: %w", err)
 		}
@@ -17,6 +17,8 @@
 package main
 
 import (
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -32,10 +34,21 @@ type crawler struct {
 	ch        chan *enode.Node
 	closed    chan struct{}
 
+	// for RLPx probing
+	prober       *rlpxProber
+	probeCh      chan *enode.Node
+	probeResults chan probeResult
+
 	// settings
 	revalidateInterval time.Duration
 }
 
+type probeResult struct {
+	id   enode.ID
+	info *probeInfo
+	err  error
+}
+
 type resolver interface {
 	RequestENR(*enode.Node) (*enode.Node, error)
 }
@@ -49,6 +62,9 @@ func newCrawler(input nodeSet, disc resolver, iters ...enode.Iterator) *crawler
 		inputIter: enode.IterNodes(input.nodes()),
 		ch:        make(chan *enode.Node),
 		closed:    make(chan struct{}),
+
+		probeCh:      make(chan *enode.Node),
+		probeResults: make(chan probeResult),
 	}
 	c.iters = append(c.iters, c.inputIter)
 	// Copy input to output initially. Any nodes that fail validation
@@ -65,17 +81,38 @@ func (c *crawler) run(timeout time.Duration) nodeSet {
 		timeoutCh    <-chan time.Time
 		doneCh       = make(chan enode.Iterator, len(c.iters))
 		liveIters    = len(c.iters)
+		probeQueue   []*enode.Node
+		probers      sync.WaitGroup
 	)
 	defer timeoutTimer.Stop()
 	for _, it := range c.iters {
 		go c.runIterator(doneCh, it)
 	}
+	if c.prober != nil {
+		probers.Add(c.prober.workers)
+		for i := 0; i < c.prober.workers; i++ {
+			go c.runProber(&probers)
+		}
+	}
 
 loop:
 	for {
+		var (
+			probeCh   chan<- *enode.Node
+			nextProbe *enode.Node
+		)
+		if len(probeQueue) > 0 {
+			probeCh, nextProbe = c.probeCh, probeQueue[0]
+		}
 		select {
 		case n := <-c.ch:
-			c.updateNode(n)
+			if nn := c.updateNode(n); nn != nil && c.prober != nil {
+				probeQueue = append(probeQueue, nn)
+			}
+		case probeCh <- nextProbe:
+			probeQueue = probeQueue[1:]
+		case r := <-c.probeResults:
+			c.updateProbe(r)
 		case it := <-doneCh:
 			if it == c.inputIter {
 				// Enable timeout when we're done revalidating the input nodes.
@@ -99,6 +136,7 @@ loop:
 	for ; liveIters > 0; liveIters-- {
 		<-doneCh
 	}
+	probers.Wait()
 	return c.output
 }
 
@@ -113,12 +151,32 @@ func (c *crawler) runIterator(done chan<- enode.Iterator, it enode.Iterator) {
 	}
 }
 
-func (c *crawler) updateNode(n *enode.Node) {
+// runProber probes nodes received from the crawler loop.
+func (c *crawler) runProber(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case n := <-c.probeCh:
+			info, err := c.prober.probe(n)
+			select {
+			case c.probeResults <- probeResult{id: n.ID(), info: info, err: err}:
+			case <-c.closed:
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// updateNode validates a node and stores it in the output set. It returns the
+// resolved node if it responded.
+func (c *crawler) updateNode(n *enode.Node) *enode.Node {
 	node, ok := c.output[n.ID()]
 
 	// Skip validation of recently-seen nodes.
 	if ok && time.Since(node.LastCheck) < c.revalidateInterval {
-		return
+		return nil
 	}
 
 	// Request the node record.
@@ -128,7 +186,7 @@ func (c *crawler) updateNode(n *enode.Node) {
 		if node.Score == 0 {
 			// Node doesn't implement EIP-868.
 			log.Debug("Skipping node", "id", n.ID())
-			return
+			return nil
 		}
 		node.Score /= 2
 	} else {
@@ -149,6 +207,32 @@ func (c *crawler) updateNode(n *enode.Node) {
 		log.Info("Updating node", "id", n.ID(), "seq", n.Seq(), "score", node.Score)
 		c.output[n.ID()] = node
 	}
+	if err != nil {
+		return nil
+	}
+	return node.N
+}
+
+// updateProbe stores the result of probing a node in the output set.
+func (c *crawler) updateProbe(r probeResult) {
+	node, ok := c.output[r.id]
+	if !ok {
+		return // removed while probing
+	}
+	node.LastProbe = truncNow()
+	if r.info != nil {
+		node.Client = r.info.Client
+		node.Caps = r.info.Caps
+		if r.info.Eth != nil || errors.Is(r.err, errNoStatus) {
+			node.EthStatus = r.info.Eth
+		}
+	}
+	if r.err != nil {
+		log.Debug("Node probe failed", "id", r.id, "err", r.err)
+	} else {
+		log.Debug("Probed node", "id", r.id, "client", node.Client)
+	}
+	c.output[r.id] = node
 }
 
 func truncNow() time.Time {
@@ -0,0 +1,396 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/urfave/cli/v2"
+)
+
+// Keys in the crawl database.
+const (
+	crawlKeyPrefix       = "crawl:" // crawl:<start> -> crawlRecord
+	nodeKeyPrefix        = "node:"  // node:<id> -> nodeHistory
+	observationKeyPrefix = "obs:"   // obs:<id><time> -> nodeObservation
+)
+
+// crawlDB stores the results of crawler runs over time.
+//
+// For every node, the database keeps the time it was first and last seen along with
+// the most recent observation of its attributes. Observations are also appended to
+// the history of the node whenever any attribute changes.
+type crawlDB struct {
+	lvl *leveldb.DB
+}
+
+// crawlRecord describes a single crawler run.
+type crawlRecord struct {
+	Start, End uint64 // UNIX time
+	Nodes      uint64 // number of nodes which responded
+}
+
+// nodeHistory is the summary of all observations of a node.
+type nodeHistory struct {
+	FirstSeen uint64 // UNIX time
+	LastSeen  uint64 // UNIX time
+	Last      nodeObservation
+}
+
+// nodeObservation holds the attributes of a node at the time it was seen.
+type nodeObservation struct {
+	Time   uint64 // UNIX time
+	Seq    uint64
+	IP     net.IP
+	ASN    uint64
+	Client string // RLPx client identifier, empty if unknown
+	ForkID forkid.ID
+}
+
+// sameAttributes reports whether two observations differ only in their time.
+func (o *nodeObservation) sameAttributes(o2 *nodeObservation) bool {
+	return o.Seq == o2.Seq && o.IP.Equal(o2.IP) && o.ASN == o2.ASN && o.Client == o2.Client && o.ForkID == o2.ForkID
+}
+
+// writeCrawlDB stores the result of a crawler run in the database given by --crawldb.
+func writeCrawlDB(ctx *cli.Context, start time.Time, ns nodeSet) error {
+	if !ctx.IsSet(crawlDBFlag.Name) {
+		return nil
+	}
+	var asn *asnTable
+	if ctx.IsSet(asnFileFlag.Name) {
+		var err error
+		if asn, err = loadASNTable(ctx.String(asnFileFlag.Name)); err != nil {
+			return err
+		}
+	}
+	db, err := openCrawlDB(ctx.String(crawlDBFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.addCrawl(start, truncNow(), ns, asn)
+}
+
+func openCrawlDB(path string) (*crawlDB, error) {
+	lvl, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &crawlDB{lvl: lvl}, nil
+}
+
+// Close closes the database.
+func (db *crawlDB) Close() error {
+	return db.lvl.Close()
+}
+
+// addCrawl stores the nodes which responded during a crawler run.
+func (db *crawlDB) addCrawl(start, end time.Time, ns nodeSet, asn *asnTable) error {
+	batch := new(leveldb.Batch)
+	var count uint64
+	for id, n := range ns {
+		if n.LastResponse.Before(start) {
+			continue // not seen in this crawl
+		}
+		obs := newNodeObservation(n, asn)
+		hist, err := db.history(id)
+		if err != nil {
+			return err
+		}
+		if hist == nil {
+			hist = &nodeHistory{FirstSeen: obs.Time}
+		}
+		if hist.LastSeen == 0 || !hist.Last.sameAttributes(&obs) {
+			enc, _ := rlp.EncodeToBytes(&obs)
+			batch.Put(observationKey(id, obs.Time), enc)
+		}
+		hist.LastSeen = obs.Time
+		hist.Last = obs
+		enc, _ := rlp.EncodeToBytes(hist)
+		batch.Put(nodeKey(id), enc)
+		count++
+	}
+	rec := crawlRecord{Start: uint64(start.Unix()), End: uint64(end.Unix()), Nodes: count}
+	enc, _ := rlp.EncodeToBytes(&rec)
+	batch.Put(crawlKey(rec.Start), enc)
+	return db.lvl.Write(batch, nil)
+}
+
+// newNodeObservation creates an observation from a node set entry.
+func newNodeObservation(n nodeJSON, asn *asnTable) nodeObservation {
+	obs := nodeObservation{
+		Time:   uint64(n.LastResponse.Unix()),
+		Seq:    n.N.Seq(),
+		IP:     n.N.IP(),
+		Client: n.Client,
+	}
+	if asn != nil {
+		obs.ASN, _ = asn.lookup(obs.IP)
+	}
+	var eth struct {
+		ForkID forkid.ID
+		Tail   []rlp.RawValue `rlp:"tail"`
+	}
+	if n.N.Load(enr.WithEntry("eth", &eth)) == nil {
+		obs.ForkID = eth.ForkID
+	} else if n.EthStatus != nil {
+		obs.ForkID = n.EthStatus.forkID()
+	}
+	return obs
+}
+
+// history returns the observation summary of a node, or nil if it was never seen.
+func (db *crawlDB) history(id enode.ID) (*nodeHistory, error) {
+	blob, err := db.lvl.Get(nodeKey(id), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	hist := new(nodeHistory)
+	if err := rlp.DecodeBytes(blob, hist); err != nil {
+		return nil, fmt.Errorf("invalid history of node %v: %v", id, err)
+	}
+	return hist, nil
+}
+
+// crawls returns the crawler runs which started at or after the given time.
+func (db *crawlDB) crawls(since time.Time) ([]crawlRecord, error) {
+	rng := util.BytesPrefix([]byte(crawlKeyPrefix))
+	if since.Unix() > 0 {
+		rng.Start = crawlKey(uint64(since.Unix()))
+	}
+	it := db.lvl.NewIterator(rng, nil)
+	defer it.Release()
+
+	var list []crawlRecord
+	for it.Next() {
+		var rec crawlRecord
+		if err := rlp.DecodeBytes(it.Value(), &rec); err != nil {
+			return nil, fmt.Errorf("invalid crawl record: %v", err)
+		}
+		list = append(list, rec)
+	}
+	return list, it.Error()
+}
+
+// forEachNode calls fn for the history of every node in the database.
+func (db *crawlDB) forEachNode(fn func(enode.ID, *nodeHistory)) error {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(nodeKeyPrefix)), nil)
+	defer it.Release()
+
+	for it.Next() {
+		var (
+			id   enode.ID
+			hist nodeHistory
+		)
+		copy(id[:], it.Key()[len(nodeKeyPrefix):])
+		if err := rlp.DecodeBytes(it.Value(), &hist); err != nil {
+			return fmt.Errorf("invalid history of node %v: %v", id, err)
+		}
+		fn(id, &hist)
+	}
+	return it.Error()
+}
+
+func crawlKey(start uint64) []byte {
+	key := make([]byte, len(crawlKeyPrefix)+8)
+	copy(key, crawlKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(crawlKeyPrefix):], start)
+	return key
+}
+
+func nodeKey(id enode.ID) []byte {
+	return append([]byte(nodeKeyPrefix), id[:]...)
+}
+
+func observationKey(id enode.ID, time uint64) []byte {
+	key := make([]byte, len(observationKeyPrefix)+len(id)+8)
+	copy(key, observationKeyPrefix)
+	copy(key[len(observationKeyPrefix):], id[:])
+	binary.BigEndian.PutUint64(key[len(observationKeyPrefix)+len(id):], time)
+	return key
+}
+
+// crawlStats summarizes the crawl database over a time window.
+type crawlStats struct {
+	Crawls int // crawler runs in window
+	Seen   int // nodes seen in window
+	Live   int // nodes seen by the latest crawl
+	New    int // nodes seen for the first time in window
+	Gone   int // nodes seen in window, but not by the latest crawl
+
+	Clients  map[string]int // client name/version -> node count
+	ForkIDs  map[forkid.ID]int
+	ASNs     map[uint64]int
+	ASNNames map[uint64]string
+}
+
+// stats computes statistics over the crawler runs since the given time.
+func (db *crawlDB) stats(since time.Time, asn *asnTable) (*crawlStats, error) {
+	crawls, err := db.crawls(since)
+	if err != nil {
+		return nil, err
+	}
+	st := &crawlStats{
+		Crawls:   len(crawls),
+		Clients:  make(map[string]int),
+		ForkIDs:  make(map[forkid.ID]int),
+		ASNs:     make(map[uint64]int),
+		ASNNames: make(map[uint64]string),
+	}
+	if len(crawls) == 0 {
+		return st, nil
+	}
+	var (
+		from   = uint64(since.Unix())
+		latest = crawls[len(crawls)-1].Start
+	)
+	err = db.forEachNode(func(id enode.ID, hist *nodeHistory) {
+		if hist.LastSeen < from {
+			return
+		}
+		st.Seen++
+		if hist.LastSeen >= latest {
+			st.Live++
+		} else {
+			st.Gone++
+		}
+		if hist.FirstSeen >= from {
+			st.New++
+		}
+		st.Clients[clientVersion(hist.Last.Client)]++
+		st.ForkIDs[hist.Last.ForkID]++
+		if hist.Last.ASN != 0 {
+			st.ASNs[hist.Last.ASN]++
+			if asn != nil {
+				st.ASNNames[hist.Last.ASN] = asn.names[hist.Last.ASN]
+			}
+		}
+	})
+	return st, err
+}
+
+// clientVersion returns the name and version part of an RLPx client identifier,
+// e.g. "Geth/v1.10.26-stable" for "Geth/v1.10.26-stable-e5eb32ac/linux-amd64/go1.18.5".
+func clientVersion(client string) string {
+	if client == "" {
+		return "unknown"
+	}
+	parts := strings.Split(client, "/")
+	name := parts[0]
+	for _, p := range parts[1:] {
+		if strings.HasPrefix(p, "v") {
+			// Strip the commit hash from versions like v1.10.26-stable-e5eb32ac.
+			if i := strings.LastIndexByte(p, '-'); i > 0 && strings.Count(p, "-") > 1 {
+				p = p[:i]
+			}
+			return name + "/" + p
+		}
+	}
+	return name
+}
+
+// asnTable maps IP addresses to autonomous system numbers.
+//
+// The table is loaded from a file in the TSV format distributed by iptoasn.com, where
+// each line contains the start and end address of a range, the AS number, country code
+// and AS description.
+type asnTable struct {
+	ranges []asnRange // sorted by start
+	names  map[uint64]string
+}
+
+type asnRange struct {
+	start, end net.IP // 16-byte form
+	asn        uint64
+}
+
+func loadASNTable(file string) (*asnTable, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	t := &asnTable{names: make(map[uint64]string)}
+	scanner := bufio.NewScanner(fd)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		var (
+			start = net.ParseIP(fields[0])
+			end   = net.ParseIP(fields[1])
+		)
+		asn, err := strconv.ParseUint(fields[2], 10, 64)
+		if start == nil || end == nil || err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid ASN range", file, line)
+		}
+		if asn == 0 {
+			continue // not routed
+		}
+		t.ranges = append(t.ranges, asnRange{start: start.To16(), end: end.To16(), asn: asn})
+		if len(fields) >= 5 {
+			t.names[asn] = fields[4]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(t.ranges, func(i, j int) bool {
+		return bytes.Compare(t.ranges[i].start, t.ranges[j].start) < 0
+	})
+	return t, nil
+}
+
+// lookup returns the AS number of the given IP.
+func (t *asnTable) lookup(ip net.IP) (uint64, bool) {
+	ip = ip.To16()
+	if ip == nil {
+		return 0, false
+	}
+	// Find the last range starting at or before ip.
+	i := sort.Search(len(t.ranges), func(i int) bool {
+		return bytes.Compare(t.ranges[i].start, ip) > 0
+	})
+	if i == 0 {
+		return 0, false
+	}
+	r := t.ranges[i-1]
+	if bytes.Compare(ip, r.end) > 0 {
+		return 0, false
+	}
+	return r.asn, true
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+func testCrawlNode(i byte, ip net.IP) *enode.Node {
+	var r enr.Record
+	r.Set(enr.IP(ip))
+	return enode.SignNull(&r, enode.ID{i})
+}
+
+func TestCrawlDBStats(t *testing.T) {
+	db, err := openCrawlDB(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var (
+		t0 = time.Unix(1000000, 0)
+		t1 = t0.Add(time.Hour)
+		n1 = testCrawlNode(1, net.IP{1, 1, 1, 1})
+		n2 = testCrawlNode(2, net.IP{2, 2, 2, 2})
+		n3 = testCrawlNode(3, net.IP{3, 3, 3, 3})
+	)
+	// The first crawl sees nodes 1 and 2.
+	crawl1 := nodeSet{
+		n1.ID(): {N: n1, LastResponse: t0.Add(time.Minute)},
+		n2.ID(): {N: n2, LastResponse: t0.Add(time.Minute)},
+	}
+	if err := db.addCrawl(t0, t0.Add(10*time.Minute), crawl1, nil); err != nil {
+		t.Fatal(err)
+	}
+	// The second crawl sees nodes 1 and 3. Node 2 is still in the set, but
+	// didn't respond during the crawl.
+	crawl2 := nodeSet{
+		n1.ID(): {N: n1, LastResponse: t1.Add(time.Minute)},
+		n2.ID(): {N: n2, LastResponse: t0.Add(time.Minute)},
+		n3.ID(): {N: n3, LastResponse: t1.Add(time.Minute)},
+	}
+	if err := db.addCrawl(t1, t1.Add(10*time.Minute), crawl2, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	hist, err := db.history(n1.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hist.FirstSeen != uint64(t0.Add(time.Minute).Unix()) || hist.LastSeen != uint64(t1.Add(time.Minute).Unix()) {
+		t.Fatalf("wrong first/last seen of node 1: %d/%d", hist.FirstSeen, hist.LastSeen)
+	}
+
+	// Stats over both crawls.
+	st, err := db.stats(t0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Crawls != 2 || st.Seen != 3 || st.Live != 2 || st.New != 3 || st.Gone != 1 {
+		t.Fatalf("wrong stats: %+v", st)
+	}
+	if st.Clients["unknown"] != 3 {
+		t.Fatalf("wrong client counts: %v", st.Clients)
+	}
+	// Stats over the second crawl only.
+	st, err = db.stats(t1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Crawls != 1 || st.Seen != 2 || st.Live != 2 || st.New != 1 || st.Gone != 0 {
+		t.Fatalf("wrong stats: %+v", st)
+	}
+}
+
+func TestASNTable(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ip2asn.tsv")
+	data := "1.0.0.0\t1.0.0.255\t13335\tUS\tCLOUDFLARENET\n" +
+		"1.0.4.0\t1.0.7.255\t38803\tAU\tGTELECOM-AUSTRALIA\n" +
+		"1.0.8.0\t1.0.15.255\t0\tNone\tNot routed\n" +
+		"2001:200::\t2001:200:ffff:ffff:ffff:ffff:ffff:ffff\t2500\tJP\tWIDE-BB\n"
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	table, err := loadASNTable(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ip  string
+		asn uint64
+	}{
+		{"1.0.0.1", 13335},
+		{"1.0.3.1", 0},
+		{"1.0.7.255", 38803},
+		{"1.0.9.1", 0},
+		{"2001:200::1", 2500},
+		{"8.8.8.8", 0},
+	}
+	for _, test := range tests {
+		if asn, _ := table.lookup(net.ParseIP(test.ip)); asn != test.asn {
+			t.Errorf("lookup(%s) = %d, want %d", test.ip, asn, test.asn)
+		}
+	}
+	if table.names[13335] != "CLOUDFLARENET" {
+		t.Errorf("wrong AS name %q", table.names[13335])
+	}
+}
+
+func TestClientVersion(t *testing.T) {
+	tests := map[string]string{
+		"": "unknown",
+		"Geth/v1.10.26-stable-e5eb32ac/linux-amd64/go1.18.5": "Geth/v1.10.26-stable",
+		"Geth/v1.10.26-stable/linux-amd64/go1.18.5":          "Geth/v1.10.26-stable",
+		"Geth/mynode/v1.10.26-stable/linux-amd64/go1.18.5":   "Geth/v1.10.26-stable",
+		"erigon/v2.29.0-4e3d1ae4/linux-amd64/go1.18.1":       "erigon/v2.29.0-4e3d1ae4",
+		"besu": "besu",
+	}
+	for id, want := range tests {
+		if v := clientVersion(id); v != want {
+			t.Errorf("clientVersion(%q) = %q, want %q", id, v, want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/devp2p/internal/ethtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/rlpx"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/urfave/cli/v2"
+)
+
+// devp2p base protocol message codes.
+const (
+	helloMsg      = 0x00
+	disconnectMsg = 0x01
+	pingMsg       = 0x02
+	pongMsg       = 0x03
+
+	baseProtocolLength = 16
+	ethStatusMsg       = baseProtocolLength + eth.StatusMsg
+)
+
+var errNoStatus = errors.New("eth protocol not supported")
+
+// probeInfo is the information obtained by probing a node.
+type probeInfo struct {
+	Client string
+	Caps   []string
+	Eth    *ethStatusJSON
+}
+
+// ethStatusJSON is the eth protocol status of a node, as stored in node sets.
+type ethStatusJSON struct {
+	Version   uint32        `json:"version"`
+	NetworkID uint64        `json:"networkID"`
+	Genesis   common.Hash   `json:"genesis"`
+	Head      common.Hash   `json:"head"`
+	TD        *hexutil.Big  `json:"td"`
+	ForkHash  hexutil.Bytes `json:"forkHash"`
+	ForkNext  uint64        `json:"forkNext,omitempty"`
+}
+
+// forkID returns the fork identifier contained in the status.
+func (st *ethStatusJSON) forkID() forkid.ID {
+	id := forkid.ID{Next: st.ForkNext}
+	copy(id.Hash[:], st.ForkHash)
+	return id
+}
+
+// rlpxProber performs the RLPx and eth protocol handshakes with nodes in order to
+// learn about their client software and chain.
+type rlpxProber struct {
+	key     *ecdsa.PrivateKey
+	timeout time.Duration
+	workers int
+}
+
+// newRLPXProber creates a prober from the command line flags. It returns nil if
+// probing is not enabled.
+func newRLPXProber(ctx *cli.Context) *rlpxProber {
+	if !ctx.Bool(crawlProbeFlag.Name) {
+		return nil
+	}
+	workers := ctx.Int(crawlProbeWorkersFlag.Name)
+	if workers < 1 {
+		exit(fmt.Errorf("invalid --%s %d", crawlProbeWorkersFlag.Name, workers))
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		exit(err)
+	}
+	return &rlpxProber{key: key, timeout: ctx.Duration(crawlProbeTimeoutFlag.Name), workers: workers}
+}
+
+// probe connects to the node and performs the handshakes. If the node doesn't support
+// the eth protocol, the returned info contains the client identifier and
+// capabilities only.
+func (p *rlpxProber) probe(n *enode.Node) (*probeInfo, error) {
+	if n.TCP() == 0 {
+		return nil, errors.New("node has no TCP endpoint")
+	}
+	addr := &net.TCPAddr{IP: n.IP(), Port: n.TCP()}
+	fd, err := net.DialTimeout("tcp", addr.String(), p.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	fd.SetDeadline(time.Now().Add(p.timeout))
+
+	conn := rlpx.NewConn(fd, n.Pubkey())
+	if _, err := conn.Handshake(p.key); err != nil {
+		return nil, err
+	}
+	// Exchange hello messages.
+	ours := &ethtest.Hello{
+		Version: 5,
+		Name:    "devp2p-crawler",
+		ID:      crypto.FromECDSAPub(&p.key.PublicKey)[1:],
+	}
+	for _, version := range eth.ProtocolVersions {
+		ours.Caps = append(ours.Caps, p2p.Cap{Name: eth.ProtocolName, Version: version})
+	}
+	if err := writeProbeMsg(conn, helloMsg, ours); err != nil {
+		return nil, err
+	}
+	hello := new(ethtest.Hello)
+	if err := readProbeMsg(conn, helloMsg, hello); err != nil {
+		return nil, err
+	}
+	info := &probeInfo{Client: hello.Name}
+	for _, cap := range hello.Caps {
+		info.Caps = append(info.Caps, cap.String())
+	}
+	if hello.Version >= 5 {
+		conn.SetSnappy(true)
+	}
+	if negotiateEth(ours.Caps, hello.Caps) == 0 {
+		return info, errNoStatus
+	}
+
+	// Wait for the status message. Our own status isn't sent because we don't know
+	// the chain of the node, so we disconnect afterwards.
+	status := new(eth.StatusPacket)
+	if err := readProbeMsg(conn, ethStatusMsg, status); err != nil {
+		return info, err
+	}
+	info.Eth = &ethStatusJSON{
+		Version:   status.ProtocolVersion,
+		NetworkID: status.NetworkID,
+		Genesis:   status.Genesis,
+		Head:      status.Head,
+		TD:        (*hexutil.Big)(status.TD),
+		ForkHash:  status.ForkID.Hash[:],
+		ForkNext:  status.ForkID.Next,
+	}
+	writeProbeMsg(conn, disconnectMsg, []p2p.DiscReason{p2p.DiscRequested})
+	return info, nil
+}
+
+// negotiateEth returns the highest eth protocol version shared by both sides.
+func negotiateEth(ours, theirs []p2p.Cap) uint {
+	var version uint
+	for _, our := range ours {
+		for _, their := range theirs {
+			if our == their && our.Name == eth.ProtocolName && our.Version > version {
+				version = our.Version
+			}
+		}
+	}
+	return version
+}
+
+func writeProbeMsg(conn *rlpx.Conn, code uint64, msg interface{}) error {
+	data, err := rlp.EncodeToBytes(msg)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(code, data)
+	return err
+}
+
+// readProbeMsg reads messages until one with the given code is received and decodes
+// it into msg. Pings are answered while waiting.
+func readProbeMsg(conn *rlpx.Conn, want uint64, msg interface{}) error {
+	for {
+		code, data, _, err := conn.Read()
+		if err != nil {
+			return err
+		}
+		switch code {
+		case want:
+			return rlp.DecodeBytes(data, msg)
+		case disconnectMsg:
+			var reason []p2p.DiscReason
+			if rlp.DecodeBytes(data, &reason); len(reason) == 0 {
+				return errors.New("disconnected")
+			}
+			return fmt.Errorf("disconnected: %v", reason[0])
+		case pingMsg:
+			writeProbeMsg(conn, pongMsg, []interface{}{})
+		case helloMsg:
+			return errors.New("unexpected hello message")
+		}
+	}
+}
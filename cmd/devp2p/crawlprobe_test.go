@@ -0,0 +1,115 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+func startProbeTestServer(t *testing.T, protocols []p2p.Protocol) *p2p.Server {
+	key, _ := crypto.GenerateKey()
+	srv := &p2p.Server{Config: p2p.Config{
+		PrivateKey:  key,
+		Name:        "test-client/v1.0.0",
+		MaxPeers:    10,
+		ListenAddr:  "127.0.0.1:0",
+		NoDiscovery: true,
+		Protocols:   protocols,
+	}}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+func TestRLPXProbe(t *testing.T) {
+	status := &eth.StatusPacket{
+		ProtocolVersion: eth.ETH67,
+		NetworkID:       1337,
+		TD:              big.NewInt(100),
+		Head:            common.Hash{1},
+		Genesis:         common.Hash{2},
+		ForkID:          forkid.ID{Hash: [4]byte{1, 2, 3, 4}, Next: 50},
+	}
+	srv := startProbeTestServer(t, []p2p.Protocol{{
+		Name:    eth.ProtocolName,
+		Version: eth.ETH67,
+		Length:  17,
+		Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
+			if err := p2p.Send(rw, eth.StatusMsg, status); err != nil {
+				return err
+			}
+			_, err := rw.ReadMsg()
+			return err
+		},
+	}})
+	defer srv.Stop()
+
+	key, _ := crypto.GenerateKey()
+	prober := &rlpxProber{key: key, timeout: 5 * time.Second, workers: 1}
+	info, err := prober.probe(srv.Self())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Client != "test-client/v1.0.0" {
+		t.Errorf("wrong client %q", info.Client)
+	}
+	if len(info.Caps) != 1 || info.Caps[0] != "eth/67" {
+		t.Errorf("wrong caps %v", info.Caps)
+	}
+	if info.Eth == nil {
+		t.Fatal("no eth status")
+	}
+	if info.Eth.NetworkID != status.NetworkID || info.Eth.Genesis != status.Genesis || info.Eth.Head != status.Head {
+		t.Errorf("wrong status %+v", info.Eth)
+	}
+	if info.Eth.forkID() != status.ForkID {
+		t.Errorf("wrong fork ID %v", info.Eth.forkID())
+	}
+}
+
+func TestRLPXProbeNoEth(t *testing.T) {
+	srv := startProbeTestServer(t, []p2p.Protocol{{
+		Name:    "foo",
+		Version: 1,
+		Length:  1,
+		Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
+			_, err := rw.ReadMsg()
+			return err
+		},
+	}})
+	defer srv.Stop()
+
+	key, _ := crypto.GenerateKey()
+	prober := &rlpxProber{key: key, timeout: 5 * time.Second, workers: 1}
+	info, err := prober.probe(srv.Self())
+	if !errors.Is(err, errNoStatus) {
+		t.Fatalf("wrong error %v", err)
+	}
+	if info.Client != "test-client/v1.0.0" || len(info.Caps) != 1 || info.Caps[0] != "foo/1" {
+		t.Errorf("wrong info %+v", info)
+	}
+}
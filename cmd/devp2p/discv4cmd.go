@@ -78,7 +78,7 @@ var (
 		Name:   "crawl",
 		Usage:  "Updates a nodes.json file with random nodes found in the DHT",
 		Action: discv4Crawl,
-		Flags:  flags.Merge(discoveryNodeFlags, []cli.Flag{crawlTimeoutFlag}),
+		Flags:  flags.Merge(discoveryNodeFlags, crawlFlags),
 	}
 	discv4TestCommand = &cli.Command{
 		Name:   "test",
@@ -120,6 +120,28 @@ var (
 		Usage: "Time limit for the crawl.",
 		Value: 30 * time.Minute,
 	}
+	crawlDBFlag = &cli.StringFlag{
+		Name:  "crawldb",
+		Usage: "Crawl database location, stores observations of all crawler runs",
+	}
+	asnFileFlag = &cli.StringFlag{
+		Name:  "asn-file",
+		Usage: "IP to AS number mapping in iptoasn.com TSV format",
+	}
+	crawlProbeFlag = &cli.BoolFlag{
+		Name:  "probe",
+		Usage: "Connects to crawled nodes via RLPx to find their client version and chain status",
+	}
+	crawlProbeWorkersFlag = &cli.IntFlag{
+		Name:  "probe-workers",
+		Usage: "Number of concurrent RLPx probes",
+		Value: 16,
+	}
+	crawlProbeTimeoutFlag = &cli.DurationFlag{
+		Name:  "probe-timeout",
+		Usage: "Time limit for probing a single node",
+		Value: 10 * time.Second,
+	}
 	remoteEnodeFlag = &cli.StringFlag{
 		Name:    "remote",
 		Usage:   "Enode of the remote node under test",
@@ -127,6 +149,15 @@ var (
 	}
 )
 
+var crawlFlags = []cli.Flag{
+	crawlTimeoutFlag,
+	crawlDBFlag,
+	asnFileFlag,
+	crawlProbeFlag,
+	crawlProbeWorkersFlag,
+	crawlProbeTimeoutFlag,
+}
+
 var discoveryNodeFlags = []cli.Flag{
 	bootnodesFlag,
 	nodekeyFlag,
@@ -214,9 +245,11 @@ func discv4Crawl(ctx *cli.Context) error {
 	defer disc.Close()
 	c := newCrawler(inputSet, disc, disc.RandomNodes())
 	c.revalidateInterval = 10 * time.Minute
+	c.prober = newRLPXProber(ctx)
+	start := truncNow()
 	output := c.run(ctx.Duration(crawlTimeoutFlag.Name))
 	writeNodesJSON(nodesFile, output)
-	return nil
+	return writeCrawlDB(ctx, start, output)
 }
 
 // discv4Test runs the protocol test suite.
@@ -55,9 +55,7 @@ var (
 		Name:   "crawl",
 		Usage:  "Updates a nodes.json file with random nodes found in the DHT",
 		Action: discv5Crawl,
-		Flags: flags.Merge(discoveryNodeFlags, []cli.Flag{
-			crawlTimeoutFlag,
-		}),
+		Flags:  flags.Merge(discoveryNodeFlags, crawlFlags),
 	}
 	discv5TestCommand = &cli.Command{
 		Name:   "test",
@@ -110,9 +108,11 @@ func discv5Crawl(ctx *cli.Context) error {
 	defer disc.Close()
 	c := newCrawler(inputSet, disc, disc.RandomNodes())
 	c.revalidateInterval = 10 * time.Minute
+	c.prober = newRLPXProber(ctx)
+	start := truncNow()
 	output := c.run(ctx.Duration(crawlTimeoutFlag.Name))
 	writeNodesJSON(nodesFile, output)
-	return nil
+	return writeCrawlDB(ctx, start, output)
 }
 
 // discv5Test runs the protocol test suite.
@@ -46,6 +46,12 @@ type nodeJSON struct {
 	LastResponse  time.Time `json:"lastResponse,omitempty"`
 	// This one tracks the time of our last attempt to contact the node.
 	LastCheck time.Time `json:"lastCheck,omitempty"`
+
+	// These fields are set when the crawler probes the node through RLPx.
+	Client    string         `json:"client,omitempty"`
+	Caps      []string       `json:"caps,omitempty"`
+	EthStatus *ethStatusJSON `json:"ethStatus,omitempty"`
+	LastProbe time.Time      `json:"lastProbe,omitempty"`
 }
 
 func loadNodesJSON(file string) nodeSet {
@@ -39,6 +39,7 @@ var (
 		Subcommands: []*cli.Command{
 			nodesetInfoCommand,
 			nodesetFilterCommand,
+			nodesetStatsCommand,
 		},
 	}
 	nodesetInfoCommand = &cli.Command{
@@ -55,6 +56,21 @@ var (
 
 		SkipFlagParsing: true,
 	}
+	nodesetStatsCommand = &cli.Command{
+		Name:      "stats",
+		Usage:     "Shows client distribution and churn from a crawl database",
+		Action:    nodesetStats,
+		ArgsUsage: "<crawldb>",
+		Flags:     []cli.Flag{statsWindowFlag, asnFileFlag},
+	}
+)
+
+var (
+	statsWindowFlag = &cli.DurationFlag{
+		Name:  "window",
+		Usage: "Time window of the statistics",
+		Value: 24 * time.Hour,
+	}
 )
 
 func nodesetInfo(ctx *cli.Context) error {
@@ -96,6 +112,85 @@ func showAttributeCounts(ns nodeSet) {
 	}
 }
 
+func nodesetStats(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return fmt.Errorf("need crawl database as argument")
+	}
+	var asn *asnTable
+	if ctx.IsSet(asnFileFlag.Name) {
+		var err error
+		if asn, err = loadASNTable(ctx.String(asnFileFlag.Name)); err != nil {
+			return err
+		}
+	}
+	db, err := openCrawlDB(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	window := ctx.Duration(statsWindowFlag.Name)
+	st, err := db.stats(time.Now().Add(-window), asn)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d crawls in the last %v.\n", st.Crawls, window)
+	if st.Crawls == 0 {
+		return nil
+	}
+	fmt.Printf("Seen %d nodes, %d in the latest crawl.\n", st.Seen, st.Live)
+	fmt.Printf("Churn: %d new, %d gone.\n", st.New, st.Gone)
+
+	fmt.Println("Clients:")
+	showCounts(st.Clients)
+	forkids := make(map[string]int, len(st.ForkIDs))
+	for id, n := range st.ForkIDs {
+		if id == (forkid.ID{}) {
+			forkids["none"] += n
+		} else {
+			forkids[fmt.Sprintf("%x/%d", id.Hash, id.Next)] += n
+		}
+	}
+	fmt.Println("Fork IDs:")
+	showCounts(forkids)
+	if len(st.ASNs) > 0 {
+		asns := make(map[string]int, len(st.ASNs))
+		for num, n := range st.ASNs {
+			name := fmt.Sprintf("AS%d", num)
+			if desc := st.ASNNames[num]; desc != "" {
+				name += " " + desc
+			}
+			asns[name] = n
+		}
+		fmt.Println("Autonomous systems:")
+		showCounts(asns)
+	}
+	return nil
+}
+
+// showCounts prints a distribution, ordered by count.
+func showCounts(counts map[string]int) {
+	var (
+		keys      []string
+		maxlength int
+	)
+	for key := range counts {
+		keys = append(keys, key)
+		if len(key) > maxlength {
+			maxlength = len(key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		fmt.Printf("%s%s: %d\n", strings.Repeat(" ", maxlength-len(key)+1), key, counts[key])
+	}
+}
+
 func nodesetFilter(ctx *cli.Context) error {
 	if ctx.NArg() < 1 {
 		return fmt.Errorf("need nodes file as argument")
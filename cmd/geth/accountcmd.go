@@ -29,6 +29,11 @@ import (
 )
 
 var (
+	mnemonicFlag = &cli.BoolFlag{
+		Name:  "mnemonic",
+		Usage: "Create a BIP-39 mnemonic seed wallet instead of a single key",
+	}
+
 	walletCommand = &cli.Command{
 		Name:      "wallet",
 		Usage:     "Manage Ethereum presale wallets",
@@ -106,6 +111,7 @@ Print a short summary of all accounts`,
 					utils.KeyStoreDirFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
+					mnemonicFlag,
 				},
 				Description: `
     geth account new
@@ -120,6 +126,10 @@ For non-interactive use the password can be specified with the --password flag:
 
 Note, this is meant to be used for testing only, it is a bad idea to save your
 password to file or expose in any other way.
+
+With the --mnemonic flag a seed wallet is created instead, storing a new BIP-39
+mnemonic encrypted with the password. Accounts are derived from the seed after
+opening the wallet with personal.openWallet and personal.deriveAccount.
 `,
 			},
 			{
@@ -149,6 +159,32 @@ For non-interactive use the password can be specified with the --password flag:
 
 Since only one password can be given, only format update can be performed,
 changing your password is only possible interactively.
+`,
+			},
+			{
+				Name:      "upgrade-kdf",
+				Usage:     "Re-encrypt existing accounts using the Argon2id KDF",
+				Action:    accountUpgradeKDF,
+				ArgsUsage: "<address>...",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+				},
+				Description: `
+    geth account upgrade-kdf <address>...
+
+Re-encrypt the key files of existing accounts, deriving the encryption key from
+the unchanged password with Argon2id instead of scrypt. You are prompted for the
+password of each account.
+
+The key files are replaced atomically in place, so a running node watching the
+keystore keeps seeing the accounts throughout the upgrade.
+
+For non-interactive use the passwords can be specified with the --password flag:
+
+    geth account upgrade-kdf [options] <address>...
 `,
 			},
 			{
@@ -277,6 +313,9 @@ func accountCreate(ctx *cli.Context) error {
 		scryptP = keystore.LightScryptP
 	}
 
+	if ctx.Bool(mnemonicFlag.Name) {
+		return mnemonicCreate(ctx, keydir, scryptN, scryptP)
+	}
 	password := utils.GetPassPhraseWithList("Your new account is locked with a password. Please give a password. Do not forget this password.", true, 0, utils.MakePasswordList(ctx))
 
 	account, err := keystore.StoreKey(keydir, password, scryptN, scryptP)
@@ -294,6 +333,37 @@ func accountCreate(ctx *cli.Context) error {
 	return nil
 }
 
+// mnemonicCreate creates a new seed wallet into the given key directory, and
+// prints its mnemonic together with the first account derived from it.
+func mnemonicCreate(ctx *cli.Context, keydir string, scryptN, scryptP int) error {
+	password := utils.GetPassPhraseWithList("Your new seed wallet is locked with a password. Please give a password. Do not forget this password.", true, 0, utils.MakePasswordList(ctx))
+
+	ks := keystore.NewKeyStore(keydir, scryptN, scryptP)
+	mnemonic, wallet, err := ks.NewMnemonic(password)
+	if err != nil {
+		utils.Fatalf("Failed to create seed wallet: %v", err)
+	}
+	if err := wallet.Open(password); err != nil {
+		utils.Fatalf("Failed to open seed wallet: %v", err)
+	}
+	defer wallet.Close()
+
+	account, err := wallet.Derive(accounts.DefaultBaseDerivationPath, false)
+	if err != nil {
+		utils.Fatalf("Failed to derive account: %v", err)
+	}
+	fmt.Printf("\nYour new seed wallet was generated\n\n")
+	fmt.Printf("Mnemonic of the seed:          %s\n", mnemonic)
+	fmt.Printf("Path of the seed file:         %s\n", wallet.URL().Path)
+	fmt.Printf("Public address of the account: %s\n", account.Address.Hex())
+	fmt.Printf("Derivation path of the account: %s\n\n", accounts.DefaultBaseDerivationPath)
+	fmt.Printf("- You can share your public addresses with anyone. Others need them to interact with you.\n")
+	fmt.Printf("- You must NEVER share the mnemonic with anyone! It controls access to the funds of all derived accounts!\n")
+	fmt.Printf("- You must BACKUP your mnemonic! Without it or the seed file, it's impossible to access account funds!\n")
+	fmt.Printf("- You must REMEMBER your password! Without the password, it's impossible to decrypt the seed file!\n\n")
+	return nil
+}
+
 // accountUpdate transitions an account from a previous format to the current
 // one, also providing the possibility to change the pass-phrase.
 func accountUpdate(ctx *cli.Context) error {
@@ -313,6 +383,34 @@ func accountUpdate(ctx *cli.Context) error {
 	return nil
 }
 
+// accountUpgradeKDF re-encrypts the key files of the given accounts with the
+// Argon2id key derivation function, keeping their passwords.
+func accountUpgradeKDF(ctx *cli.Context) error {
+	if ctx.Args().Len() == 0 {
+		utils.Fatalf("No accounts specified to upgrade")
+	}
+	stack, _ := makeConfigNode(ctx)
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+
+	var (
+		time    uint32 = keystore.StandardArgon2idT
+		memory  uint32 = keystore.StandardArgon2idM
+		threads uint8  = keystore.StandardArgon2idP
+	)
+	if stack.Config().UseLightweightKDF {
+		time, memory, threads = keystore.LightArgon2idT, keystore.LightArgon2idM, keystore.LightArgon2idP
+	}
+	passwords := utils.MakePasswordList(ctx)
+	for i, addr := range ctx.Args().Slice() {
+		account, password := unlockAccount(ks, addr, i, passwords)
+		if err := ks.UpgradeKDF(account, password, time, memory, threads); err != nil {
+			utils.Fatalf("Could not upgrade the account: %v", err)
+		}
+		fmt.Printf("Upgraded account: %s\n", account.Address.Hex())
+	}
+	return nil
+}
+
 func importWallet(ctx *cli.Context) error {
 	if ctx.Args().Len() != 1 {
 		utils.Fatalf("keyfile must be given as the only argument")
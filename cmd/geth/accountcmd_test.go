@@ -95,6 +95,30 @@ Path of the secret key file: .*UTC--.+--[0-9a-f]{40}
 `)
 }
 
+func TestAccountNewMnemonic(t *testing.T) {
+	geth := runGeth(t, "account", "new", "--lightkdf", "--mnemonic")
+	defer geth.ExpectExit()
+	geth.Expect(`
+Your new seed wallet is locked with a password. Please give a password. Do not forget this password.
+!! Unsupported terminal, password will be echoed.
+Password: {{.InputLine "foobar"}}
+Repeat password: {{.InputLine "foobar"}}
+
+Your new seed wallet was generated
+`)
+	geth.ExpectRegexp(`
+Mnemonic of the seed:          ([a-z]+ ){23}[a-z]+
+Path of the seed file:         .*seeds/UTC--.+--[0-9a-f-]{36}
+Public address of the account: 0x[0-9a-fA-F]{40}
+Derivation path of the account: m/44'/60'/0'/0/0
+
+- You can share your public addresses with anyone. Others need them to interact with you.
+- You must NEVER share the mnemonic with anyone! It controls access to the funds of all derived accounts!
+- You must BACKUP your mnemonic! Without it or the seed file, it's impossible to access account funds!
+- You must REMEMBER your password! Without the password, it's impossible to decrypt the seed file!
+`)
+}
+
 func TestAccountImport(t *testing.T) {
 	tests := []struct{ name, key, output string }{
 		{
@@ -174,6 +198,20 @@ Repeat password: {{.InputLine "foobar2"}}
 `)
 }
 
+func TestAccountUpgradeKDF(t *testing.T) {
+	datadir := tmpDatadirWithKeystore(t)
+	geth := runGeth(t, "account", "upgrade-kdf",
+		"--datadir", datadir, "--lightkdf",
+		"f466859ead1932d743d622cb74fc058882e8648a")
+	defer geth.ExpectExit()
+	geth.Expect(`
+Unlocking account f466859ead1932d743d622cb74fc058882e8648a | Attempt 1/3
+!! Unsupported terminal, password will be echoed.
+Password: {{.InputLine "foobar"}}
+Upgraded account: 0xf466859eAD1932D743d622CB74FC058882E8648A
+`)
+}
+
 func TestWalletImport(t *testing.T) {
 	geth := runGeth(t, "wallet", "import", "--lightkdf", "testdata/guswallet.json")
 	defer geth.ExpectExit()
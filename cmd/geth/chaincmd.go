@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/era"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -117,6 +118,34 @@ Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
 be gzipped.`,
+	}
+	importHistoryCommand = &cli.Command{
+		Action:    importHistory,
+		Name:      "import-history",
+		Usage:     "Import an era1 history archive",
+		ArgsUsage: "<dir>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+			utils.TxLookupLimitFlag,
+		}, utils.DatabasePathFlags, utils.NetworkFlags),
+		Description: `
+The import-history command imports the blocks, receipts and total difficulties
+from the era1 files in the given directory, as written by export-history. Every
+file is verified against its checksum and accumulator root before any of its
+blocks are imported. History can only be imported into a fresh database.`,
+	}
+	exportHistoryCommand = &cli.Command{
+		Action:    exportHistory,
+		Name:      "export-history",
+		Usage:     "Export blockchain history into an era1 archive",
+		ArgsUsage: "<dumpdir> <blockNumFirst> <blockNumLast>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+		}, utils.DatabasePathFlags, utils.NetworkFlags),
+		Description: `
+The export-history command writes the blocks, receipts and total difficulties in
+the given range into era1 files of 8192 blocks each, along with a checksums.txt
+file listing their sha256 checksums. The first block has to be a multiple of 8192.`,
 	}
 	importPreimagesCommand = &cli.Command{
 		Action:    importPreimages,
@@ -362,6 +391,61 @@ func exportChain(ctx *cli.Context) error {
 	return nil
 }
 
+// importHistory imports the era1 history archive in the specified directory.
+func importHistory(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, false)
+	defer db.Close()
+	defer chain.Stop()
+	start := time.Now()
+
+	if err := utils.ImportHistory(chain, ctx.Args().First()); err != nil {
+		utils.Fatalf("Import error: %v\n", err)
+	}
+	fmt.Printf("Import done in %v\n", time.Since(start))
+	return nil
+}
+
+// exportHistory exports the chain history into era1 files in the specified
+// directory.
+func exportHistory(ctx *cli.Context) error {
+	if ctx.Args().Len() != 3 {
+		utils.Fatalf("This command requires three arguments.")
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	start := time.Now()
+
+	var (
+		dir         = ctx.Args().Get(0)
+		first, ferr = strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+		last, lerr  = strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
+	}
+	if first%era.MaxEra1Size != 0 {
+		utils.Fatalf("Export error: first block must be a multiple of %d\n", era.MaxEra1Size)
+	}
+	if head := chain.CurrentFastBlock(); last > head.NumberU64() {
+		utils.Fatalf("Export error: block number %d larger than head block %d\n", last, head.NumberU64())
+	}
+	if err := utils.ExportHistory(chain, dir, first, last, era.MaxEra1Size); err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+	fmt.Printf("Export done in %v\n", time.Since(start))
+	return nil
+}
+
 // importPreimages imports preimage data from the specified file.
 func importPreimages(ctx *cli.Context) error {
 	if ctx.Args().Len() < 1 {
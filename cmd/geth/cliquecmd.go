@@ -0,0 +1,141 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	cliqueCommand = &cli.Command{
+		Name:        "clique",
+		Usage:       "A set of commands for managing clique voting snapshots",
+		Description: "",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "export-snapshot",
+				Usage:     "Export the voting snapshot at an epoch transition into a checkpoint file",
+				ArgsUsage: "<filename> [<epochBlockNum>]",
+				Action:    exportCliqueSnapshot,
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabasePathFlags),
+				Description: `
+geth clique export-snapshot <filename> [<epochBlockNum>]
+
+Exports the clique voting snapshot at the given epoch transition block, bundled
+with the block header itself, into a JSON checkpoint file. If no block number is
+given, the last epoch transition of the local chain is used.
+
+The checkpoint can be imported into another node via 'geth clique import-snapshot'
+or configured as a trusted checkpoint via --clique.checkpoint.
+`,
+			},
+			{
+				Name:      "import-snapshot",
+				Usage:     "Import a voting snapshot from a checkpoint file",
+				ArgsUsage: "<filename>",
+				Action:    importCliqueSnapshot,
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabasePathFlags),
+				Description: `
+geth clique import-snapshot <filename>
+
+Imports a clique voting snapshot from a JSON checkpoint file into the database.
+The snapshot is verified against the signer list in the extra-data of the bundled
+epoch transition header before import.
+`,
+			},
+		},
+	}
+)
+
+// exportCliqueSnapshot exports the voting snapshot at an epoch transition into
+// a checkpoint file.
+func exportCliqueSnapshot(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		utils.Fatalf("This command requires one or two arguments.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	defer chain.Stop()
+
+	engine := chain.Engine()
+	if b, ok := engine.(*beacon.Beacon); ok {
+		engine = b.InnerEngine()
+	}
+	c, ok := engine.(*clique.Clique)
+	if !ok {
+		return errors.New("chain is not running clique")
+	}
+	number := chain.CurrentHeader().Number.Uint64() / c.Epoch() * c.Epoch()
+	if ctx.NArg() == 2 {
+		n, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid block number: %v", err)
+		}
+		number = n
+	}
+	cp, err := c.Checkpoint(chain, number)
+	if err != nil {
+		return err
+	}
+	if err := clique.WriteCheckpoint(ctx.Args().First(), cp); err != nil {
+		return err
+	}
+	log.Info("Exported clique checkpoint", "number", cp.Snapshot.Number, "hash", cp.Snapshot.Hash, "signers", len(cp.Snapshot.Signers), "file", ctx.Args().First())
+	return nil
+}
+
+// importCliqueSnapshot imports a voting snapshot from a checkpoint file into
+// the database.
+func importCliqueSnapshot(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	config, err := core.LoadCliqueConfig(db, utils.MakeGenesis(ctx))
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return errors.New("chain is not running clique")
+	}
+	cp, err := clique.ReadCheckpoint(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	if err := clique.ImportCheckpoint(db, config, cp); err != nil {
+		return err
+	}
+	log.Info("Imported clique checkpoint", "number", cp.Snapshot.Number, "hash", cp.Snapshot.Hash, "signers", len(cp.Snapshot.Signers))
+	return nil
+}
@@ -244,12 +244,27 @@ var (
 		Value:    true,
 		Category: flags.EthCategory,
 	}
+	SnapshotPinFlag = &cli.StringFlag{
+		Name:     "snapshot.pin",
+		Usage:    "Comma separated block numbers whose state is kept available for snap sync serving",
+		Category: flags.EthCategory,
+	}
+	SnapshotPinIntervalFlag = &cli.Uint64Flag{
+		Name:     "snapshot.pininterval",
+		Usage:    "Interval of blocks whose state is kept available for snap sync serving (0 = off)",
+		Category: flags.EthCategory,
+	}
 	TxLookupLimitFlag = &cli.Uint64Flag{
 		Name:     "txlookuplimit",
 		Usage:    "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value:    ethconfig.Defaults.TxLookupLimit,
 		Category: flags.EthCategory,
 	}
+	CliqueCheckpointFlag = &cli.StringFlag{
+		Name:     "clique.checkpoint",
+		Usage:    "File containing a trusted clique checkpoint to seed the signer set from",
+		Category: flags.EthCategory,
+	}
 	LightKDFFlag = &cli.BoolFlag{
 		Name:     "lightkdf",
 		Usage:    "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -852,17 +867,42 @@ var (
 		Usage:    "Restricts network communication to the given IP networks (CIDR masks)",
 		Category: flags.NetworkingCategory,
 	}
+	NetdenyFlag = &cli.StringFlag{
+		Name:     "netdeny",
+		Usage:    "Denies network communication with the given IP networks (CIDR masks)",
+		Category: flags.NetworkingCategory,
+	}
+	NetfilterFileFlag = &cli.StringFlag{
+		Name:     "netfilter",
+		Usage:    "JSON file with IP networks to allow and deny, reloaded on change",
+		Category: flags.NetworkingCategory,
+	}
 	DNSDiscoveryFlag = &cli.StringFlag{
 		Name:     "discovery.dns",
 		Usage:    "Sets DNS discovery entry points (use \"\" to disable DNS)",
 		Category: flags.NetworkingCategory,
 	}
+	DiscoveryTopicFlag = &cli.BoolFlag{
+		Name:     "discovery.topic",
+		Usage:    "Advertises and searches for peers through discovery v5 topics (requires --v5disc)",
+		Category: flags.NetworkingCategory,
+	}
 	DiscoveryPortFlag = &cli.IntFlag{
 		Name:     "discovery.port",
 		Usage:    "Use a custom UDP port for P2P discovery",
 		Value:    30303,
 		Category: flags.NetworkingCategory,
 	}
+	TxBroadcastPeersFlag = &cli.IntFlag{
+		Name:     "txbroadcast.peers",
+		Usage:    "Number of peers to send full transactions to (0 = square root of the peer count)",
+		Category: flags.NetworkingCategory,
+	}
+	TxBroadcastMaxSizeFlag = &cli.Uint64Flag{
+		Name:     "txbroadcast.maxsize",
+		Usage:    "Size in bytes above which transactions are only announced to peers (0 = no limit)",
+		Category: flags.NetworkingCategory,
+	}
 
 	// Console
 	JSpathFlag = &flags.DirectoryFlag{
@@ -1461,6 +1501,16 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		}
 		cfg.NetRestrict = list
 	}
+	if netdeny := ctx.String(NetdenyFlag.Name); netdeny != "" {
+		list, err := netutil.ParseNetlist(netdeny)
+		if err != nil {
+			Fatalf("Option %q: %v", NetdenyFlag.Name, err)
+		}
+		cfg.NetDeny = list
+	}
+	if ctx.IsSet(NetfilterFileFlag.Name) {
+		cfg.NetFilterFile = ctx.String(NetfilterFileFlag.Name)
+	}
 
 	if ctx.Bool(DeveloperFlag.Name) {
 		// --dev mode can't use p2p networking.
@@ -1821,6 +1871,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.Uint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.IsSet(CliqueCheckpointFlag.Name) {
+		cfg.CliqueCheckpoint = ctx.String(CliqueCheckpointFlag.Name)
+	}
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheTrieFlag.Name) / 100
 	}
@@ -1848,6 +1901,19 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.SnapshotCache = 0 // Disabled
 		}
 	}
+	if ctx.IsSet(SnapshotPinFlag.Name) {
+		cfg.SnapshotPinned = nil
+		for _, entry := range strings.Split(ctx.String(SnapshotPinFlag.Name), ",") {
+			number, err := strconv.ParseUint(strings.TrimSpace(entry), 0, 64)
+			if err != nil {
+				Fatalf("Invalid pinned block number %q: %v", entry, err)
+			}
+			cfg.SnapshotPinned = append(cfg.SnapshotPinned, number)
+		}
+	}
+	if ctx.IsSet(SnapshotPinIntervalFlag.Name) {
+		cfg.SnapshotPinInterval = ctx.Uint64(SnapshotPinIntervalFlag.Name)
+	}
 	if ctx.IsSet(DocRootFlag.Name) {
 		cfg.DocRoot = ctx.String(DocRootFlag.Name)
 	}
@@ -1880,6 +1946,15 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.EthDiscoveryURLs = SplitAndTrim(urls)
 		}
 	}
+	if ctx.IsSet(DiscoveryTopicFlag.Name) {
+		cfg.DiscoveryTopic = ctx.Bool(DiscoveryTopicFlag.Name)
+	}
+	if ctx.IsSet(TxBroadcastPeersFlag.Name) {
+		cfg.TxBroadcastPeers = ctx.Int(TxBroadcastPeersFlag.Name)
+	}
+	if ctx.IsSet(TxBroadcastMaxSizeFlag.Name) {
+		cfg.TxBroadcastMaxSize = ctx.Uint64(TxBroadcastMaxSizeFlag.Name)
+	}
 	// Override any default configs for hard coded networks.
 	switch {
 	case ctx.Bool(MainnetFlag.Name):
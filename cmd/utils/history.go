@@ -0,0 +1,295 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// historyChecksums is the name of the file listing the sha256 checksums of the
+// era1 files in an exported history directory.
+const historyChecksums = "checksums.txt"
+
+// historyHeaderCheckFrequency is the frequency of header seals verified while
+// importing history.
+const historyHeaderCheckFrequency = 100
+
+// HistoryNetwork returns the network name used in the era1 file names of the
+// chain with the given genesis.
+func HistoryNetwork(genesis common.Hash, chainID *big.Int) string {
+	switch genesis {
+	case params.MainnetGenesisHash:
+		return "mainnet"
+	case params.GoerliGenesisHash:
+		return "goerli"
+	case params.SepoliaGenesisHash:
+		return "sepolia"
+	case params.RinkebyGenesisHash:
+		return "rinkeby"
+	}
+	return fmt.Sprintf("chain%d", chainID)
+}
+
+// ExportHistory exports the blocks, receipts and total difficulties in the range
+// [first, last] into era1 files of step blocks each, along with their checksums.
+func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) error {
+	log.Info("Exporting blockchain history", "dir", dir)
+	if head := bc.CurrentFastBlock().NumberU64(); head < last {
+		log.Warn("Last block beyond head, setting last = head", "head", head, "last", last)
+		last = head
+	}
+	if step == 0 || step > era.MaxEra1Size {
+		return fmt.Errorf("invalid era1 size %d", step)
+	}
+	if first > last {
+		return fmt.Errorf("invalid range: first %d > last %d", first, last)
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("error creating output directory: %w", err)
+	}
+	var (
+		network   = HistoryNetwork(bc.Genesis().Hash(), bc.Config().ChainID)
+		start     = time.Now()
+		reported  = time.Now()
+		checksums []string
+	)
+	for i := first; i <= last; i += step {
+		checksum, err := exportEra1(bc, dir, network, i, min(i+step-1, last), step, &reported)
+		if err != nil {
+			return err
+		}
+		checksums = append(checksums, checksum)
+	}
+	if err := os.WriteFile(filepath.Join(dir, historyChecksums), []byte(strings.Join(checksums, "\n")), os.ModePerm); err != nil {
+		return err
+	}
+	log.Info("Exported blockchain history", "dir", dir, "files", len(checksums), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// exportEra1 writes the blocks in the range [first, last] into an era1 file,
+// returning the checksum of the file.
+func exportEra1(bc *core.BlockChain, dir, network string, first, last, step uint64, reported *time.Time) (string, error) {
+	tmp := filepath.Join(dir, era.Filename(network, int(first/step), common.Hash{})+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", fmt.Errorf("could not create era1 file: %w", err)
+	}
+	defer f.Close()
+
+	builder := era.NewBuilder(f)
+	for n := first; n <= last; n++ {
+		block := bc.GetBlockByNumber(n)
+		if block == nil {
+			return "", fmt.Errorf("export failed on #%d: not found", n)
+		}
+		td := bc.GetTd(block.Hash(), n)
+		if td == nil {
+			return "", fmt.Errorf("export failed on #%d: total difficulty not found", n)
+		}
+		if err := builder.Add(block, bc.GetReceiptsByHash(block.Hash()), td); err != nil {
+			return "", fmt.Errorf("export failed on #%d: %w", n, err)
+		}
+		if time.Since(*reported) >= 8*time.Second {
+			log.Info("Exporting blocks", "exported", n, "elapsed", common.PrettyDuration(time.Since(*reported)))
+			*reported = time.Now()
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		return "", fmt.Errorf("export failed to finalize %d: %w", first/step, err)
+	}
+	// Compute the checksum of the entire file and move it to its final name.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("unable to calculate checksum: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, era.Filename(network, int(first/step), root))); err != nil {
+		return "", err
+	}
+	return common.BytesToHash(h.Sum(nil)).Hex(), nil
+}
+
+// ImportHistory imports the era1 files exported into the directory. The files
+// are verified against their checksums and accumulators before any of their
+// blocks are imported. History can only be imported into an empty chain.
+func ImportHistory(chain *core.BlockChain, dir string) error {
+	if chain.CurrentFastBlock().NumberU64() != 0 || chain.CurrentHeader().Number.Sign() != 0 {
+		return errors.New("history import only supported when starting from genesis")
+	}
+	network := HistoryNetwork(chain.Genesis().Hash(), chain.Config().ChainID)
+	files, err := era.ReadDir(dir, network)
+	if err != nil {
+		return fmt.Errorf("unable to read era1 files: %w", err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no era1 files of network %s found", network)
+	}
+	blob, err := os.ReadFile(filepath.Join(dir, historyChecksums))
+	if err != nil {
+		return fmt.Errorf("unable to read checksums: %w", err)
+	}
+	checksums := strings.Split(strings.TrimSpace(string(blob)), "\n")
+	if len(checksums) != len(files) {
+		return fmt.Errorf("mismatched era1 files and checksums: have %d files, %d checksums", len(files), len(checksums))
+	}
+	log.Info("Importing blockchain history", "dir", dir, "files", len(files))
+
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		imported int
+		parent   = chain.Genesis()
+		ptd      = chain.GetTd(parent.Hash(), 0)
+	)
+	for i, name := range files {
+		e, err := openVerifiedEra1(filepath.Join(dir, name), checksums[i])
+		if err != nil {
+			return err
+		}
+		err = func() error {
+			defer e.Close()
+
+			// Ensure the file continues the chain imported so far.
+			if i == 0 && e.Start() != 0 {
+				return fmt.Errorf("%s: history doesn't start at genesis", name)
+			}
+			if i > 0 && e.Start() != parent.NumberU64()+1 {
+				return fmt.Errorf("%s: wrong block range, starting at %d", name, e.Start())
+			}
+			var (
+				it       = era.NewIterator(e)
+				blocks   []*types.Block
+				receipts []types.Receipts
+			)
+			for it.Next() {
+				block := it.Block()
+				if block.NumberU64() == 0 {
+					if block.Hash() != parent.Hash() {
+						return fmt.Errorf("%s: genesis mismatch: have %x, want %x", name, block.Hash(), parent.Hash())
+					}
+					continue
+				}
+				if block.ParentHash() != parent.Hash() {
+					return fmt.Errorf("%s: block %d doesn't extend the chain", name, block.NumberU64())
+				}
+				if want := new(big.Int).Add(ptd, block.Difficulty()); it.TotalDifficulty().Cmp(want) != 0 {
+					return fmt.Errorf("%s: block %d total difficulty mismatch: have %v, want %v", name, block.NumberU64(), it.TotalDifficulty(), want)
+				}
+				blocks, receipts = append(blocks, block), append(receipts, it.Receipts())
+				parent, ptd = block, it.TotalDifficulty()
+
+				if len(blocks) == importBatchSize {
+					if err := importHistoryBatch(chain, blocks, receipts); err != nil {
+						return err
+					}
+					imported += len(blocks)
+					blocks, receipts = blocks[:0], receipts[:0]
+				}
+				if time.Since(reported) >= 8*time.Second {
+					log.Info("Importing history", "file", name, "imported", imported, "elapsed", common.PrettyDuration(time.Since(start)))
+					reported = time.Now()
+				}
+			}
+			if err := it.Error(); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if len(blocks) > 0 {
+				if err := importHistoryBatch(chain, blocks, receipts); err != nil {
+					return err
+				}
+				imported += len(blocks)
+			}
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+	}
+	log.Info("Imported blockchain history", "blocks", imported, "head", parent.NumberU64(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// openVerifiedEra1 opens an era1 file after checking it against its checksum,
+// accumulator and the integrity of the contained blocks.
+func openVerifiedEra1(path string, checksum string) (*era.Era, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open era1: %w", err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to checksum %s: %w", path, err)
+	}
+	if have := common.BytesToHash(h.Sum(nil)).Hex(); have != strings.TrimSpace(checksum) {
+		f.Close()
+		return nil, fmt.Errorf("checksum mismatch of %s: have %s, want %s", path, have, checksum)
+	}
+	e, err := era.From(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error opening era1 %s: %w", path, err)
+	}
+	if err := era.Verify(e); err != nil {
+		e.Close()
+		return nil, fmt.Errorf("verification of %s failed: %w", path, err)
+	}
+	return e, nil
+}
+
+// importHistoryBatch inserts the headers of a batch of blocks, then their bodies
+// and receipts directly into the ancient store.
+func importHistoryBatch(chain *core.BlockChain, blocks []*types.Block, receipts []types.Receipts) error {
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if n, err := chain.InsertHeaderChain(headers, historyHeaderCheckFrequency); err != nil {
+		return fmt.Errorf("error inserting header %d: %w", headers[n].Number, err)
+	}
+	if n, err := chain.InsertReceiptChain(blocks, receipts, math.MaxUint64); err != nil {
+		return fmt.Errorf("error inserting body %d: %w", blocks[n].NumberU64(), err)
+	}
+	return nil
+}
+
+func min(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,161 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	historyKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	historyAddr   = crypto.PubkeyToAddress(historyKey.PublicKey)
+)
+
+func TestHistoryImportAndExport(t *testing.T) {
+	var (
+		count   = 100
+		step    = uint64(16)
+		signer  = types.LatestSigner(params.TestChainConfig)
+		genesis = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{historyAddr: {Balance: big.NewInt(1e18)}},
+		}
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), count, func(i int, g *core.BlockGen) {
+		if i%3 == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(g.TxNonce(historyAddr), common.Address{0xaa}, big.NewInt(1000), params.TxGas, g.BaseFee(), nil), signer, historyKey)
+			g.AddTx(tx)
+		}
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("error inserting chain: %v", err)
+	}
+
+	// Export the history and check the produced files.
+	dir := t.TempDir()
+	if err := ExportHistory(chain, dir, 0, uint64(count), step); err != nil {
+		t.Fatalf("error exporting history: %v", err)
+	}
+	network := HistoryNetwork(chain.Genesis().Hash(), chain.Config().ChainID)
+	files, err := era.ReadDir(dir, network)
+	if err != nil {
+		t.Fatalf("error reading era1 files: %v", err)
+	}
+	if want := (count + int(step)) / int(step); len(files) != want {
+		t.Fatalf("wrong number of era1 files: have %d, want %d", len(files), want)
+	}
+
+	// Import the history into a fresh chain and compare it to the original.
+	db, err := rawdb.NewDatabaseWithFreezer(memorydb.New(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+	imported, err := core.NewBlockChain(db, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	defer imported.Stop()
+	if err := ImportHistory(imported, dir); err != nil {
+		t.Fatalf("failed to import history: %v", err)
+	}
+	if head := imported.CurrentFastBlock(); head.Hash() != blocks[count-1].Hash() {
+		t.Fatalf("wrong head after import: have %d, want %d", head.NumberU64(), count)
+	}
+	for _, want := range blocks {
+		have := imported.GetBlockByNumber(want.NumberU64())
+		if have == nil || have.Hash() != want.Hash() || len(have.Transactions()) != len(want.Transactions()) {
+			t.Fatalf("block %d mismatch", want.NumberU64())
+		}
+		var (
+			haveReceipts = imported.GetReceiptsByHash(want.Hash())
+			wantReceipts = chain.GetReceiptsByHash(want.Hash())
+		)
+		if !reflect.DeepEqual(haveReceipts, wantReceipts) {
+			t.Fatalf("receipts of block %d mismatch", want.NumberU64())
+		}
+		if have, want := imported.GetTd(want.Hash(), want.NumberU64()), chain.GetTd(want.Hash(), want.NumberU64()); have.Cmp(want) != 0 {
+			t.Fatalf("total difficulty mismatch: have %v, want %v", have, want)
+		}
+	}
+}
+
+func TestHistoryImportVerification(t *testing.T) {
+	genesis := &core.Genesis{Config: params.TestChainConfig}
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 32, nil)
+
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("error inserting chain: %v", err)
+	}
+	dir := t.TempDir()
+	if err := ExportHistory(chain, dir, 0, 32, 16); err != nil {
+		t.Fatalf("error exporting history: %v", err)
+	}
+	// Corrupt a single byte of the last file, the import must fail without
+	// importing any of its blocks.
+	files, _ := era.ReadDir(dir, HistoryNetwork(chain.Genesis().Hash(), chain.Config().ChainID))
+	path := filepath.Join(dir, files[len(files)-1])
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob[len(blob)/2] ^= 0xff
+	if err := os.WriteFile(path, blob, 0644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := rawdb.NewDatabaseWithFreezer(memorydb.New(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+	imported, err := core.NewBlockChain(db, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	defer imported.Stop()
+	if err := ImportHistory(imported, dir); err == nil {
+		t.Fatalf("corrupted history imported")
+	}
+	if head := imported.CurrentFastBlock().NumberU64(); head >= 32 {
+		t.Fatalf("blocks of corrupted file imported, head %d", head)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// errIncompleteCheckpoint is returned if a checkpoint file is missing either
+	// the header or the voting snapshot.
+	errIncompleteCheckpoint = errors.New("incomplete checkpoint")
+
+	// errNotEpochCheckpoint is returned if a checkpoint is attempted to be created
+	// or imported at a block which is not an epoch transition.
+	errNotEpochCheckpoint = errors.New("checkpoint not at epoch transition")
+
+	// errCheckpointHashMismatch is returned if the voting snapshot within a
+	// checkpoint does not belong to the bundled header.
+	errCheckpointHashMismatch = errors.New("checkpoint snapshot does not match header")
+
+	// errCheckpointPendingVotes is returned if a checkpoint snapshot contains
+	// votes, which are always discarded at epoch transitions.
+	errCheckpointPendingVotes = errors.New("checkpoint snapshot contains pending votes")
+
+	// errCheckpointNotCanonical is returned if a checkpoint is attempted to be
+	// imported into a database which has a different canonical block at the
+	// same height.
+	errCheckpointNotCanonical = errors.New("checkpoint not on canonical chain")
+)
+
+// Checkpoint is a portable voting snapshot taken at an epoch transition block,
+// bundled together with the header it was taken at. It allows fresh nodes to
+// bootstrap the signer set without walking all the headers back to genesis.
+type Checkpoint struct {
+	Header   *types.Header `json:"header"`   // Epoch transition header the snapshot belongs to
+	Snapshot *Snapshot     `json:"snapshot"` // Voting snapshot at the epoch transition
+}
+
+// Verify checks that the checkpoint is self-consistent: the snapshot must belong
+// to the bundled header, the header must be an epoch transition and the signer
+// set in the snapshot must match the signer list in the header's extra-data.
+func (cp *Checkpoint) Verify(config *params.CliqueConfig) error {
+	if cp.Header == nil || cp.Snapshot == nil || cp.Header.Number == nil {
+		return errIncompleteCheckpoint
+	}
+	epoch := config.Epoch
+	if epoch == 0 {
+		epoch = epochLength
+	}
+	number := cp.Header.Number.Uint64()
+	if number%epoch != 0 {
+		return errNotEpochCheckpoint
+	}
+	if cp.Snapshot.Number != number || cp.Snapshot.Hash != cp.Header.Hash() {
+		return errCheckpointHashMismatch
+	}
+	if len(cp.Snapshot.Votes) > 0 || len(cp.Snapshot.Tally) > 0 {
+		return errCheckpointPendingVotes
+	}
+	// Ensure the signer list in the header matches the authorized signers
+	if len(cp.Header.Extra) < extraVanity+extraSeal {
+		return errMissingSignature
+	}
+	checkpoint := cp.Header.Extra[extraVanity : len(cp.Header.Extra)-extraSeal]
+	if len(checkpoint)%common.AddressLength != 0 {
+		return errInvalidCheckpointSigners
+	}
+	signers := make([]byte, len(cp.Snapshot.Signers)*common.AddressLength)
+	for i, signer := range cp.Snapshot.signers() {
+		copy(signers[i*common.AddressLength:], signer[:])
+	}
+	if !bytes.Equal(checkpoint, signers) {
+		return errMismatchingCheckpointSigners
+	}
+	return nil
+}
+
+// ReadCheckpoint loads a checkpoint from a JSON file on disk.
+func ReadCheckpoint(path string) (*Checkpoint, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := new(Checkpoint)
+	if err := json.Unmarshal(blob, cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file: %v", err)
+	}
+	return cp, nil
+}
+
+// WriteCheckpoint saves a checkpoint into a JSON file on disk.
+func WriteCheckpoint(path string, cp *Checkpoint) error {
+	blob, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, blob, 0644)
+}
+
+// ImportCheckpoint verifies a checkpoint and persists its voting snapshot into
+// the database, from where the engine will pick it up when reaching the block.
+func ImportCheckpoint(db ethdb.Database, config *params.CliqueConfig, cp *Checkpoint) error {
+	if err := cp.Verify(config); err != nil {
+		return err
+	}
+	number, hash := cp.Header.Number.Uint64(), cp.Header.Hash()
+	if canon := rawdb.ReadCanonicalHash(db, number); canon != (common.Hash{}) && canon != hash {
+		return errCheckpointNotCanonical
+	}
+	return cp.Snapshot.store(db)
+}
+
+// Epoch returns the number of blocks after which the pending votes are reset and
+// the signer list is checkpointed into the header.
+func (c *Clique) Epoch() uint64 {
+	return c.config.Epoch
+}
+
+// Checkpoint creates a portable checkpoint of the voting snapshot at the given
+// epoch transition block of the local chain.
+func (c *Clique) Checkpoint(chain consensus.ChainHeaderReader, number uint64) (*Checkpoint, error) {
+	if number%c.config.Epoch != 0 {
+		return nil, errNotEpochCheckpoint
+	}
+	header := chain.GetHeaderByNumber(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := c.snapshot(chain, number, header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Checkpoint{Header: header, Snapshot: snap}, nil
+}
+
+// SetCheckpoint configures a trusted checkpoint to seed the voting snapshot from
+// when the engine reaches the checkpoint block, instead of reconstructing it
+// from the headers preceding it.
+func (c *Clique) SetCheckpoint(cp *Checkpoint) error {
+	if err := cp.Verify(c.config); err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.checkpoint = cp
+	log.Info("Configured trusted clique checkpoint", "number", cp.Snapshot.Number, "hash", cp.Snapshot.Hash, "signers", len(cp.Snapshot.Signers))
+	return nil
+}
+
+// trustedCheckpoint returns a copy of the voting snapshot of the configured
+// trusted checkpoint if it matches the requested block, or nil otherwise.
+func (c *Clique) trustedCheckpoint(number uint64, hash common.Hash) *Snapshot {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.checkpoint == nil || c.checkpoint.Snapshot.Number != number || c.checkpoint.Snapshot.Hash != hash {
+		return nil
+	}
+	snap := c.checkpoint.Snapshot.copy()
+	snap.config = c.config
+	snap.sigcache = c.signatures
+	return snap
+}
@@ -0,0 +1,198 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// partialHeaderChain is a header reader which only knows about the headers at
+// and above a certain height, simulating a node that skipped old history.
+type partialHeaderChain struct {
+	config  *params.ChainConfig
+	headers map[uint64]*types.Header
+	head    uint64
+}
+
+func (hc *partialHeaderChain) Config() *params.ChainConfig  { return hc.config }
+func (hc *partialHeaderChain) CurrentHeader() *types.Header { return hc.headers[hc.head] }
+
+func (hc *partialHeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := hc.headers[number]; header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+
+func (hc *partialHeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	return hc.headers[number]
+}
+
+func (hc *partialHeaderChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	for _, header := range hc.headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+
+func (hc *partialHeaderChain) GetTd(hash common.Hash, number uint64) *big.Int { return nil }
+
+// makeCheckpointChain creates a two signer clique chain with an epoch length of
+// three blocks, returning the imported chain and the generated blocks.
+func makeCheckpointChain(t *testing.T, accounts *testerAccountPool) (*core.BlockChain, *Clique, []*types.Block) {
+	signers := []string{"A", "B"}
+
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+common.AddressLength*len(signers)+extraSeal),
+		BaseFee:   big.NewInt(params.InitialBaseFee),
+	}
+	accounts.checkpoint(&types.Header{Extra: genesis.ExtraData}, signers)
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 3}
+	genesis.Config = &config
+
+	engine := New(config.Clique, rawdb.NewMemoryDatabase())
+	engine.fakeDiff = true
+
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 8, func(int, *core.BlockGen) {})
+	for i, block := range blocks {
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		if header.Number.Uint64()%config.Clique.Epoch == 0 {
+			header.Extra = make([]byte, extraVanity+len(signers)*common.AddressLength+extraSeal)
+			accounts.checkpoint(header, signers)
+		}
+		header.Difficulty = diffInTurn
+
+		accounts.sign(header, signers[i%len(signers)])
+		blocks[i] = block.WithSeal(header)
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create test chain: %v", err)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import block %d: %v", n, err)
+	}
+	return chain, engine, blocks
+}
+
+// Tests that checkpoints can be exported, verified and round-tripped via disk.
+func TestCheckpointExport(t *testing.T) {
+	accounts := newTesterAccountPool()
+	chain, engine, _ := makeCheckpointChain(t, accounts)
+	defer chain.Stop()
+
+	if _, err := engine.Checkpoint(chain, 5); err != errNotEpochCheckpoint {
+		t.Fatalf("non-epoch checkpoint error mismatch: have %v, want %v", err, errNotEpochCheckpoint)
+	}
+	cp, err := engine.Checkpoint(chain, 6)
+	if err != nil {
+		t.Fatalf("failed to create checkpoint: %v", err)
+	}
+	if err := cp.Verify(engine.config); err != nil {
+		t.Fatalf("failed to verify checkpoint: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := WriteCheckpoint(path, cp); err != nil {
+		t.Fatalf("failed to write checkpoint: %v", err)
+	}
+	loaded, err := ReadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("failed to read checkpoint: %v", err)
+	}
+	if err := loaded.Verify(engine.config); err != nil {
+		t.Fatalf("failed to verify loaded checkpoint: %v", err)
+	}
+	if loaded.Header.Hash() != cp.Header.Hash() || len(loaded.Snapshot.Recents) != len(cp.Snapshot.Recents) {
+		t.Fatalf("checkpoint mismatch after round trip")
+	}
+	// Tamper with the signer set and ensure it's rejected
+	delete(loaded.Snapshot.Signers, accounts.address("A"))
+	if err := loaded.Verify(engine.config); err != errMismatchingCheckpointSigners {
+		t.Fatalf("tampered checkpoint error mismatch: have %v, want %v", err, errMismatchingCheckpointSigners)
+	}
+}
+
+// Tests that a trusted checkpoint, either configured on the engine or imported
+// into the database, is used to seed the voting snapshot.
+func TestCheckpointSeeding(t *testing.T) {
+	accounts := newTesterAccountPool()
+	chain, engine, blocks := makeCheckpointChain(t, accounts)
+	defer chain.Stop()
+
+	cp, err := engine.Checkpoint(chain, 6)
+	if err != nil {
+		t.Fatalf("failed to create checkpoint: %v", err)
+	}
+	if len(cp.Snapshot.Recents) != 2 {
+		t.Fatalf("recent signers mismatch: have %d, want %d", len(cp.Snapshot.Recents), 2)
+	}
+	partial := &partialHeaderChain{config: chain.Config(), headers: make(map[uint64]*types.Header), head: 8}
+	for _, block := range blocks[5:] {
+		partial.headers[block.NumberU64()] = block.Header()
+	}
+	head := blocks[5]
+
+	// Without a checkpoint, the snapshot is reconstructed from the header alone
+	plain := New(engine.config, rawdb.NewMemoryDatabase())
+	snap, err := plain.snapshot(partial, head.NumberU64(), head.Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	if _, ok := snap.Recents[5]; ok {
+		t.Fatalf("pre-checkpoint recent signer known without checkpoint")
+	}
+	// With a configured checkpoint, the snapshot should carry the recents over
+	seeded := New(engine.config, rawdb.NewMemoryDatabase())
+	if err := seeded.SetCheckpoint(cp); err != nil {
+		t.Fatalf("failed to set checkpoint: %v", err)
+	}
+	if snap, err = seeded.snapshot(partial, head.NumberU64(), head.Hash(), nil); err != nil {
+		t.Fatalf("failed to create seeded snapshot: %v", err)
+	}
+	if _, ok := snap.Recents[5]; !ok {
+		t.Fatalf("pre-checkpoint recent signer missing from seeded snapshot")
+	}
+	// With an imported checkpoint, the snapshot should be loaded from disk
+	db := rawdb.NewMemoryDatabase()
+	if err := ImportCheckpoint(db, engine.config, cp); err != nil {
+		t.Fatalf("failed to import checkpoint: %v", err)
+	}
+	imported := New(engine.config, db)
+	if snap, err = imported.snapshot(partial, head.NumberU64(), head.Hash(), nil); err != nil {
+		t.Fatalf("failed to create imported snapshot: %v", err)
+	}
+	if _, ok := snap.Recents[5]; !ok {
+		t.Fatalf("pre-checkpoint recent signer missing from imported snapshot")
+	}
+}
@@ -176,11 +176,12 @@ type Clique struct {
 	recents    *lru.Cache[common.Hash, *Snapshot] // Snapshots for recent block to speed up reorgs
 	signatures *sigLRU                            // Signatures of recent blocks to speed up mining
 
-	proposals map[common.Address]bool // Current list of proposals we are pushing
+	proposals  map[common.Address]bool // Current list of proposals we are pushing
+	checkpoint *Checkpoint             // Trusted checkpoint to seed the voting snapshot from
 
 	signer common.Address // Ethereum address of the signing key
 	signFn SignerFn       // Signer function to authorize hashes with
-	lock   sync.RWMutex   // Protects the signer and proposals fields
+	lock   sync.RWMutex   // Protects the signer, proposals and checkpoint fields
 
 	// The fields below are for testing only
 	fakeDiff bool // Skip difficulty verifications
@@ -378,14 +379,24 @@ func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 			snap = s
 			break
 		}
-		// If an on-disk checkpoint snapshot can be found, use that
-		if number%checkpointInterval == 0 {
+		// If an on-disk checkpoint snapshot can be found, use that. Epoch transitions
+		// are checked too, since those may have been imported from a checkpoint.
+		if number%checkpointInterval == 0 || number%c.config.Epoch == 0 {
 			if s, err := loadSnapshot(c.config, c.signatures, c.db, hash); err == nil {
 				log.Trace("Loaded voting snapshot from disk", "number", number, "hash", hash)
 				snap = s
 				break
 			}
 		}
+		// If we've reached the configured trusted checkpoint, seed the snapshot from it
+		if s := c.trustedCheckpoint(number, hash); s != nil {
+			snap = s
+			if err := snap.store(c.db); err != nil {
+				return nil, err
+			}
+			log.Info("Stored trusted checkpoint snapshot to disk", "number", number, "hash", hash)
+			break
+		}
 		// If we're at the genesis, snapshot the initial state. Alternatively if we're
 		// at a checkpoint block without a parent (light client CHT), or we have piled
 		// up more headers than allowed to be reorged (chain reinit from a freezer),
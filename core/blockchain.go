@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"runtime"
 	"sort"
@@ -97,6 +98,7 @@ const (
 	maxFutureBlocks     = 256
 	maxTimeFutureBlocks = 30
 	TriesInMemory       = 128
+	maxPinnedStates     = 1024
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
@@ -139,6 +141,9 @@ type CacheConfig struct {
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
+
+	PinnedStates     []uint64 // Block numbers whose state is persisted and kept available for serving
+	PinnedStateEvery uint64   // Interval of blocks whose state is persisted and kept available (0 = off)
 }
 
 // defaultCacheConfig are the default caching values if none are specified by the
@@ -178,6 +183,9 @@ type BlockChain struct {
 	triedb        *trie.Database // The database handler for maintaining trie nodes.
 	stateCache    state.Database // State database to reuse between imports (contains state cache)
 
+	pinned     map[common.Hash]uint64 // State roots pinned for serving, mapped to their block numbers
+	pinnedLock sync.RWMutex
+
 	// txLookupLimit is the maximum number of blocks from head whose tx indices
 	// are reserved:
 	//  * 0:   means no limit and regenerate any missing indexes
@@ -260,6 +268,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 		cacheConfig:   cacheConfig,
 		db:            db,
 		triedb:        triedb,
+		pinned:        make(map[common.Hash]uint64),
 		flushInterval: int64(cacheConfig.TrieTimeLimit),
 		triegc:        prque.New(nil),
 		quit:          make(chan struct{}),
@@ -407,6 +416,8 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 		}
 		bc.snaps, _ = snapshot.New(snapconfig, bc.db, bc.triedb, head.Root())
 	}
+	// Load the pinned states which are already persisted
+	bc.loadPinnedStates()
 
 	// Start future block processor.
 	bc.wg.Add(1)
@@ -723,7 +734,11 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 		bc.SetFinalized(nil)
 	}
 
-	return rootNumber, bc.loadLastState()
+	err := bc.loadLastState()
+
+	// Stop serving the states of the rewound blocks
+	bc.unpinStates(bc.CurrentBlock().NumberU64())
+	return rootNumber, err
 }
 
 // SnapSyncCommitHead sets the current head block to the one defined by the hash
@@ -856,6 +871,12 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 
 	bc.currentBlock.Store(block)
 	headBlockGauge.Update(int64(block.NumberU64()))
+
+	// Serve the state of the block if it's pinned, the state is persisted
+	// already in writeBlockWithState
+	if bc.isPinnedBlock(block.NumberU64()) && bc.HasState(block.Root()) {
+		bc.pinState(block.Root(), block.NumberU64())
+	}
 }
 
 // stop stops the blockchain service. If any imports are currently in progress
@@ -1309,6 +1330,12 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	if err != nil {
 		return err
 	}
+	// Persist the state of pinned blocks, it's served once the block becomes canonical
+	if bc.isPinnedBlock(block.NumberU64()) {
+		if err := bc.triedb.Commit(root, false, nil); err != nil {
+			return err
+		}
+	}
 	// If we're running an archive node, always flush
 	if bc.cacheConfig.TrieDirtyDisabled {
 		return bc.triedb.Commit(root, false, nil)
@@ -1375,6 +1402,83 @@ func (bc *BlockChain) WriteBlockAndSetHead(block *types.Block, receipts []*types
 	return bc.writeBlockAndSetHead(block, receipts, logs, state, emitHeadEvent)
 }
 
+// isPinnedBlock reports whether the state of the given block should be pinned.
+func (bc *BlockChain) isPinnedBlock(number uint64) bool {
+	if every := bc.cacheConfig.PinnedStateEvery; every > 0 && number > 0 && number%every == 0 {
+		return true
+	}
+	for _, pinned := range bc.cacheConfig.PinnedStates {
+		if pinned == number {
+			return true
+		}
+	}
+	return false
+}
+
+// pinState marks the given state root as pinned. If the number of pinned states
+// exceeds the limit, the oldest ones are unpinned.
+func (bc *BlockChain) pinState(root common.Hash, number uint64) {
+	bc.pinnedLock.Lock()
+	defer bc.pinnedLock.Unlock()
+
+	bc.pinned[root] = number
+	for len(bc.pinned) > maxPinnedStates {
+		var (
+			oldest       common.Hash
+			oldestNumber = uint64(math.MaxUint64)
+		)
+		for root, number := range bc.pinned {
+			if number < oldestNumber {
+				oldest, oldestNumber = root, number
+			}
+		}
+		delete(bc.pinned, oldest)
+	}
+}
+
+// unpinStates unpins all states of blocks above the given number, which are no
+// longer part of the canonical chain after a rewind or reorg.
+func (bc *BlockChain) unpinStates(number uint64) {
+	bc.pinnedLock.Lock()
+	defer bc.pinnedLock.Unlock()
+
+	for root, pinned := range bc.pinned {
+		if pinned > number {
+			delete(bc.pinned, root)
+		}
+	}
+}
+
+// loadPinnedStates collects the pinned states which are already persisted in
+// the database. Periodically pinned states are searched backwards from the head,
+// stopping at the first one missing, which is where pinning was turned on.
+func (bc *BlockChain) loadPinnedStates() {
+	pin := func(number uint64) bool {
+		header := bc.GetHeaderByNumber(number)
+		if header == nil || !bc.HasState(header.Root) {
+			return false
+		}
+		bc.pinState(header.Root, number)
+		return true
+	}
+	head := bc.CurrentBlock().NumberU64()
+	for _, number := range bc.cacheConfig.PinnedStates {
+		if number <= head && !pin(number) {
+			log.Warn("Pinned state not available", "number", number)
+		}
+	}
+	if every := bc.cacheConfig.PinnedStateEvery; every > 0 {
+		for number := head / every * every; number > 0 && len(bc.pinned) < maxPinnedStates; number -= every {
+			if !pin(number) {
+				break
+			}
+		}
+	}
+	if len(bc.pinned) > 0 {
+		log.Info("Loaded pinned states", "count", len(bc.pinned))
+	}
+}
+
 // writeBlockAndSetHead is the internal implementation of WriteBlockAndSetHead.
 // This function expects the chain mutex to be held.
 func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
@@ -2098,6 +2202,10 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		// rewind the canonical chain to a lower point.
 		log.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.Number(), "oldhash", oldBlock.Hash(), "oldblocks", len(oldChain), "newnum", newBlock.Number(), "newhash", newBlock.Hash(), "newblocks", len(newChain))
 	}
+	// Stop serving the states of the dropped blocks, the ones of the new chain
+	// are pinned as they are written as head.
+	bc.unpinStates(commonBlock.NumberU64())
+
 	// Insert the new chain(except the head block(reverse order)),
 	// taking care of the proper incremental order.
 	for i := len(newChain) - 1; i >= 1; i-- {
@@ -278,6 +278,28 @@ func (bc *BlockChain) HasState(hash common.Hash) bool {
 	return err == nil
 }
 
+// PinnedState reports whether the given state root is pinned, meaning it is
+// persisted and kept available for serving to other nodes.
+func (bc *BlockChain) PinnedState(root common.Hash) bool {
+	bc.pinnedLock.RLock()
+	defer bc.pinnedLock.RUnlock()
+
+	_, ok := bc.pinned[root]
+	return ok
+}
+
+// PinnedStates returns the pinned state roots, mapped to their block numbers.
+func (bc *BlockChain) PinnedStates() map[common.Hash]uint64 {
+	bc.pinnedLock.RLock()
+	defer bc.pinnedLock.RUnlock()
+
+	pinned := make(map[common.Hash]uint64, len(bc.pinned))
+	for root, number := range bc.pinned {
+		pinned[root] = number
+	}
+	return pinned
+}
+
 // HasBlockAndState checks if a block and associated state trie is fully present
 // in the database or not, caching it if present.
 func (bc *BlockChain) HasBlockAndState(hash common.Hash, number uint64) bool {
@@ -22,6 +22,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -4332,3 +4333,133 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// Tests that the states of pinned blocks are persisted and kept available, and
+// that they are found again after a restart.
+func TestPinnedStates(t *testing.T) {
+	engine := ethash.NewFaker()
+	genesis := &Genesis{
+		Config:  params.TestChainConfig,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	_, blocks, _ := GenerateChainWithGenesis(genesis, engine, 2*TriesInMemory+10, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{1}) })
+
+	db := rawdb.NewMemoryDatabase()
+	config := *defaultCacheConfig
+	config.PinnedStates = []uint64{5}
+	config.PinnedStateEvery = 100
+
+	chain, err := NewBlockChain(db, &config, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	want := map[common.Hash]uint64{
+		blocks[4].Root():   5,
+		blocks[99].Root():  100,
+		blocks[199].Root(): 200,
+	}
+	check := func(chain *BlockChain) {
+		if pinned := chain.PinnedStates(); !reflect.DeepEqual(pinned, want) {
+			t.Fatalf("pinned states mismatch: have %v, want %v", pinned, want)
+		}
+		for root, number := range want {
+			if !chain.PinnedState(root) || !chain.HasState(root) {
+				t.Fatalf("state of pinned block %d not available", number)
+			}
+		}
+	}
+	check(chain)
+	if chain.HasState(blocks[5].Root()) || chain.PinnedState(blocks[5].Root()) {
+		t.Fatalf("state of unpinned block 6 available")
+	}
+	chain.Stop()
+
+	chain, err = NewBlockChain(db, &config, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to recreate tester chain: %v", err)
+	}
+	defer chain.Stop()
+	check(chain)
+}
+
+// Tests that the states of blocks dropped from the canonical chain by a rewind
+// or reorg are unpinned, and the ones of the new canonical blocks pinned.
+func TestPinnedStatesRewind(t *testing.T) {
+	engine := ethash.NewFaker()
+	genesis := &Genesis{
+		Config:  params.TestChainConfig,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	genDb, blocks, _ := GenerateChainWithGenesis(genesis, engine, 50, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{1}) })
+
+	config := *defaultCacheConfig
+	config.PinnedStateEvery = 10
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &config, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	check := func(want map[common.Hash]uint64) {
+		t.Helper()
+		if pinned := chain.PinnedStates(); !reflect.DeepEqual(pinned, want) {
+			t.Fatalf("pinned states mismatch: have %v, want %v", pinned, want)
+		}
+	}
+	check(map[common.Hash]uint64{
+		blocks[9].Root():  10,
+		blocks[19].Root(): 20,
+		blocks[29].Root(): 30,
+		blocks[39].Root(): 40,
+		blocks[49].Root(): 50,
+	})
+	// Rewinding the chain should unpin the states above the new head
+	if err := chain.SetHead(35); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	check(map[common.Hash]uint64{
+		blocks[9].Root():  10,
+		blocks[19].Root(): 20,
+		blocks[29].Root(): 30,
+	})
+	// Reorging to a longer fork should replace the states of the dropped blocks
+	fork, _ := GenerateChain(params.TestChainConfig, blocks[14], engine, genDb, 30, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{2}) })
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	check(map[common.Hash]uint64{
+		blocks[9].Root(): 10,
+		fork[4].Root():   20,
+		fork[14].Root():  30,
+		fork[24].Root():  40,
+	})
+}
+
+// Tests that the number of pinned states is capped, unpinning the oldest ones.
+func TestPinnedStatesLimit(t *testing.T) {
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, &Genesis{Config: params.TestChainConfig}, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	for i := uint64(1); i <= maxPinnedStates+2; i++ {
+		chain.pinState(common.BigToHash(new(big.Int).SetUint64(i)), i)
+	}
+	pinned := chain.PinnedStates()
+	if len(pinned) != maxPinnedStates {
+		t.Fatalf("pinned state count mismatch: have %d, want %d", len(pinned), maxPinnedStates)
+	}
+	for i := uint64(1); i <= 2; i++ {
+		if _, ok := pinned[common.BigToHash(new(big.Int).SetUint64(i))]; ok {
+			t.Errorf("oldest pinned state %d not unpinned", i)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -48,6 +49,8 @@ type BlockGen struct {
 
 	config *params.ChainConfig
 	engine consensus.Engine
+
+	overrideContext func(*vm.BlockContext) // Optional modifier of the EVM block context
 }
 
 // SetCoinbase sets the coinbase of the generated block.
@@ -96,8 +99,12 @@ func (b *BlockGen) addTx(bc *BlockChain, vmConfig vm.Config, tx *types.Transacti
 	if b.gasPool == nil {
 		b.SetCoinbase(common.Address{})
 	}
+	msg, err := tx.AsMessage(types.MakeSigner(b.config, b.header.Number), b.header.BaseFee)
+	if err != nil {
+		panic(err)
+	}
 	b.statedb.SetTxContext(tx.Hash(), len(b.txs))
-	receipt, err := ApplyTransaction(b.config, bc, &b.header.Coinbase, b.gasPool, b.statedb, b.header, tx, &b.header.GasUsed, vmConfig)
+	receipt, err := applyTransaction(msg, b.config, b.gasPool, b.statedb, b.header.Number, b.header.Hash(), tx, &b.header.GasUsed, b.newEVM(bc, vmConfig))
 	if err != nil {
 		panic(err)
 	}
@@ -136,11 +143,75 @@ func (b *BlockGen) AddTxWithVMConfig(tx *types.Transaction, config vm.Config) {
 	b.addTx(nil, config, tx)
 }
 
+// AddImpersonatedTx adds a transaction to the generated block, executing it as
+// if it was sent by the given account, irrespective of its signature. The nonce
+// of the transaction is not checked and the sender is allowed to have code.
+//
+// AddImpersonatedTx will cause consensus failures when used during real chain
+// processing. This is best used in conjunction with raw block insertion.
+func (b *BlockGen) AddImpersonatedTx(bc *BlockChain, from common.Address, tx *types.Transaction) {
+	if b.gasPool == nil {
+		b.SetCoinbase(common.Address{})
+	}
+	gasPrice := new(big.Int).Set(tx.GasPrice())
+	if b.header.BaseFee != nil {
+		gasPrice = math.BigMin(gasPrice.Add(tx.GasTipCap(), b.header.BaseFee), tx.GasFeeCap())
+	}
+	msg := types.NewMessage(from, tx.To(), tx.Nonce(), tx.Value(), tx.Gas(), gasPrice, tx.GasFeeCap(), tx.GasTipCap(), tx.Data(), tx.AccessList(), true)
+
+	b.statedb.SetTxContext(tx.Hash(), len(b.txs))
+	receipt, err := applyTransaction(msg, b.config, b.gasPool, b.statedb, b.header.Number, b.header.Hash(), tx, &b.header.GasUsed, b.newEVM(bc, vm.Config{}))
+	if err != nil {
+		panic(err)
+	}
+	b.txs = append(b.txs, tx)
+	b.receipts = append(b.receipts, receipt)
+}
+
+// OverrideBlockContext sets a function modifying the EVM block context the
+// transactions added afterwards are executed in (e.g. to report a different
+// block number to contracts).
+//
+// OverrideBlockContext will cause consensus failures when used during real chain
+// processing. This is best used in conjunction with raw block insertion.
+func (b *BlockGen) OverrideBlockContext(override func(*vm.BlockContext)) {
+	b.overrideContext = override
+}
+
+// newEVM creates the EVM executing the transactions of the generated block.
+func (b *BlockGen) newEVM(bc *BlockChain, vmConfig vm.Config) *vm.EVM {
+	blockContext := NewEVMBlockContext(b.header, bc, &b.header.Coinbase)
+	if b.overrideContext != nil {
+		b.overrideContext(&blockContext)
+	}
+	return vm.NewEVM(blockContext, vm.TxContext{}, b.statedb, b.config, vmConfig)
+}
+
 // GetBalance returns the balance of the given address at the generated block.
 func (b *BlockGen) GetBalance(addr common.Address) *big.Int {
 	return b.statedb.GetBalance(addr)
 }
 
+// SetBalance sets the balance of the given address at the generated block.
+func (b *BlockGen) SetBalance(addr common.Address, balance *big.Int) {
+	b.statedb.SetBalance(addr, balance)
+}
+
+// SetAccountNonce sets the nonce of the given address at the generated block.
+func (b *BlockGen) SetAccountNonce(addr common.Address, nonce uint64) {
+	b.statedb.SetNonce(addr, nonce)
+}
+
+// SetCode sets the code of the given address at the generated block.
+func (b *BlockGen) SetCode(addr common.Address, code []byte) {
+	b.statedb.SetCode(addr, code)
+}
+
+// SetState sets a storage slot of the given address at the generated block.
+func (b *BlockGen) SetState(addr common.Address, key, value common.Hash) {
+	b.statedb.SetState(addr, key, value)
+}
+
 // AddUncheckedTx forcefully adds a transaction to the block without any
 // validation.
 //
@@ -160,6 +231,23 @@ func (b *BlockGen) BaseFee() *big.Int {
 	return new(big.Int).Set(b.header.BaseFee)
 }
 
+// SetBaseFee overrides the EIP-1559 base fee of the block being generated. It
+// must be called before adding any transactions.
+//
+// SetBaseFee will cause consensus failures when used during real chain
+// processing. This is best used in conjunction with raw block insertion.
+func (b *BlockGen) SetBaseFee(baseFee *big.Int) {
+	if len(b.txs) > 0 {
+		panic("base fee must be set before adding transactions")
+	}
+	b.header.BaseFee = new(big.Int).Set(baseFee)
+}
+
+// Timestamp returns the timestamp of the block being generated.
+func (b *BlockGen) Timestamp() uint64 {
+	return b.header.Time
+}
+
 // AddUncheckedReceipt forcefully adds a receipts to the block without a
 // backing transaction.
 //
@@ -243,6 +331,13 @@ func (b *BlockGen) OffsetTime(seconds int64) {
 // values. Inserting them into BlockChain requires use of FakePow or
 // a similar non-validating proof of work implementation.
 func GenerateChain(config *params.ChainConfig, parent *types.Block, engine consensus.Engine, db ethdb.Database, n int, gen func(int, *BlockGen)) ([]*types.Block, []types.Receipts) {
+	return GenerateChainWithStateDatabase(config, parent, engine, state.NewDatabase(db), n, gen)
+}
+
+// GenerateChainWithStateDatabase is a variant of GenerateChain which accesses the
+// states through the given state database instead of a plain one on top of a key
+// value store. It allows generating chains with custom state backends.
+func GenerateChainWithStateDatabase(config *params.ChainConfig, parent *types.Block, engine consensus.Engine, sdb state.Database, n int, gen func(int, *BlockGen)) ([]*types.Block, []types.Receipts) {
 	if config == nil {
 		config = params.TestChainConfig
 	}
@@ -297,7 +392,7 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		return nil, nil
 	}
 	for i := 0; i < n; i++ {
-		statedb, err := state.New(parent.Root(), state.NewDatabase(db), nil)
+		statedb, err := state.New(parent.Root(), sdb, nil)
 		if err != nil {
 			panic(err)
 		}
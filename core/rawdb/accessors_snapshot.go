@@ -208,3 +208,23 @@ func WriteSnapshotSyncStatus(db ethdb.KeyValueWriter, status []byte) {
 		log.Crit("Failed to store snapshot sync status", "err", err)
 	}
 }
+
+// ReadSnapshotSyncHeal retrieves the serialized healing state saved at shutdown.
+func ReadSnapshotSyncHeal(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(snapshotSyncHealKey)
+	return data
+}
+
+// WriteSnapshotSyncHeal stores the serialized healing state to save at shutdown.
+func WriteSnapshotSyncHeal(db ethdb.KeyValueWriter, state []byte) {
+	if err := db.Put(snapshotSyncHealKey, state); err != nil {
+		log.Crit("Failed to store snapshot sync healing state", "err", err)
+	}
+}
+
+// DeleteSnapshotSyncHeal deletes the serialized healing state.
+func DeleteSnapshotSyncHeal(db ethdb.KeyValueWriter) {
+	if err := db.Delete(snapshotSyncHealKey); err != nil {
+		log.Crit("Failed to remove snapshot sync healing state", "err", err)
+	}
+}
@@ -66,6 +66,10 @@ var (
 	// snapshotSyncStatusKey tracks the snapshot sync status across restarts.
 	snapshotSyncStatusKey = []byte("SnapshotSyncStatus")
 
+	// snapshotSyncHealKey tracks the retrieved but uncommitted trie nodes of the
+	// snapshot sync healing phase across restarts.
+	snapshotSyncHealKey = []byte("SnapshotSyncHeal")
+
 	// skeletonSyncStatusKey tracks the skeleton sync status across restarts.
 	skeletonSyncStatusKey = []byte("SkeletonSyncStatus")
 
@@ -34,6 +34,8 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/eth/protocols/snap"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -243,6 +245,17 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// PeerTraffic retrieves the transaction gossip traffic exchanged with each
+// connected peer, keyed by peer ID and message type.
+func (api *AdminAPI) PeerTraffic() map[string]map[string]eth.MsgTraffic {
+	peers := api.eth.handler.peers.allPeers()
+	traffic := make(map[string]map[string]eth.MsgTraffic, len(peers))
+	for _, p := range peers {
+		traffic[p.ID()] = p.Traffic()
+	}
+	return traffic
+}
+
 // DebugAPI is the collection of Ethereum full node APIs for debugging the
 // protocol.
 type DebugAPI struct {
@@ -604,3 +617,9 @@ func (api *DebugAPI) SetTrieFlushInterval(interval string) error {
 	api.eth.blockchain.SetTrieFlushInterval(t)
 	return nil
 }
+
+// SnapSyncStatus returns a detailed report of the snap sync progress, or nil if
+// snap sync was not run by this node.
+func (api *DebugAPI) SnapSyncStatus() *snap.SyncStatus {
+	return api.eth.Downloader().SnapSyncer.Status()
+}
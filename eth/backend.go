@@ -70,7 +70,7 @@ type Ethereum struct {
 	txPool             *txpool.TxPool
 	blockchain         *core.BlockChain
 	handler            *handler
-	ethDialCandidates  enode.Iterator
+	ethDialCandidates  *enode.FairMix
 	snapDialCandidates enode.Iterator
 	merger             *consensus.Merger
 
@@ -142,6 +142,11 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 	engine := ethconfig.CreateConsensusEngine(stack, &ethashConfig, cliqueConfig, config.Miner.Notify, config.Miner.Noverify, chainDb)
+	if config.CliqueCheckpoint != "" {
+		if err := ethconfig.SetCliqueCheckpoint(engine, stack.ResolvePath(config.CliqueCheckpoint)); err != nil {
+			return nil, fmt.Errorf("invalid clique checkpoint: %v", err)
+		}
+	}
 
 	eth := &Ethereum{
 		config:            config,
@@ -191,6 +196,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
+			PinnedStates:        config.SnapshotPinned,
+			PinnedStateEvery:    config.SnapshotPinInterval,
 		}
 	)
 	// Override the chain config with provided settings.
@@ -229,6 +236,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		EventMux:       eth.eventMux,
 		Checkpoint:     checkpoint,
 		RequiredBlocks: config.RequiredBlocks,
+
+		TxBroadcastPeers:   config.TxBroadcastPeers,
+		TxBroadcastMaxSize: config.TxBroadcastMaxSize,
 	}); err != nil {
 		return nil, err
 	}
@@ -248,10 +258,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 
 	// Setup DNS discovery iterators.
 	dnsclient := dnsdisc.NewClient(dnsdisc.Config{})
-	eth.ethDialCandidates, err = dnsclient.NewIterator(eth.config.EthDiscoveryURLs...)
+	ethDNSCandidates, err := dnsclient.NewIterator(eth.config.EthDiscoveryURLs...)
 	if err != nil {
 		return nil, err
 	}
+	eth.ethDialCandidates = enode.NewFairMix(0)
+	eth.ethDialCandidates.AddSource(ethDNSCandidates)
 	eth.snapDialCandidates, err = dnsclient.NewIterator(eth.config.SnapDiscoveryURLs...)
 	if err != nil {
 		return nil, err
@@ -520,6 +532,17 @@ func (s *Ethereum) Start() error {
 	// Regularly update shutdown marker
 	s.shutdownTracker.Start()
 
+	// Advertise and search for peers through discovery v5 topics if requested
+	if s.config.DiscoveryTopic {
+		if disc := s.p2pServer.DiscV5; disc != nil {
+			topic := eth.DiscoveryTopic(s.blockchain.Genesis().Hash())
+			disc.RegisterTopic(topic)
+			s.ethDialCandidates.AddSource(disc.TopicNodes(topic))
+		} else {
+			log.Warn("Topic discovery requires discovery v5, ignoring")
+		}
+	}
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
 	if s.config.LightServ > 0 {
@@ -537,6 +560,9 @@ func (s *Ethereum) Start() error {
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
 	// Stop all the peer-related stuff first.
+	if disc := s.p2pServer.DiscV5; s.config.DiscoveryTopic && disc != nil {
+		disc.StopRegisterTopic(eth.DiscoveryTopic(s.blockchain.Genesis().Hash()))
+	}
 	s.ethDialCandidates.Close()
 	s.snapDialCandidates.Close()
 	s.handler.Stop()
@@ -131,6 +131,14 @@ type Config struct {
 	EthDiscoveryURLs  []string
 	SnapDiscoveryURLs []string
 
+	// DiscoveryTopic enables advertising and searching for `eth` peers through
+	// discovery v5 topics.
+	DiscoveryTopic bool
+
+	// Transaction broadcast options
+	TxBroadcastPeers   int    `toml:",omitempty"` // Number of peers to send full transactions to (0 = square root of the peers)
+	TxBroadcastMaxSize uint64 `toml:",omitempty"` // Size above which transactions are only announced (0 = no limit)
+
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
@@ -169,6 +177,10 @@ type Config struct {
 	SnapshotCache           int
 	Preimages               bool
 
+	// Pinned states, kept available for serving over snap
+	SnapshotPinned      []uint64 `toml:",omitempty"` // Block numbers whose state is pinned
+	SnapshotPinInterval uint64   `toml:",omitempty"` // Interval of blocks whose state is pinned (0 = off)
+
 	// This is the number of blocks for which logs will be cached in the filter system.
 	FilterLogCacheSize int
 
@@ -206,6 +218,10 @@ type Config struct {
 	// CheckpointOracle is the configuration for checkpoint oracle.
 	CheckpointOracle *params.CheckpointOracleConfig `toml:",omitempty"`
 
+	// CliqueCheckpoint is the path of a trusted clique checkpoint file to seed the
+	// voting snapshot from, instead of reconstructing it from the headers.
+	CliqueCheckpoint string `toml:",omitempty"`
+
 	// OverrideTerminalTotalDifficulty (TODO: remove after the fork)
 	OverrideTerminalTotalDifficulty *big.Int `toml:",omitempty"`
 
@@ -244,3 +260,21 @@ func CreateConsensusEngine(stack *node.Node, ethashConfig *ethash.Config, clique
 	}
 	return beacon.New(engine)
 }
+
+// SetCliqueCheckpoint loads a trusted clique checkpoint from the given file and
+// configures it on the consensus engine, if the engine runs clique.
+func SetCliqueCheckpoint(engine consensus.Engine, path string) error {
+	if b, ok := engine.(*beacon.Beacon); ok {
+		engine = b.InnerEngine()
+	}
+	c, ok := engine.(*clique.Clique)
+	if !ok {
+		log.Warn("Ignoring clique checkpoint for non-clique chain", "file", path)
+		return nil
+	}
+	cp, err := clique.ReadCheckpoint(path)
+	if err != nil {
+		return err
+	}
+	return c.SetCheckpoint(cp)
+}
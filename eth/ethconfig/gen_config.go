@@ -24,6 +24,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SyncMode                              downloader.SyncMode
 		EthDiscoveryURLs                      []string
 		SnapDiscoveryURLs                     []string
+		DiscoveryTopic                        bool
+		TxBroadcastPeers                      int    `toml:",omitempty"`
+		TxBroadcastMaxSize                    uint64 `toml:",omitempty"`
 		NoPruning                             bool
 		NoPrefetch                            bool
 		TxLookupLimit                         uint64                 `toml:",omitempty"`
@@ -48,6 +51,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TrieDirtyCache                        int
 		TrieTimeout                           time.Duration
 		SnapshotCache                         int
+		SnapshotPinned                        []uint64 `toml:",omitempty"`
+		SnapshotPinInterval                   uint64   `toml:",omitempty"`
 		Preimages                             bool
 		FilterLogCacheSize                    int
 		Miner                                 miner.Config
@@ -61,6 +66,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RPCTxFeeCap                           float64
 		Checkpoint                            *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle                      *params.CheckpointOracleConfig `toml:",omitempty"`
+		CliqueCheckpoint                      string                         `toml:",omitempty"`
 		OverrideTerminalTotalDifficulty       *big.Int                       `toml:",omitempty"`
 		OverrideTerminalTotalDifficultyPassed *bool                          `toml:",omitempty"`
 	}
@@ -70,6 +76,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SyncMode = c.SyncMode
 	enc.EthDiscoveryURLs = c.EthDiscoveryURLs
 	enc.SnapDiscoveryURLs = c.SnapDiscoveryURLs
+	enc.DiscoveryTopic = c.DiscoveryTopic
+	enc.TxBroadcastPeers = c.TxBroadcastPeers
+	enc.TxBroadcastMaxSize = c.TxBroadcastMaxSize
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
 	enc.TxLookupLimit = c.TxLookupLimit
@@ -94,6 +103,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TrieDirtyCache = c.TrieDirtyCache
 	enc.TrieTimeout = c.TrieTimeout
 	enc.SnapshotCache = c.SnapshotCache
+	enc.SnapshotPinned = c.SnapshotPinned
+	enc.SnapshotPinInterval = c.SnapshotPinInterval
 	enc.Preimages = c.Preimages
 	enc.FilterLogCacheSize = c.FilterLogCacheSize
 	enc.Miner = c.Miner
@@ -107,6 +118,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
+	enc.CliqueCheckpoint = c.CliqueCheckpoint
 	enc.OverrideTerminalTotalDifficulty = c.OverrideTerminalTotalDifficulty
 	enc.OverrideTerminalTotalDifficultyPassed = c.OverrideTerminalTotalDifficultyPassed
 	return &enc, nil
@@ -120,6 +132,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SyncMode                              *downloader.SyncMode
 		EthDiscoveryURLs                      []string
 		SnapDiscoveryURLs                     []string
+		DiscoveryTopic                        *bool
+		TxBroadcastPeers                      *int    `toml:",omitempty"`
+		TxBroadcastMaxSize                    *uint64 `toml:",omitempty"`
 		NoPruning                             *bool
 		NoPrefetch                            *bool
 		TxLookupLimit                         *uint64                `toml:",omitempty"`
@@ -144,6 +159,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TrieDirtyCache                        *int
 		TrieTimeout                           *time.Duration
 		SnapshotCache                         *int
+		SnapshotPinned                        []uint64 `toml:",omitempty"`
+		SnapshotPinInterval                   *uint64  `toml:",omitempty"`
 		Preimages                             *bool
 		FilterLogCacheSize                    *int
 		Miner                                 *miner.Config
@@ -157,6 +174,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RPCTxFeeCap                           *float64
 		Checkpoint                            *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle                      *params.CheckpointOracleConfig `toml:",omitempty"`
+		CliqueCheckpoint                      *string                        `toml:",omitempty"`
 		OverrideTerminalTotalDifficulty       *big.Int                       `toml:",omitempty"`
 		OverrideTerminalTotalDifficultyPassed *bool                          `toml:",omitempty"`
 	}
@@ -179,6 +197,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SnapDiscoveryURLs != nil {
 		c.SnapDiscoveryURLs = dec.SnapDiscoveryURLs
 	}
+	if dec.DiscoveryTopic != nil {
+		c.DiscoveryTopic = *dec.DiscoveryTopic
+	}
+	if dec.TxBroadcastPeers != nil {
+		c.TxBroadcastPeers = *dec.TxBroadcastPeers
+	}
+	if dec.TxBroadcastMaxSize != nil {
+		c.TxBroadcastMaxSize = *dec.TxBroadcastMaxSize
+	}
 	if dec.NoPruning != nil {
 		c.NoPruning = *dec.NoPruning
 	}
@@ -251,6 +278,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SnapshotCache != nil {
 		c.SnapshotCache = *dec.SnapshotCache
 	}
+	if dec.SnapshotPinned != nil {
+		c.SnapshotPinned = dec.SnapshotPinned
+	}
+	if dec.SnapshotPinInterval != nil {
+		c.SnapshotPinInterval = *dec.SnapshotPinInterval
+	}
 	if dec.Preimages != nil {
 		c.Preimages = *dec.Preimages
 	}
@@ -290,6 +323,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.CheckpointOracle != nil {
 		c.CheckpointOracle = dec.CheckpointOracle
 	}
+	if dec.CliqueCheckpoint != nil {
+		c.CliqueCheckpoint = *dec.CliqueCheckpoint
+	}
 	if dec.OverrideTerminalTotalDifficulty != nil {
 		c.OverrideTerminalTotalDifficulty = dec.OverrideTerminalTotalDifficulty
 	}
@@ -87,6 +87,9 @@ type handlerConfig struct {
 	EventMux       *event.TypeMux            // Legacy event mux, deprecate for `feed`
 	Checkpoint     *params.TrustedCheckpoint // Hard coded checkpoint for sync challenges
 	RequiredBlocks map[uint64]common.Hash    // Hard coded map of required block hashes for sync challenges
+
+	TxBroadcastPeers   int    // Number of peers to send full transactions to (0 = square root of the peers)
+	TxBroadcastMaxSize uint64 // Size above which transactions are only announced (0 = no limit)
 }
 
 type handler struct {
@@ -117,6 +120,9 @@ type handler struct {
 
 	requiredBlocks map[uint64]common.Hash
 
+	txBroadcastPeers   int
+	txBroadcastMaxSize uint64
+
 	// channels for fetcher, syncer, txsyncLoop
 	quitSync chan struct{}
 
@@ -142,6 +148,9 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		merger:         config.Merger,
 		requiredBlocks: config.RequiredBlocks,
 		quitSync:       make(chan struct{}),
+
+		txBroadcastPeers:   config.TxBroadcastPeers,
+		txBroadcastMaxSize: config.TxBroadcastMaxSize,
 	}
 	if config.Sync == downloader.FullSync {
 		// The database seems empty as the current block is the genesis. Yet the snap
@@ -292,7 +301,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return n, err
 	}
-	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.removePeer)
+	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.punishPeer)
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
@@ -432,6 +441,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 
 			case <-timeout.C:
 				peer.Log().Warn("Checkpoint challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
+				peer.Report(p2p.SlowResponse)
 				h.removePeer(peer.ID())
 
 			case <-dead:
@@ -477,6 +487,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 				res.Done <- nil
 			case <-timeout.C:
 				peer.Log().Warn("Required block challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
+				peer.Report(p2p.SlowResponse)
 				h.removePeer(peer.ID())
 			}
 		}(number, hash, req)
@@ -508,6 +519,16 @@ func (h *handler) removePeer(id string) {
 	}
 }
 
+// punishPeer reports a peer that delivered invalid chain data to the reputation
+// service and requests its disconnection.
+func (h *handler) punishPeer(id string) {
+	peer := h.peers.peer(id)
+	if peer != nil {
+		peer.Report(p2p.InvalidBlock)
+		peer.Peer.Disconnect(p2p.DiscUselessPeer)
+	}
+}
+
 // unregisterPeer removes a peer from the downloader, fetchers and main peer set.
 func (h *handler) unregisterPeer(id string) {
 	// Create a custom logger to avoid printing the entire id
@@ -622,7 +643,8 @@ func (h *handler) BroadcastBlock(block *types.Block, propagate bool) {
 }
 
 // BroadcastTransactions will propagate a batch of transactions
-// - To a square root of all peers
+// - To a square root of all peers, or the configured number of peers. Transactions
+// above the configured size limit are not sent directly.
 // - And, separately, as announcements to all peers which are not known to
 // already have the given transaction.
 func (h *handler) BroadcastTransactions(txs types.Transactions) {
@@ -640,7 +662,7 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 	for _, tx := range txs {
 		peers := h.peers.peersWithoutTransaction(tx.Hash())
 		// Send the tx unconditionally to a subset of our peers
-		numDirect := int(math.Sqrt(float64(len(peers))))
+		numDirect := h.txBroadcastFanout(tx, len(peers))
 		for _, peer := range peers[:numDirect] {
 			txset[peer] = append(txset[peer], tx.Hash())
 		}
@@ -664,6 +686,21 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		"tx packs", directPeers, "broadcast txs", directCount)
 }
 
+// txBroadcastFanout returns the number of peers the transaction is sent to
+// directly, out of the given number of peers not knowing about it.
+func (h *handler) txBroadcastFanout(tx *types.Transaction, peers int) int {
+	if h.txBroadcastMaxSize > 0 && tx.Size() > h.txBroadcastMaxSize {
+		return 0
+	}
+	if h.txBroadcastPeers > 0 {
+		if h.txBroadcastPeers < peers {
+			return h.txBroadcastPeers
+		}
+		return peers
+	}
+	return int(math.Sqrt(float64(peers)))
+}
+
 // minedBroadcastLoop sends mined blocks to connected peers.
 func (h *handler) minedBroadcastLoop() {
 	defer h.wg.Done()
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
@@ -92,6 +93,7 @@ func (h *ethHandler) handleBlockAnnounces(peer *eth.Peer, hashes []common.Hash,
 	// remote peer.
 	if h.merger.PoSFinalized() {
 		// TODO (MariusVanDerWijden) drop non-updated peers after the merge
+		peer.Report(p2p.UselessAnnouncement)
 		return nil
 		// return errors.New("unexpected block announces")
 	}
@@ -120,6 +122,7 @@ func (h *ethHandler) handleBlockBroadcast(peer *eth.Peer, block *types.Block, td
 	// remote peer.
 	if h.merger.PoSFinalized() {
 		// TODO (MariusVanDerWijden) drop non-updated peers after the merge
+		peer.Report(p2p.UselessAnnouncement)
 		return nil
 		// return errors.New("unexpected block announces")
 	}
@@ -756,3 +756,31 @@ func testBroadcastMalformedBlock(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that the transaction broadcast fan-out respects the configured peer count
+// and size limit.
+func TestTxBroadcastFanout(t *testing.T) {
+	var (
+		small = types.NewTransaction(0, common.Address{}, common.Big0, 21000, common.Big1, nil)
+		large = types.NewTransaction(0, common.Address{}, common.Big0, 21000, common.Big1, make([]byte, 1024))
+	)
+	tests := []struct {
+		peers, maxSize uint64
+		tx             *types.Transaction
+		total, want    int
+	}{
+		{0, 0, small, 16, 4},
+		{0, 0, large, 16, 4},
+		{6, 0, small, 16, 6},
+		{6, 0, small, 3, 3},
+		{0, 512, small, 16, 4},
+		{0, 512, large, 16, 0},
+		{6, 512, large, 16, 0},
+	}
+	for i, test := range tests {
+		h := &handler{txBroadcastPeers: int(test.peers), txBroadcastMaxSize: test.maxSize}
+		if n := h.txBroadcastFanout(test.tx, test.total); n != test.want {
+			t.Errorf("test %d: fanout %d, want %d", i, n, test.want)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package eth
 import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
@@ -46,5 +47,10 @@ func (h *snapHandler) PeerInfo(id enode.ID) interface{} {
 // Handle is invoked from a peer's message handler when it receives a new remote
 // message that the handler couldn't consume and serve itself.
 func (h *snapHandler) Handle(peer *snap.Peer, packet snap.Packet) error {
-	return h.downloader.DeliverSnapPacket(peer, packet)
+	if err := h.downloader.DeliverSnapPacket(peer, packet); err != nil {
+		// The packet was either malformed or failed verification
+		peer.Report(p2p.InvalidMessage)
+		return err
+	}
+	return nil
 }
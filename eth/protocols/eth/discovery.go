@@ -17,8 +17,10 @@
 package eth
 
 import (
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -63,3 +65,9 @@ func currentENREntry(chain *core.BlockChain) *enrEntry {
 		ForkID: forkid.NewID(chain.Config(), chain.Genesis().Hash(), chain.CurrentHeader().Number.Uint64()),
 	}
 }
+
+// DiscoveryTopic returns the discovery v5 topic under which nodes of the network
+// with the given genesis block advertise the `eth` protocol.
+func DiscoveryTopic(genesis common.Hash) discover.Topic {
+	return discover.NewTopic("eth/" + genesis.Hex())
+}
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -153,6 +154,9 @@ func Handle(backend Backend, peer *Peer) error {
 	for {
 		if err := handleMessage(backend, peer); err != nil {
 			peer.Log().Debug("Message handling failed in `eth`", "err", err)
+			if errors.Is(err, errMsgTooLarge) || errors.Is(err, errDecode) || errors.Is(err, errInvalidMsgCode) {
+				peer.Report(p2p.InvalidMessage)
+			}
 			return err
 		}
 	}
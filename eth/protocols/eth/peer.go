@@ -71,6 +71,7 @@ type Peer struct {
 	*p2p.Peer                   // The embedded P2P package peer
 	rw        p2p.MsgReadWriter // Input/output streams for snap
 	version   uint              // Protocol version negotiated
+	traffic   *trafficMeter     // Transaction gossip traffic accounting
 
 	head common.Hash // Latest advertised head block hash
 	td   *big.Int    // Latest advertised head block total difficulty
@@ -95,11 +96,13 @@ type Peer struct {
 // NewPeer create a wrapper for a network connection and negotiated  protocol
 // version.
 func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Peer {
+	traffic := newTrafficMeter(rw)
 	peer := &Peer{
 		id:              p.ID().String(),
 		Peer:            p,
-		rw:              rw,
+		rw:              traffic,
 		version:         version,
+		traffic:         traffic,
 		knownTxs:        newKnownCache(maxKnownTxs),
 		knownBlocks:     newKnownCache(maxKnownBlocks),
 		queuedBlocks:    make(chan *blockPropagation, maxQueuedBlocks),
@@ -138,6 +141,12 @@ func (p *Peer) Version() uint {
 	return p.version
 }
 
+// Traffic retrieves the transaction gossip traffic exchanged with the peer,
+// keyed by message type.
+func (p *Peer) Traffic() map[string]MsgTraffic {
+	return p.traffic.stats()
+}
+
 // Head retrieves the current head hash and total difficulty of the peer.
 func (p *Peer) Head() (hash common.Hash, td *big.Int) {
 	p.lock.RLock()
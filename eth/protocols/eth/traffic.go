@@ -0,0 +1,106 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// txGossipMsgNames are the names of the transaction gossip messages, which are
+// accounted per peer.
+var txGossipMsgNames = map[uint64]string{
+	TransactionsMsg:               "Transactions",
+	NewPooledTransactionHashesMsg: "NewPooledTransactionHashes",
+	GetPooledTransactionsMsg:      "GetPooledTransactions",
+	PooledTransactionsMsg:         "PooledTransactions",
+}
+
+// MsgTraffic is the amount of data exchanged with a peer for one message type.
+type MsgTraffic struct {
+	MessagesIn  uint64 `json:"messagesIn"`
+	BytesIn     uint64 `json:"bytesIn"`
+	MessagesOut uint64 `json:"messagesOut"`
+	BytesOut    uint64 `json:"bytesOut"`
+}
+
+// trafficMeter wraps the message stream of a peer and accounts the size of
+// transaction gossip messages passing through it.
+type trafficMeter struct {
+	p2p.MsgReadWriter
+
+	lock    sync.Mutex
+	traffic map[uint64]*MsgTraffic
+}
+
+func newTrafficMeter(rw p2p.MsgReadWriter) *trafficMeter {
+	return &trafficMeter{MsgReadWriter: rw, traffic: make(map[uint64]*MsgTraffic)}
+}
+
+// ReadMsg implements p2p.MsgReader.
+func (m *trafficMeter) ReadMsg() (p2p.Msg, error) {
+	msg, err := m.MsgReadWriter.ReadMsg()
+	if err == nil {
+		m.account(msg.Code, msg.Size, true)
+	}
+	return msg, err
+}
+
+// WriteMsg implements p2p.MsgWriter.
+func (m *trafficMeter) WriteMsg(msg p2p.Msg) error {
+	// The size has to be taken before writing, the payload is consumed by it.
+	code, size := msg.Code, msg.Size
+	err := m.MsgReadWriter.WriteMsg(msg)
+	if err == nil {
+		m.account(code, size, false)
+	}
+	return err
+}
+
+func (m *trafficMeter) account(code uint64, size uint32, ingress bool) {
+	if _, ok := txGossipMsgNames[code]; !ok {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t := m.traffic[code]
+	if t == nil {
+		t = new(MsgTraffic)
+		m.traffic[code] = t
+	}
+	if ingress {
+		t.MessagesIn++
+		t.BytesIn += uint64(size)
+	} else {
+		t.MessagesOut++
+		t.BytesOut += uint64(size)
+	}
+}
+
+// stats returns a copy of the accounted traffic, keyed by message name.
+func (m *trafficMeter) stats() map[string]MsgTraffic {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	stats := make(map[string]MsgTraffic, len(m.traffic))
+	for code, t := range m.traffic {
+		stats[txGossipMsgNames[code]] = *t
+	}
+	return stats
+}
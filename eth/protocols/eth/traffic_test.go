@@ -0,0 +1,82 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestPeerTraffic(t *testing.T) {
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+
+	peer := NewPeer(ETH67, p2p.NewPeer(enode.ID{1}, "test", nil), net, nil)
+	defer peer.Close()
+
+	// Outbound transactions are accounted.
+	txs := types.Transactions{
+		types.NewTransaction(0, common.Address{1}, common.Big1, 21000, common.Big1, nil),
+		types.NewTransaction(1, common.Address{1}, common.Big1, 21000, common.Big1, make([]byte, 100)),
+	}
+	sent := make(chan p2p.Msg, 1)
+	go func() {
+		msg, _ := app.ReadMsg()
+		msg.Discard()
+		sent <- msg
+	}()
+	if err := peer.SendTransactions(txs); err != nil {
+		t.Fatal(err)
+	}
+	out := <-sent
+
+	// Inbound pooled transactions are accounted, other messages are not.
+	go func() {
+		p2p.Send(app, PooledTransactionsMsg, &PooledTransactionsPacket66{RequestId: 1, PooledTransactionsPacket: PooledTransactionsPacket(txs)})
+		p2p.Send(app, BlockHeadersMsg, &BlockHeadersPacket66{RequestId: 2})
+	}()
+	var in uint32
+	for i := 0; i < 2; i++ {
+		msg, err := peer.rw.ReadMsg()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Code == PooledTransactionsMsg {
+			in = msg.Size
+		}
+		msg.Discard()
+	}
+
+	traffic := peer.Traffic()
+	if len(traffic) != 2 {
+		t.Fatalf("wrong number of accounted message types: %v", traffic)
+	}
+	want := MsgTraffic{MessagesOut: 1, BytesOut: uint64(out.Size)}
+	if traffic["Transactions"] != want {
+		t.Errorf("wrong Transactions traffic: got %+v, want %+v", traffic["Transactions"], want)
+	}
+	enc, _ := rlp.EncodeToBytes(&PooledTransactionsPacket66{RequestId: 1, PooledTransactionsPacket: PooledTransactionsPacket(txs)})
+	want = MsgTraffic{MessagesIn: 1, BytesIn: uint64(len(enc))}
+	if traffic["PooledTransactions"] != want || uint64(in) != want.BytesIn {
+		t.Errorf("wrong PooledTransactions traffic: got %+v, want %+v", traffic["PooledTransactions"], want)
+	}
+}
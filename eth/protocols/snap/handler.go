@@ -18,6 +18,7 @@ package snap
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"time"
 
@@ -120,6 +121,9 @@ func Handle(backend Backend, peer *Peer) error {
 	for {
 		if err := HandleMessage(backend, peer); err != nil {
 			peer.Log().Debug("Message handling failed in `snap`", "err", err)
+			if errors.Is(err, errMsgTooLarge) || errors.Is(err, errDecode) || errors.Is(err, errInvalidMsgCode) || errors.Is(err, errBadRequest) {
+				peer.Report(p2p.InvalidMessage)
+			}
 			return err
 		}
 	}
@@ -160,7 +164,15 @@ func HandleMessage(backend Backend, peer *Peer) error {
 			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 		}
 		// Service the request, potentially returning nothing in case of errors
-		accounts, proofs := ServiceGetAccountRangeQuery(backend.Chain(), &req)
+		// or if the request for a pinned state is rejected by the accounting
+		var (
+			accounts []*AccountData
+			proofs   [][]byte
+		)
+		if done, ok := peer.admitRequest(backend.Chain(), req.Root, req.Bytes); ok {
+			accounts, proofs = ServiceGetAccountRangeQuery(backend.Chain(), &req)
+			done()
+		}
 
 		// Send back anything accumulated (or empty in case of errors)
 		return p2p.Send(peer.rw, AccountRangeMsg, &AccountRangePacket{
@@ -192,7 +204,15 @@ func HandleMessage(backend Backend, peer *Peer) error {
 			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 		}
 		// Service the request, potentially returning nothing in case of errors
-		slots, proofs := ServiceGetStorageRangesQuery(backend.Chain(), &req)
+		// or if the request for a pinned state is rejected by the accounting
+		var (
+			slots  [][]*StorageData
+			proofs [][]byte
+		)
+		if done, ok := peer.admitRequest(backend.Chain(), req.Root, req.Bytes); ok {
+			slots, proofs = ServiceGetStorageRangesQuery(backend.Chain(), &req)
+			done()
+		}
 
 		// Send back anything accumulated (or empty in case of errors)
 		return p2p.Send(peer.rw, StorageRangesMsg, &StorageRangesPacket{
@@ -251,9 +271,14 @@ func HandleMessage(backend Backend, peer *Peer) error {
 			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 		}
 		// Service the request, potentially returning nothing in case of errors
-		nodes, err := ServiceGetTrieNodesQuery(backend.Chain(), &req, start)
-		if err != nil {
-			return err
+		// or if the request for a pinned state is rejected by the accounting
+		var nodes [][]byte
+		if done, ok := peer.admitRequest(backend.Chain(), req.Root, req.Bytes); ok {
+			nodes, err = ServiceGetTrieNodesQuery(backend.Chain(), &req, start)
+			done()
+			if err != nil {
+				return err
+			}
 		}
 		// Send back anything accumulated (or empty in case of errors)
 		return p2p.Send(peer.rw, TrieNodesMsg, &TrieNodesPacket{
@@ -287,7 +312,7 @@ func ServiceGetAccountRangeQuery(chain *core.BlockChain, req *GetAccountRangePac
 	if err != nil {
 		return nil, nil
 	}
-	it, err := chain.Snapshots().AccountIterator(req.Root, req.Origin)
+	it, err := accountIterator(chain, req.Root, req.Origin)
 	if err != nil {
 		return nil, nil
 	}
@@ -371,7 +396,7 @@ func ServiceGetStorageRangesQuery(chain *core.BlockChain, req *GetStorageRangesP
 			limit, req.Limit = common.BytesToHash(req.Limit), nil
 		}
 		// Retrieve the requested state and bail out if non existent
-		it, err := chain.Snapshots().StorageIterator(req.Root, account, origin)
+		it, err := storageIterator(chain, req.Root, account, origin)
 		if err != nil {
 			return nil, nil
 		}
@@ -0,0 +1,36 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	syncAccountsGauge  = metrics.NewRegisteredGauge("eth/protocols/snap/sync/accounts", nil)
+	syncStorageGauge   = metrics.NewRegisteredGauge("eth/protocols/snap/sync/storage", nil)
+	syncBytecodesGauge = metrics.NewRegisteredGauge("eth/protocols/snap/sync/bytecodes", nil)
+	syncProgressGauge  = metrics.NewRegisteredGauge("eth/protocols/snap/sync/progress", nil) // Percentage of the snapshot phase done
+	syncETAGauge       = metrics.NewRegisteredGauge("eth/protocols/snap/sync/eta", nil)      // Seconds until the snapshot phase is done
+
+	healTrienodesGauge = metrics.NewRegisteredGauge("eth/protocols/snap/heal/trienodes", nil)
+	healBytecodesGauge = metrics.NewRegisteredGauge("eth/protocols/snap/heal/bytecodes", nil)
+	healPendingGauge   = metrics.NewRegisteredGauge("eth/protocols/snap/heal/pending", nil)
+
+	pinnedServeMeter  = metrics.NewRegisteredMeter("eth/protocols/snap/serve/pinned/requests", nil) // Requests served from pinned states
+	pinnedRejectMeter = metrics.NewRegisteredMeter("eth/protocols/snap/serve/pinned/rejected", nil) // Requests rejected by the accounting
+)
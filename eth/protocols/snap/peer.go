@@ -29,6 +29,7 @@ type Peer struct {
 	*p2p.Peer                   // The embedded P2P package peer
 	rw        p2p.MsgReadWriter // Input/output streams for snap
 	version   uint              // Protocol version negotiated
+	pinned    *serveBudget      // Budget for serving requests from pinned states
 
 	logger log.Logger // Contextual logger with the peer id injected
 }
@@ -42,6 +43,7 @@ func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter) *Peer {
 		Peer:    p,
 		rw:      rw,
 		version: version,
+		pinned:  newServeBudget(),
 		logger:  log.New("peer", id[:8]),
 	}
 }
@@ -52,6 +54,7 @@ func NewFakePeer(version uint, id string, rw p2p.MsgReadWriter) *Peer {
 		id:      id,
 		rw:      rw,
 		version: version,
+		pinned:  newServeBudget(),
 		logger:  log.New("peer", id[:8]),
 	}
 }
@@ -0,0 +1,220 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// States pinned by the chain are served from the tries once they are no longer
+// covered by the snapshot layers. Iterating the tries is a lot more expensive,
+// so these requests are metered per peer and limited in concurrency.
+const (
+	// pinnedServeRate is the number of response bytes per second a peer may
+	// request from pinned states.
+	pinnedServeRate = 1024 * 1024
+
+	// pinnedServeBurst is the maximum number of response bytes a peer may
+	// request from pinned states at once.
+	pinnedServeBurst = 4 * softResponseLimit
+
+	// maxPinnedServes is the maximum number of requests for pinned states that
+	// are served concurrently, across all peers.
+	maxPinnedServes = 4
+)
+
+// errStateNotPinned is returned if a state is requested which is neither covered
+// by the snapshot layers nor pinned.
+var errStateNotPinned = errors.New("state not pinned")
+
+// pinnedServeSlots limits the number of concurrently served pinned requests.
+var pinnedServeSlots = make(chan struct{}, maxPinnedServes)
+
+// serveBudget is a token bucket metering the response bytes a peer requests
+// from pinned states. A request is admitted as long as the budget is positive,
+// and charged with the number of bytes requested.
+type serveBudget struct {
+	lock    sync.Mutex
+	tokens  float64
+	updated time.Time
+}
+
+func newServeBudget() *serveBudget {
+	return &serveBudget{tokens: pinnedServeBurst, updated: time.Now()}
+}
+
+// charge refills the budget according to the elapsed time and takes the given
+// amount of bytes from it, returning false if the budget was exhausted.
+func (b *serveBudget) charge(bytes uint64) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.updated).Seconds() * pinnedServeRate
+	if b.tokens > pinnedServeBurst {
+		b.tokens = pinnedServeBurst
+	}
+	b.updated = now
+
+	if b.tokens <= 0 {
+		return false
+	}
+	b.tokens -= float64(bytes)
+	return true
+}
+
+// servesFromPinned reports whether the given state root can only be served from
+// the pinned tries, not from the snapshot layers.
+func servesFromPinned(chain *core.BlockChain, root common.Hash) bool {
+	if snaps := chain.Snapshots(); snaps != nil && snaps.Snapshot(root) != nil {
+		return false
+	}
+	return chain.PinnedState(root)
+}
+
+// admitRequest checks whether a state request of the peer may be served. Requests
+// for states covered by the snapshot layers are always admitted, those for pinned
+// states are subject to the request accounting. If admitted, the returned function
+// must be called after the request was served.
+func (p *Peer) admitRequest(chain *core.BlockChain, root common.Hash, bytes uint64) (func(), bool) {
+	if !servesFromPinned(chain, root) {
+		return func() {}, true
+	}
+	if bytes > softResponseLimit {
+		bytes = softResponseLimit
+	}
+	if !p.pinned.charge(bytes) {
+		pinnedRejectMeter.Mark(1)
+		p.logger.Debug("Rejected pinned state request, budget exhausted", "root", root)
+		return nil, false
+	}
+	select {
+	case pinnedServeSlots <- struct{}{}:
+	default:
+		pinnedRejectMeter.Mark(1)
+		p.logger.Debug("Rejected pinned state request, too many in flight", "root", root)
+		return nil, false
+	}
+	pinnedServeMeter.Mark(1)
+	return func() { <-pinnedServeSlots }, true
+}
+
+// accountIterator returns an iterator over the accounts of the given state,
+// served from the snapshot layers if possible, or from the trie if pinned.
+func accountIterator(chain *core.BlockChain, root common.Hash, origin common.Hash) (snapshot.AccountIterator, error) {
+	if snaps := chain.Snapshots(); snaps != nil {
+		if it, err := snaps.AccountIterator(root, origin); err == nil {
+			return it, nil
+		}
+	}
+	if !chain.PinnedState(root) {
+		return nil, errStateNotPinned
+	}
+	tr, err := trie.New(trie.StateTrieID(root), chain.StateCache().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	return &trieAccountIterator{it: trie.NewIterator(tr.NodeIterator(origin[:]))}, nil
+}
+
+// storageIterator returns an iterator over the storage slots of an account in
+// the given state, served from the snapshot layers if possible, or from the
+// trie if pinned.
+func storageIterator(chain *core.BlockChain, root common.Hash, account common.Hash, origin common.Hash) (snapshot.StorageIterator, error) {
+	if snaps := chain.Snapshots(); snaps != nil {
+		if it, err := snaps.StorageIterator(root, account, origin); err == nil {
+			return it, nil
+		}
+	}
+	if !chain.PinnedState(root) {
+		return nil, errStateNotPinned
+	}
+	accTrie, err := trie.New(trie.StateTrieID(root), chain.StateCache().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	blob, err := accTrie.TryGet(account[:])
+	if err != nil {
+		return nil, err
+	}
+	// Accounts without storage are iterated over an empty trie
+	storageRoot := types.EmptyRootHash
+	if len(blob) > 0 {
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(blob, &acc); err != nil {
+			return nil, err
+		}
+		storageRoot = acc.Root
+	}
+	tr, err := trie.New(trie.StorageTrieID(root, account, storageRoot), chain.StateCache().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	return &trieStorageIterator{it: trie.NewIterator(tr.NodeIterator(origin[:]))}, nil
+}
+
+// trieAccountIterator iterates the accounts of a state trie, converting them
+// into the slim format served by the snapshot layers.
+type trieAccountIterator struct {
+	it      *trie.Iterator
+	account []byte
+	err     error
+}
+
+func (it *trieAccountIterator) Next() bool {
+	if it.err != nil || !it.it.Next() {
+		return false
+	}
+	var acc types.StateAccount
+	if err := rlp.DecodeBytes(it.it.Value, &acc); err != nil {
+		it.err = err
+		return false
+	}
+	it.account = snapshot.SlimAccountRLP(acc.Nonce, acc.Balance, acc.Root, acc.CodeHash)
+	return true
+}
+
+func (it *trieAccountIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Err
+}
+
+func (it *trieAccountIterator) Hash() common.Hash { return common.BytesToHash(it.it.Key) }
+func (it *trieAccountIterator) Account() []byte   { return it.account }
+func (it *trieAccountIterator) Release()          {}
+
+// trieStorageIterator iterates the slots of a storage trie.
+type trieStorageIterator struct {
+	it *trie.Iterator
+}
+
+func (it *trieStorageIterator) Next() bool        { return it.it.Next() }
+func (it *trieStorageIterator) Error() error      { return it.it.Err }
+func (it *trieStorageIterator) Hash() common.Hash { return common.BytesToHash(it.it.Key) }
+func (it *trieStorageIterator) Slot() []byte      { return it.it.Value }
+func (it *trieStorageIterator) Release()          {}
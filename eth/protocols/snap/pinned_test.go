@@ -0,0 +1,178 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newPinnedTestChain creates a chain without snapshots which pins the state of
+// every 64th block.
+func newPinnedTestChain(t *testing.T) (*core.BlockChain, []*types.Block, common.Address) {
+	var (
+		engine   = ethash.NewFaker()
+		contract = common.Address{0xcc}
+		genesis  = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+			Alloc: core.GenesisAlloc{
+				contract: {
+					Balance: big.NewInt(1),
+					Code:    []byte{0x0},
+					Storage: map[common.Hash]common.Hash{
+						{0x01}: {0x01},
+						{0x02}: {0x02},
+						{0x03}: {0x03},
+					},
+				},
+			},
+		}
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 2*core.TriesInMemory, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{byte(i)})
+	})
+	config := &core.CacheConfig{
+		TrieCleanLimit:   256,
+		TrieDirtyLimit:   256,
+		TrieTimeLimit:    5 * time.Minute,
+		PinnedStateEvery: 64,
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), config, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return chain, blocks, contract
+}
+
+// Tests that account and storage ranges of pinned states are served from the
+// tries if there are no snapshot layers for them.
+func TestServePinnedState(t *testing.T) {
+	chain, blocks, contract := newPinnedTestChain(t)
+	defer chain.Stop()
+
+	pinned, unpinned := blocks[63].Root(), blocks[64].Root()
+	if !chain.PinnedState(pinned) || chain.PinnedState(unpinned) {
+		t.Fatalf("unexpected pinned states: %v", chain.PinnedStates())
+	}
+	// Only the pinned state is served
+	limit := common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	req := &GetAccountRangePacket{Root: unpinned, Limit: limit, Bytes: softResponseLimit}
+	if accounts, _ := ServiceGetAccountRangeQuery(chain, req); len(accounts) != 0 {
+		t.Fatalf("served %d accounts of unpinned state", len(accounts))
+	}
+	req = &GetAccountRangePacket{Root: pinned, Limit: limit, Bytes: softResponseLimit}
+	accounts, _ := ServiceGetAccountRangeQuery(chain, req)
+
+	// The coinbases of the first 64 blocks and the contract are expected, all
+	// accounts encoded in the slim format of the snapshots
+	if len(accounts) != 65 {
+		t.Fatalf("wrong number of accounts: have %d, want %d", len(accounts), 65)
+	}
+	var found bool
+	for _, account := range accounts {
+		full, err := snapshot.FullAccount(account.Body)
+		if err != nil {
+			t.Fatalf("invalid account encoding: %v", err)
+		}
+		if account.Hash == crypto.Keccak256Hash(contract[:]) {
+			found = true
+			if full.Balance.Cmp(big.NewInt(1)) != 0 {
+				t.Fatalf("wrong contract balance: %v", full.Balance)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("contract not served")
+	}
+	// The storage of the contract is served too
+	sreq := &GetStorageRangesPacket{
+		Root:     pinned,
+		Accounts: []common.Hash{crypto.Keccak256Hash(contract[:])},
+		Bytes:    softResponseLimit,
+	}
+	slots, _ := ServiceGetStorageRangesQuery(chain, sreq)
+	if len(slots) != 1 || len(slots[0]) != 3 {
+		t.Fatalf("wrong storage slots served: %v", slots)
+	}
+}
+
+// Tests that requests for pinned states are rejected once a peer exceeds its
+// budget, or too many of them are served at once.
+func TestServePinnedAccounting(t *testing.T) {
+	chain, blocks, _ := newPinnedTestChain(t)
+	defer chain.Stop()
+
+	var (
+		pinned = blocks[63].Root()
+		peer   = NewFakePeer(1, "aaaaaaaaaaaaaaaa", nil)
+	)
+	// Requests for states in the snapshot layers (or unavailable) are not accounted
+	for i := 0; i < 10; i++ {
+		done, ok := peer.admitRequest(chain, blocks[64].Root(), softResponseLimit)
+		if !ok {
+			t.Fatalf("request %d for unpinned state rejected", i)
+		}
+		done()
+	}
+	// Requests for pinned states exhaust the budget
+	var admitted int
+	for ; admitted < 10; admitted++ {
+		done, ok := peer.admitRequest(chain, pinned, softResponseLimit)
+		if !ok {
+			break
+		}
+		done()
+	}
+	if admitted < pinnedServeBurst/softResponseLimit || admitted > pinnedServeBurst/softResponseLimit+1 {
+		t.Fatalf("wrong number of admitted requests: %d", admitted)
+	}
+	// Concurrent requests are limited across peers
+	var releases []func()
+	for i := 0; i < maxPinnedServes; i++ {
+		done, ok := NewFakePeer(1, "bbbbbbbbbbbbbbbb", nil).admitRequest(chain, pinned, 1024)
+		if !ok {
+			t.Fatalf("concurrent request %d rejected", i)
+		}
+		releases = append(releases, done)
+	}
+	if _, ok := NewFakePeer(1, "cccccccccccccccc", nil).admitRequest(chain, pinned, 1024); ok {
+		t.Fatalf("request exceeding the concurrency limit admitted")
+	}
+	for _, done := range releases {
+		done()
+	}
+	if done, ok := NewFakePeer(1, "dddddddddddddddd", nil).admitRequest(chain, pinned, 1024); !ok {
+		t.Fatalf("request rejected after releasing slots")
+	} else {
+		done()
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Phases of the snap sync reported in SyncStatus.
+const (
+	PhaseSnapshot = "snapshot" // Downloading account and storage ranges and bytecodes
+	PhaseHealing  = "healing"  // Fixing up the state trie by retrieving missing nodes
+	PhaseDone     = "done"     // State sync completed
+)
+
+// SyncStatus is a detailed report of the snap sync progress, broken down by
+// sync phase.
+type SyncStatus struct {
+	Phase   string      `json:"phase"`   // Current phase of the sync cycle
+	Root    common.Hash `json:"root"`    // State root being synced
+	Elapsed uint64      `json:"elapsed"` // Seconds since the sync was started by this process
+
+	// Progress of the snapshot phase
+	Accounts  PhaseProgress `json:"accounts"`
+	Storage   PhaseProgress `json:"storage"`
+	Bytecodes PhaseProgress `json:"bytecodes"`
+	Progress  float64       `json:"progress"` // Estimated percentage of the snapshot phase done
+	ETA       uint64        `json:"eta"`      // Estimated seconds until the snapshot phase is done (0 = unknown)
+
+	// Progress of the healing phase
+	HealTrienodes PhaseProgress `json:"healTrienodes"`
+	HealBytecodes PhaseProgress `json:"healBytecodes"`
+	HealPending   uint64        `json:"healPending"` // Number of trie nodes and bytecodes known to be missing
+	HealETA       uint64        `json:"healEta"`     // Seconds to retrieve the known missing items (0 = unknown)
+}
+
+// PhaseProgress is the progress of retrieving one kind of state data.
+type PhaseProgress struct {
+	Items    uint64             `json:"items"`    // Number of items retrieved
+	Bytes    common.StorageSize `json:"bytes"`    // Number of bytes retrieved
+	ItemRate float64            `json:"itemRate"` // Items per second retrieved by this process
+	ByteRate float64            `json:"byteRate"` // Bytes per second retrieved by this process
+}
+
+// newPhaseProgress creates a progress report from the current and the initial
+// counters of a phase.
+func newPhaseProgress(items, startItems uint64, bytes, startBytes common.StorageSize, elapsed time.Duration) PhaseProgress {
+	progress := PhaseProgress{Items: items, Bytes: bytes}
+	if secs := elapsed.Seconds(); secs > 0 {
+		if items > startItems {
+			progress.ItemRate = float64(items-startItems) / secs
+		}
+		if bytes > startBytes {
+			progress.ByteRate = float64(bytes-startBytes) / secs
+		}
+	}
+	return progress
+}
+
+// Status returns a detailed report of the snap sync progress, or nil if no
+// sync cycle was run yet.
+func (s *Syncer) Status() *SyncStatus {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.extStatus
+}
+
+// counters returns the current sync statistics, without the tasks.
+func (s *Syncer) counters() *SyncProgress {
+	return &SyncProgress{
+		AccountSynced:      s.accountSynced,
+		AccountBytes:       s.accountBytes,
+		BytecodeSynced:     s.bytecodeSynced,
+		BytecodeBytes:      s.bytecodeBytes,
+		StorageSynced:      s.storageSynced,
+		StorageBytes:       s.storageBytes,
+		TrienodeHealSynced: s.trienodeHealSynced,
+		TrienodeHealBytes:  s.trienodeHealBytes,
+		BytecodeHealSynced: s.bytecodeHealSynced,
+		BytecodeHealBytes:  s.bytecodeHealBytes,
+	}
+}
+
+// snapshotProgress returns the number of bytes retrieved during the snapshot
+// phase, along with the total size extrapolated from the filled part of the
+// account hash space. The estimate is zero if there's no meaningful progress.
+func (s *Syncer) snapshotProgress() (common.StorageSize, float64) {
+	synced := s.accountBytes + s.bytecodeBytes + s.storageBytes
+	if synced == 0 {
+		return 0, 0
+	}
+	accountGaps := new(big.Int)
+	for _, task := range s.tasks {
+		accountGaps.Add(accountGaps, new(big.Int).Sub(task.Last.Big(), task.Next.Big()))
+	}
+	accountFills := new(big.Int).Sub(hashSpace, accountGaps)
+	if accountFills.BitLen() == 0 {
+		return synced, 0
+	}
+	estBytes := float64(new(big.Int).Div(
+		new(big.Int).Mul(new(big.Int).SetUint64(uint64(synced)), hashSpace),
+		accountFills,
+	).Uint64())
+	return synced, estBytes
+}
+
+// snapshotETA estimates the time until the snapshot phase is done, based on the
+// download rate of this process. Zero is returned if it can't be estimated.
+func (s *Syncer) snapshotETA(synced common.StorageSize, estBytes float64) time.Duration {
+	if s.startStats == nil || estBytes < float64(synced) {
+		return 0
+	}
+	start := s.startStats.AccountBytes + s.startStats.BytecodeBytes + s.startStats.StorageBytes
+	if synced <= start {
+		return 0
+	}
+	rate := float64(synced-start) / float64(time.Since(s.startTime))
+	return time.Duration((estBytes - float64(synced)) / rate)
+}
+
+// updateStatus recalculates the detailed progress report returned by Status and
+// updates the sync metrics.
+func (s *Syncer) updateStatus(done bool) {
+	var (
+		now    = time.Now()
+		start  = s.startStats
+		status = &SyncStatus{
+			Root:    s.root,
+			Elapsed: uint64(now.Sub(s.startTime) / time.Second),
+		}
+	)
+	switch {
+	case done:
+		status.Phase = PhaseDone
+	case len(s.tasks) > 0:
+		status.Phase = PhaseSnapshot
+	default:
+		status.Phase = PhaseHealing
+	}
+	elapsed := now.Sub(s.startTime)
+	status.Accounts = newPhaseProgress(s.accountSynced, start.AccountSynced, s.accountBytes, start.AccountBytes, elapsed)
+	status.Storage = newPhaseProgress(s.storageSynced, start.StorageSynced, s.storageBytes, start.StorageBytes, elapsed)
+	status.Bytecodes = newPhaseProgress(s.bytecodeSynced, start.BytecodeSynced, s.bytecodeBytes, start.BytecodeBytes, elapsed)
+
+	if len(s.tasks) > 0 {
+		synced, estBytes := s.snapshotProgress()
+		if estBytes >= 1 {
+			status.Progress = float64(synced) * 100 / estBytes
+			status.ETA = uint64(s.snapshotETA(synced, estBytes) / time.Second)
+		}
+	} else {
+		status.Progress = 100
+	}
+	if s.healStats != nil {
+		var (
+			heal    = s.healStats
+			elapsed = now.Sub(s.healTime)
+		)
+		status.HealTrienodes = newPhaseProgress(s.trienodeHealSynced, heal.TrienodeHealSynced, s.trienodeHealBytes, heal.TrienodeHealBytes, elapsed)
+		status.HealBytecodes = newPhaseProgress(s.bytecodeHealSynced, heal.BytecodeHealSynced, s.bytecodeHealBytes, heal.BytecodeHealBytes, elapsed)
+	} else {
+		status.HealTrienodes = PhaseProgress{Items: s.trienodeHealSynced, Bytes: s.trienodeHealBytes}
+		status.HealBytecodes = PhaseProgress{Items: s.bytecodeHealSynced, Bytes: s.bytecodeHealBytes}
+	}
+	if !done && s.healer != nil {
+		status.HealPending = uint64(s.healer.scheduler.Pending())
+	}
+	// The healing estimate is very rough, as the number of missing items grows
+	// while the trie is being explored.
+	if rate := status.HealTrienodes.ItemRate + status.HealBytecodes.ItemRate; rate > 0 {
+		status.HealETA = uint64(float64(status.HealPending) / rate)
+	}
+	syncAccountsGauge.Update(int64(status.Accounts.Items))
+	syncStorageGauge.Update(int64(status.Storage.Items))
+	syncBytecodesGauge.Update(int64(status.Bytecodes.Items))
+	syncProgressGauge.Update(int64(status.Progress))
+	syncETAGauge.Update(int64(status.ETA))
+	healTrienodesGauge.Update(int64(status.HealTrienodes.Items))
+	healBytecodesGauge.Update(int64(status.HealBytecodes.Items))
+	healPendingGauge.Update(int64(status.HealPending))
+
+	s.lock.Lock()
+	s.extStatus = status
+	s.lock.Unlock()
+}
+
+// saveHealState persists the trie nodes retrieved by the healer which can't be
+// committed yet, so healing can resume from them after a restart.
+func (s *Syncer) saveHealState() {
+	var nodes []trie.NodeSyncResult
+	if s.healer != nil {
+		nodes = s.healer.scheduler.Retrieved()
+	}
+	if len(nodes) == 0 {
+		rawdb.DeleteSnapshotSyncHeal(s.db)
+		return
+	}
+	blob, err := rlp.EncodeToBytes(nodes)
+	if err != nil {
+		panic(err) // This can only fail during implementation
+	}
+	rawdb.WriteSnapshotSyncHeal(s.db, blob)
+}
+
+// resumeHeal feeds the trie nodes persisted by a previous healing cycle into the
+// fresh healer, restoring its progress without retrieving them again. Nodes are
+// only used if they match the hash requested by the healer, so the nodes of a
+// previous state root which are not part of the current one are dropped.
+func (s *Syncer) resumeHeal() {
+	blob := rawdb.ReadSnapshotSyncHeal(s.db)
+	if len(blob) == 0 {
+		return
+	}
+	var nodes []trie.NodeSyncResult
+	if err := rlp.DecodeBytes(blob, &nodes); err != nil {
+		log.Error("Failed to decode snap sync healing state", "err", err)
+		return
+	}
+	cache := make(map[string][]byte, len(nodes))
+	for _, node := range nodes {
+		cache[node.Path] = node.Data
+	}
+	var restored int
+	for len(cache) > 0 {
+		paths, hashes, codes := s.healer.scheduler.Missing(0)
+		if len(paths) == 0 && len(codes) == 0 {
+			break
+		}
+		for i, path := range paths {
+			data, ok := cache[path]
+			delete(cache, path)
+			if ok && crypto.Keccak256Hash(data) == hashes[i] {
+				if err := s.healer.scheduler.ProcessNode(trie.NodeSyncResult{Path: path, Data: data}); err == nil {
+					restored++
+					continue
+				}
+			}
+			s.healer.trieTasks[path] = hashes[i]
+		}
+		for _, hash := range codes {
+			s.healer.codeTasks[hash] = struct{}{}
+		}
+	}
+	log.Info("Resumed state healing", "restored", restored, "dropped", len(nodes)-restored)
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/msgrate"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
@@ -405,6 +406,20 @@ type SyncPeer interface {
 	Log() log.Logger
 }
 
+// reputationReporter is implemented by sync peers backed by a live p2p connection,
+// allowing the syncer to feed their behaviour into the node's reputation service.
+type reputationReporter interface {
+	Report(ev p2p.ReputationEvent)
+}
+
+// reportPeer feeds a reputation event about a sync peer into the p2p layer, if
+// the peer supports it.
+func reportPeer(peer SyncPeer, ev p2p.ReputationEvent) {
+	if r, ok := peer.(reputationReporter); ok {
+		r.Report(ev)
+	}
+}
+
 // Syncer is an Ethereum account and storage trie syncer based on snapshots and
 // the  snap protocol. It's purpose is to download all the accounts and storage
 // slots from remote peers and reassemble chunks of the state trie, on top of
@@ -449,6 +464,7 @@ type Syncer struct {
 	storageBytes   common.StorageSize // Number of storage trie bytes persisted to disk
 
 	extProgress *SyncProgress // progress that can be exposed to external caller.
+	extStatus   *SyncStatus   // detailed progress that can be exposed to external caller.
 
 	// Request tracking during healing phase
 	trienodeHealIdlers map[string]struct{} // Peers that aren't serving trie node requests
@@ -477,8 +493,11 @@ type Syncer struct {
 	storageHealed      uint64             // Number of storage slots downloaded during the healing stage
 	storageHealedBytes common.StorageSize // Number of raw storage bytes persisted to disk during the healing stage
 
-	startTime time.Time // Time instance when snapshot sync started
-	logTime   time.Time // Time instance when status was last reported
+	startTime  time.Time     // Time instance when snapshot sync started
+	startStats *SyncProgress // Sync statistics when snapshot sync started
+	healTime   time.Time     // Time instance when state healing started
+	healStats  *SyncProgress // Sync statistics when state healing started
+	logTime    time.Time     // Time instance when status was last reported
 
 	pend sync.WaitGroup // Tracks network request goroutines for graceful shutdown
 	lock sync.RWMutex   // Protects fields that can change outside of sync (peers, reqs, root)
@@ -595,10 +614,18 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 	}
 	// Retrieve the previous sync status from LevelDB and abort if already synced
 	s.loadSyncStatus()
+	if s.startStats == nil {
+		s.startStats = s.counters()
+	}
 	if len(s.tasks) == 0 && s.healer.scheduler.Pending() == 0 {
 		log.Debug("Snapshot sync already completed")
+		s.updateStatus(true)
 		return nil
 	}
+	// If the snapshot phase is done, restore any previous healing progress
+	if len(s.tasks) == 0 {
+		s.resumeHeal()
+	}
 	defer func() { // Persist any progress, independent of failure
 		for _, task := range s.tasks {
 			s.forwardAccountTask(task)
@@ -660,6 +687,7 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 		s.cleanStorageTasks()
 		s.cleanAccountTasks()
 		if len(s.tasks) == 0 && s.healer.scheduler.Pending() == 0 {
+			s.updateStatus(true)
 			return nil
 		}
 		// Assign all the data retrieval tasks to any free peers
@@ -669,24 +697,17 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 
 		if len(s.tasks) == 0 {
 			// Sync phase done, run heal phase
+			if s.healStats == nil {
+				s.healTime, s.healStats = time.Now(), s.counters()
+			}
 			s.assignTrienodeHealTasks(trienodeHealResps, trienodeHealReqFails, cancel)
 			s.assignBytecodeHealTasks(bytecodeHealResps, bytecodeHealReqFails, cancel)
 		}
 		// Update sync progress
 		s.lock.Lock()
-		s.extProgress = &SyncProgress{
-			AccountSynced:      s.accountSynced,
-			AccountBytes:       s.accountBytes,
-			BytecodeSynced:     s.bytecodeSynced,
-			BytecodeBytes:      s.bytecodeBytes,
-			StorageSynced:      s.storageSynced,
-			StorageBytes:       s.storageBytes,
-			TrienodeHealSynced: s.trienodeHealSynced,
-			TrienodeHealBytes:  s.trienodeHealBytes,
-			BytecodeHealSynced: s.bytecodeHealSynced,
-			BytecodeHealBytes:  s.bytecodeHealBytes,
-		}
+		s.extProgress = s.counters()
 		s.lock.Unlock()
+		s.updateStatus(false)
 		// Wait for something to happen
 		select {
 		case <-s.update:
@@ -840,24 +861,17 @@ func (s *Syncer) saveSyncStatus() {
 		}
 	}
 	// Store the actual progress markers
-	progress := &SyncProgress{
-		Tasks:              s.tasks,
-		AccountSynced:      s.accountSynced,
-		AccountBytes:       s.accountBytes,
-		BytecodeSynced:     s.bytecodeSynced,
-		BytecodeBytes:      s.bytecodeBytes,
-		StorageSynced:      s.storageSynced,
-		StorageBytes:       s.storageBytes,
-		TrienodeHealSynced: s.trienodeHealSynced,
-		TrienodeHealBytes:  s.trienodeHealBytes,
-		BytecodeHealSynced: s.bytecodeHealSynced,
-		BytecodeHealBytes:  s.bytecodeHealBytes,
-	}
+	progress := s.counters()
+	progress.Tasks = s.tasks
+
 	status, err := json.Marshal(progress)
 	if err != nil {
 		panic(err) // This can only fail during implementation
 	}
 	rawdb.WriteSnapshotSyncStatus(s.db, status)
+
+	// Store the healing progress, allowing it to resume after a restart
+	s.saveHealState()
 }
 
 // Progress returns the snap sync status statistics.
@@ -1000,6 +1014,7 @@ func (s *Syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Account range request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.SlowResponse)
 			s.rates.Update(idle, AccountRangeMsg, 0, 0)
 			s.scheduleRevertAccountRequest(req)
 		})
@@ -1111,6 +1126,7 @@ func (s *Syncer) assignBytecodeTasks(success chan *bytecodeResponse, fail chan *
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.SlowResponse)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeRequest(req)
 		})
@@ -1258,6 +1274,7 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Storage request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.SlowResponse)
 			s.rates.Update(idle, StorageRangesMsg, 0, 0)
 			s.scheduleRevertStorageRequest(req)
 		})
@@ -1395,6 +1412,7 @@ func (s *Syncer) assignTrienodeHealTasks(success chan *trienodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Trienode heal request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.SlowResponse)
 			s.rates.Update(idle, TrieNodesMsg, 0, 0)
 			s.scheduleRevertTrienodeHealRequest(req)
 		})
@@ -1511,6 +1529,7 @@ func (s *Syncer) assignBytecodeHealTasks(success chan *bytecodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode heal request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.SlowResponse)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeHealRequest(req)
 		})
@@ -2430,6 +2449,7 @@ func (s *Syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 		accounts: accs,
 		cont:     cont,
 	}
+	reportPeer(peer, p2p.UsefulResponse)
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -2541,6 +2561,7 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 		hashes: req.hashes,
 		codes:  codes,
 	}
+	reportPeer(peer, p2p.UsefulResponse)
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -2687,6 +2708,7 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 		slots:    slots,
 		cont:     cont,
 	}
+	reportPeer(peer, p2p.UsefulResponse)
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -2794,6 +2816,7 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 		hashes: req.hashes,
 		nodes:  nodes,
 	}
+	reportPeer(peer, p2p.UsefulResponse)
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -2892,6 +2915,7 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 		hashes: req.hashes,
 		codes:  codes,
 	}
+	reportPeer(peer, p2p.UsefulResponse)
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -2946,29 +2970,11 @@ func (s *Syncer) reportSyncProgress(force bool) {
 		return
 	}
 	// Don't report anything until we have a meaningful progress
-	synced := s.accountBytes + s.bytecodeBytes + s.storageBytes
-	if synced == 0 {
-		return
-	}
-	accountGaps := new(big.Int)
-	for _, task := range s.tasks {
-		accountGaps.Add(accountGaps, new(big.Int).Sub(task.Last.Big(), task.Next.Big()))
-	}
-	accountFills := new(big.Int).Sub(hashSpace, accountGaps)
-	if accountFills.BitLen() == 0 {
-		return
-	}
-	s.logTime = time.Now()
-	estBytes := float64(new(big.Int).Div(
-		new(big.Int).Mul(new(big.Int).SetUint64(uint64(synced)), hashSpace),
-		accountFills,
-	).Uint64())
-	// Don't report anything until we have a meaningful progress
+	synced, estBytes := s.snapshotProgress()
 	if estBytes < 1.0 {
 		return
 	}
-	elapsed := time.Since(s.startTime)
-	estTime := elapsed / time.Duration(synced) * time.Duration(estBytes)
+	s.logTime = time.Now()
 
 	// Create a mega progress report
 	var (
@@ -2978,7 +2984,7 @@ func (s *Syncer) reportSyncProgress(force bool) {
 		bytecode = fmt.Sprintf("%v@%v", log.FormatLogfmtUint64(s.bytecodeSynced), s.bytecodeBytes.TerminalString())
 	)
 	log.Info("Syncing: state download in progress", "synced", progress, "state", synced,
-		"accounts", accounts, "slots", storage, "codes", bytecode, "eta", common.PrettyDuration(estTime-elapsed))
+		"accounts", accounts, "slots", storage, "codes", bytecode, "eta", common.PrettyDuration(s.snapshotETA(synced, estBytes)))
 }
 
 // reportHealProgress calculates various status reports and provides it to the user.
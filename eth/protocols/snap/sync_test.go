@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"sort"
@@ -1789,3 +1790,80 @@ func TestSlotEstimation(t *testing.T) {
 		}
 	}
 }
+
+// TestSyncHealResume tests that healing resumes from the trie nodes retrieved
+// before an interruption, instead of retrieving them again.
+func TestSyncHealResume(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		rootPath = trie.NewSyncPath(nil)
+	)
+	nodeScheme, sourceAccountTrie, elems := makeAccountTrieNoStorage(1000)
+
+	// Skip the snapshot phase, so the whole state has to be healed.
+	status, _ := json.Marshal(&SyncProgress{})
+	rawdb.WriteSnapshotSyncStatus(db, status)
+
+	// Run the first cycle, which is interrupted after the root is retrieved.
+	var (
+		once   sync.Once
+		cancel = make(chan struct{})
+		term   = func() {
+			once.Do(func() {
+				close(cancel)
+			})
+		}
+		requests int
+	)
+	source := newTestPeer("source", t, term)
+	source.accountTrie = sourceAccountTrie.Copy()
+	source.accountValues = elems
+	source.trieRequestHandler = func(t *testPeer, requestId uint64, root common.Hash, paths []TrieNodePathSet, cap uint64) error {
+		if requests++; requests > 1 {
+			t.term()
+			return nil
+		}
+		return defaultTrieRequestHandler(t, requestId, root, paths, cap)
+	}
+	syncer := NewSyncer(db, nodeScheme)
+	syncer.Register(source)
+	source.remote = syncer
+	if err := syncer.Sync(sourceAccountTrie.Hash(), cancel); err != ErrCancelled {
+		t.Fatalf("wrong error from interrupted sync: %v", err)
+	}
+	if status := syncer.Status(); status == nil || status.Phase != PhaseHealing || status.HealTrienodes.Items != 1 {
+		t.Fatalf("wrong status of interrupted sync: %+v", status)
+	}
+	if len(rawdb.ReadSnapshotSyncHeal(db)) == 0 {
+		t.Fatal("healing state not persisted")
+	}
+
+	// Run the second cycle, which must not retrieve the root again.
+	source = newTestPeer("source", t, func() {})
+	source.accountTrie = sourceAccountTrie.Copy()
+	source.accountValues = elems
+	source.trieRequestHandler = func(t *testPeer, requestId uint64, root common.Hash, paths []TrieNodePathSet, cap uint64) error {
+		for _, pathset := range paths {
+			if len(pathset) == 1 && bytes.Equal(pathset[0], rootPath[0]) {
+				t.test.Errorf("root node retrieved again")
+			}
+		}
+		return defaultTrieRequestHandler(t, requestId, root, paths, cap)
+	}
+	syncer = NewSyncer(db, nodeScheme)
+	syncer.Register(source)
+	source.remote = syncer
+	if err := syncer.Sync(sourceAccountTrie.Hash(), make(chan struct{})); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	verifyTrie(db, sourceAccountTrie.Hash(), t)
+
+	if status := syncer.Status(); status.Phase != PhaseDone || status.Progress != 100 || status.HealPending != 0 {
+		t.Errorf("wrong status of finished sync: %+v", status)
+	}
+	if len(rawdb.ReadSnapshotSyncHeal(db)) != 0 {
+		t.Error("healing state not deleted after sync")
+	}
+}
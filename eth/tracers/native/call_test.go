@@ -0,0 +1,127 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+)
+
+// Tests that the output of the call tracer can be decoded into the types of the
+// Go client.
+func TestCallFrameClientTypes(t *testing.T) {
+	frame := callFrame{
+		Type:     vm.CALL,
+		From:     common.Address{1},
+		To:       common.Address{2},
+		Gas:      100000,
+		GasUsed:  30000,
+		Input:    []byte{0x01, 0x02},
+		Output:   []byte{0x03},
+		Value:    big.NewInt(7),
+		Error:    "execution reverted",
+		Revertal: "nope",
+		Calls: []callFrame{{
+			Type:    vm.CREATE2,
+			From:    common.Address{2},
+			To:      common.Address{3},
+			Gas:     50000,
+			GasUsed: 20000,
+			Input:   []byte{0x60},
+			Logs:    []callLog{{Address: common.Address{3}, Topics: []common.Hash{{4}}, Data: []byte{0x05}}},
+		}},
+	}
+	enc, err := json.Marshal(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have gethclient.CallFrame
+	if err := json.Unmarshal(enc, &have); err != nil {
+		t.Fatalf("failed to decode call frame: %v", err)
+	}
+	want := gethclient.CallFrame{
+		Type:         "CALL",
+		From:         common.Address{1},
+		To:           common.Address{2},
+		Gas:          100000,
+		GasUsed:      30000,
+		Input:        []byte{0x01, 0x02},
+		Output:       []byte{0x03},
+		Value:        big.NewInt(7),
+		Error:        "execution reverted",
+		RevertReason: "nope",
+		Calls: []gethclient.CallFrame{{
+			Type:    "CREATE2",
+			From:    common.Address{2},
+			To:      common.Address{3},
+			Gas:     50000,
+			GasUsed: 20000,
+			Input:   []byte{0x60},
+			Logs:    []gethclient.CallLog{{Address: common.Address{3}, Topics: []common.Hash{{4}}, Data: []byte{0x05}}},
+		}},
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("call frame mismatch:\nhave %+v\nwant %+v", have, want)
+	}
+}
+
+// Tests that the output of the prestate tracer can be decoded into the types of
+// the Go client.
+func TestPrestateClientTypes(t *testing.T) {
+	state := map[common.Address]*account{
+		{1}: {
+			Balance: big.NewInt(100),
+			Code:    []byte{0x60, 0x00},
+			Nonce:   3,
+			Storage: map[common.Hash]common.Hash{{1}: {2}},
+		},
+		{2}: {Balance: new(big.Int)},
+	}
+	enc, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have gethclient.PrestateAccounts
+	if err := json.Unmarshal(enc, &have); err != nil {
+		t.Fatalf("failed to decode prestate: %v", err)
+	}
+	if len(have) != len(state) {
+		t.Fatalf("account count mismatch: have %d, want %d", len(have), len(state))
+	}
+	for addr, want := range state {
+		acc := have[addr]
+		if acc == nil {
+			t.Errorf("account %x missing", addr)
+			continue
+		}
+		if acc.Balance.Cmp(want.Balance) != 0 || !bytes.Equal(acc.Code, want.Code) || acc.Nonce != want.Nonce || len(acc.Storage) != len(want.Storage) {
+			t.Errorf("account %x mismatch: have %+v, want %+v", addr, acc, want)
+		}
+		for slot, val := range want.Storage {
+			if acc.Storage[slot] != val {
+				t.Errorf("account %x storage slot %x mismatch: have %x, want %x", addr, slot, acc.Storage[slot], val)
+			}
+		}
+	}
+}
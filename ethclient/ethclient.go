@@ -314,6 +314,11 @@ func (ec *Client) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, err
 
 // SubscribeNewHead subscribes to notifications about the current blockchain head
 // on the given channel.
+//
+// If the RPC client was dialed with rpc.WithReconnect, the subscription is resumed
+// after the connection is lost. The returned subscription is then an
+// *rpc.ClientSubscription, whose Gap channel signals that headers may have been
+// missed and need to be backfilled.
 func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
 	return ec.c.EthSubscribe(ctx, ch, "newHeads")
 }
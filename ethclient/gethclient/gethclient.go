@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -166,6 +167,265 @@ func (ec *Client) GetNodeInfo(ctx context.Context) (*p2p.NodeInfo, error) {
 	return &result, err
 }
 
+// Peers retrieves information about the peers connected to a geth node.
+func (ec *Client) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
+	var result []*p2p.PeerInfo
+	err := ec.c.CallContext(ctx, &result, "admin_peers")
+	return result, err
+}
+
+// TxPoolContent is the content of the transaction pool, grouped by status, sender
+// and nonce.
+type TxPoolContent struct {
+	Pending map[common.Address]map[uint64]*types.Transaction `json:"pending"`
+	Queued  map[common.Address]map[uint64]*types.Transaction `json:"queued"`
+}
+
+// TxPoolContentFrom is the content of the transaction pool originating from a
+// single sender, grouped by status and nonce.
+type TxPoolContentFrom struct {
+	Pending map[uint64]*types.Transaction `json:"pending"`
+	Queued  map[uint64]*types.Transaction `json:"queued"`
+}
+
+// TxPoolStatus is the number of pending and queued transactions in the pool.
+type TxPoolStatus struct {
+	Pending uint64
+	Queued  uint64
+}
+
+// TxPoolContent retrieves the pending and queued transactions of the transaction
+// pool, grouped by sender and nonce.
+func (ec *Client) TxPoolContent(ctx context.Context) (*TxPoolContent, error) {
+	var result TxPoolContent
+	err := ec.c.CallContext(ctx, &result, "txpool_content")
+	return &result, err
+}
+
+// TxPoolContentFrom retrieves the pending and queued transactions of the
+// transaction pool sent by the given account, grouped by nonce.
+func (ec *Client) TxPoolContentFrom(ctx context.Context, account common.Address) (*TxPoolContentFrom, error) {
+	var result TxPoolContentFrom
+	err := ec.c.CallContext(ctx, &result, "txpool_contentFrom", account)
+	return &result, err
+}
+
+// TxPoolStatus retrieves the number of pending and queued transactions in the
+// transaction pool.
+func (ec *Client) TxPoolStatus(ctx context.Context) (*TxPoolStatus, error) {
+	var result struct {
+		Pending hexutil.Uint64 `json:"pending"`
+		Queued  hexutil.Uint64 `json:"queued"`
+	}
+	err := ec.c.CallContext(ctx, &result, "txpool_status")
+	return &TxPoolStatus{Pending: uint64(result.Pending), Queued: uint64(result.Queued)}, err
+}
+
+// TraceConfig selects the tracer used to trace a transaction or call, along with
+// its configuration. The struct logger is used if no tracer is set.
+type TraceConfig struct {
+	*logger.Config
+	Tracer  *string `json:"tracer,omitempty"`
+	Timeout *string `json:"timeout,omitempty"`
+	Reexec  *uint64 `json:"reexec,omitempty"`
+
+	// TracerConfig is the configuration specific to the selected tracer. Note,
+	// the struct logger config is historically embedded in the main object.
+	TracerConfig json.RawMessage `json:"tracerConfig,omitempty"`
+}
+
+// TraceCallConfig is the configuration of a traced call, which can additionally
+// override the state the call is executed on.
+type TraceCallConfig struct {
+	TraceConfig
+	StateOverrides *map[common.Address]OverrideAccount `json:"stateOverrides,omitempty"`
+}
+
+// CallTracerConfig is the configuration accepted by the call tracer.
+type CallTracerConfig struct {
+	OnlyTopCall bool `json:"onlyTopCall"` // If true, nested calls are not collected
+	WithLog     bool `json:"withLog"`     // If true, event logs are collected
+}
+
+// CallFrame is a single call reported by the call tracer, along with all the
+// nested calls made by it.
+type CallFrame struct {
+	Type         string // Opcode of the call, e.g. CALL or CREATE
+	From         common.Address
+	To           common.Address
+	Gas          uint64
+	GasUsed      uint64
+	Input        []byte
+	Output       []byte
+	Value        *big.Int
+	Error        string
+	RevertReason string
+	Calls        []CallFrame
+	Logs         []CallLog
+}
+
+// CallLog is an event emitted during a call, reported by the call tracer if
+// logging is enabled.
+type CallLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// UnmarshalJSON decodes a call frame from the output of the call tracer.
+func (f *CallFrame) UnmarshalJSON(input []byte) error {
+	type callFrame struct {
+		Type         string         `json:"type"`
+		From         common.Address `json:"from"`
+		To           common.Address `json:"to"`
+		Gas          hexutil.Uint64 `json:"gas"`
+		GasUsed      hexutil.Uint64 `json:"gasUsed"`
+		Input        hexutil.Bytes  `json:"input"`
+		Output       hexutil.Bytes  `json:"output"`
+		Value        *hexutil.Big   `json:"value"`
+		Error        string         `json:"error"`
+		RevertReason string         `json:"revertReason"`
+		Calls        []CallFrame    `json:"calls"`
+		Logs         []CallLog      `json:"logs"`
+	}
+	var dec callFrame
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*f = CallFrame{
+		Type:         dec.Type,
+		From:         dec.From,
+		To:           dec.To,
+		Gas:          uint64(dec.Gas),
+		GasUsed:      uint64(dec.GasUsed),
+		Input:        dec.Input,
+		Output:       dec.Output,
+		Value:        (*big.Int)(dec.Value),
+		Error:        dec.Error,
+		RevertReason: dec.RevertReason,
+		Calls:        dec.Calls,
+		Logs:         dec.Logs,
+	}
+	return nil
+}
+
+// PrestateTracerConfig is the configuration accepted by the prestate tracer.
+type PrestateTracerConfig struct {
+	DiffMode bool `json:"diffMode"` // If true, the state modifications are returned
+}
+
+// PrestateAccounts is the result of the prestate tracer in default mode, holding
+// the accounts touched by a transaction as they were prior to its execution.
+type PrestateAccounts map[common.Address]*PrestateAccount
+
+// PrestateDiff is the result of the prestate tracer in diff mode.
+type PrestateDiff struct {
+	Post PrestateAccounts `json:"post"`
+	Pre  PrestateAccounts `json:"pre"`
+}
+
+// PrestateAccount is the state of a single account reported by the prestate tracer.
+type PrestateAccount struct {
+	Balance *big.Int
+	Code    []byte
+	Nonce   uint64
+	Storage map[common.Hash]common.Hash
+}
+
+// UnmarshalJSON decodes an account from the output of the prestate tracer.
+func (a *PrestateAccount) UnmarshalJSON(input []byte) error {
+	type account struct {
+		Balance *hexutil.Big                `json:"balance"`
+		Code    hexutil.Bytes               `json:"code"`
+		Nonce   uint64                      `json:"nonce"`
+		Storage map[common.Hash]common.Hash `json:"storage"`
+	}
+	var dec account
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*a = PrestateAccount{
+		Balance: (*big.Int)(dec.Balance),
+		Code:    dec.Code,
+		Nonce:   dec.Nonce,
+		Storage: dec.Storage,
+	}
+	return nil
+}
+
+// TraceTransaction replays the given transaction and returns the raw output of
+// the tracer selected by the config, or the structured logs if none was set.
+func (ec *Client) TraceTransaction(ctx context.Context, hash common.Hash, config *TraceConfig) (json.RawMessage, error) {
+	var result json.RawMessage
+	err := ec.c.CallContext(ctx, &result, "debug_traceTransaction", hash, config)
+	return result, err
+}
+
+// TraceCall executes the given call on top of the state of the given block and
+// returns the raw output of the tracer selected by the config, or the structured
+// logs if none was set.
+func (ec *Client) TraceCall(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, config *TraceCallConfig) (json.RawMessage, error) {
+	var result json.RawMessage
+	err := ec.c.CallContext(ctx, &result, "debug_traceCall", toCallArg(msg), toBlockNumArg(blockNumber), config)
+	return result, err
+}
+
+// TraceTransactionWithStructLogger replays the given transaction and returns the
+// structured logs of its execution.
+func (ec *Client) TraceTransactionWithStructLogger(ctx context.Context, hash common.Hash, config *logger.Config) (*logger.ExecutionResult, error) {
+	var result logger.ExecutionResult
+	err := ec.c.CallContext(ctx, &result, "debug_traceTransaction", hash, &TraceConfig{Config: config})
+	return &result, err
+}
+
+// TraceTransactionWithCallTracer replays the given transaction and returns the
+// tree of calls made during its execution.
+func (ec *Client) TraceTransactionWithCallTracer(ctx context.Context, hash common.Hash, config *CallTracerConfig) (*CallFrame, error) {
+	cfg, err := nativeTraceConfig("callTracer", config)
+	if err != nil {
+		return nil, err
+	}
+	var result CallFrame
+	err = ec.c.CallContext(ctx, &result, "debug_traceTransaction", hash, cfg)
+	return &result, err
+}
+
+// TraceCallWithCallTracer executes the given call on top of the state of the given
+// block and returns the tree of calls made during its execution.
+func (ec *Client) TraceCallWithCallTracer(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, config *CallTracerConfig) (*CallFrame, error) {
+	cfg, err := nativeTraceConfig("callTracer", config)
+	if err != nil {
+		return nil, err
+	}
+	var result CallFrame
+	err = ec.c.CallContext(ctx, &result, "debug_traceCall", toCallArg(msg), toBlockNumArg(blockNumber), &TraceCallConfig{TraceConfig: *cfg})
+	return &result, err
+}
+
+// TraceTransactionWithPrestateTracer replays the given transaction and returns the
+// state of the accounts it touched, prior to its execution.
+func (ec *Client) TraceTransactionWithPrestateTracer(ctx context.Context, hash common.Hash) (PrestateAccounts, error) {
+	cfg, err := nativeTraceConfig("prestateTracer", nil)
+	if err != nil {
+		return nil, err
+	}
+	var result PrestateAccounts
+	err = ec.c.CallContext(ctx, &result, "debug_traceTransaction", hash, cfg)
+	return result, err
+}
+
+// TraceTransactionWithPrestateDiff replays the given transaction and returns the
+// state of the accounts it modified, both before and after its execution.
+func (ec *Client) TraceTransactionWithPrestateDiff(ctx context.Context, hash common.Hash) (*PrestateDiff, error) {
+	cfg, err := nativeTraceConfig("prestateTracer", &PrestateTracerConfig{DiffMode: true})
+	if err != nil {
+		return nil, err
+	}
+	var result PrestateDiff
+	err = ec.c.CallContext(ctx, &result, "debug_traceTransaction", hash, cfg)
+	return &result, err
+}
+
 // SubscribeFullPendingTransactions subscribes to new pending transactions.
 func (ec *Client) SubscribeFullPendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (*rpc.ClientSubscription, error) {
 	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions", true)
@@ -195,6 +455,20 @@ func toBlockNumArg(number *big.Int) string {
 	return hexutil.EncodeBig(number)
 }
 
+// nativeTraceConfig assembles the trace config selecting the given native tracer,
+// along with its optional tracer specific configuration.
+func nativeTraceConfig(tracer string, config interface{}) (*TraceConfig, error) {
+	cfg := &TraceConfig{Tracer: &tracer}
+	if config != nil {
+		blob, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+		cfg.TracerConfig = blob
+	}
+	return cfg, nil
+}
+
 func toCallArg(msg ethereum.CallMsg) interface{} {
 	arg := map[string]interface{}{
 		"from": msg.From,
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum"
@@ -32,6 +33,9 @@ import (
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
@@ -44,11 +48,16 @@ var (
 	testSlot    = common.HexToHash("0xdeadbeef")
 	testValue   = crypto.Keccak256Hash(testSlot[:])
 	testBalance = big.NewInt(2e15)
+	testTo      = common.HexToAddress("0x2000000000000000000000000000000000000002")
 )
 
 func newTestBackend(t *testing.T) (*node.Node, []*types.Block) {
 	// Generate test chain.
 	genesis, blocks := generateTestChain()
+	return newTestBackendWithChain(t, genesis, blocks), blocks
+}
+
+func newTestBackendWithChain(t *testing.T, genesis *core.Genesis, blocks []*types.Block) *node.Node {
 	// Create node
 	n, err := node.New(&node.Config{})
 	if err != nil {
@@ -66,6 +75,7 @@ func newTestBackend(t *testing.T) (*node.Node, []*types.Block) {
 		Namespace: "eth",
 		Service:   filters.NewFilterAPI(filterSystem, false),
 	}})
+	n.RegisterAPIs(tracers.APIs(ethservice.APIBackend))
 
 	// Import the test chain.
 	if err := n.Start(); err != nil {
@@ -74,7 +84,7 @@ func newTestBackend(t *testing.T) (*node.Node, []*types.Block) {
 	if _, err := ethservice.BlockChain().InsertChain(blocks[1:]); err != nil {
 		t.Fatalf("can't import test blocks: %v", err)
 	}
-	return n, blocks
+	return n
 }
 
 func generateTestChain() (*core.Genesis, []*types.Block) {
@@ -118,6 +128,12 @@ func TestGethClient(t *testing.T) {
 		}, {
 			"TestGetNodeInfo",
 			func(t *testing.T) { testGetNodeInfo(t, client) },
+		}, {
+			"TestPeers",
+			func(t *testing.T) { testPeers(t, client) },
+		}, {
+			"TestTraceCall",
+			func(t *testing.T) { testTraceCall(t, client) },
 		}, {
 			"TestSetHead",
 			func(t *testing.T) { testSetHead(t, client) },
@@ -127,6 +143,9 @@ func TestGethClient(t *testing.T) {
 		}, {
 			"TestSubscribePendingTxs",
 			func(t *testing.T) { testSubscribeFullPendingTransactions(t, client) },
+		}, {
+			"TestTxPool",
+			func(t *testing.T) { testTxPool(t, client) },
 		}, {
 			"TestCallContract",
 			func(t *testing.T) { testCallContract(t, client) },
@@ -264,6 +283,152 @@ func testGetNodeInfo(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testPeers(t *testing.T, client *rpc.Client) {
+	ec := New(client)
+	peers, err := ec.Peers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 0 {
+		t.Fatalf("unexpected peers: %v", len(peers))
+	}
+}
+
+func TestTraceTransaction(t *testing.T) {
+	// Generate a test chain containing a single transfer
+	genesis, _ := generateTestChain()
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 1, func(i int, g *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(0, testTo, big.NewInt(1), params.TxGas, g.BaseFee(), nil), types.HomesteadSigner{}, testKey)
+		g.AddTx(tx)
+	})
+	blocks = append([]*types.Block{genesis.ToBlock()}, blocks...)
+	tx := blocks[1].Transactions()[0]
+
+	backend := newTestBackendWithChain(t, genesis, blocks)
+	client, err := backend.Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+	defer client.Close()
+
+	ec := New(client)
+	// Test the typed call tracer result
+	call, err := ec.TraceTransactionWithCallTracer(context.Background(), tx.Hash(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Type != "CALL" {
+		t.Fatalf("invalid call type, want: %v got: %v", "CALL", call.Type)
+	}
+	if call.From != testAddr || call.To != testTo {
+		t.Fatalf("invalid call parties, want: %v -> %v got: %v -> %v", testAddr, testTo, call.From, call.To)
+	}
+	if call.Value.Cmp(tx.Value()) != 0 || call.GasUsed != params.TxGas {
+		t.Fatalf("invalid call, want value %v gas %v, got value %v gas %v", tx.Value(), params.TxGas, call.Value, call.GasUsed)
+	}
+	// Test the typed prestate tracer results
+	prestate, err := ec.TraceTransactionWithPrestateTracer(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account, ok := prestate[testAddr]; !ok || account.Balance.Cmp(testBalance) != 0 {
+		t.Fatalf("invalid sender prestate: %v", account)
+	}
+	diff, err := ec.TraceTransactionWithPrestateDiff(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account, ok := diff.Post[testTo]; !ok || account.Balance.Cmp(tx.Value()) != 0 {
+		t.Fatalf("invalid recipient poststate: %v", account)
+	}
+	// Test the struct logger result
+	res, err := ec.TraceTransactionWithStructLogger(context.Background(), tx.Hash(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Failed || res.Gas != params.TxGas || len(res.StructLogs) != 0 {
+		t.Fatalf("invalid struct logger result: %+v", res)
+	}
+	// Test that the raw result matches the typed one
+	tracer := "callTracer"
+	raw, err := ec.TraceTransaction(context.Background(), tx.Hash(), &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded CallFrame
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, call) {
+		t.Fatalf("call trace mismatch, want: %+v got: %+v", decoded, call)
+	}
+}
+
+func testTraceCall(t *testing.T, client *rpc.Client) {
+	ec := New(client)
+	msg := ethereum.CallMsg{
+		From:  testAddr,
+		To:    &testTo,
+		Value: big.NewInt(2),
+	}
+	call, err := ec.TraceCallWithCallTracer(context.Background(), msg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Type != "CALL" || call.From != testAddr || call.To != testTo || call.Value.Cmp(msg.Value) != 0 {
+		t.Fatalf("invalid call trace: %+v", call)
+	}
+	raw, err := ec.TraceCall(context.Background(), msg, nil, &TraceCallConfig{TraceConfig: TraceConfig{Config: &logger.Config{DisableStorage: true}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res logger.ExecutionResult
+	if err := json.Unmarshal(raw, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Failed || res.Gas != params.TxGas {
+		t.Fatalf("invalid struct logger result: %+v", res)
+	}
+}
+
+func testTxPool(t *testing.T, client *rpc.Client) {
+	ec := New(client)
+	// The pending transactions sent by the subscription tests are expected to
+	// still reside in the pool.
+	status, err := ec.TxPoolStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Pending != 2 || status.Queued != 0 {
+		t.Fatalf("invalid pool status, want: 2/0 got: %d/%d", status.Pending, status.Queued)
+	}
+	content, err := ec.TxPoolContent(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(content.Pending[testAddr]) != 2 || len(content.Queued) != 0 {
+		t.Fatalf("invalid pool content: %v pending, %v queued", len(content.Pending[testAddr]), len(content.Queued))
+	}
+	from, err := ec.TxPoolContentFrom(context.Background(), testAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, ok := from.Pending[nonce]
+		if !ok {
+			t.Fatalf("pending transaction %d missing", nonce)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sender != testAddr || tx.Nonce() != nonce {
+			t.Fatalf("invalid pending transaction %d: from %v nonce %d", nonce, sender, tx.Nonce())
+		}
+	}
+}
+
 func testSetHead(t *testing.T, client *rpc.Client) {
 	ec := New(client)
 	err := ec.SetHead(context.Background(), big.NewInt(0))
@@ -0,0 +1,92 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// accumulatorDepth is the depth of the merkle tree over the header records of
+// an archive, log2(MaxEra1Size).
+const accumulatorDepth = 13
+
+// ComputeAccumulator calculates the accumulator root of an archive: the SSZ hash
+// tree root of List[HeaderRecord, MaxEra1Size], where each header record is the
+// container of the block hash and the total difficulty as uint256.
+func ComputeAccumulator(hashes []common.Hash, tds []*big.Int) (common.Hash, error) {
+	if len(hashes) != len(tds) {
+		return common.Hash{}, fmt.Errorf("must have equal number hashes as td values")
+	}
+	if len(hashes) > MaxEra1Size {
+		return common.Hash{}, fmt.Errorf("too many records: have %d, max %d", len(hashes), MaxEra1Size)
+	}
+	layer := make([]common.Hash, len(hashes))
+	for i := range hashes {
+		td, err := tdBytes(tds[i])
+		if err != nil {
+			return common.Hash{}, err
+		}
+		layer[i] = sha256.Sum256(append(hashes[i].Bytes(), td[:]...))
+	}
+	// Merkleize the records, padding the tree with zero subtrees up to the limit
+	var zero common.Hash
+	for d := 0; d < accumulatorDepth; d++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zero)
+		}
+		next := make([]common.Hash, len(layer)/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(layer[2*i].Bytes(), layer[2*i+1][:]...))
+		}
+		layer, zero = next, sha256.Sum256(append(zero.Bytes(), zero[:]...))
+	}
+	root := zero
+	if len(layer) > 0 {
+		root = layer[0]
+	}
+	// Mix in the length of the list
+	var length common.Hash
+	binary.LittleEndian.PutUint64(length[:], uint64(len(hashes)))
+	return sha256.Sum256(append(root.Bytes(), length[:]...)), nil
+}
+
+// tdBytes encodes a total difficulty as little endian uint256.
+func tdBytes(td *big.Int) ([32]byte, error) {
+	var b [32]byte
+	if td.Sign() < 0 || td.BitLen() > 256 {
+		return b, fmt.Errorf("invalid total difficulty %v", td)
+	}
+	td.FillBytes(b[:])
+	for i := 0; i < 16; i++ {
+		b[i], b[31-i] = b[31-i], b[i]
+	}
+	return b, nil
+}
+
+// tdFromBytes decodes a little endian uint256 total difficulty.
+func tdFromBytes(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era/e2store"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+)
+
+var (
+	errBuilderFull      = errors.New("era1 file is full")
+	errBuilderFinalized = errors.New("era1 file is already finalized")
+	errBuilderEmpty     = errors.New("era1 file has no blocks")
+)
+
+// Builder writes an era1 file block by block. The blocks have to be added in
+// order, and the file is completed by Finalize.
+type Builder struct {
+	w       *e2store.Writer
+	written uint64
+
+	start     *uint64
+	indexes   []uint64
+	hashes    []common.Hash
+	tds       []*big.Int
+	finalized bool
+
+	buf    *bytes.Buffer
+	snappy *snappy.Writer
+}
+
+// NewBuilder creates a builder writing an era1 file into w.
+func NewBuilder(w io.Writer) *Builder {
+	buf := new(bytes.Buffer)
+	return &Builder{
+		w:      e2store.NewWriter(w),
+		buf:    buf,
+		snappy: snappy.NewBufferedWriter(buf),
+	}
+}
+
+// Add appends a block with its receipts and the total difficulty of the chain up
+// to and including it.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	if b.finalized {
+		return errBuilderFinalized
+	}
+	if len(b.indexes) >= MaxEra1Size {
+		return errBuilderFull
+	}
+	if b.start != nil && block.NumberU64() != *b.start+uint64(len(b.indexes)) {
+		return fmt.Errorf("non-consecutive block %d, expected %d", block.NumberU64(), *b.start+uint64(len(b.indexes)))
+	}
+	tdb, err := tdBytes(td)
+	if err != nil {
+		return err
+	}
+	if b.start == nil {
+		start := block.NumberU64()
+		b.start = &start
+		if err := b.write(TypeVersion, nil); err != nil {
+			return err
+		}
+	}
+	b.indexes = append(b.indexes, b.written)
+	b.hashes = append(b.hashes, block.Hash())
+	b.tds = append(b.tds, new(big.Int).Set(td))
+
+	if err := b.writeCompressed(TypeCompressedHeader, block.Header()); err != nil {
+		return err
+	}
+	if err := b.writeCompressed(TypeCompressedBody, block.Body()); err != nil {
+		return err
+	}
+	if err := b.writeCompressed(TypeCompressedReceipts, receipts); err != nil {
+		return err
+	}
+	return b.write(TypeTotalDifficulty, tdb[:])
+}
+
+// Finalize writes the accumulator and the block index, completing the file. The
+// accumulator root is returned.
+func (b *Builder) Finalize() (common.Hash, error) {
+	if b.finalized {
+		return common.Hash{}, errBuilderFinalized
+	}
+	if b.start == nil {
+		return common.Hash{}, errBuilderEmpty
+	}
+	root, err := ComputeAccumulator(b.hashes, b.tds)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := b.write(TypeAccumulator, root[:]); err != nil {
+		return common.Hash{}, err
+	}
+	// The block offsets are relative to the start of the index entry
+	var (
+		base  = b.written
+		count = len(b.indexes)
+		index = make([]byte, 16+count*8)
+	)
+	binary.LittleEndian.PutUint64(index, *b.start)
+	for i, offset := range b.indexes {
+		binary.LittleEndian.PutUint64(index[8+i*8:], uint64(int64(offset)-int64(base)))
+	}
+	binary.LittleEndian.PutUint64(index[8+count*8:], uint64(count))
+	if err := b.write(TypeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	b.finalized = true
+	return root, nil
+}
+
+func (b *Builder) write(typ uint16, value []byte) error {
+	n, err := b.w.Write(typ, value)
+	b.written += uint64(n)
+	return err
+}
+
+// writeCompressed writes the snappy framed RLP encoding of val.
+func (b *Builder) writeCompressed(typ uint16, val interface{}) error {
+	b.buf.Reset()
+	b.snappy.Reset(b.buf)
+	if err := rlp.Encode(b.snappy, val); err != nil {
+		return err
+	}
+	if err := b.snappy.Flush(); err != nil {
+		return err
+	}
+	return b.write(typ, b.buf.Bytes())
+}
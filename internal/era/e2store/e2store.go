@@ -0,0 +1,147 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package e2store implements the e2store container format, a simple sequence of
+// type-length-value entries which is the basis of the era archive formats.
+package e2store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// headerSize is the size of an entry header: type (2 bytes), length (4 bytes)
+// and reserved (2 bytes), all little endian.
+const headerSize = 8
+
+var (
+	errReserved   = errors.New("reserved bytes are non-zero")
+	errTooLarge   = errors.New("entry value too large")
+	errTruncation = errors.New("entry truncated")
+)
+
+// Entry is a single type-length-value record of an e2store file.
+type Entry struct {
+	Type  uint16
+	Value []byte
+}
+
+// Writer writes e2store entries to an underlying stream.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter creates a new e2store writer.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes a single entry, returning the number of bytes written including
+// the header.
+func (w *Writer) Write(typ uint16, value []byte) (int, error) {
+	if uint64(len(value)) > uint64(^uint32(0)) {
+		return 0, errTooLarge
+	}
+	var header [headerSize]byte
+	binary.LittleEndian.PutUint16(header[0:2], typ)
+	binary.LittleEndian.PutUint32(header[2:6], uint32(len(value)))
+	if n, err := w.w.Write(header[:]); err != nil {
+		return n, err
+	}
+	n, err := w.w.Write(value)
+	return headerSize + n, err
+}
+
+// Reader reads e2store entries from an underlying random access source.
+type Reader struct {
+	r      io.ReaderAt
+	offset int64
+}
+
+// NewReader creates a new e2store reader, positioned at the first entry.
+func NewReader(r io.ReaderAt) *Reader {
+	return &Reader{r: r}
+}
+
+// Read reads the next entry and advances the reader. It returns io.EOF if there
+// are no more entries.
+func (r *Reader) Read() (*Entry, error) {
+	entry, n, err := r.ReadAt(r.offset)
+	if err != nil {
+		return nil, err
+	}
+	r.offset += int64(n)
+	return entry, nil
+}
+
+// ReadAt reads the entry at the given offset, returning it along with its total
+// size including the header.
+func (r *Reader) ReadAt(off int64) (*Entry, int, error) {
+	typ, length, err := r.readHeader(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	value := make([]byte, length)
+	if length == 0 {
+		return &Entry{Type: typ, Value: value}, headerSize, nil
+	}
+	if _, err := r.r.ReadAt(value, off+headerSize); err != nil {
+		if err == io.EOF {
+			err = errTruncation
+		}
+		return nil, 0, err
+	}
+	return &Entry{Type: typ, Value: value}, headerSize + int(length), nil
+}
+
+// ReaderAt returns a reader over the value of the entry at the given offset,
+// which has to be of the given type. The total size of the entry is returned too.
+func (r *Reader) ReaderAt(want uint16, off int64) (io.Reader, int, error) {
+	typ, length, err := r.readHeader(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	if typ != want {
+		return nil, 0, fmt.Errorf("wrong entry type at offset %d: have %#x, want %#x", off, typ, want)
+	}
+	return io.NewSectionReader(r.r, off+headerSize, int64(length)), headerSize + int(length), nil
+}
+
+// LengthAt returns the total size of the entry at the given offset, including
+// the header.
+func (r *Reader) LengthAt(off int64) (int64, error) {
+	_, length, err := r.readHeader(off)
+	if err != nil {
+		return 0, err
+	}
+	return headerSize + int64(length), nil
+}
+
+func (r *Reader) readHeader(off int64) (uint16, uint32, error) {
+	var header [headerSize]byte
+	if n, err := r.r.ReadAt(header[:], off); err != nil {
+		if err == io.EOF && n > 0 {
+			err = errTruncation
+		}
+		return 0, 0, err
+	}
+	if header[6] != 0 || header[7] != 0 {
+		return 0, 0, errReserved
+	}
+	return binary.LittleEndian.Uint16(header[0:2]), binary.LittleEndian.Uint32(header[2:6]), nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2store
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestEncode(t *testing.T) {
+	for _, test := range []struct {
+		entries []Entry
+		want    string
+		name    string
+	}{
+		{
+			name:    "emptyEntry",
+			entries: []Entry{{0xffff, nil}},
+			want:    "ffff000000000000",
+		},
+		{
+			name:    "beef",
+			entries: []Entry{{42, common.Hex2Bytes("beef")}},
+			want:    "2a00020000000000beef",
+		},
+		{
+			name: "twoEntries",
+			entries: []Entry{
+				{42, common.Hex2Bytes("beef")},
+				{9, common.Hex2Bytes("abcdabcd")},
+			},
+			want: "2a00020000000000beef0900040000000000abcdabcd",
+		},
+	} {
+		var (
+			b = bytes.NewBuffer(nil)
+			w = NewWriter(b)
+		)
+		for _, e := range test.entries {
+			if _, err := w.Write(e.Type, e.Value); err != nil {
+				t.Fatalf("%s: encoding error: %v", test.name, err)
+			}
+		}
+		if have := common.Bytes2Hex(b.Bytes()); have != test.want {
+			t.Fatalf("%s: encoding mismatch: have %s, want %s", test.name, have, test.want)
+		}
+		r := NewReader(bytes.NewReader(b.Bytes()))
+		for _, want := range test.entries {
+			have, err := r.Read()
+			if err != nil {
+				t.Fatalf("%s: decoding error: %v", test.name, err)
+			}
+			if have.Type != want.Type || !bytes.Equal(have.Value, want.Value) {
+				t.Fatalf("%s: decoding mismatch: have %v, want %v", test.name, have, want)
+			}
+		}
+		if _, err := r.Read(); err != io.EOF {
+			t.Fatalf("%s: expected EOF after last entry, got %v", test.name, err)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, test := range []struct {
+		have string
+		err  error
+	}{
+		{"beef", errTruncation},
+		{"ffff000000000001", errReserved},
+		{"0100020000000000be", errTruncation},
+	} {
+		r := NewReader(bytes.NewReader(common.FromHex(test.have)))
+		if _, err := r.Read(); err != test.err {
+			t.Fatalf("%s: wrong error: have %v, want %v", test.have, err, test.err)
+		}
+	}
+}
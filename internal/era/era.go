@@ -0,0 +1,326 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package era implements the era1 history archive format. An era1 file is an
+// e2store file containing up to 8192 consecutive blocks with their receipts and
+// total difficulties, closed by an accumulator root committing to the block
+// hashes and total difficulties, and an index for random access:
+//
+//	era1 := Version | block-tuple* | Accumulator | BlockIndex
+//	block-tuple := CompressedHeader | CompressedBody | CompressedReceipts | TotalDifficulty
+//
+// Headers, bodies and receipts are RLP encoded and snappy framed, the total
+// difficulty is a little endian uint256. The block index holds the number of the
+// first block, the offset of each block tuple relative to the index entry, and
+// the number of blocks, all as little endian 64 bit integers.
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era/e2store"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+)
+
+// Entry types of the era1 format.
+const (
+	TypeVersion            uint16 = 0x3265
+	TypeCompressedHeader   uint16 = 0x03
+	TypeCompressedBody     uint16 = 0x04
+	TypeCompressedReceipts uint16 = 0x05
+	TypeTotalDifficulty    uint16 = 0x06
+	TypeAccumulator        uint16 = 0x07
+	TypeBlockIndex         uint16 = 0x3266
+
+	// MaxEra1Size is the maximum number of blocks in an era1 file.
+	MaxEra1Size = 8192
+
+	headerSize = 8 // Size of an e2store entry header
+)
+
+// Filename returns the canonical name of an era1 file, which includes the first
+// bytes of its accumulator root.
+func Filename(network string, epoch int, root common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%s.era1", network, epoch, root.Hex()[2:10])
+}
+
+// ReadDir returns the era1 files of the given network in the directory, sorted
+// by epoch. It fails if the epochs are not consecutive starting from zero.
+func ReadDir(dir, network string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %s: %w", dir, err)
+	}
+	var (
+		epochs []int
+		names  = make(map[int]string)
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		if filepath.Ext(name) != ".era1" {
+			continue
+		}
+		parts := strings.Split(strings.TrimSuffix(name, ".era1"), "-")
+		if len(parts) != 3 || parts[0] != network {
+			continue
+		}
+		epoch, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed era1 filename: %s", name)
+		}
+		if _, ok := names[epoch]; ok {
+			return nil, fmt.Errorf("duplicate era1 epoch %d: %s", epoch, name)
+		}
+		epochs = append(epochs, epoch)
+		names[epoch] = name
+	}
+	sort.Ints(epochs)
+
+	files := make([]string, len(epochs))
+	for i, epoch := range epochs {
+		if epoch != i {
+			return nil, fmt.Errorf("missing era1 epoch %d", i)
+		}
+		files[i] = names[epoch]
+	}
+	return files, nil
+}
+
+// ReadAtSeekCloser is the file interface required by Era.
+type ReadAtSeekCloser interface {
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+}
+
+// Era reads an era1 file.
+type Era struct {
+	f ReadAtSeekCloser
+	s *e2store.Reader
+	m metadata
+}
+
+// metadata is the information about the file stored in its block index.
+type metadata struct {
+	start  uint64 // Number of the first block
+	count  uint64 // Number of blocks
+	length int64  // Size of the file
+}
+
+// Open opens the era1 file with the given name.
+func Open(filename string) (*Era, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	e, err := From(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// From creates an era1 reader from an open file. The file is closed along with
+// the reader.
+func From(f ReadAtSeekCloser) (*Era, error) {
+	m, err := readMetadata(f)
+	if err != nil {
+		return nil, err
+	}
+	e := &Era{f: f, s: e2store.NewReader(f), m: m}
+
+	version, _, err := e.s.ReadAt(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version: %w", err)
+	}
+	if version.Type != TypeVersion {
+		return nil, fmt.Errorf("not an era1 file, first entry type %#x", version.Type)
+	}
+	return e, nil
+}
+
+// Close closes the underlying file.
+func (e *Era) Close() error {
+	return e.f.Close()
+}
+
+// Start returns the number of the first block in the file.
+func (e *Era) Start() uint64 {
+	return e.m.start
+}
+
+// Count returns the number of blocks in the file.
+func (e *Era) Count() uint64 {
+	return e.m.count
+}
+
+// Accumulator returns the accumulator root stored in the file.
+func (e *Era) Accumulator() (common.Hash, error) {
+	entry, _, err := e.s.ReadAt(e.indexOffset() - headerSize - common.HashLength)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if entry.Type != TypeAccumulator || len(entry.Value) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid accumulator entry")
+	}
+	return common.BytesToHash(entry.Value), nil
+}
+
+// GetBlockByNumber returns the block with the given number.
+func (e *Era) GetBlockByNumber(num uint64) (*types.Block, error) {
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	var header types.Header
+	n, err := e.readCompressed(TypeCompressedHeader, off, &header)
+	if err != nil {
+		return nil, err
+	}
+	var body types.Body
+	if _, err := e.readCompressed(TypeCompressedBody, off+n, &body); err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(&header).WithBody(body.Transactions, body.Uncles), nil
+}
+
+// GetReceiptsByNumber returns the receipts of the block with the given number.
+func (e *Era) GetReceiptsByNumber(num uint64) (types.Receipts, error) {
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	// Skip over the header and body entries
+	for i := 0; i < 2; i++ {
+		n, err := e.s.LengthAt(off)
+		if err != nil {
+			return nil, err
+		}
+		off += n
+	}
+	var receipts types.Receipts
+	if _, err := e.readCompressed(TypeCompressedReceipts, off, &receipts); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// GetTotalDifficultyByNumber returns the total difficulty of the chain up to and
+// including the block with the given number.
+func (e *Era) GetTotalDifficultyByNumber(num uint64) (*big.Int, error) {
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	// Skip over the header, body and receipts entries
+	for i := 0; i < 3; i++ {
+		n, err := e.s.LengthAt(off)
+		if err != nil {
+			return nil, err
+		}
+		off += n
+	}
+	entry, _, err := e.s.ReadAt(off)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Type != TypeTotalDifficulty || len(entry.Value) != 32 {
+		return nil, fmt.Errorf("invalid total difficulty entry of block %d", num)
+	}
+	return tdFromBytes(entry.Value), nil
+}
+
+// readCompressed decodes the snappy framed RLP entry of the given type at the
+// offset, returning the size of the entry.
+func (e *Era) readCompressed(typ uint16, off int64, val interface{}) (int64, error) {
+	r, n, err := e.s.ReaderAt(typ, off)
+	if err != nil {
+		return 0, err
+	}
+	if err := rlp.Decode(snappy.NewReader(r), val); err != nil {
+		return 0, fmt.Errorf("failed to decode entry %#x at offset %d: %w", typ, off, err)
+	}
+	return int64(n), nil
+}
+
+// indexOffset returns the offset of the block index entry.
+func (e *Era) indexOffset() int64 {
+	return e.m.length - headerSize - 16 - int64(e.m.count)*8
+}
+
+// readOffset returns the absolute offset of the tuple of the given block.
+func (e *Era) readOffset(num uint64) (int64, error) {
+	if num < e.m.start || num >= e.m.start+e.m.count {
+		return 0, fmt.Errorf("block %d out of range [%d, %d)", num, e.m.start, e.m.start+e.m.count)
+	}
+	var (
+		base = e.indexOffset()
+		pos  = base + headerSize + 8 + int64(num-e.m.start)*8
+		buf  [8]byte
+	)
+	if _, err := e.f.ReadAt(buf[:], pos); err != nil {
+		return 0, err
+	}
+	return base + int64(binary.LittleEndian.Uint64(buf[:])), nil
+}
+
+// readMetadata reads the first block number and the block count from the block
+// index at the end of the file.
+func readMetadata(f ReadAtSeekCloser) (m metadata, err error) {
+	if m.length, err = f.Seek(0, io.SeekEnd); err != nil {
+		return m, err
+	}
+	if m.length < headerSize+24 {
+		return m, fmt.Errorf("file too short")
+	}
+	var buf [8]byte
+	if _, err = f.ReadAt(buf[:], m.length-8); err != nil {
+		return m, err
+	}
+	m.count = binary.LittleEndian.Uint64(buf[:])
+	if m.count == 0 || m.count > MaxEra1Size {
+		return m, fmt.Errorf("invalid block count %d", m.count)
+	}
+	index := m.length - headerSize - 16 - int64(m.count)*8
+	if index < 0 {
+		return m, fmt.Errorf("invalid block index")
+	}
+	var header [headerSize]byte
+	if _, err = f.ReadAt(header[:], index); err != nil {
+		return m, err
+	}
+	if binary.LittleEndian.Uint16(header[:2]) != TypeBlockIndex || !bytes.Equal(header[6:], []byte{0, 0}) {
+		return m, fmt.Errorf("invalid block index entry")
+	}
+	if _, err = f.ReadAt(buf[:], index+headerSize); err != nil {
+		return m, err
+	}
+	m.start = binary.LittleEndian.Uint64(buf[:])
+	return m, nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// makeTestBlocks creates a chain of empty blocks starting at the given number.
+func makeTestBlocks(start uint64, n int) ([]*types.Block, []*big.Int) {
+	var (
+		blocks []*types.Block
+		tds    []*big.Int
+		parent common.Hash
+		td     = big.NewInt(int64(start))
+	)
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(start + uint64(i)),
+			Difficulty: big.NewInt(int64(i + 1)),
+			Extra:      []byte{byte(i)},
+		}
+		block := types.NewBlock(header, nil, nil, nil, trie.NewStackTrie(nil))
+		td = new(big.Int).Add(td, block.Difficulty())
+
+		blocks = append(blocks, block)
+		tds = append(tds, td)
+		parent = block.Hash()
+	}
+	return blocks, tds
+}
+
+func TestEra1Builder(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		fn        = filepath.Join(dir, "test.era1")
+		blocks, _ = makeTestBlocks(1000, 128)
+	)
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := NewBuilder(f)
+	var (
+		hashes []common.Hash
+		tds    []*big.Int
+		td     = big.NewInt(1000)
+	)
+	for _, block := range blocks {
+		td = new(big.Int).Add(td, block.Difficulty())
+		if err := builder.Add(block, nil, td); err != nil {
+			t.Fatalf("error adding block %d: %v", block.NumberU64(), err)
+		}
+		hashes = append(hashes, block.Hash())
+		tds = append(tds, td)
+	}
+	if err := builder.Add(blocks[0], nil, td); err == nil {
+		t.Fatalf("non-consecutive block accepted")
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatalf("error finalizing: %v", err)
+	}
+	f.Close()
+
+	e, err := Open(fn)
+	if err != nil {
+		t.Fatalf("failed to open era1: %v", err)
+	}
+	defer e.Close()
+
+	if e.Start() != 1000 || e.Count() != 128 {
+		t.Fatalf("wrong range: start %d, count %d", e.Start(), e.Count())
+	}
+	if have, _ := e.Accumulator(); have != root {
+		t.Fatalf("wrong accumulator: have %x, want %x", have, root)
+	}
+	if want, _ := ComputeAccumulator(hashes, tds); want != root {
+		t.Fatalf("accumulator doesn't match the blocks: have %x, want %x", root, want)
+	}
+	it := NewIterator(e)
+	for i := 0; it.Next(); i++ {
+		if it.Block().Hash() != hashes[i] {
+			t.Fatalf("block %d: hash mismatch", i)
+		}
+		if it.TotalDifficulty().Cmp(tds[i]) != 0 {
+			t.Fatalf("block %d: total difficulty mismatch: have %v, want %v", i, it.TotalDifficulty(), tds[i])
+		}
+		if len(it.Receipts()) != 0 {
+			t.Fatalf("block %d: unexpected receipts", i)
+		}
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	if _, err := e.GetBlockByNumber(1128); err == nil {
+		t.Fatalf("block out of range returned")
+	}
+	if err := Verify(e); err != nil {
+		t.Fatalf("verification failed: %v", err)
+	}
+}
+
+func TestEra1Verify(t *testing.T) {
+	var (
+		fn          = filepath.Join(t.TempDir(), "test.era1")
+		blocks, tds = makeTestBlocks(0, 16)
+		f, err      = os.Create(fn)
+		builder     = NewBuilder(f)
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, block := range blocks {
+		td := tds[i]
+		if i == 8 {
+			// Total difficulty not matching the chain.
+			td = new(big.Int).Add(td, common.Big1)
+		}
+		if err := builder.Add(block, nil, td); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := builder.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	e, err := Open(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	if err := Verify(e); err == nil {
+		t.Fatalf("inconsistent total difficulty not detected")
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		Filename("mainnet", 1, common.Hash{0x02}),
+		Filename("mainnet", 0, common.Hash{0x01}),
+		Filename("sepolia", 0, common.Hash{0x03}),
+		"checksums.txt",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := ReadDir(dir, "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0] != "mainnet-00000-01000000.era1" || files[1] != "mainnet-00001-02000000.era1" {
+		t.Fatalf("wrong files: %v", files)
+	}
+	if err := os.Remove(filepath.Join(dir, files[0])); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadDir(dir, "mainnet"); err == nil {
+		t.Fatalf("missing epoch not detected")
+	}
+}
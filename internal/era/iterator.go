@@ -0,0 +1,130 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Iterator walks over the blocks of an era1 file in order.
+type Iterator struct {
+	e    *Era
+	next uint64
+
+	block    *types.Block
+	receipts types.Receipts
+	td       *big.Int
+	err      error
+}
+
+// NewIterator creates an iterator over all blocks of the file.
+func NewIterator(e *Era) *Iterator {
+	return &Iterator{e: e, next: e.Start()}
+}
+
+// Next loads the next block, returning false when the iteration is complete or
+// an error occurred.
+func (it *Iterator) Next() bool {
+	if it.err != nil || it.next >= it.e.Start()+it.e.Count() {
+		return false
+	}
+	num := it.next
+	it.next++
+
+	if it.block, it.err = it.e.GetBlockByNumber(num); it.err != nil {
+		return false
+	}
+	if it.receipts, it.err = it.e.GetReceiptsByNumber(num); it.err != nil {
+		return false
+	}
+	if it.td, it.err = it.e.GetTotalDifficultyByNumber(num); it.err != nil {
+		return false
+	}
+	return true
+}
+
+// Error returns the error which stopped the iteration, if any.
+func (it *Iterator) Error() error { return it.err }
+
+// Block returns the current block.
+func (it *Iterator) Block() *types.Block { return it.block }
+
+// Receipts returns the receipts of the current block.
+func (it *Iterator) Receipts() types.Receipts { return it.receipts }
+
+// TotalDifficulty returns the total difficulty up to the current block.
+func (it *Iterator) TotalDifficulty() *big.Int { return it.td }
+
+// Verify checks the integrity of an era1 file: the bodies and receipts have to
+// match their headers, the blocks have to form a chain with consistent total
+// difficulties, and the accumulator has to match the contained blocks.
+func Verify(e *Era) error {
+	var (
+		it     = NewIterator(e)
+		hashes = make([]common.Hash, 0, e.Count())
+		tds    = make([]*big.Int, 0, e.Count())
+		parent *types.Block
+		ptd    *big.Int
+	)
+	for it.Next() {
+		var (
+			block  = it.Block()
+			header = block.Header()
+			td     = it.TotalDifficulty()
+		)
+		if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
+			return fmt.Errorf("block %d: transaction root mismatch: have %x, want %x", block.NumberU64(), hash, header.TxHash)
+		}
+		if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+			return fmt.Errorf("block %d: uncle root mismatch: have %x, want %x", block.NumberU64(), hash, header.UncleHash)
+		}
+		if hash := types.DeriveSha(it.Receipts(), trie.NewStackTrie(nil)); hash != header.ReceiptHash {
+			return fmt.Errorf("block %d: receipt root mismatch: have %x, want %x", block.NumberU64(), hash, header.ReceiptHash)
+		}
+		if parent != nil {
+			if block.ParentHash() != parent.Hash() {
+				return fmt.Errorf("block %d: parent hash mismatch", block.NumberU64())
+			}
+			if want := new(big.Int).Add(ptd, block.Difficulty()); td.Cmp(want) != 0 {
+				return fmt.Errorf("block %d: total difficulty mismatch: have %v, want %v", block.NumberU64(), td, want)
+			}
+		}
+		hashes = append(hashes, block.Hash())
+		tds = append(tds, td)
+		parent, ptd = block, td
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	want, err := e.Accumulator()
+	if err != nil {
+		return err
+	}
+	have, err := ComputeAccumulator(hashes, tds)
+	if err != nil {
+		return err
+	}
+	if have != want {
+		return fmt.Errorf("accumulator mismatch: have %x, want %x", have, want)
+	}
+	return nil
+}
@@ -323,7 +323,8 @@ func (s *PersonalAccountAPI) ListWallets() []rawWallet {
 // OpenWallet initiates a hardware wallet opening procedure, establishing a USB
 // connection and attempting to authenticate via the provided passphrase. Note,
 // the method may return an extra challenge requiring a second open (e.g. the
-// Trezor PIN matrix challenge).
+// Trezor PIN matrix challenge). Keystore seed wallets are opened by decrypting
+// their mnemonic with the passphrase, staying open for 300 seconds.
 func (s *PersonalAccountAPI) OpenWallet(url string, passphrase *string) error {
 	wallet, err := s.am.Wallet(url)
 	if err != nil {
@@ -333,6 +334,14 @@ func (s *PersonalAccountAPI) OpenWallet(url string, passphrase *string) error {
 	if passphrase != nil {
 		pass = *passphrase
 	}
+	// Open seed wallets sign without a passphrase, so they are subject to the
+	// same restrictions as account unlocking.
+	if ks, err := fetchKeystore(s.am); err == nil && ks.IsSeedWallet(wallet) {
+		if s.b.ExtRPCEnabled() && !s.b.AccountManager().Config().InsecureUnlockAllowed {
+			return errors.New("seed wallet opening with HTTP access is forbidden")
+		}
+		return ks.TimedOpen(wallet, pass, 300*time.Second)
+	}
 	return wallet.Open(pass)
 }
 
@@ -392,6 +401,21 @@ func (s *PersonalAccountAPI) ImportRawKey(privkey string, password string) (comm
 	return acc.Address, err
 }
 
+// ImportMnemonic stores the given BIP-39 mnemonic as a seed wallet into the key
+// directory, encrypting it with the passphrase. It returns the URL of the wallet
+// to open and derive accounts from.
+func (s *PersonalAccountAPI) ImportMnemonic(mnemonic string, password string) (string, error) {
+	ks, err := fetchKeystore(s.am)
+	if err != nil {
+		return "", err
+	}
+	wallet, err := ks.ImportMnemonic(mnemonic, password)
+	if err != nil {
+		return "", err
+	}
+	return wallet.URL().String(), nil
+}
+
 // UnlockAccount will unlock the account associated with the given address with
 // the given password for duration seconds. If duration is nil it will use a
 // default of 300 seconds. It returns an indication if the account was unlocked.
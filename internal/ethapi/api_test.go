@@ -0,0 +1,88 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+)
+
+// txPoolBackendMock is a backend serving a fixed transaction pool content.
+type txPoolBackendMock struct {
+	*backendMock
+	pending, queued map[common.Address]types.Transactions
+}
+
+func (b *txPoolBackendMock) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
+	return b.pending, b.queued
+}
+
+func (b *txPoolBackendMock) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return b.pending[addr], b.queued[addr]
+}
+
+// Tests that the transaction pool content returned by the API can be decoded
+// into the types of the Go client.
+func TestTxPoolContentClientTypes(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSignerForChainID(big.NewInt(42))
+		b      = &txPoolBackendMock{backendMock: newBackendMock()}
+	)
+	pending := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{Nonce: 0, Gas: 21000, GasFeeCap: big.NewInt(20), GasTipCap: big.NewInt(1), To: &common.Address{1}, Value: big.NewInt(1)})
+	queued := types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 2, Gas: 21000, GasPrice: big.NewInt(20), To: &common.Address{2}, Data: []byte{0xff}})
+	b.pending = map[common.Address]types.Transactions{sender: {pending}}
+	b.queued = map[common.Address]types.Transactions{sender: {queued}}
+	api := NewTxPoolAPI(b)
+
+	enc, err := json.Marshal(api.Content())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content gethclient.TxPoolContent
+	if err := json.Unmarshal(enc, &content); err != nil {
+		t.Fatalf("failed to decode content: %v", err)
+	}
+	if tx := content.Pending[sender][0]; tx == nil || tx.Hash() != pending.Hash() {
+		t.Errorf("pending transaction mismatch: have %v, want %x", tx, pending.Hash())
+	}
+	if tx := content.Queued[sender][2]; tx == nil || tx.Hash() != queued.Hash() {
+		t.Errorf("queued transaction mismatch: have %v, want %x", tx, queued.Hash())
+	}
+
+	enc, err = json.Marshal(api.ContentFrom(sender))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contentFrom gethclient.TxPoolContentFrom
+	if err := json.Unmarshal(enc, &contentFrom); err != nil {
+		t.Fatalf("failed to decode content: %v", err)
+	}
+	if tx := contentFrom.Pending[0]; tx == nil || tx.Hash() != pending.Hash() {
+		t.Errorf("pending transaction mismatch: have %v, want %x", tx, pending.Hash())
+	}
+	if tx := contentFrom.Queued[2]; tx == nil || tx.Hash() != queued.Hash() {
+		t.Errorf("queued transaction mismatch: have %v, want %x", tx, queued.Hash())
+	}
+}
@@ -141,6 +141,26 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'addAllowedNet',
+			call: 'admin_addAllowedNet',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removeAllowedNet',
+			call: 'admin_removeAllowedNet',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'addDeniedNet',
+			call: 'admin_addDeniedNet',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removeDeniedNet',
+			call: 'admin_removeDeniedNet',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -199,6 +219,18 @@ web3._extend({
 			name: 'peers',
 			getter: 'admin_peers'
 		}),
+		new web3._extend.Property({
+			name: 'peerTraffic',
+			getter: 'admin_peerTraffic'
+		}),
+		new web3._extend.Property({
+			name: 'peerScores',
+			getter: 'admin_peerScores'
+		}),
+		new web3._extend.Property({
+			name: 'netFilter',
+			getter: 'admin_netFilter'
+		}),
 		new web3._extend.Property({
 			name: 'datadir',
 			getter: 'admin_datadir'
@@ -495,6 +527,10 @@ web3._extend({
 			call: 'debug_setTrieFlushInterval',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'snapSyncStatus',
+			call: 'debug_snapSyncStatus',
+		}),
 	],
 	properties: []
 });
@@ -707,6 +743,11 @@ web3._extend({
 			call: 'personal_importRawKey',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'importMnemonic',
+			call: 'personal_importMnemonic',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'sign',
 			call: 'personal_sign',
@@ -137,6 +137,11 @@ func New(stack *node.Node, config *ethconfig.Config) (*LightEthereum, error) {
 		udpEnabled:      stack.Config().P2P.DiscoveryV5,
 		shutdownTracker: shutdowncheck.NewShutdownTracker(chainDb),
 	}
+	if config.CliqueCheckpoint != "" {
+		if err := ethconfig.SetCliqueCheckpoint(leth.engine, stack.ResolvePath(config.CliqueCheckpoint)); err != nil {
+			return nil, fmt.Errorf("invalid clique checkpoint: %v", err)
+		}
+	}
 
 	var prenegQuery vfc.QueryFunc
 	if leth.udpEnabled {
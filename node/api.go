@@ -19,6 +19,7 @@ package node
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -116,6 +117,93 @@ func (api *adminAPI) RemoveTrustedPeer(url string) (bool, error) {
 	return true, nil
 }
 
+// NetFilter retrieves the IP networks the node is allowed to communicate with. A
+// null allow list means all networks are allowed.
+func (api *adminAPI) NetFilter() (*p2p.NetFilterInfo, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.NetFilter()
+}
+
+// AddAllowedNet adds an IP network (CIDR mask or single IP) to the allow list.
+// If all networks were allowed before, only the given one is allowed afterwards.
+func (api *adminAPI) AddAllowedNet(cidr string) (bool, error) {
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	n, err := parseIPNet(cidr)
+	if err != nil {
+		return false, err
+	}
+	if err := server.AddAllowedNet(n); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveAllowedNet removes an IP network from the allow list. If the list becomes
+// empty, all networks are allowed.
+func (api *adminAPI) RemoveAllowedNet(cidr string) (bool, error) {
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	n, err := parseIPNet(cidr)
+	if err != nil {
+		return false, err
+	}
+	return server.RemoveAllowedNet(n)
+}
+
+// AddDeniedNet adds an IP network (CIDR mask or single IP) to the deny list,
+// disconnecting any peers within it.
+func (api *adminAPI) AddDeniedNet(cidr string) (bool, error) {
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	n, err := parseIPNet(cidr)
+	if err != nil {
+		return false, err
+	}
+	if err := server.AddDeniedNet(n); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveDeniedNet removes an IP network from the deny list.
+func (api *adminAPI) RemoveDeniedNet(cidr string) (bool, error) {
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	n, err := parseIPNet(cidr)
+	if err != nil {
+		return false, err
+	}
+	return server.RemoveDeniedNet(n)
+}
+
+// parseIPNet parses a CIDR mask, or a single IP address which is turned into a
+// network containing only that address.
+func parseIPNet(s string) (net.IPNet, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return net.IPNet{}, fmt.Errorf("invalid network: %v", err)
+	}
+	return *n, nil
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *adminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
@@ -297,6 +385,16 @@ func (api *adminAPI) Peers() ([]*p2p.PeerInfo, error) {
 	return server.PeersInfo(), nil
 }
 
+// PeerScores retrieves the reputation of all known nodes, including the ones
+// currently banned.
+func (api *adminAPI) PeerScores() ([]*p2p.PeerScoreInfo, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.PeerScores(), nil
+}
+
 // NodeInfo retrieves all the information we know about the host node at the
 // protocol granularity.
 func (api *adminAPI) NodeInfo() (*p2p.NodeInfo, error) {
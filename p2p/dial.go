@@ -78,7 +78,10 @@ var (
 	errAlreadyConnected = errors.New("already connected")
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNetRestrict      = errors.New("not contained in netrestrict list")
+	errNetDeny          = errors.New("contained in netdeny list")
 	errNoPort           = errors.New("node does not provide TCP port")
+	errBanned           = errors.New("node is banned")
+	errLowReputation    = errors.New("reputation too low")
 )
 
 // dialer creates outbound connections and submits them into Server.
@@ -129,10 +132,11 @@ type dialScheduler struct {
 type dialSetupFunc func(net.Conn, connFlag, *enode.Node) error
 
 type dialConfig struct {
-	self           enode.ID         // our own ID
-	maxDialPeers   int              // maximum number of dialed peers
-	maxActiveDials int              // maximum number of active dials
-	netRestrict    *netutil.Netlist // IP netrestrict list, disabled if nil
+	self           enode.ID          // our own ID
+	maxDialPeers   int               // maximum number of dialed peers
+	maxActiveDials int               // maximum number of active dials
+	netFilter      *netutil.IPFilter // IP allow and deny lists, disabled if nil
+	reputation     *reputation       // reputation service, disabled if nil
 	resolver       nodeResolver
 	dialer         NodeDialer
 	log            log.Logger
@@ -241,7 +245,10 @@ loop:
 
 		select {
 		case node := <-nodesCh:
-			if err := d.checkDial(node); err != nil {
+			if err := d.checkDynDial(node); err != nil {
+				if err == errNetRestrict || err == errNetDeny {
+					dialRejectMeter.Mark(1)
+				}
 				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IP(), "reason", err)
 			} else {
 				d.startDial(newDialTask(node, dynDialedConn))
@@ -400,15 +407,30 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 	if _, ok := d.peers[n.ID()]; ok {
 		return errAlreadyConnected
 	}
-	if d.netRestrict != nil && !d.netRestrict.Contains(n.IP()) {
+	if !d.netFilter.Allowed(n.IP()) {
 		return errNetRestrict
 	}
+	if d.netFilter.Denied(n.IP()) {
+		return errNetDeny
+	}
 	if d.history.contains(string(n.ID().Bytes())) {
 		return errRecentlyDialed
 	}
 	return nil
 }
 
+// checkDynDial returns an error if discovered node n should not be dialed. Unlike
+// static nodes, these are also filtered by reputation.
+func (d *dialScheduler) checkDynDial(n *enode.Node) error {
+	if err := d.checkDial(n); err != nil {
+		return err
+	}
+	if d.reputation != nil {
+		return d.reputation.checkDial(n.ID())
+	}
+	return nil
+}
+
 // startStaticDials starts n static dial tasks.
 func (d *dialScheduler) startStaticDials(n int) (started int) {
 	for started = 0; started < n && len(d.staticPool) > 0; started++ {
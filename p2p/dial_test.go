@@ -130,12 +130,13 @@ func TestDialSchedNetRestrict(t *testing.T) {
 		newNode(uintID(0x07), "127.0.2.7:30303"),
 		newNode(uintID(0x08), "127.0.2.8:30303"),
 	}
+	netRestrict := new(netutil.Netlist)
+	netRestrict.Add("127.0.2.0/24")
 	config := dialConfig{
-		netRestrict:    new(netutil.Netlist),
+		netFilter:      netutil.NewIPFilter(netRestrict, nil),
 		maxActiveDials: 10,
 		maxDialPeers:   10,
 	}
-	config.netRestrict.Add("127.0.2.0/24")
 	runDialTest(t, config, []dialTestRound{
 		{
 			discovered:   nodes,
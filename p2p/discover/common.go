@@ -22,11 +22,15 @@ import (
 
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 )
 
+// netFilterRejectMeter counts discovery packets dropped because of the IP filter.
+var netFilterRejectMeter = metrics.NewRegisteredMeter("p2p/discover/rejected", nil)
+
 // UDPConn is a network connection on which discovery can operate.
 type UDPConn interface {
 	ReadFromUDP(b []byte) (n int, addr *net.UDPAddr, err error)
@@ -46,6 +50,7 @@ type Config struct {
 
 	// These settings are optional:
 	NetRestrict *netutil.Netlist  // list of allowed IP networks
+	NetFilter   *netutil.IPFilter // allowed and denied IP networks, overrides NetRestrict
 	Bootnodes   []*enode.Node     // list of bootstrap nodes
 	Unhandled   chan<- ReadPacket // unhandled packets are sent on this channel
 	Log         log.Logger        // if set, log messages go here
@@ -67,6 +72,9 @@ func (cfg Config) withDefaults() Config {
 	if cfg.Clock == nil {
 		cfg.Clock = mclock.System{}
 	}
+	if cfg.NetFilter == nil && cfg.NetRestrict != nil {
+		cfg.NetFilter = netutil.NewIPFilter(cfg.NetRestrict, nil)
+	}
 	return cfg
 }
 
@@ -45,6 +45,7 @@ var (
 	errClockWarp        = errors.New("reply deadline too far in the future")
 	errClosed           = errors.New("socket closed")
 	errLowPort          = errors.New("low port")
+	errNetFilter        = errors.New("rejected by IP filter")
 )
 
 const (
@@ -65,15 +66,15 @@ const (
 
 // UDPv4 implements the v4 wire protocol.
 type UDPv4 struct {
-	conn        UDPConn
-	log         log.Logger
-	netrestrict *netutil.Netlist
-	priv        *ecdsa.PrivateKey
-	localNode   *enode.LocalNode
-	db          *enode.DB
-	tab         *Table
-	closeOnce   sync.Once
-	wg          sync.WaitGroup
+	conn      UDPConn
+	log       log.Logger
+	netfilter *netutil.IPFilter
+	priv      *ecdsa.PrivateKey
+	localNode *enode.LocalNode
+	db        *enode.DB
+	tab       *Table
+	closeOnce sync.Once
+	wg        sync.WaitGroup
 
 	addReplyMatcher chan *replyMatcher
 	gotreply        chan reply
@@ -132,7 +133,7 @@ func ListenV4(c UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv4, error) {
 	t := &UDPv4{
 		conn:            c,
 		priv:            cfg.PrivateKey,
-		netrestrict:     cfg.NetRestrict,
+		netfilter:       cfg.NetFilter,
 		localNode:       ln,
 		db:              ln.Database(),
 		gotreply:        make(chan reply),
@@ -531,6 +532,12 @@ func (t *UDPv4) readLoop(unhandled chan<- ReadPacket) {
 			}
 			return
 		}
+		if !t.netfilter.Accept(from.IP) {
+			// Drop packets from filtered hosts. They are not passed on to
+			// the unhandled channel either, discv5 shares the same filter.
+			netFilterRejectMeter.Mark(1)
+			continue
+		}
 		if t.handlePacket(from, buf[:nbytes]) != nil && unhandled != nil {
 			select {
 			case unhandled <- ReadPacket{buf[:nbytes], from}:
@@ -582,8 +589,8 @@ func (t *UDPv4) nodeFromRPC(sender *net.UDPAddr, rn v4wire.Node) (*node, error)
 	if err := netutil.CheckRelayIP(sender.IP, rn.IP); err != nil {
 		return nil, err
 	}
-	if t.netrestrict != nil && !t.netrestrict.Contains(rn.IP) {
-		return nil, errors.New("not contained in netrestrict list")
+	if !t.netfilter.Accept(rn.IP) {
+		return nil, errNetFilter
 	}
 	key, err := v4wire.DecodePubkey(crypto.S256(), rn.ID)
 	if err != nil {
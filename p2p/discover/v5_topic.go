@@ -0,0 +1,521 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	topicAdLifetime    = 15 * time.Minute // how long an ad stays in the ad cache
+	topicQueueCapacity = 50               // max ads per topic
+	topicCacheCapacity = 5000             // max ads across all topics
+	topicRegWindow     = 10 * time.Second // how long a ticket can be used after its wait time
+
+	topicRegInterval      = 10 * time.Minute // how often registrations are renewed
+	topicRegRetryInterval = 30 * time.Second // retry delay when no registrar accepted the ad
+	topicSearchInterval   = 10 * time.Second // min time between topic search rounds
+)
+
+var (
+	errInvalidTicket = errors.New("invalid ticket")
+	errTicketWait    = errors.New("ticket wait time too long")
+	errTicketWindow  = errors.New("ticket used outside of registration window")
+	errTopicRejected = errors.New("topic registration rejected")
+)
+
+// Topic is the identifier of a topic which nodes can advertise themselves under.
+type Topic [32]byte
+
+// NewTopic creates the topic identifier for the given name.
+func NewTopic(name string) Topic {
+	return Topic(sha256.Sum256([]byte(name)))
+}
+
+func (t Topic) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// target returns the DHT location of the topic. Ads for a topic are placed on the
+// nodes closest to this ID.
+func (t Topic) target() enode.ID {
+	return enode.ID(t)
+}
+
+// RegisterTopic starts advertising the local node under the given topic. Ads are
+// placed on the nodes closest to the topic and renewed until StopRegisterTopic is
+// called or the transport is closed.
+func (t *UDPv5) RegisterTopic(topic Topic) {
+	t.topicLock.Lock()
+	defer t.topicLock.Unlock()
+
+	if _, ok := t.topicRegs[topic]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	t.topicRegs[topic] = cancel
+	go t.topicRegLoop(ctx, topic)
+}
+
+// StopRegisterTopic stops advertising the local node under the given topic. Ads
+// which were already placed remain valid until they expire.
+func (t *UDPv5) StopRegisterTopic(topic Topic) {
+	t.topicLock.Lock()
+	defer t.topicLock.Unlock()
+
+	if cancel, ok := t.topicRegs[topic]; ok {
+		cancel()
+		delete(t.topicRegs, topic)
+	}
+}
+
+// TopicNodes returns an iterator that finds nodes advertising the given topic.
+func (t *UDPv5) TopicNodes(topic Topic) enode.Iterator {
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	return &topicIterator{t: t, topic: topic, ctx: ctx, cancel: cancel}
+}
+
+// topicRegLoop runs in its own goroutine and keeps the ads of a topic alive.
+func (t *UDPv5) topicRegLoop(ctx context.Context, topic Topic) {
+	timer := t.clock.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			return
+		}
+		n := t.registerTopic(ctx, topic)
+		t.log.Debug("Registered topic", "topic", topic, "registrars", n)
+		if n == 0 {
+			timer.Reset(topicRegRetryInterval)
+		} else {
+			timer.Reset(topicRegInterval)
+		}
+	}
+}
+
+// registerTopic places ads on the nodes closest to the topic. It returns the number
+// of nodes which accepted the ad.
+func (t *UDPv5) registerTopic(ctx context.Context, topic Topic) int {
+	var (
+		registrars = t.newLookup(ctx, topic.target()).run()
+		wg         sync.WaitGroup
+		count      int32
+	)
+	for _, n := range registrars {
+		wg.Add(1)
+		go func(n *enode.Node) {
+			defer wg.Done()
+			if err := t.registerTopicAt(ctx, n, topic); err != nil {
+				t.log.Trace("Topic registration failed", "id", n.ID(), "topic", topic, "err", err)
+				return
+			}
+			atomic.AddInt32(&count, 1)
+		}(n)
+	}
+	wg.Wait()
+	return int(count)
+}
+
+// registerTopicAt obtains a ticket from n, waits until it becomes valid and then
+// uses it to place an ad.
+func (t *UDPv5) registerTopicAt(ctx context.Context, n *enode.Node, topic Topic) error {
+	ticket, wait, err := t.requestTicket(n, topic)
+	if err != nil {
+		return err
+	}
+	if wait > topicAdLifetime {
+		return errTicketWait
+	}
+	if wait > 0 {
+		timer := t.clock.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	ok, err := t.regtopic(n, ticket)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errTopicRejected
+	}
+	return nil
+}
+
+// requestTicket calls REQTICKET on a node and waits for a TICKET response.
+func (t *UDPv5) requestTicket(n *enode.Node, topic Topic) ([]byte, time.Duration, error) {
+	resp := t.call(n, v5wire.TicketMsg, &v5wire.RequestTicket{Topic: topic[:]})
+	defer t.callDone(resp)
+
+	select {
+	case respMsg := <-resp.ch:
+		ticket := respMsg.(*v5wire.Ticket)
+		return ticket.Ticket, time.Duration(ticket.WaitTime) * time.Millisecond, nil
+	case err := <-resp.err:
+		return nil, 0, err
+	}
+}
+
+// regtopic calls REGTOPIC on a node and waits for a REGCONFIRMATION response.
+func (t *UDPv5) regtopic(n *enode.Node, ticket []byte) (bool, error) {
+	req := &v5wire.Regtopic{Ticket: ticket, ENR: t.localNode.Node().Record()}
+	resp := t.call(n, v5wire.RegconfirmationMsg, req)
+	defer t.callDone(resp)
+
+	select {
+	case respMsg := <-resp.ch:
+		return respMsg.(*v5wire.Regconfirmation).Registered, nil
+	case err := <-resp.err:
+		return false, err
+	}
+}
+
+// topicQuery calls TOPICQUERY on a node and waits for responses.
+func (t *UDPv5) topicQuery(n *enode.Node, topic Topic) ([]*enode.Node, error) {
+	resp := t.call(n, v5wire.NodesMsg, &v5wire.TopicQuery{Topic: topic[:]})
+	return t.waitForNodes(resp, nil)
+}
+
+// handleRequestTicket issues a ticket for the requested topic.
+func (t *UDPv5) handleRequestTicket(p *v5wire.RequestTicket, fromID enode.ID, fromAddr *net.UDPAddr) {
+	var topic Topic
+	if len(p.Topic) != len(topic) {
+		t.log.Debug("Invalid "+p.Name(), "id", fromID, "addr", fromAddr, "err", "bad topic length")
+		return
+	}
+	copy(topic[:], p.Topic)
+
+	now := t.clock.Now()
+	wait := t.topicAds.waitTime(topic, fromID, now)
+	ticket := t.topicAds.issueTicket(&topicTicket{
+		Topic:  topic,
+		Node:   fromID,
+		IP:     fromAddr.IP,
+		Issued: uint64(now),
+		Wait:   uint64(wait),
+	})
+	t.sendResponse(fromID, fromAddr, &v5wire.Ticket{
+		ReqID:    p.ReqID,
+		Ticket:   ticket,
+		WaitTime: uint64(wait / time.Millisecond),
+	})
+}
+
+// handleRegtopic places an ad for the sender if its ticket is valid.
+func (t *UDPv5) handleRegtopic(p *v5wire.Regtopic, fromID enode.ID, fromAddr *net.UDPAddr) {
+	now := t.clock.Now()
+	topic, n, err := t.checkRegtopic(p, fromID, fromAddr, now)
+	registered := false
+	if err != nil {
+		t.log.Debug("Invalid "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+	} else {
+		registered = t.topicAds.register(topic, n, now)
+	}
+	t.sendResponse(fromID, fromAddr, &v5wire.Regconfirmation{ReqID: p.ReqID, Registered: registered})
+}
+
+// checkRegtopic verifies the ticket and record of a REGTOPIC request.
+func (t *UDPv5) checkRegtopic(p *v5wire.Regtopic, fromID enode.ID, fromAddr *net.UDPAddr, now mclock.AbsTime) (Topic, *enode.Node, error) {
+	ticket, err := t.topicAds.verifyTicket(p.Ticket)
+	if err != nil {
+		return Topic{}, nil, err
+	}
+	if ticket.Node != fromID || !ticket.IP.Equal(fromAddr.IP) {
+		return Topic{}, nil, errInvalidTicket
+	}
+	start := mclock.AbsTime(ticket.Issued + ticket.Wait)
+	if now < start || now > start.Add(topicRegWindow) {
+		return Topic{}, nil, errTicketWindow
+	}
+	if p.ENR == nil {
+		return Topic{}, nil, errors.New("missing record")
+	}
+	n, err := enode.New(t.validSchemes, p.ENR)
+	if err != nil {
+		return Topic{}, nil, err
+	}
+	if n.ID() != fromID {
+		return Topic{}, nil, errors.New("record does not match sender")
+	}
+	if !n.IP().Equal(fromAddr.IP) {
+		return Topic{}, nil, errors.New("record IP does not match sender")
+	}
+	return ticket.Topic, n, nil
+}
+
+// handleTopicQuery returns the nodes advertising the requested topic.
+func (t *UDPv5) handleTopicQuery(p *v5wire.TopicQuery, fromID enode.ID, fromAddr *net.UDPAddr) {
+	var topic Topic
+	if len(p.Topic) != len(topic) {
+		t.log.Debug("Invalid "+p.Name(), "id", fromID, "addr", fromAddr, "err", "bad topic length")
+		return
+	}
+	copy(topic[:], p.Topic)
+
+	var nodes []*enode.Node
+	for _, n := range t.topicAds.nodes(topic, t.clock.Now(), findnodeResultLimit) {
+		if n.ID() != fromID && netutil.CheckRelayIP(fromAddr.IP, n.IP()) == nil {
+			nodes = append(nodes, n)
+		}
+	}
+	for _, resp := range packNodes(p.ReqID, nodes) {
+		t.sendResponse(fromID, fromAddr, resp)
+	}
+}
+
+// topicAdCache stores the ads placed on the local node. It is accessed by the
+// dispatch goroutine only.
+type topicAdCache struct {
+	queues    map[Topic][]topicAd // ads of each topic, oldest first
+	total     int
+	ticketKey []byte
+}
+
+type topicAd struct {
+	node    *enode.Node
+	expires mclock.AbsTime
+}
+
+// topicTicket is the content of a ticket. Tickets are opaque to the registrant and
+// authenticated with a key that is only known to the registrar.
+type topicTicket struct {
+	Topic  Topic
+	Node   enode.ID
+	IP     net.IP
+	Issued uint64 // mclock.AbsTime
+	Wait   uint64 // time.Duration
+}
+
+func newTopicAdCache() *topicAdCache {
+	c := &topicAdCache{
+		queues:    make(map[Topic][]topicAd),
+		ticketKey: make([]byte, 32),
+	}
+	crand.Read(c.ticketKey)
+	return c
+}
+
+// issueTicket encodes and authenticates a ticket.
+func (c *topicAdCache) issueTicket(ticket *topicTicket) []byte {
+	enc, _ := rlp.EncodeToBytes(ticket)
+	mac := hmac.New(sha256.New, c.ticketKey)
+	mac.Write(enc)
+	return mac.Sum(enc)
+}
+
+// verifyTicket checks the authenticity of a ticket and decodes it.
+func (c *topicAdCache) verifyTicket(data []byte) (*topicTicket, error) {
+	if len(data) <= sha256.Size {
+		return nil, errInvalidTicket
+	}
+	enc, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	mac := hmac.New(sha256.New, c.ticketKey)
+	mac.Write(enc)
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return nil, errInvalidTicket
+	}
+	ticket := new(topicTicket)
+	if err := rlp.DecodeBytes(enc, ticket); err != nil {
+		return nil, errInvalidTicket
+	}
+	return ticket, nil
+}
+
+// waitTime returns how long the node has to wait until an ad for it can be placed
+// in the topic queue. The result is rounded up to whole milliseconds.
+func (c *topicAdCache) waitTime(topic Topic, id enode.ID, now mclock.AbsTime) time.Duration {
+	c.expire(now)
+
+	var next mclock.AbsTime
+	q := c.queues[topic]
+	switch {
+	case indexOfAd(q, id) >= 0:
+		return 0
+	case len(q) >= topicQueueCapacity:
+		next = q[0].expires
+	case c.total >= topicCacheCapacity:
+		next = c.nextExpiry()
+	default:
+		return 0
+	}
+	wait := next.Sub(now)
+	return (wait + time.Millisecond - 1).Truncate(time.Millisecond)
+}
+
+// register places an ad for n in the topic queue. Existing ads of n are renewed.
+// It returns false if there is no space for the ad.
+func (c *topicAdCache) register(topic Topic, n *enode.Node, now mclock.AbsTime) bool {
+	c.expire(now)
+
+	q := c.queues[topic]
+	if i := indexOfAd(q, n.ID()); i >= 0 {
+		q = append(q[:i], q[i+1:]...)
+		c.total--
+	} else if len(q) >= topicQueueCapacity || c.total >= topicCacheCapacity {
+		return false
+	}
+	c.queues[topic] = append(q, topicAd{node: n, expires: now.Add(topicAdLifetime)})
+	c.total++
+	return true
+}
+
+// nodes returns up to limit randomly chosen nodes advertising the topic.
+func (c *topicAdCache) nodes(topic Topic, now mclock.AbsTime, limit int) []*enode.Node {
+	c.expire(now)
+
+	q := c.queues[topic]
+	nodes := make([]*enode.Node, 0, min(len(q), limit))
+	for _, i := range rand.Perm(len(q)) {
+		if len(nodes) >= limit {
+			break
+		}
+		nodes = append(nodes, q[i].node)
+	}
+	return nodes
+}
+
+// expire removes all ads which have expired.
+func (c *topicAdCache) expire(now mclock.AbsTime) {
+	for topic, q := range c.queues {
+		i := 0
+		for i < len(q) && q[i].expires <= now {
+			i++
+		}
+		c.total -= i
+		if i == len(q) {
+			delete(c.queues, topic)
+		} else {
+			c.queues[topic] = q[i:]
+		}
+	}
+}
+
+// nextExpiry returns the time at which the next ad expires.
+func (c *topicAdCache) nextExpiry() mclock.AbsTime {
+	var next mclock.AbsTime
+	for _, q := range c.queues {
+		if next == 0 || q[0].expires < next {
+			next = q[0].expires
+		}
+	}
+	return next
+}
+
+func indexOfAd(q []topicAd, id enode.ID) int {
+	for i, ad := range q {
+		if ad.node.ID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// topicIterator performs topic queries and iterates over the nodes found. Each
+// search round queries the nodes closest to the topic, and rounds are repeated
+// for as long as the iterator is open.
+type topicIterator struct {
+	t      *UDPv5
+	topic  Topic
+	ctx    context.Context
+	cancel func()
+
+	registrars []*enode.Node         // nodes left to query in this round
+	seen       map[enode.ID]struct{} // nodes found in this round
+	buffer     []*enode.Node
+	nextRound  mclock.AbsTime
+}
+
+// Node returns the current node.
+func (it *topicIterator) Node() *enode.Node {
+	if len(it.buffer) == 0 {
+		return nil
+	}
+	return it.buffer[0]
+}
+
+// Next moves to the next node.
+func (it *topicIterator) Next() bool {
+	if len(it.buffer) > 0 {
+		it.buffer = it.buffer[1:]
+	}
+	for len(it.buffer) == 0 {
+		if it.ctx.Err() != nil {
+			it.registrars = nil
+			it.buffer = nil
+			return false
+		}
+		if len(it.registrars) == 0 {
+			it.startRound()
+			continue
+		}
+		n := it.registrars[0]
+		it.registrars = it.registrars[1:]
+		nodes, _ := it.t.topicQuery(n, it.topic)
+		for _, n := range nodes {
+			if _, ok := it.seen[n.ID()]; ok || n.ID() == it.t.Self().ID() {
+				continue
+			}
+			it.seen[n.ID()] = struct{}{}
+			it.buffer = append(it.buffer, n)
+		}
+	}
+	return true
+}
+
+// startRound looks up the nodes closest to the topic. Rounds are spaced at least
+// topicSearchInterval apart.
+func (it *topicIterator) startRound() {
+	if wait := it.nextRound.Sub(it.t.clock.Now()); wait > 0 {
+		timer := it.t.clock.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+		case <-it.ctx.Done():
+			return
+		}
+	}
+	it.nextRound = it.t.clock.Now().Add(topicSearchInterval)
+	it.seen = make(map[enode.ID]struct{})
+	it.registrars = it.t.newLookup(it.ctx, it.topic.target()).run()
+}
+
+// Close ends the iterator.
+func (it *topicIterator) Close() {
+	it.cancel()
+}
@@ -62,7 +62,7 @@ type UDPv5 struct {
 	// static fields
 	conn         UDPConn
 	tab          *Table
-	netrestrict  *netutil.Netlist
+	netfilter    *netutil.IPFilter
 	priv         *ecdsa.PrivateKey
 	localNode    *enode.LocalNode
 	db           *enode.DB
@@ -74,6 +74,11 @@ type UDPv5 struct {
 	trlock     sync.Mutex
 	trhandlers map[string]TalkRequestHandler
 
+	// topic advertisement
+	topicLock sync.Mutex
+	topicRegs map[Topic]context.CancelFunc // active registrations
+	topicAds  *topicAdCache                // accessed by dispatch only
+
 	// channels into dispatch
 	packetInCh    chan ReadPacket
 	readNextCh    chan struct{}
@@ -141,12 +146,14 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		conn:         conn,
 		localNode:    ln,
 		db:           ln.Database(),
-		netrestrict:  cfg.NetRestrict,
+		netfilter:    cfg.NetFilter,
 		priv:         cfg.PrivateKey,
 		log:          cfg.Log,
 		validSchemes: cfg.ValidSchemes,
 		clock:        cfg.Clock,
 		trhandlers:   make(map[string]TalkRequestHandler),
+		topicRegs:    make(map[Topic]context.CancelFunc),
+		topicAds:     newTopicAdCache(),
 		// channels into dispatch
 		packetInCh:    make(chan ReadPacket, 1),
 		readNextCh:    make(chan struct{}, 1),
@@ -405,8 +412,8 @@ func (t *UDPv5) verifyResponseNode(c *callV5, r *enr.Record, distances []uint, s
 	if err := netutil.CheckRelayIP(c.node.IP(), node.IP()); err != nil {
 		return nil, err
 	}
-	if t.netrestrict != nil && !t.netrestrict.Contains(node.IP()) {
-		return nil, errors.New("not contained in netrestrict list")
+	if !t.netfilter.Accept(node.IP()) {
+		return nil, errNetFilter
 	}
 	if c.node.UDP() <= 1024 {
 		return nil, errLowPort
@@ -645,6 +652,10 @@ func (t *UDPv5) dispatchReadPacket(from *net.UDPAddr, content []byte) bool {
 
 // handlePacket decodes and processes an incoming packet from the network.
 func (t *UDPv5) handlePacket(rawpacket []byte, fromAddr *net.UDPAddr) error {
+	if !t.netfilter.Accept(fromAddr.IP) {
+		netFilterRejectMeter.Mark(1)
+		return errNetFilter
+	}
 	addr := fromAddr.String()
 	fromID, fromNode, packet, err := t.codec.Decode(rawpacket, addr)
 	if err != nil {
@@ -715,6 +726,16 @@ func (t *UDPv5) handle(p v5wire.Packet, fromID enode.ID, fromAddr *net.UDPAddr)
 		t.handleTalkRequest(p, fromID, fromAddr)
 	case *v5wire.TalkResponse:
 		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.RequestTicket:
+		t.handleRequestTicket(p, fromID, fromAddr)
+	case *v5wire.Ticket:
+		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.Regtopic:
+		t.handleRegtopic(p, fromID, fromAddr)
+	case *v5wire.Regconfirmation:
+		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.TopicQuery:
+		t.handleTopicQuery(p, fromID, fromAddr)
 	}
 }
 
@@ -28,6 +28,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/internal/testlog"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
@@ -663,6 +665,214 @@ func TestUDPv5_PingWithIPV4MappedAddress(t *testing.T) {
 	<-done
 }
 
+// This test checks the REQTICKET, REGTOPIC and TOPICQUERY handlers.
+func TestUDPv5_topicHandling(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	topic := NewTopic("test")
+	remote := test.getNode(test.remotekey, test.remoteaddr).Node()
+
+	// Request a ticket. The queue is empty, so there is no wait time.
+	var ticket []byte
+	test.packetIn(&v5wire.RequestTicket{ReqID: []byte("1"), Topic: topic[:]})
+	test.waitPacketOut(func(p *v5wire.Ticket, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if !bytes.Equal(p.ReqID, []byte("1")) {
+			t.Error("wrong request ID in response:", p.ReqID)
+		}
+		if p.WaitTime != 0 {
+			t.Errorf("wrong wait time %d, want 0", p.WaitTime)
+		}
+		ticket = p.Ticket
+	})
+
+	// A modified ticket is rejected.
+	bad := common.CopyBytes(ticket)
+	bad[0]++
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte("2"), Ticket: bad, ENR: remote.Record()})
+	test.waitPacketOut(func(p *v5wire.Regconfirmation, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if p.Registered {
+			t.Error("registered with invalid ticket")
+		}
+	})
+
+	// The ticket can't be used by another node.
+	otherKey, otherAddr := newkey(), &net.UDPAddr{IP: net.IP{10, 0, 1, 100}, Port: 30303}
+	other := test.getNode(otherKey, otherAddr).Node()
+	test.packetInFrom(otherKey, otherAddr, &v5wire.Regtopic{ReqID: []byte("3"), Ticket: ticket, ENR: other.Record()})
+	test.waitPacketOut(func(p *v5wire.Regconfirmation, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if p.Registered {
+			t.Error("registered with ticket of other node")
+		}
+	})
+
+	// Register using the valid ticket.
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte("4"), Ticket: ticket, ENR: remote.Record()})
+	test.waitPacketOut(func(p *v5wire.Regconfirmation, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if !bytes.Equal(p.ReqID, []byte("4")) {
+			t.Error("wrong request ID in response:", p.ReqID)
+		}
+		if !p.Registered {
+			t.Error("registration failed")
+		}
+	})
+
+	// The registered node is returned by TOPICQUERY.
+	test.packetInFrom(otherKey, otherAddr, &v5wire.TopicQuery{ReqID: []byte("5"), Topic: topic[:]})
+	test.waitPacketOut(func(p *v5wire.Nodes, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if len(p.Nodes) != 1 {
+			t.Fatalf("wrong number of nodes in response: %d", len(p.Nodes))
+		}
+		n, err := enode.New(enode.ValidSchemesForTesting, p.Nodes[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.ID() != remote.ID() {
+			t.Errorf("wrong node in response: %v", n.ID())
+		}
+	})
+
+	// Other topics are empty.
+	other2 := NewTopic("other")
+	test.packetIn(&v5wire.TopicQuery{ReqID: []byte("6"), Topic: other2[:]})
+	test.expectNodes([]byte("6"), 1, nil)
+}
+
+// This test checks that topic ads expire and that the wait time is derived from
+// the next expiry when a topic queue is full.
+func TestTopicAdCache(t *testing.T) {
+	var (
+		c     = newTopicAdCache()
+		topic = NewTopic("test")
+		clock mclock.Simulated
+		nodes []*enode.Node
+	)
+	for i := 0; i < topicQueueCapacity+1; i++ {
+		nodes = append(nodes, enode.SignNull(new(enr.Record), enode.ID{byte(i), byte(i >> 8)}))
+	}
+	for i := 0; i < topicQueueCapacity; i++ {
+		if !c.register(topic, nodes[i], clock.Now()) {
+			t.Fatalf("registration %d failed", i)
+		}
+		clock.Run(time.Second)
+	}
+
+	// The queue is full, so the last node has to wait for the first ad to expire.
+	last := nodes[topicQueueCapacity]
+	if c.register(topic, last, clock.Now()) {
+		t.Fatal("registration succeeded with full queue")
+	}
+	wantWait := topicAdLifetime - topicQueueCapacity*time.Second
+	if wait := c.waitTime(topic, last.ID(), clock.Now()); wait != wantWait {
+		t.Fatalf("wrong wait time %v, want %v", wait, wantWait)
+	}
+	// Nodes in the queue can renew their ad at any time.
+	if wait := c.waitTime(topic, nodes[1].ID(), clock.Now()); wait != 0 {
+		t.Fatalf("wrong wait time %v for registered node, want 0", wait)
+	}
+	if !c.register(topic, nodes[1], clock.Now()) {
+		t.Fatal("renewal failed")
+	}
+
+	clock.Run(wantWait)
+	if !c.register(topic, last, clock.Now()) {
+		t.Fatal("registration failed after expiry")
+	}
+	if n := len(c.nodes(topic, clock.Now(), 2*topicQueueCapacity)); n != topicQueueCapacity {
+		t.Fatalf("wrong number of nodes %d, want %d", n, topicQueueCapacity)
+	}
+	clock.Run(topicAdLifetime)
+	if n := len(c.nodes(topic, clock.Now(), topicQueueCapacity)); n != 0 {
+		t.Fatalf("%d nodes left after expiry", n)
+	}
+	if c.total != 0 {
+		t.Fatalf("wrong total %d after expiry", c.total)
+	}
+}
+
+// This test checks that nodes can find each other through topic advertisement.
+// The registrar is played by the test, backed by an ad cache.
+func TestUDPv5_topicE2E(t *testing.T) {
+	t.Parallel()
+	var (
+		topic = NewTopic("test")
+		ads   = newTopicAdCache()
+	)
+
+	// Register the topic with the registrar.
+	advertiser := newUDPV5Test(t)
+	fillTable(advertiser.table, []*node{wrapNode(advertiser.getNode(advertiser.remotekey, advertiser.remoteaddr).Node())})
+	advertiser.udp.RegisterTopic(topic)
+
+	for registered := false; !registered; {
+		advertiser.waitPacketOut(func(p v5wire.Packet, to *net.UDPAddr, _ v5wire.Nonce) {
+			switch p := p.(type) {
+			case *v5wire.Ping:
+				advertiser.packetIn(&v5wire.Pong{ReqID: p.ReqID})
+			case *v5wire.Findnode:
+				advertiser.packetIn(packNodes(p.ReqID, nil)[0])
+			case *v5wire.RequestTicket:
+				ticket := ads.issueTicket(&topicTicket{Topic: topic, Node: advertiser.udp.Self().ID(), IP: advertiser.udp.Self().IP()})
+				advertiser.packetIn(&v5wire.Ticket{ReqID: p.ReqID, Ticket: ticket})
+			case *v5wire.Regtopic:
+				if _, err := ads.verifyTicket(p.Ticket); err != nil {
+					t.Fatalf("invalid ticket: %v", err)
+				}
+				n, err := enode.New(enode.ValidSchemesForTesting, p.ENR)
+				if err != nil {
+					t.Fatalf("invalid ENR: %v", err)
+				}
+				registered = ads.register(topic, n, mclock.Now())
+				advertiser.packetIn(&v5wire.Regconfirmation{ReqID: p.ReqID, Registered: registered})
+			default:
+				t.Fatalf("unexpected packet %v", p.Name())
+			}
+		})
+	}
+	self := advertiser.udp.Self()
+	advertiser.close()
+
+	// Search the topic from another node.
+	searcher := newUDPV5Test(t)
+	defer searcher.close()
+	fillTable(searcher.table, []*node{wrapNode(searcher.getNode(searcher.remotekey, searcher.remoteaddr).Node())})
+
+	it := searcher.udp.TopicNodes(topic)
+	defer it.Close()
+	result := make(chan *enode.Node, 1)
+	go func() {
+		if it.Next() {
+			result <- it.Node()
+		}
+		close(result)
+	}()
+	for queried := false; !queried; {
+		searcher.waitPacketOut(func(p v5wire.Packet, to *net.UDPAddr, _ v5wire.Nonce) {
+			switch p := p.(type) {
+			case *v5wire.Ping:
+				searcher.packetIn(&v5wire.Pong{ReqID: p.ReqID})
+			case *v5wire.Findnode:
+				searcher.packetIn(packNodes(p.ReqID, nil)[0])
+			case *v5wire.TopicQuery:
+				for _, resp := range packNodes(p.ReqID, ads.nodes(topic, mclock.Now(), findnodeResultLimit)) {
+					searcher.packetIn(resp)
+				}
+				queried = true
+			default:
+				t.Fatalf("unexpected packet %v", p.Name())
+			}
+		})
+	}
+	n := <-result
+	if n == nil {
+		t.Fatal("topic search ended without results")
+	}
+	if n.ID() != self.ID() {
+		t.Fatalf("found wrong node %v, want %v", n.ID(), self.ID())
+	}
+}
+
 // udpV5Test is the framework for all tests above.
 // It runs the UDPv5 transport on a virtual socket and allows testing outgoing packets.
 type udpV5Test struct {
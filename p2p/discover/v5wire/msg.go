@@ -121,8 +121,9 @@ type (
 
 	// Ticket is the response to RequestTicket.
 	Ticket struct {
-		ReqID  []byte
-		Ticket []byte
+		ReqID    []byte
+		Ticket   []byte
+		WaitTime uint64 // milliseconds until the ticket can be used
 	}
 
 	// Regtopic registers the sender in a topic queue using a ticket.
@@ -21,6 +21,7 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"sync"
@@ -40,6 +41,7 @@ const (
 	dbVersionKey   = "version" // Version of the database to flush if changes
 	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
 	dbLocalPrefix  = "local:"
+	dbRepPrefix    = "rep:" // Identifier to prefix reputation entries with
 	dbDiscoverRoot = "v4"
 	dbDiscv5Root   = "v5"
 
@@ -53,11 +55,18 @@ const (
 	// Local information is keyed by ID only, the full key is "local:<ID>:seq".
 	// Use localItemKey to create those keys.
 	dbLocalSeq = "seq"
+
+	// Reputation information is keyed by ID only, the full key is "rep:<ID>:score".
+	// Use reputationItemKey to create those keys.
+	dbRepScore   = "score"
+	dbRepUpdated = "updated"
+	dbRepBan     = "ban"
 )
 
 const (
-	dbNodeExpiration = 24 * time.Hour // Time after which an unseen node should be dropped.
-	dbCleanupCycle   = time.Hour      // Time period for running the expiration task.
+	dbNodeExpiration = 24 * time.Hour     // Time after which an unseen node should be dropped.
+	dbRepExpiration  = 7 * 24 * time.Hour // Time after which an untouched reputation should be dropped.
+	dbCleanupCycle   = time.Hour          // Time period for running the expiration task.
 	dbVersion        = 9
 )
 
@@ -195,6 +204,24 @@ func localItemKey(id ID, field string) []byte {
 	return key
 }
 
+// reputationItemKey returns the database key for a reputation field of a node.
+func reputationItemKey(id ID, field string) []byte {
+	key := append([]byte(dbRepPrefix), id[:]...)
+	key = append(key, ':')
+	key = append(key, field...)
+	return key
+}
+
+// splitReputationItemKey returns the components of a key created by reputationItemKey.
+func splitReputationItemKey(key []byte) (id ID, field string) {
+	if !bytes.HasPrefix(key, []byte(dbRepPrefix)) || len(key) < len(dbRepPrefix)+len(id)+1 {
+		return ID{}, ""
+	}
+	item := key[len(dbRepPrefix):]
+	copy(id[:], item[:len(id)])
+	return id, string(item[len(id)+1:])
+}
+
 // fetchInt64 retrieves an integer associated with a particular key.
 func (db *DB) fetchInt64(key []byte) int64 {
 	blob, err := db.lvl.Get(key, nil)
@@ -315,6 +342,7 @@ func (db *DB) expirer() {
 		select {
 		case <-tick.C:
 			db.expireNodes()
+			db.expireReputations()
 		case <-db.quit:
 			return
 		}
@@ -360,6 +388,24 @@ func (db *DB) expireNodes() {
 	}
 }
 
+// expireReputations deletes the reputation of all nodes which have not been
+// touched for some time and are not banned anymore.
+func (db *DB) expireReputations() {
+	var (
+		now       = time.Now()
+		threshold = now.Add(-dbRepExpiration).Unix()
+	)
+	for _, id := range db.ReputationNodes() {
+		if db.fetchInt64(reputationItemKey(id, dbRepUpdated)) >= threshold {
+			continue
+		}
+		if db.BanExpiry(id).After(now) {
+			continue
+		}
+		db.DeleteReputation(id)
+	}
+}
+
 // LastPingReceived retrieves the time of the last ping packet received from
 // a remote node.
 func (db *DB) LastPingReceived(id ID, ip net.IP) time.Time {
@@ -427,6 +473,57 @@ func (db *DB) UpdateFindFailsV5(id ID, ip net.IP, fails int) error {
 	return db.storeInt64(v5Key(id, ip, dbNodeFindFails), int64(fails))
 }
 
+// Reputation retrieves the reputation score of a node, along with the time it
+// was last updated.
+func (db *DB) Reputation(id ID) (float64, time.Time) {
+	score := math.Float64frombits(db.fetchUint64(reputationItemKey(id, dbRepScore)))
+	updated := time.Unix(db.fetchInt64(reputationItemKey(id, dbRepUpdated)), 0)
+	return score, updated
+}
+
+// UpdateReputation stores the reputation score of a node.
+func (db *DB) UpdateReputation(id ID, score float64, updated time.Time) error {
+	if err := db.storeUint64(reputationItemKey(id, dbRepScore), math.Float64bits(score)); err != nil {
+		return err
+	}
+	return db.storeInt64(reputationItemKey(id, dbRepUpdated), updated.Unix())
+}
+
+// BanExpiry retrieves the time until which a node is banned. The zero time is
+// returned if the node was never banned.
+func (db *DB) BanExpiry(id ID) time.Time {
+	expiry := db.fetchInt64(reputationItemKey(id, dbRepBan))
+	if expiry == 0 {
+		return time.Time{}
+	}
+	return time.Unix(expiry, 0)
+}
+
+// UpdateBanExpiry stores the time until which a node is banned.
+func (db *DB) UpdateBanExpiry(id ID, expiry time.Time) error {
+	return db.storeInt64(reputationItemKey(id, dbRepBan), expiry.Unix())
+}
+
+// DeleteReputation deletes all reputation information associated with a node.
+func (db *DB) DeleteReputation(id ID) {
+	deleteRange(db.lvl, append([]byte(dbRepPrefix), id[:]...))
+}
+
+// ReputationNodes returns the IDs of all nodes that have a stored reputation.
+func (db *DB) ReputationNodes() []ID {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbRepPrefix)), nil)
+	defer it.Release()
+
+	var ids []ID
+	for it.Next() {
+		id, field := splitReputationItemKey(it.Key())
+		if field == dbRepUpdated {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // localSeq retrieves the local record sequence counter, defaulting to the current
 // timestamp if no previous exists. This ensures that wiping all data associated
 // with a node (apart from its key) will not generate already used sequence nums.
@@ -467,3 +467,46 @@ func TestDBExpireV5(t *testing.T) {
 	db.UpdateFindFailsV5(ID{}, ip, 4)
 	db.expireNodes()
 }
+
+// This test checks that reputation data can be stored and is expired only
+// after it becomes stale and any bans have lifted.
+func TestDBReputation(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	var (
+		now     = time.Now().Truncate(time.Second)
+		stale   = now.Add(-dbRepExpiration - time.Hour)
+		fresh   = ID{0x01}
+		expired = ID{0x02}
+		banned  = ID{0x03}
+	)
+	db.UpdateReputation(fresh, 12.5, now)
+	db.UpdateReputation(expired, -3, stale)
+	db.UpdateReputation(banned, -80, stale)
+	db.UpdateBanExpiry(banned, now.Add(time.Hour))
+
+	// Ensure stored reputations are retrievable.
+	if score, updated := db.Reputation(fresh); score != 12.5 || !updated.Equal(now) {
+		t.Errorf("reputation mismatch: have %v/%v, want %v/%v", score, updated, 12.5, now)
+	}
+	if expiry := db.BanExpiry(banned); !expiry.Equal(now.Add(time.Hour)) {
+		t.Errorf("ban expiry mismatch: have %v, want %v", expiry, now.Add(time.Hour))
+	}
+	if expiry := db.BanExpiry(fresh); !expiry.IsZero() {
+		t.Errorf("unbanned node has ban expiry %v", expiry)
+	}
+	if ids := db.ReputationNodes(); len(ids) != 3 {
+		t.Fatalf("reputation node count mismatch: have %d, want 3", len(ids))
+	}
+	// Expire stale data and check that banned nodes are retained.
+	db.expireReputations()
+
+	ids := db.ReputationNodes()
+	if len(ids) != 2 || ids[0] != fresh || ids[1] != banned {
+		t.Fatalf("unexpected reputation nodes after expiration: %v", ids)
+	}
+	if score, _ := db.Reputation(expired); score != 0 {
+		t.Errorf("expired reputation still present: %v", score)
+	}
+}
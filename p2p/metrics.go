@@ -41,6 +41,8 @@ var (
 	egressConnectMeter  = metrics.NewRegisteredMeter("p2p/dials", nil)
 	egressTrafficMeter  = metrics.NewRegisteredMeter(egressMeterName, nil)
 	activePeerGauge     = metrics.NewRegisteredGauge("p2p/peers", nil)
+	inboundRejectMeter  = metrics.NewRegisteredMeter("p2p/serves/rejected", nil)
+	dialRejectMeter     = metrics.NewRegisteredMeter("p2p/dials/rejected", nil)
 )
 
 // meteredConn is a wrapper around a net.Conn that meters both the
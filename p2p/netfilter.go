@@ -0,0 +1,234 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+)
+
+// netFilterReloadInterval is how often the IP filter file is checked for changes.
+const netFilterReloadInterval = 5 * time.Second
+
+// NetFilterInfo represents the IP networks the server is allowed to communicate
+// with. Allow is nil if all networks are allowed.
+type NetFilterInfo struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// NetFilter returns the current allow and deny lists of the server.
+func (srv *Server) NetFilter() (*NetFilterInfo, error) {
+	filter := srv.runningNetFilter()
+	if filter == nil {
+		return nil, errServerStopped
+	}
+	allow, deny := filter.Lists()
+
+	info := &NetFilterInfo{Deny: make([]string, 0, len(*deny))}
+	if allow != nil {
+		info.Allow = make([]string, 0, len(*allow))
+		for _, n := range *allow {
+			info.Allow = append(info.Allow, n.String())
+		}
+	}
+	for _, n := range *deny {
+		info.Deny = append(info.Deny, n.String())
+	}
+	return info, nil
+}
+
+// SetNetFilter replaces the allow and deny lists of the server. A nil allow list
+// allows all networks. Connected peers which are not accepted by the new lists are
+// disconnected.
+func (srv *Server) SetNetFilter(allow, deny *netutil.Netlist) error {
+	filter := srv.runningNetFilter()
+	if filter == nil {
+		return errServerStopped
+	}
+	filter.SetLists(allow, deny)
+	srv.dropFilteredPeers()
+	return nil
+}
+
+// AddAllowedNet adds a network to the allow list. If all networks were allowed
+// before, only the given network is allowed afterwards and peers outside of it
+// are disconnected.
+func (srv *Server) AddAllowedNet(n net.IPNet) error {
+	filter := srv.runningNetFilter()
+	if filter == nil {
+		return errServerStopped
+	}
+	filter.AddAllowed(n)
+	srv.dropFilteredPeers()
+	return nil
+}
+
+// RemoveAllowedNet removes a network from the allow list, returning whether it
+// was present. Peers within the network are disconnected unless the allow list
+// becomes empty, in which case all networks are allowed.
+func (srv *Server) RemoveAllowedNet(n net.IPNet) (bool, error) {
+	filter := srv.runningNetFilter()
+	if filter == nil {
+		return false, errServerStopped
+	}
+	if !filter.RemoveAllowed(n) {
+		return false, nil
+	}
+	srv.dropFilteredPeers()
+	return true, nil
+}
+
+// AddDeniedNet adds a network to the deny list and disconnects any peers within it.
+func (srv *Server) AddDeniedNet(n net.IPNet) error {
+	filter := srv.runningNetFilter()
+	if filter == nil {
+		return errServerStopped
+	}
+	filter.AddDenied(n)
+	srv.dropFilteredPeers()
+	return nil
+}
+
+// RemoveDeniedNet removes a network from the deny list, returning whether it was
+// present.
+func (srv *Server) RemoveDeniedNet(n net.IPNet) (bool, error) {
+	filter := srv.runningNetFilter()
+	if filter == nil {
+		return false, errServerStopped
+	}
+	return filter.RemoveDenied(n), nil
+}
+
+// runningNetFilter returns the IP filter of the server, or nil if the server is
+// not running.
+func (srv *Server) runningNetFilter() *netutil.IPFilter {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if !srv.running {
+		return nil
+	}
+	return srv.netfilter
+}
+
+// dropFilteredPeers disconnects all peers which are not accepted by the IP filter.
+func (srv *Server) dropFilteredPeers() {
+	srv.doPeerOp(func(peers map[enode.ID]*Peer) {
+		for _, p := range peers {
+			if srv.connFiltered(p.rw) {
+				p.log.Debug("Disconnecting filtered peer", "addr", p.RemoteAddr())
+				p.Disconnect(DiscRequested)
+			}
+		}
+	})
+}
+
+// netFilterFile is the format of the IP filter file. An empty or missing allow
+// list allows all networks.
+type netFilterFile struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// loadNetFilterFile reads the allow and deny lists from an IP filter file.
+func loadNetFilterFile(path string) (allow, deny *netutil.Netlist, err error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var file netFilterFile
+	if err := json.Unmarshal(blob, &file); err != nil {
+		return nil, nil, fmt.Errorf("invalid IP filter file %s: %v", path, err)
+	}
+	parse := func(masks []string) (*netutil.Netlist, error) {
+		list := make(netutil.Netlist, 0, len(masks))
+		for _, mask := range masks {
+			_, n, err := net.ParseCIDR(mask)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IP filter file %s: %v", path, err)
+			}
+			list = append(list, *n)
+		}
+		return &list, nil
+	}
+	if len(file.Allow) > 0 {
+		if allow, err = parse(file.Allow); err != nil {
+			return nil, nil, err
+		}
+	}
+	if deny, err = parse(file.Deny); err != nil {
+		return nil, nil, err
+	}
+	return allow, deny, nil
+}
+
+// setupNetFilterFile loads the IP filter file, returning its file info for
+// watchNetFilterFile to detect changes against.
+func (srv *Server) setupNetFilterFile() (os.FileInfo, error) {
+	stat, err := os.Stat(srv.NetFilterFile)
+	if err != nil {
+		return nil, err
+	}
+	allow, deny, err := loadNetFilterFile(srv.NetFilterFile)
+	if err != nil {
+		return nil, err
+	}
+	srv.netfilter.SetLists(allow, deny)
+	return stat, nil
+}
+
+// watchNetFilterFile runs in its own goroutine and reloads the IP filter file
+// whenever it changes.
+func (srv *Server) watchNetFilterFile(last os.FileInfo) {
+	defer srv.loopWG.Done()
+
+	tick := time.NewTicker(netFilterReloadInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			stat, err := os.Stat(srv.NetFilterFile)
+			if err != nil {
+				srv.log.Warn("Failed to check IP filter file", "path", srv.NetFilterFile, "err", err)
+				continue
+			}
+			if stat.ModTime().Equal(last.ModTime()) && stat.Size() == last.Size() {
+				continue
+			}
+			last = stat
+
+			allow, deny, err := loadNetFilterFile(srv.NetFilterFile)
+			if err != nil {
+				srv.log.Warn("Failed to reload IP filter file", "err", err)
+				continue
+			}
+			srv.log.Info("Reloaded IP filter file", "path", srv.NetFilterFile)
+			srv.SetNetFilter(allow, deny)
+
+		case <-srv.quit:
+			return
+		}
+	}
+}
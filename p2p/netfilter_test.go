@@ -0,0 +1,160 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// This test checks that connected peers are dropped when their network gets
+// denied, and that further inbound connections are refused.
+func TestServerNetFilterDisconnect(t *testing.T) {
+	srv1 := &Server{Config: Config{
+		PrivateKey:  newkey(),
+		MaxPeers:    1,
+		NoDiscovery: true,
+		Logger:      testlog.Logger(t, log.LvlTrace).New("server", "1"),
+	}}
+	srv2 := &Server{Config: Config{
+		PrivateKey:  newkey(),
+		MaxPeers:    1,
+		NoDiscovery: true,
+		NoDial:      true,
+		ListenAddr:  "127.0.0.1:0",
+		Logger:      testlog.Logger(t, log.LvlTrace).New("server", "2"),
+	}}
+	srv1.Start()
+	defer srv1.Stop()
+	srv2.Start()
+	defer srv2.Stop()
+
+	ch := make(chan *PeerEvent, 1)
+	sub := srv2.SubscribeEvents(ch)
+	defer sub.Unsubscribe()
+
+	// Wait for the inbound peer to be added on the listening side.
+	srv1.AddPeer(srv2.Self())
+	timeout := time.After(5 * time.Second)
+	select {
+	case ev := <-ch:
+		if ev.Type != PeerEventTypeAdd {
+			t.Fatalf("unexpected peer event %v", ev.Type)
+		}
+	case <-timeout:
+		t.Fatal("peer not connected")
+	}
+	if err := srv2.AddDeniedNet(net.IPNet{IP: net.IP{127, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}); err != nil {
+		t.Fatal(err)
+	}
+	for dropped := false; !dropped; {
+		select {
+		case ev := <-ch:
+			dropped = ev.Type == PeerEventTypeDrop
+		case <-timeout:
+			t.Fatal("denied peer not disconnected")
+		}
+	}
+	if err := srv2.checkInboundConn(net.IP{127, 0, 0, 1}); err == nil {
+		t.Fatal("inbound connection from denied network accepted")
+	}
+	if ok, err := srv2.RemoveDeniedNet(net.IPNet{IP: net.IP{127, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}); !ok || err != nil {
+		t.Fatalf("failed to remove denied network: %v %v", ok, err)
+	}
+	if err := srv2.checkInboundConn(net.IP{127, 0, 0, 1}); err != nil {
+		t.Fatalf("inbound connection rejected after removing network from deny list: %v", err)
+	}
+}
+
+func TestServerNetFilterFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netfilter.json")
+	if err := os.WriteFile(path, []byte(`{"deny": ["10.0.0.0/8", "192.168.1.0/24"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{Config: Config{
+		PrivateKey:    newkey(),
+		MaxPeers:      1,
+		NoDiscovery:   true,
+		NoDial:        true,
+		NetFilterFile: path,
+		Logger:        testlog.Logger(t, log.LvlTrace),
+	}}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	have, err := srv.NetFilter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &NetFilterInfo{Deny: []string{"10.0.0.0/8", "192.168.1.0/24"}}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("filter mismatch: have %+v, want %+v", have, want)
+	}
+	// Invalid files should be rejected
+	for _, content := range []string{`{"allow": ["10.0.0.1"]}`, `{"deny": 1}`} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := loadNetFilterFile(path); err == nil {
+			t.Errorf("invalid filter file %s accepted", content)
+		}
+	}
+}
+
+// This test checks that the IP filter file is not watched if the server fails
+// to start.
+func TestServerNetFilterFileStartFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netfilter.json")
+	if err := os.WriteFile(path, []byte(`{"deny": ["10.0.0.0/8"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{Config: Config{
+		PrivateKey:    newkey(),
+		MaxPeers:      1,
+		NoDiscovery:   true,
+		NoDial:        true,
+		ListenAddr:    "localhost:0",
+		NetFilterFile: path,
+		Logger:        testlog.Logger(t, log.LvlTrace),
+	}}
+	srv.listenFunc = func(network, addr string) (net.Listener, error) {
+		return nil, errors.New("listen failed")
+	}
+	if err := srv.Start(); err == nil {
+		t.Fatal("server started despite listen failure")
+	}
+	done := make(chan struct{})
+	go func() {
+		srv.loopWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("goroutines still running after failed start")
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netutil
+
+import (
+	"net"
+	"sync"
+)
+
+// IPFilter is a pair of IP network lists deciding which hosts may be communicated
+// with. An IP is accepted if it is contained in the allow list, or no allow list is
+// set, and it is not contained in the deny list. Both lists can be replaced while
+// the filter is in use.
+//
+// A nil filter accepts all IPs.
+type IPFilter struct {
+	lock  sync.RWMutex
+	allow *Netlist // nil means all IPs are allowed
+	deny  Netlist
+}
+
+// NewIPFilter creates a filter from the given lists. Either list may be nil.
+func NewIPFilter(allow, deny *Netlist) *IPFilter {
+	f := new(IPFilter)
+	f.SetLists(allow, deny)
+	return f
+}
+
+// Accept reports whether the filter admits the given IP.
+func (f *IPFilter) Accept(ip net.IP) bool {
+	return f.Allowed(ip) && !f.Denied(ip)
+}
+
+// Allowed reports whether the IP is contained in the allow list. It returns true
+// if there is no allow list.
+func (f *IPFilter) Allowed(ip net.IP) bool {
+	if f == nil {
+		return true
+	}
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.allow == nil || f.allow.Contains(ip)
+}
+
+// Denied reports whether the IP is contained in the deny list.
+func (f *IPFilter) Denied(ip net.IP) bool {
+	if f == nil {
+		return false
+	}
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.deny.Contains(ip)
+}
+
+// Lists returns copies of the allow and deny lists. The allow list is nil if
+// all IPs are allowed.
+func (f *IPFilter) Lists() (allow, deny *Netlist) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return copyNetlist(f.allow), copyNetlist(&f.deny)
+}
+
+// SetLists replaces both lists of the filter. A nil allow list allows all IPs.
+func (f *IPFilter) SetLists(allow, deny *Netlist) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.allow = copyNetlist(allow)
+	f.deny = nil
+	if deny != nil {
+		f.deny = *copyNetlist(deny)
+	}
+}
+
+// AddAllowed adds a network to the allow list. If there was no allow list before,
+// only the given network is allowed afterwards.
+func (f *IPFilter) AddAllowed(n net.IPNet) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.allow == nil {
+		f.allow = new(Netlist)
+	}
+	f.allow.add(n)
+}
+
+// RemoveAllowed removes a network from the allow list, reporting whether it was
+// present. Removing the last network drops the allow list, allowing all IPs.
+func (f *IPFilter) RemoveAllowed(n net.IPNet) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.allow.remove(n) {
+		return false
+	}
+	if len(*f.allow) == 0 {
+		f.allow = nil
+	}
+	return true
+}
+
+// AddDenied adds a network to the deny list.
+func (f *IPFilter) AddDenied(n net.IPNet) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.deny.add(n)
+}
+
+// RemoveDenied removes a network from the deny list, reporting whether it was
+// present.
+func (f *IPFilter) RemoveDenied(n net.IPNet) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.deny.remove(n)
+}
+
+// add appends a network to the list unless it is already present.
+func (l *Netlist) add(n net.IPNet) {
+	for _, have := range *l {
+		if have.String() == n.String() {
+			return
+		}
+	}
+	*l = append(*l, n)
+}
+
+// remove deletes a network from the list, reporting whether it was present.
+func (l *Netlist) remove(n net.IPNet) bool {
+	if l == nil {
+		return false
+	}
+	for i, have := range *l {
+		if have.String() == n.String() {
+			*l = append((*l)[:i], (*l)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func copyNetlist(l *Netlist) *Netlist {
+	if l == nil {
+		return nil
+	}
+	cpy := make(Netlist, len(*l))
+	copy(cpy, *l)
+	return &cpy
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netutil
+
+import (
+	"net"
+	"testing"
+)
+
+func mustParseCIDR(s string) net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return *n
+}
+
+func TestNilIPFilter(t *testing.T) {
+	var f *IPFilter
+	if !f.Accept(net.ParseIP("1.2.3.4")) {
+		t.Fatal("nil filter rejected IP")
+	}
+}
+
+func TestIPFilter(t *testing.T) {
+	var (
+		lan     = net.ParseIP("10.0.1.1")
+		denied  = net.ParseIP("10.0.2.1")
+		foreign = net.ParseIP("1.2.3.4")
+	)
+	f := NewIPFilter(nil, nil)
+	for _, ip := range []net.IP{lan, denied, foreign} {
+		if !f.Accept(ip) {
+			t.Fatalf("empty filter rejected %v", ip)
+		}
+	}
+	// Allowing a network should restrict communication to it
+	f.AddAllowed(mustParseCIDR("10.0.0.0/8"))
+	if !f.Accept(lan) || !f.Accept(denied) || f.Accept(foreign) {
+		t.Fatal("allow list not applied")
+	}
+	// Denying a subnet should take precedence over the allow list
+	f.AddDenied(mustParseCIDR("10.0.2.0/24"))
+	f.AddDenied(mustParseCIDR("10.0.2.0/24"))
+	if !f.Accept(lan) || f.Accept(denied) || f.Accept(foreign) {
+		t.Fatal("deny list not applied")
+	}
+	if allow, deny := f.Lists(); len(*allow) != 1 || len(*deny) != 1 {
+		t.Fatalf("unexpected lists: allow %v, deny %v", allow, deny)
+	}
+	// Removing the last allowed network should allow everything again
+	if f.RemoveAllowed(mustParseCIDR("192.168.0.0/16")) {
+		t.Fatal("removed unknown network")
+	}
+	if !f.RemoveAllowed(mustParseCIDR("10.0.0.0/8")) {
+		t.Fatal("failed to remove allowed network")
+	}
+	if !f.Accept(foreign) || f.Accept(denied) {
+		t.Fatal("allow list not dropped")
+	}
+	if allow, _ := f.Lists(); allow != nil {
+		t.Fatalf("allow list not dropped: %v", allow)
+	}
+	if !f.RemoveDenied(mustParseCIDR("10.0.2.0/24")) || !f.Accept(denied) {
+		t.Fatal("failed to remove denied network")
+	}
+}
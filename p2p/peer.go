@@ -117,6 +117,9 @@ type Peer struct {
 	// events receives message send / receive events if set
 	events   *event.Feed
 	testPipe *MsgPipeRW // for testing
+
+	// reputation receives behaviour reports about the peer if set
+	reputation *reputation
 }
 
 // NewPeer returns a peer for testing purposes.
@@ -238,6 +241,19 @@ func newPeer(log log.Logger, conn *conn, protocols []Protocol) *Peer {
 	return p
 }
 
+// Report feeds an observation about the behaviour of the peer into the reputation
+// service of the server. If the peer's score drops too low, it is disconnected and
+// banned for a while, unless it is a trusted or static peer.
+func (p *Peer) Report(ev ReputationEvent) {
+	if p.reputation == nil {
+		return
+	}
+	if p.reputation.report(p.ID(), ev) && !p.rw.is(trustedConn|staticDialedConn) {
+		p.log.Debug("Dropping banned peer", "event", ev)
+		p.Disconnect(DiscUselessPeer)
+	}
+}
+
 func (p *Peer) Log() log.Logger {
 	return p.log
 }
@@ -0,0 +1,310 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+const (
+	// Scores are kept within these bounds, so a long history of good behaviour
+	// cannot offset arbitrary amounts of misbehaviour and vice versa.
+	maxReputation = 100
+	minReputation = -100
+
+	// Scores decay towards zero with this half-life, so past behaviour is
+	// gradually forgotten.
+	reputationHalfLife = time.Hour
+
+	// Peers whose score drops to or below banThreshold are disconnected and
+	// refused for banDuration.
+	banThreshold = -50
+	banDuration  = time.Hour
+
+	// Discovered nodes with a score below dialThreshold are not dialed, while
+	// nodes with a score of at least goodThreshold are fed to the dialer from
+	// the node database on startup.
+	dialThreshold = -25
+	goodThreshold = 10
+
+	// Scores are kept in memory while they change, and written to the node
+	// database when the peer disconnects or at least this often.
+	reputationFlushInterval = time.Minute
+)
+
+// ReputationEvent is an observation about the behaviour of a remote peer, made by
+// a protocol handler. Events are fed into the reputation service of the server
+// via Peer.Report.
+type ReputationEvent int
+
+const (
+	// UsefulResponse is reported when a peer answers a request with valid data.
+	UsefulResponse ReputationEvent = iota
+
+	// SlowResponse is reported when a peer fails to answer a request in time.
+	SlowResponse
+
+	// UselessAnnouncement is reported when a peer announces data that we have
+	// no use for, e.g. block propagation after the merge.
+	UselessAnnouncement
+
+	// InvalidMessage is reported when a peer violates the wire protocol, e.g.
+	// by sending undecodable messages or responses failing verification.
+	InvalidMessage
+
+	// InvalidBlock is reported when a peer delivers invalid chain data.
+	InvalidBlock
+)
+
+// reputationWeights maps each event to the score adjustment it causes.
+var reputationWeights = map[ReputationEvent]float64{
+	UsefulResponse:      1,
+	SlowResponse:        -5,
+	UselessAnnouncement: -2,
+	InvalidMessage:      -25,
+	InvalidBlock:        -50,
+}
+
+func (ev ReputationEvent) String() string {
+	switch ev {
+	case UsefulResponse:
+		return "useful response"
+	case SlowResponse:
+		return "slow response"
+	case UselessAnnouncement:
+		return "useless announcement"
+	case InvalidMessage:
+		return "invalid message"
+	case InvalidBlock:
+		return "invalid block"
+	default:
+		return fmt.Sprintf("unknown event %d", int(ev))
+	}
+}
+
+// PeerScoreInfo represents a short summary of the reputation of a node.
+type PeerScoreInfo struct {
+	ID          string     `json:"id"`                    // Unique node identifier
+	Score       float64    `json:"score"`                 // Current (decayed) reputation score
+	Connected   bool       `json:"connected"`             // Whether the node is currently a peer
+	BannedUntil *time.Time `json:"bannedUntil,omitempty"` // Expiry of an active ban, if any
+}
+
+// reputation tracks the behaviour of remote nodes, persisting scores and bans in
+// the node database so they survive restarts. Score updates are cached in memory
+// and written out by flush, keeping the database off the peer hot path. Bans are
+// rare and written immediately.
+type reputation struct {
+	db  *enode.DB
+	log log.Logger
+	now func() time.Time // wall clock, overridable for tests
+
+	scores map[enode.ID]*reputationScore // scores updated since the last flush
+	lock   sync.Mutex                    // protects scores
+}
+
+// reputationScore is the cached score of a node.
+type reputationScore struct {
+	score   float64
+	updated time.Time
+}
+
+func newReputation(db *enode.DB, log log.Logger) *reputation {
+	return &reputation{
+		db:     db,
+		log:    log,
+		now:    time.Now,
+		scores: make(map[enode.ID]*reputationScore),
+	}
+}
+
+// report applies an event to the score of a node. It returns whether the node
+// is banned, either as a result of this event or from earlier misbehaviour.
+func (r *reputation) report(id enode.ID, ev ReputationEvent) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+	score := r.scoreLocked(id, now) + reputationWeights[ev]
+	score = math.Max(minReputation, math.Min(maxReputation, score))
+	r.scores[id] = &reputationScore{score: score, updated: now}
+
+	if r.db.BanExpiry(id).After(now) {
+		return true
+	}
+	if score > banThreshold {
+		return false
+	}
+	r.log.Debug("Banning misbehaving node", "id", id, "score", score, "event", ev, "duration", banDuration)
+	r.db.UpdateBanExpiry(id, now.Add(banDuration))
+	return true
+}
+
+// flush writes the cached score of a node to the database and drops it from
+// memory. It is called when the node disconnects.
+func (r *reputation) flush(id enode.ID) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if s := r.scores[id]; s != nil {
+		r.db.UpdateReputation(id, s.score, s.updated)
+		delete(r.scores, id)
+	}
+}
+
+// flushAll writes all cached scores to the database and drops them from memory.
+func (r *reputation) flushAll() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for id, s := range r.scores {
+		r.db.UpdateReputation(id, s.score, s.updated)
+	}
+	r.scores = make(map[enode.ID]*reputationScore)
+}
+
+// score returns the reputation of a node, decayed up to the given time.
+func (r *reputation) score(id enode.ID, now time.Time) float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.scoreLocked(id, now)
+}
+
+func (r *reputation) scoreLocked(id enode.ID, now time.Time) float64 {
+	var (
+		score   float64
+		updated time.Time
+	)
+	if s := r.scores[id]; s != nil {
+		score, updated = s.score, s.updated
+	} else {
+		score, updated = r.db.Reputation(id)
+	}
+	if score == 0 || !now.After(updated) {
+		return score
+	}
+	return score * math.Exp2(-float64(now.Sub(updated))/float64(reputationHalfLife))
+}
+
+// banned returns whether a node is currently banned.
+func (r *reputation) banned(id enode.ID) bool {
+	return r.db.BanExpiry(id).After(r.now())
+}
+
+// checkDial returns an error if a discovered node should not be dialed because
+// of its reputation.
+func (r *reputation) checkDial(id enode.ID) error {
+	now := r.now()
+	if r.db.BanExpiry(id).After(now) {
+		return errBanned
+	}
+	if r.score(id, now) < dialThreshold {
+		return errLowReputation
+	}
+	return nil
+}
+
+// nodes returns the IDs of all nodes with a reputation, either cached or in the
+// database.
+func (r *reputation) nodes() []enode.ID {
+	var (
+		ids    = r.db.ReputationNodes()
+		stored = make(map[enode.ID]bool, len(ids))
+	)
+	for _, id := range ids {
+		stored[id] = true
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for id := range r.scores {
+		if !stored[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// goodNodes returns the records of all nodes in the database that have a good
+// reputation, best first.
+func (r *reputation) goodNodes() []*enode.Node {
+	var (
+		now    = r.now()
+		nodes  []*enode.Node
+		scores = make(map[enode.ID]float64)
+	)
+	for _, id := range r.nodes() {
+		score := r.score(id, now)
+		if score < goodThreshold || r.db.BanExpiry(id).After(now) {
+			continue
+		}
+		if n := r.db.Node(id); n != nil {
+			nodes = append(nodes, n)
+			scores[id] = score
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return scores[nodes[i].ID()] > scores[nodes[j].ID()]
+	})
+	return nodes
+}
+
+// info gathers the reputation of all known nodes, including connected peers
+// which have no recorded reputation yet, best first.
+func (r *reputation) info(connected map[enode.ID]bool) []*PeerScoreInfo {
+	var (
+		now   = r.now()
+		seen  = make(map[enode.ID]bool)
+		infos []*PeerScoreInfo
+	)
+	add := func(id enode.ID) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		info := &PeerScoreInfo{
+			ID:        id.String(),
+			Score:     r.score(id, now),
+			Connected: connected[id],
+		}
+		if expiry := r.db.BanExpiry(id); expiry.After(now) {
+			info.BannedUntil = &expiry
+		}
+		infos = append(infos, info)
+	}
+	for _, id := range r.nodes() {
+		add(id)
+	}
+	for id := range connected {
+		add(id)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Score != infos[j].Score {
+			return infos[i].Score > infos[j].Score
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
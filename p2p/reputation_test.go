@@ -0,0 +1,174 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func newTestReputation(t *testing.T) (*reputation, *time.Time) {
+	db, err := enode.OpenDB("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	now := time.Unix(1672531200, 0)
+	rep := newReputation(db, log.Root())
+	rep.now = func() time.Time { return now }
+	return rep, &now
+}
+
+func TestReputationScoring(t *testing.T) {
+	rep, now := newTestReputation(t)
+	id := enode.ID{0x01}
+
+	for i := 0; i < 8; i++ {
+		if rep.report(id, UsefulResponse) {
+			t.Fatalf("report %d: well behaved node banned", i)
+		}
+	}
+	if score := rep.score(id, *now); score != 8 {
+		t.Fatalf("score mismatch: have %v, want 8", score)
+	}
+	// Scores should halve every half-life
+	*now = now.Add(reputationHalfLife)
+	if score := rep.score(id, *now); math.Abs(score-4) > 1e-9 {
+		t.Fatalf("decayed score mismatch: have %v, want 4", score)
+	}
+	// Scores should be capped
+	for i := 0; i < 2*maxReputation; i++ {
+		rep.report(id, UsefulResponse)
+	}
+	if score := rep.score(id, *now); score != maxReputation {
+		t.Fatalf("capped score mismatch: have %v, want %v", score, maxReputation)
+	}
+}
+
+func TestReputationFlush(t *testing.T) {
+	rep, now := newTestReputation(t)
+	var (
+		a = enode.ID{0x01}
+		b = enode.ID{0x02}
+	)
+	rep.report(a, UsefulResponse)
+	rep.report(b, UsefulResponse)
+	rep.report(b, UsefulResponse)
+
+	// Scores should only be written to the database when flushed
+	if score, _ := rep.db.Reputation(a); score != 0 {
+		t.Fatalf("score stored before flush: %v", score)
+	}
+	if infos := rep.info(nil); len(infos) != 2 {
+		t.Fatalf("cached scores not reported: %v", infos)
+	}
+	rep.flush(a)
+	if score, _ := rep.db.Reputation(a); score != 1 {
+		t.Fatalf("flushed score mismatch: have %v, want 1", score)
+	}
+	if score, _ := rep.db.Reputation(b); score != 0 {
+		t.Fatalf("unflushed score stored: %v", score)
+	}
+	rep.flushAll()
+	if score, _ := rep.db.Reputation(b); score != 2 {
+		t.Fatalf("flushed score mismatch: have %v, want 2", score)
+	}
+	// Scores should continue from the stored value after flushing
+	rep.report(b, UsefulResponse)
+	if score := rep.score(b, *now); score != 3 {
+		t.Fatalf("score mismatch after flush: have %v, want 3", score)
+	}
+}
+
+func TestReputationBanning(t *testing.T) {
+	rep, now := newTestReputation(t)
+	var (
+		slow    = enode.ID{0x01}
+		invalid = enode.ID{0x02}
+	)
+	// A few slow responses should make the node undialable, but not banned
+	for i := 0; i < 6; i++ {
+		if rep.report(slow, SlowResponse) {
+			t.Fatalf("report %d: slow node banned", i)
+		}
+	}
+	if err := rep.checkDial(slow); err != errLowReputation {
+		t.Fatalf("slow node dial check mismatch: have %v, want %v", err, errLowReputation)
+	}
+	// Invalid blocks should result in an immediate ban
+	if !rep.report(invalid, InvalidBlock) {
+		t.Fatal("node delivering invalid block not banned")
+	}
+	if !rep.banned(invalid) {
+		t.Fatal("ban not recorded")
+	}
+	if err := rep.checkDial(invalid); err != errBanned {
+		t.Fatalf("banned node dial check mismatch: have %v, want %v", err, errBanned)
+	}
+	// Further events while banned should keep reporting the ban
+	if !rep.report(invalid, UsefulResponse) {
+		t.Fatal("banned node not reported as such")
+	}
+	infos := rep.info(map[enode.ID]bool{slow: true, {0x03}: true})
+	if len(infos) != 3 {
+		t.Fatalf("info count mismatch: have %d, want 3", len(infos))
+	}
+	if infos[0].ID != (enode.ID{0x03}).String() || infos[0].Score != 0 || !infos[0].Connected {
+		t.Errorf("unscored peer info mismatch: %+v", infos[0])
+	}
+	if infos[2].ID != invalid.String() || infos[2].BannedUntil == nil || infos[2].Connected {
+		t.Errorf("banned node info mismatch: %+v", infos[2])
+	}
+	// Bans should lift after the ban duration, with the score having decayed
+	*now = now.Add(banDuration + time.Second)
+	if rep.banned(invalid) {
+		t.Fatal("ban not lifted")
+	}
+	if err := rep.checkDial(invalid); err != nil {
+		t.Fatalf("node undialable after ban expired: %v", err)
+	}
+}
+
+func TestReputationGoodNodes(t *testing.T) {
+	rep, _ := newTestReputation(t)
+
+	var nodes []*enode.Node
+	for i := 0; i < 3; i++ {
+		n := newNode(enode.ID{byte(i + 1)}, "127.0.0.1:30303")
+		if err := rep.db.UpdateNode(n); err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, n)
+	}
+	for i := 0; i < 2*goodThreshold; i++ {
+		rep.report(nodes[2].ID(), UsefulResponse)
+	}
+	for i := 0; i < goodThreshold; i++ {
+		rep.report(nodes[1].ID(), UsefulResponse)
+	}
+	rep.report(nodes[0].ID(), UsefulResponse)
+
+	good := rep.goodNodes()
+	if len(good) != 2 || good[0].ID() != nodes[2].ID() || good[1].ID() != nodes[1].ID() {
+		t.Fatalf("good node mismatch: %v", good)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -119,6 +120,15 @@ type Config struct {
 	// IP networks contained in the list are considered.
 	NetRestrict *netutil.Netlist `toml:",omitempty"`
 
+	// NetDeny lists IP networks which are never communicated with. It takes
+	// precedence over NetRestrict.
+	NetDeny *netutil.Netlist `toml:",omitempty"`
+
+	// NetFilterFile is the path of a JSON file holding "allow" and "deny" lists of
+	// CIDR masks. If set, the file is watched while the server is running and its
+	// lists replace NetRestrict and NetDeny whenever it changes.
+	NetFilterFile string `toml:",omitempty"`
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string `toml:",omitempty"`
@@ -182,12 +192,14 @@ type Server struct {
 	peerFeed     event.Feed
 	log          log.Logger
 
-	nodedb    *enode.DB
-	localnode *enode.LocalNode
-	ntab      *discover.UDPv4
-	DiscV5    *discover.UDPv5
-	discmix   *enode.FairMix
-	dialsched *dialScheduler
+	netfilter  *netutil.IPFilter
+	nodedb     *enode.DB
+	reputation *reputation
+	localnode  *enode.LocalNode
+	ntab       *discover.UDPv4
+	DiscV5     *discover.UDPv5
+	discmix    *enode.FairMix
+	dialsched  *dialScheduler
 
 	// Channels into the run loop.
 	quit                    chan struct{}
@@ -474,6 +486,13 @@ func (srv *Server) Start() (err error) {
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
 
+	srv.netfilter = netutil.NewIPFilter(srv.NetRestrict, srv.NetDeny)
+	var netFilterStat os.FileInfo
+	if srv.NetFilterFile != "" {
+		if netFilterStat, err = srv.setupNetFilterFile(); err != nil {
+			return err
+		}
+	}
 	if err := srv.setupLocalNode(); err != nil {
 		return err
 	}
@@ -487,6 +506,12 @@ func (srv *Server) Start() (err error) {
 	}
 	srv.setupDialScheduler()
 
+	// Only watch the IP filter file once startup can no longer fail, so the
+	// watcher can't outlive a failed start.
+	if netFilterStat != nil {
+		srv.loopWG.Add(1)
+		go srv.watchNetFilterFile(netFilterStat)
+	}
 	srv.loopWG.Add(1)
 	go srv.run()
 	return nil
@@ -507,6 +532,7 @@ func (srv *Server) setupLocalNode() error {
 		return err
 	}
 	srv.nodedb = db
+	srv.reputation = newReputation(db, srv.log)
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
 	// TODO: check conflicts
@@ -539,6 +565,11 @@ func (srv *Server) setupLocalNode() error {
 func (srv *Server) setupDiscovery() error {
 	srv.discmix = enode.NewFairMix(discmixTimeout)
 
+	// Prefer nodes which proved useful in previous sessions.
+	if good := srv.reputation.goodNodes(); len(good) > 0 {
+		srv.discmix.AddSource(enode.IterNodes(good))
+	}
+
 	// Add protocol-specific discovery sources.
 	added := make(map[string]bool)
 	for _, proto := range srv.Protocols {
@@ -591,11 +622,11 @@ func (srv *Server) setupDiscovery() error {
 			sconn = &sharedUDPConn{conn, unhandled}
 		}
 		cfg := discover.Config{
-			PrivateKey:  srv.PrivateKey,
-			NetRestrict: srv.NetRestrict,
-			Bootnodes:   srv.BootstrapNodes,
-			Unhandled:   unhandled,
-			Log:         srv.log,
+			PrivateKey: srv.PrivateKey,
+			NetFilter:  srv.netfilter,
+			Bootnodes:  srv.BootstrapNodes,
+			Unhandled:  unhandled,
+			Log:        srv.log,
 		}
 		ntab, err := discover.ListenV4(conn, srv.localnode, cfg)
 		if err != nil {
@@ -608,10 +639,10 @@ func (srv *Server) setupDiscovery() error {
 	// Discovery V5
 	if srv.DiscoveryV5 {
 		cfg := discover.Config{
-			PrivateKey:  srv.PrivateKey,
-			NetRestrict: srv.NetRestrict,
-			Bootnodes:   srv.BootstrapNodesV5,
-			Log:         srv.log,
+			PrivateKey: srv.PrivateKey,
+			NetFilter:  srv.netfilter,
+			Bootnodes:  srv.BootstrapNodesV5,
+			Log:        srv.log,
 		}
 		var err error
 		if sconn != nil {
@@ -632,7 +663,8 @@ func (srv *Server) setupDialScheduler() {
 		maxDialPeers:   srv.maxDialedConns(),
 		maxActiveDials: srv.MaxPendingPeers,
 		log:            srv.Logger,
-		netRestrict:    srv.NetRestrict,
+		netFilter:      srv.netfilter,
+		reputation:     srv.reputation,
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
 	}
@@ -714,7 +746,10 @@ func (srv *Server) run() {
 		peers        = make(map[enode.ID]*Peer)
 		inboundCount = 0
 		trusted      = make(map[enode.ID]bool, len(srv.TrustedNodes))
+		flush        = time.NewTicker(reputationFlushInterval)
 	)
+	defer flush.Stop()
+
 	// Put trusted nodes into a map to speed up checks.
 	// Trusted peers are loaded on startup or added via AddTrustedPeer RPC.
 	for _, n := range srv.TrustedNodes {
@@ -786,6 +821,11 @@ running:
 			if pd.Inbound() {
 				inboundCount--
 			}
+			srv.reputation.flush(pd.ID())
+
+		case <-flush.C:
+			// Persist the reputation of connected peers.
+			srv.reputation.flushAll()
 		}
 	}
 
@@ -810,6 +850,7 @@ running:
 		p.log.Trace("<-delpeer (spindown)")
 		delete(peers, p.ID())
 	}
+	srv.reputation.flushAll()
 }
 
 func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
@@ -822,11 +863,22 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 		return DiscAlreadyConnected
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
+	case !c.is(trustedConn|staticDialedConn) && srv.reputation.banned(c.node.ID()):
+		return DiscUselessPeer
+	case srv.connFiltered(c):
+		return DiscRequested
 	default:
 		return nil
 	}
 }
 
+// connFiltered reports whether the remote IP of a connection is rejected by the
+// IP filter. The filter may have changed while the connection was set up.
+func (srv *Server) connFiltered(c *conn) bool {
+	ip := netutil.AddrIP(c.fd.RemoteAddr())
+	return ip != nil && !srv.netfilter.Accept(ip)
+}
+
 func (srv *Server) addPeerChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	// Drop connections with no matching protocols.
 	if len(srv.Protocols) > 0 && countMatchingProtocols(srv.Protocols, c.caps) == 0 {
@@ -913,10 +965,15 @@ func (srv *Server) checkInboundConn(remoteIP net.IP) error {
 	if remoteIP == nil {
 		return nil
 	}
-	// Reject connections that do not match NetRestrict.
-	if srv.NetRestrict != nil && !srv.NetRestrict.Contains(remoteIP) {
+	// Reject connections that do not match NetRestrict or are denied.
+	if !srv.netfilter.Allowed(remoteIP) {
+		inboundRejectMeter.Mark(1)
 		return fmt.Errorf("not in netrestrict list")
 	}
+	if srv.netfilter.Denied(remoteIP) {
+		inboundRejectMeter.Mark(1)
+		return fmt.Errorf("in netdeny list")
+	}
 	// Reject Internet peers that try too often.
 	now := srv.clock.Now()
 	srv.inboundHistory.expire(now, nil)
@@ -1025,6 +1082,7 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 
 func (srv *Server) launchPeer(c *conn) *Peer {
 	p := newPeer(srv.log, c, srv.Protocols)
+	p.reputation = srv.reputation
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
@@ -1111,6 +1169,21 @@ func (srv *Server) NodeInfo() *NodeInfo {
 	return info
 }
 
+// PeerScores returns the reputation of all known nodes, including connected peers,
+// sorted by score.
+func (srv *Server) PeerScores() []*PeerScoreInfo {
+	connected := make(map[enode.ID]bool)
+	for _, peer := range srv.Peers() {
+		connected[peer.ID()] = true
+	}
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	if !srv.running {
+		return nil
+	}
+	return srv.reputation.info(connected)
+}
+
 // PeersInfo returns an array of metadata objects describing connected peers.
 func (srv *Server) PeersInfo() []*PeerInfo {
 	// Gather all the generic and sub-protocol specific infos
@@ -365,6 +365,58 @@ func TestServerPeerLimits(t *testing.T) {
 	conn.Close()
 }
 
+// This test checks that banned nodes are refused after the encryption handshake,
+// unless they are trusted.
+func TestServerBannedPeer(t *testing.T) {
+	var (
+		srvkey     = newkey()
+		clientkey  = newkey()
+		clientnode = enode.NewV4(&clientkey.PublicKey, nil, 0, 0)
+		tp         = &setupTransport{pubkey: &clientkey.PublicKey, phs: protoHandshake{ID: crypto.FromECDSAPub(&clientkey.PublicKey)[1:]}}
+	)
+	srv := &Server{
+		Config: Config{
+			PrivateKey:  srvkey,
+			MaxPeers:    10,
+			NoDial:      true,
+			NoDiscovery: true,
+			Protocols:   []Protocol{discard},
+			Logger:      testlog.Logger(t, log.LvlTrace),
+		},
+		newTransport: func(fd net.Conn, dialDest *ecdsa.PublicKey) transport { return tp },
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("couldn't start server: %v", err)
+	}
+	defer srv.Stop()
+
+	if !srv.reputation.report(clientnode.ID(), InvalidBlock) {
+		t.Fatal("node not banned")
+	}
+	conn, _ := net.Pipe()
+	srv.SetupConn(conn, inboundConn, nil)
+	if tp.closeErr != DiscUselessPeer || tp.calls != "doEncHandshake,close," {
+		t.Errorf("banned node not rejected: err %v, calls %q", tp.closeErr, tp.calls)
+	}
+	conn.Close()
+
+	// Check that trusted nodes bypass the ban.
+	srv.AddTrustedPeer(clientnode)
+	tp.calls, tp.closeErr = "", nil
+
+	conn, _ = net.Pipe()
+	srv.SetupConn(conn, inboundConn, nil)
+	if tp.calls != "doEncHandshake,doProtoHandshake,close," {
+		t.Errorf("trusted banned node rejected: err %v, calls %q", tp.closeErr, tp.calls)
+	}
+	conn.Close()
+
+	scores := srv.PeerScores()
+	if len(scores) != 1 || scores[0].ID != clientnode.ID().String() || scores[0].BannedUntil == nil {
+		t.Errorf("peer scores mismatch: %+v", scores)
+	}
+}
+
 func TestServerSetupConn(t *testing.T) {
 	var (
 		clientkey, srvkey = newkey(), newkey()
@@ -0,0 +1,633 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultHealthCheckInterval = 5 * time.Second
+	healthCheckMethod          = "eth_blockNumber"
+)
+
+var errNoEndpoint = errors.New("no endpoint available")
+
+// defaultIdempotentMethods are the read-only methods which a balancing client
+// retries on another endpoint by default.
+var defaultIdempotentMethods = []string{
+	"eth_blockNumber", "eth_call", "eth_chainId", "eth_createAccessList", "eth_estimateGas",
+	"eth_feeHistory", "eth_gasPrice", "eth_getBalance", "eth_getBlockByHash",
+	"eth_getBlockByNumber", "eth_getBlockTransactionCountByHash",
+	"eth_getBlockTransactionCountByNumber", "eth_getCode", "eth_getHeaderByHash",
+	"eth_getHeaderByNumber", "eth_getLogs", "eth_getProof", "eth_getStorageAt",
+	"eth_getTransactionByBlockHashAndIndex", "eth_getTransactionByBlockNumberAndIndex",
+	"eth_getTransactionByHash", "eth_getTransactionCount", "eth_getTransactionReceipt",
+	"eth_getUncleByBlockHashAndIndex", "eth_getUncleByBlockNumberAndIndex",
+	"eth_getUncleCountByBlockHash", "eth_getUncleCountByBlockNumber",
+	"eth_maxPriorityFeePerGas", "eth_syncing", "net_listening", "net_peerCount",
+	"net_version", "rpc_modules", "web3_clientVersion",
+}
+
+// BalancePolicy selects which of the healthy endpoints of a balancing client serves
+// a request.
+type BalancePolicy int
+
+const (
+	// RoundRobin spreads requests evenly across the healthy endpoints.
+	RoundRobin BalancePolicy = iota
+
+	// LowestLatency sends requests to the healthy endpoint with the lowest average
+	// response time.
+	LowestLatency
+
+	// StickyByBlock keeps sending requests to the same endpoint for as long as it is
+	// healthy and at the highest known head block, so that consecutive requests
+	// observe a consistent chain state.
+	StickyByBlock
+)
+
+// BalancerConfig configures a client created by DialBalanced.
+type BalancerConfig struct {
+	Policy BalancePolicy // Selection of the endpoint serving a request
+
+	// HealthCheckInterval is the time between two checks of the endpoints' head
+	// block. If zero, endpoints are checked every five seconds.
+	HealthCheckInterval time.Duration
+
+	// MaxHeadLag is the number of blocks an endpoint may lag behind the highest head
+	// reported by the others while still being considered healthy.
+	MaxHeadLag uint64
+
+	// MaxAttempts is the number of endpoints an idempotent request is sent to before
+	// giving up, if sending it fails. If zero, every endpoint is tried once.
+	MaxAttempts int
+
+	// IdempotentMethods are the methods which may be retried on another endpoint. If
+	// nil, a default set of read-only methods is used.
+	IdempotentMethods []string
+}
+
+// DialBalanced creates a client which spreads requests across multiple endpoints.
+// The client can be used like any other, e.g. with ethclient.NewClient.
+//
+// The head block of every endpoint is checked periodically. Endpoints which fail to
+// respond, or which lag too far behind the others, are avoided until they recover.
+// Requests for idempotent methods are retried on another endpoint if sending them
+// fails, while errors returned by the server are passed on. Subscriptions are served
+// by a single endpoint and are moved to another one if it fails, in which case
+// notifications may be lost.
+//
+// The given options are used to dial every endpoint. DialBalanced fails only if none
+// of the endpoints can be reached, the others are dialed again on each health check.
+func DialBalanced(ctx context.Context, endpoints []string, config BalancerConfig, options ...ClientOption) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no endpoints given")
+	}
+	b := newBalancer(endpoints, config, options)
+	if err := b.checkHealth(ctx); err != nil {
+		b.close()
+		return nil, err
+	}
+	go b.loop()
+	return initClient(b, randomIDGenerator(), new(serviceRegistry), new(clientConfig), nil), nil
+}
+
+// forward sends a message received from another client. The response is returned
+// under the ID of the original message.
+func (c *Client) forward(ctx context.Context, msg *jsonrpcMessage) (*jsonrpcMessage, error) {
+	var (
+		fwd = &jsonrpcMessage{Version: vsn, Method: msg.Method, Params: msg.Params}
+		op  = new(requestOp)
+		err error
+	)
+	if msg.ID != nil {
+		fwd.ID = c.nextID()
+		op = &requestOp{ids: []json.RawMessage{fwd.ID}, resp: make(chan *jsonrpcMessage, 1)}
+	}
+	if c.isHTTP {
+		err = c.sendHTTP(ctx, op, fwd)
+	} else {
+		err = c.send(ctx, op, fwd)
+	}
+	if err != nil || fwd.ID == nil {
+		return nil, err
+	}
+	resp, err := op.wait(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonrpcMessage{Version: vsn, ID: msg.ID, Error: resp.Error, Result: resp.Result}, nil
+}
+
+// balancerEndpoint is a single endpoint of a balancer.
+type balancerEndpoint struct {
+	url string
+
+	// These fields are protected by the balancer lock.
+	client  *Client       // nil while the endpoint can't be dialed
+	alive   bool          // whether the endpoint responded to its last request
+	healthy bool          // whether the endpoint is alive and synced
+	head    uint64        // head block reported on the last health check
+	latency time.Duration // moving average of the response time
+}
+
+// balancerSub is a subscription relayed by a balancer.
+type balancerSub struct {
+	id        string        // subscription ID reported to the client
+	namespace string        // namespace of the subscribe method
+	args      []interface{} // subscribe method arguments
+	quit      chan struct{} // closed when the client unsubscribes
+}
+
+// balancer is a virtual connection forwarding the messages of a client to one of
+// multiple endpoints.
+type balancer struct {
+	config     BalancerConfig
+	options    []ClientOption
+	idempotent map[string]bool
+	endpoints  []*balancerEndpoint
+	idgen      func() ID
+
+	lock   sync.Mutex
+	next   int                     // round robin position
+	sticky *balancerEndpoint       // current endpoint of the sticky policy
+	subs   map[string]*balancerSub // relayed subscriptions by ID
+
+	resp      chan *jsonrpcMessage // messages to be read by the client
+	closeCh   chan interface{}
+	closeOnce sync.Once
+}
+
+func newBalancer(endpoints []string, config BalancerConfig, options []ClientOption) *balancer {
+	if config.HealthCheckInterval == 0 {
+		config.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if config.MaxAttempts <= 0 || config.MaxAttempts > len(endpoints) {
+		config.MaxAttempts = len(endpoints)
+	}
+	methods := config.IdempotentMethods
+	if methods == nil {
+		methods = defaultIdempotentMethods
+	}
+	b := &balancer{
+		config:     config,
+		options:    options,
+		idempotent: make(map[string]bool, len(methods)),
+		idgen:      randomIDGenerator(),
+		subs:       make(map[string]*balancerSub),
+		resp:       make(chan *jsonrpcMessage),
+		closeCh:    make(chan interface{}),
+	}
+	for _, method := range methods {
+		b.idempotent[method] = true
+	}
+	for _, url := range endpoints {
+		b.endpoints = append(b.endpoints, &balancerEndpoint{url: url})
+	}
+	return b
+}
+
+// loop checks the health of the endpoints periodically.
+func (b *balancer) loop() {
+	ticker := time.NewTicker(b.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), b.config.HealthCheckInterval)
+			b.checkHealth(ctx)
+			cancel()
+		case <-b.closeCh:
+			return
+		}
+	}
+}
+
+// checkHealth retrieves the head block of all endpoints, dialing them if needed,
+// and marks the ones which failed or fell behind as unhealthy. The error of an
+// arbitrary endpoint is returned if none of them responded.
+func (b *balancer) checkHealth(ctx context.Context) error {
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(b.endpoints))
+	)
+	for i, ep := range b.endpoints {
+		wg.Add(1)
+		go func(i int, ep *balancerEndpoint) {
+			defer wg.Done()
+			errs[i] = b.checkEndpoint(ctx, ep)
+		}(i, ep)
+	}
+	wg.Wait()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var best uint64
+	for _, ep := range b.endpoints {
+		if ep.alive && ep.head > best {
+			best = ep.head
+		}
+	}
+	for _, ep := range b.endpoints {
+		ep.healthy = ep.alive && ep.head+b.config.MaxHeadLag >= best
+	}
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return errs[0]
+}
+
+// checkEndpoint retrieves the head block of a single endpoint.
+func (b *balancer) checkEndpoint(ctx context.Context, ep *balancerEndpoint) error {
+	b.lock.Lock()
+	client := ep.client
+	b.lock.Unlock()
+
+	if client == nil {
+		c, err := DialOptions(ctx, ep.url, b.options...)
+		if err != nil {
+			b.fail(ep, err)
+			return err
+		}
+		b.lock.Lock()
+		select {
+		case <-b.closeCh:
+			b.lock.Unlock()
+			c.Close()
+			return ErrClientQuit
+		default:
+			ep.client, client = c, c
+		}
+		b.lock.Unlock()
+	}
+	var (
+		head  hexutil.Uint64
+		start = time.Now()
+	)
+	if err := client.CallContext(ctx, &head, healthCheckMethod); err != nil {
+		b.fail(ep, err)
+		return err
+	}
+	b.lock.Lock()
+	ep.head = uint64(head)
+	b.lock.Unlock()
+
+	b.observe(ep, time.Since(start))
+	return nil
+}
+
+// observe records a successful response of an endpoint.
+func (b *balancer) observe(ep *balancerEndpoint, elapsed time.Duration) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if ep.latency == 0 {
+		ep.latency = elapsed
+	} else {
+		ep.latency = (4*ep.latency + elapsed) / 5
+	}
+	ep.alive = true
+}
+
+// fail marks an endpoint as unhealthy until its next successful health check.
+func (b *balancer) fail(ep *balancerEndpoint, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if ep.alive {
+		log.Debug("RPC endpoint failed", "err", err)
+	}
+	ep.alive, ep.healthy = false, false
+}
+
+// pick selects the endpoint serving the next request according to the balancing
+// policy. Endpoints in exclude are skipped, as well as the ones not supporting
+// subscriptions if requested.
+func (b *balancer) pick(exclude map[*balancerEndpoint]bool, subscribe bool) (*balancerEndpoint, *Client) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var candidates, fallback []*balancerEndpoint
+	for _, ep := range b.endpoints {
+		if ep.client == nil || exclude[ep] || (subscribe && ep.client.isHTTP) {
+			continue
+		}
+		if ep.healthy {
+			candidates = append(candidates, ep)
+		} else {
+			fallback = append(fallback, ep)
+		}
+	}
+	// Trying an unhealthy endpoint is still better than failing right away.
+	if len(candidates) == 0 {
+		candidates = fallback
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	var ep *balancerEndpoint
+	switch b.config.Policy {
+	case LowestLatency:
+		ep = candidates[0]
+		for _, c := range candidates[1:] {
+			if c.latency < ep.latency {
+				ep = c
+			}
+		}
+	case StickyByBlock:
+		best, current := candidates[0], false
+		for _, c := range candidates {
+			if c.head > best.head || (c.head == best.head && c.latency < best.latency) {
+				best = c
+			}
+			current = current || c == b.sticky
+		}
+		if !current || b.sticky.head < best.head {
+			b.sticky = best
+		}
+		ep = b.sticky
+	default:
+		ep = candidates[b.next%len(candidates)]
+		b.next++
+	}
+	return ep, ep.client
+}
+
+// call forwards a call or notification, retrying idempotent calls on other endpoints
+// if sending them fails.
+func (b *balancer) call(ctx context.Context, msg *jsonrpcMessage) *jsonrpcMessage {
+	attempts := 1
+	if b.idempotent[msg.Method] {
+		attempts = b.config.MaxAttempts
+	}
+	var (
+		tried = make(map[*balancerEndpoint]bool)
+		err   = errNoEndpoint
+	)
+	for i := 0; i < attempts; i++ {
+		ep, client := b.pick(tried, false)
+		if ep == nil {
+			break
+		}
+		tried[ep] = true
+
+		var (
+			resp  *jsonrpcMessage
+			start = time.Now()
+		)
+		if resp, err = client.forward(ctx, msg); err == nil {
+			b.observe(ep, time.Since(start))
+			return resp
+		}
+		if ctx.Err() != nil {
+			break // The caller gave up
+		}
+		b.fail(ep, err)
+	}
+	return msg.errorResponse(err)
+}
+
+// subscribe establishes a subscription on one of the endpoints and starts relaying
+// its notifications. The response is delivered before the first notification.
+func (b *balancer) subscribe(msg *jsonrpcMessage) *jsonrpcMessage {
+	var args []json.RawMessage
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &args); err != nil {
+			return msg.errorResponse(&invalidParamsError{err.Error()})
+		}
+	}
+	sub := &balancerSub{
+		id:        string(b.idgen()),
+		namespace: msg.namespace(),
+		args:      make([]interface{}, len(args)),
+		quit:      make(chan struct{}),
+	}
+	for i, arg := range args {
+		sub.args[i] = arg
+	}
+	ep, csub, ch, err := b.subscribeEndpoint(sub)
+	if err != nil {
+		return msg.errorResponse(err)
+	}
+	b.lock.Lock()
+	b.subs[sub.id] = sub
+	b.lock.Unlock()
+
+	if !b.deliver(msg.response(sub.id)) {
+		csub.Unsubscribe()
+		return nil
+	}
+	go b.relay(sub, ep, csub, ch)
+	return nil
+}
+
+// subscribeEndpoint establishes a subscription on the first endpoint accepting it.
+func (b *balancer) subscribeEndpoint(sub *balancerSub) (*balancerEndpoint, *ClientSubscription, chan json.RawMessage, error) {
+	var (
+		tried = make(map[*balancerEndpoint]bool)
+		err   = errNoEndpoint
+	)
+	for {
+		ep, client := b.pick(tried, true)
+		if ep == nil {
+			return nil, nil, nil, err
+		}
+		tried[ep] = true
+
+		var (
+			ch          = make(chan json.RawMessage)
+			csub        *ClientSubscription
+			ctx, cancel = context.WithTimeout(context.Background(), subscribeTimeout)
+		)
+		csub, err = client.Subscribe(ctx, sub.namespace, ch, sub.args...)
+		cancel()
+		if err == nil {
+			return ep, csub, ch, nil
+		}
+		// Don't try other endpoints if the subscription was rejected.
+		var rpcErr Error
+		if errors.As(err, &rpcErr) {
+			return nil, nil, nil, err
+		}
+		b.fail(ep, err)
+	}
+}
+
+// relay forwards the notifications of a subscription to the client, moving the
+// subscription to another endpoint if the current one fails.
+func (b *balancer) relay(sub *balancerSub, ep *balancerEndpoint, csub *ClientSubscription, ch chan json.RawMessage) {
+	for {
+		select {
+		case result := <-ch:
+			params, _ := json.Marshal(&subscriptionResult{ID: sub.id, Result: result})
+			note := &jsonrpcMessage{Version: vsn, Method: sub.namespace + notificationMethodSuffix, Params: params}
+			if !b.deliver(note) {
+				csub.Unsubscribe()
+				return
+			}
+
+		case err := <-csub.Err():
+			select {
+			case <-b.closeCh:
+				return
+			default:
+			}
+			if err == nil {
+				err = ErrClientQuit
+			}
+			b.fail(ep, err)
+			for {
+				var rerr error
+				if ep, csub, ch, rerr = b.subscribeEndpoint(sub); rerr == nil {
+					break
+				}
+				log.Debug("RPC subscription failed to move", "err", rerr)
+				select {
+				case <-time.After(b.config.HealthCheckInterval):
+				case <-sub.quit:
+					return
+				case <-b.closeCh:
+					return
+				}
+			}
+
+		case <-sub.quit:
+			csub.Unsubscribe()
+			return
+
+		case <-b.closeCh:
+			return
+		}
+	}
+}
+
+// unsubscribe ends a relayed subscription.
+func (b *balancer) unsubscribe(msg *jsonrpcMessage) *jsonrpcMessage {
+	var args []string
+	if err := json.Unmarshal(msg.Params, &args); err != nil || len(args) != 1 {
+		return msg.errorResponse(&invalidParamsError{"expected subscription id as only argument"})
+	}
+	b.lock.Lock()
+	sub := b.subs[args[0]]
+	delete(b.subs, args[0])
+	b.lock.Unlock()
+
+	if sub == nil {
+		return msg.errorResponse(ErrSubscriptionNotFound)
+	}
+	close(sub.quit)
+	return msg.response(true)
+}
+
+// deliver hands a message to the client's read loop.
+func (b *balancer) deliver(msg *jsonrpcMessage) bool {
+	select {
+	case b.resp <- msg:
+		return true
+	case <-b.closeCh:
+		return false
+	}
+}
+
+// handle processes a single message sent by the client.
+func (b *balancer) handle(ctx context.Context, msg *jsonrpcMessage) {
+	var resp *jsonrpcMessage
+	switch {
+	case msg.isSubscribe():
+		resp = b.subscribe(msg)
+	case msg.isUnsubscribe():
+		resp = b.unsubscribe(msg)
+	default:
+		resp = b.call(ctx, msg)
+	}
+	if resp != nil && msg.isCall() {
+		b.deliver(resp)
+	}
+}
+
+func (b *balancer) writeJSON(ctx context.Context, v interface{}, isError bool) error {
+	select {
+	case <-b.closeCh:
+		return errDead
+	default:
+	}
+	var msgs []*jsonrpcMessage
+	switch v := v.(type) {
+	case *jsonrpcMessage:
+		msgs = []*jsonrpcMessage{v}
+	case []*jsonrpcMessage:
+		msgs = v
+	default:
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	for _, msg := range msgs {
+		// Responses to calls from the endpoints are not supported.
+		if msg.isCall() || msg.isNotification() {
+			go b.handle(ctx, msg)
+		}
+	}
+	return nil
+}
+
+func (b *balancer) readBatch() ([]*jsonrpcMessage, bool, error) {
+	select {
+	case msg := <-b.resp:
+		return []*jsonrpcMessage{msg}, false, nil
+	case <-b.closeCh:
+		return nil, false, io.EOF
+	}
+}
+
+func (b *balancer) close() {
+	b.closeOnce.Do(func() {
+		b.lock.Lock()
+		close(b.closeCh)
+		b.lock.Unlock()
+
+		for _, ep := range b.endpoints {
+			if ep.client != nil {
+				ep.client.Close()
+			}
+		}
+	})
+}
+
+func (b *balancer) closed() <-chan interface{} {
+	return b.closeCh
+}
+
+func (b *balancer) remoteAddr() string {
+	return ""
+}
+
+func (b *balancer) peerInfo() PeerInfo {
+	return PeerInfo{Transport: "balancer"}
+}
@@ -0,0 +1,251 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// balancerTestService is a node behind a balancing client, reporting its name.
+type balancerTestService struct {
+	name  string
+	head  uint64
+	delay time.Duration
+}
+
+func (s *balancerTestService) BlockNumber() hexutil.Uint64 {
+	time.Sleep(s.delay)
+	return hexutil.Uint64(atomic.LoadUint64(&s.head))
+}
+
+func (s *balancerTestService) Name() string {
+	return s.name
+}
+
+func (s *balancerTestService) Names(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go notifier.Notify(sub.ID, s.name)
+	return sub, nil
+}
+
+type balancerTestEndpoint struct {
+	service *balancerTestService
+	server  *Server
+	http    *httptest.Server
+	url     string
+}
+
+func (e *balancerTestEndpoint) stop() {
+	e.server.Stop()
+	e.http.Close()
+}
+
+func newBalancerTestEndpoint(transport string, service *balancerTestService) *balancerTestEndpoint {
+	server := NewServer()
+	if err := server.RegisterName("eth", service); err != nil {
+		panic(err)
+	}
+	e := &balancerTestEndpoint{service: service, server: server}
+	switch transport {
+	case "http":
+		e.http = httptest.NewServer(server)
+		e.url = e.http.URL
+	case "ws":
+		e.http = httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+		e.url = "ws:" + strings.TrimPrefix(e.http.URL, "http:")
+	default:
+		panic("unknown transport: " + transport)
+	}
+	return e
+}
+
+// newBalancerTestClient starts the given services and a balancing client in front
+// of them. Health checks only run when triggered explicitly.
+func newBalancerTestClient(t *testing.T, transport string, config BalancerConfig, services ...*balancerTestService) (*Client, []*balancerTestEndpoint) {
+	t.Helper()
+
+	var (
+		endpoints []*balancerTestEndpoint
+		urls      []string
+	)
+	for _, service := range services {
+		e := newBalancerTestEndpoint(transport, service)
+		t.Cleanup(e.stop)
+		endpoints = append(endpoints, e)
+		urls = append(urls, e.url)
+	}
+	config.HealthCheckInterval = time.Hour
+	client, err := DialBalanced(context.Background(), urls, config)
+	if err != nil {
+		t.Fatal("can't dial:", err)
+	}
+	t.Cleanup(client.Close)
+	return client, endpoints
+}
+
+// balancerTestNames calls eth_name n times and counts the responding endpoints.
+func balancerTestNames(t *testing.T, client *Client, n int) map[string]int {
+	t.Helper()
+
+	names := make(map[string]int)
+	for i := 0; i < n; i++ {
+		var name string
+		if err := client.Call(&name, "eth_name"); err != nil {
+			t.Fatal("call failed:", err)
+		}
+		names[name]++
+	}
+	return names
+}
+
+func TestBalancerRoundRobin(t *testing.T) {
+	client, _ := newBalancerTestClient(t, "http", BalancerConfig{Policy: RoundRobin},
+		&balancerTestService{name: "a", head: 10},
+		&balancerTestService{name: "b", head: 10},
+		&balancerTestService{name: "c", head: 10},
+	)
+	names := balancerTestNames(t, client, 6)
+	for _, name := range []string{"a", "b", "c"} {
+		if names[name] != 2 {
+			t.Errorf("endpoint %s served %d requests, want %d", name, names[name], 2)
+		}
+	}
+}
+
+func TestBalancerHeadLag(t *testing.T) {
+	client, _ := newBalancerTestClient(t, "http", BalancerConfig{Policy: RoundRobin, MaxHeadLag: 2},
+		&balancerTestService{name: "a", head: 10},
+		&balancerTestService{name: "b", head: 8},
+		&balancerTestService{name: "c", head: 7},
+	)
+	names := balancerTestNames(t, client, 6)
+	if names["a"] != 3 || names["b"] != 3 || names["c"] != 0 {
+		t.Errorf("lagging endpoint used: %v", names)
+	}
+}
+
+func TestBalancerLowestLatency(t *testing.T) {
+	client, _ := newBalancerTestClient(t, "http", BalancerConfig{Policy: LowestLatency},
+		&balancerTestService{name: "a", head: 10, delay: 100 * time.Millisecond},
+		&balancerTestService{name: "b", head: 10},
+	)
+	names := balancerTestNames(t, client, 4)
+	if names["b"] != 4 {
+		t.Errorf("slow endpoint used: %v", names)
+	}
+}
+
+func TestBalancerStickyByBlock(t *testing.T) {
+	client, endpoints := newBalancerTestClient(t, "http", BalancerConfig{Policy: StickyByBlock, MaxHeadLag: 5},
+		&balancerTestService{name: "a", head: 10},
+		&balancerTestService{name: "b", head: 10},
+	)
+	names := balancerTestNames(t, client, 4)
+	if len(names) != 1 {
+		t.Fatalf("requests spread across endpoints: %v", names)
+	}
+	// Advance the other endpoint, requests should follow it.
+	other := endpoints[0].service
+	if names["a"] != 0 {
+		other = endpoints[1].service
+	}
+	atomic.StoreUint64(&other.head, 11)
+	if err := client.writeConn.(*balancer).checkHealth(context.Background()); err != nil {
+		t.Fatal("health check failed:", err)
+	}
+	names = balancerTestNames(t, client, 4)
+	if names[other.name] != 4 {
+		t.Errorf("requests not moved to the endpoint with the highest head: %v", names)
+	}
+}
+
+func TestBalancerFailover(t *testing.T) {
+	client, endpoints := newBalancerTestClient(t, "http", BalancerConfig{Policy: RoundRobin},
+		&balancerTestService{name: "a", head: 10},
+		&balancerTestService{name: "b", head: 10},
+	)
+	endpoints[0].stop()
+
+	// Idempotent requests are retried on the live endpoint.
+	for i := 0; i < 4; i++ {
+		var head hexutil.Uint64
+		if err := client.Call(&head, "eth_blockNumber"); err != nil {
+			t.Fatal("idempotent call failed:", err)
+		}
+	}
+	// The failed endpoint is avoided until it recovers.
+	names := balancerTestNames(t, client, 4)
+	if names["b"] != 4 {
+		t.Errorf("failed endpoint used: %v", names)
+	}
+	// Errors returned by the server are passed on.
+	if err := client.Call(nil, "eth_unknown"); err == nil {
+		t.Error("call of unknown method succeeded")
+	}
+}
+
+func TestBalancerSubscription(t *testing.T) {
+	client, endpoints := newBalancerTestClient(t, "ws", BalancerConfig{Policy: RoundRobin},
+		&balancerTestService{name: "a", head: 10},
+		&balancerTestService{name: "b", head: 10},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ch := make(chan string)
+	sub, err := client.Subscribe(ctx, "eth", ch, "names")
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	defer sub.Unsubscribe()
+
+	var first string
+	select {
+	case first = <-ch:
+	case <-ctx.Done():
+		t.Fatal("no notification received")
+	}
+	// Stop the serving endpoint, the subscription should move to the other one.
+	want := "b"
+	if first == "b" {
+		endpoints[1].stop()
+		want = "a"
+	} else {
+		endpoints[0].stop()
+	}
+	select {
+	case name := <-ch:
+		if name != want {
+			t.Errorf("notification from wrong endpoint: have %s, want %s", name, want)
+		}
+	case err := <-sub.Err():
+		t.Fatal("subscription ended:", err)
+	case <-ctx.Done():
+		t.Fatal("subscription not moved")
+	}
+}
@@ -44,6 +44,10 @@ const (
 	// Timeouts
 	defaultDialTimeout = 10 * time.Second // used if context has no deadline
 	subscribeTimeout   = 5 * time.Second  // overall timeout eth_subscribe, rpc_modules calls
+
+	// Automatic reconnection backoff, used if WithReconnect is given zero delays
+	defaultReconnectMinDelay = 100 * time.Millisecond
+	defaultReconnectMaxDelay = 30 * time.Second
 )
 
 const (
@@ -84,6 +88,10 @@ type Client struct {
 	// This function, if non-nil, is called when the connection is lost.
 	reconnectFunc reconnectFunc
 
+	// Backoff of the automatic reconnection, zero if it is disabled.
+	reconnectMinDelay time.Duration
+	reconnectMaxDelay time.Duration
+
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
 	// taken by sending on reqInit and released by sending on reqSent.
@@ -91,14 +99,15 @@ type Client struct {
 
 	// for dispatch
 	close       chan struct{}
-	closing     chan struct{}    // closed when client is quitting
-	didClose    chan struct{}    // closed when client quits
-	reconnected chan ServerCodec // where write/reconnect sends the new connection
-	readOp      chan readOp      // read messages
-	readErr     chan error       // errors from read
-	reqInit     chan *requestOp  // register response IDs, takes write lock
-	reqSent     chan error       // signals write completion, releases write lock
-	reqTimeout  chan *requestOp  // removes response IDs when call timeout expires
+	closing     chan struct{}            // closed when client is quitting
+	didClose    chan struct{}            // closed when client quits
+	reconnected chan ServerCodec         // where write/reconnect sends the new connection
+	readOp      chan readOp              // read messages
+	readErr     chan error               // errors from read
+	reqInit     chan *requestOp          // register response IDs, takes write lock
+	reqSent     chan error               // signals write completion, releases write lock
+	reqTimeout  chan *requestOp          // removes response IDs when call timeout expires
+	resubFailed chan *ClientSubscription // subscriptions to resume on the next connection
 }
 
 type reconnectFunc func(context.Context) (ServerCodec, error)
@@ -211,7 +220,7 @@ func DialOptions(ctx context.Context, rawurl string, options ...ClientOption) (*
 		return nil, fmt.Errorf("no known transport for URL scheme %q", u.Scheme)
 	}
 
-	return newClient(ctx, reconnect)
+	return newClient(ctx, cfg, reconnect)
 }
 
 // ClientFromContext retrieves the client from the context, if any. This can be used to perform
@@ -221,32 +230,35 @@ func ClientFromContext(ctx context.Context) (*Client, bool) {
 	return client, ok
 }
 
-func newClient(initctx context.Context, connect reconnectFunc) (*Client, error) {
+func newClient(initctx context.Context, cfg *clientConfig, connect reconnectFunc) (*Client, error) {
 	conn, err := connect(initctx)
 	if err != nil {
 		return nil, err
 	}
-	c := initClient(conn, randomIDGenerator(), new(serviceRegistry))
-	c.reconnectFunc = connect
+	c := initClient(conn, randomIDGenerator(), new(serviceRegistry), cfg, connect)
 	return c, nil
 }
 
-func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry) *Client {
+func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, cfg *clientConfig, connect reconnectFunc) *Client {
 	_, isHTTP := conn.(*httpConn)
 	c := &Client{
-		isHTTP:      isHTTP,
-		idgen:       idgen,
-		services:    services,
-		writeConn:   conn,
-		close:       make(chan struct{}),
-		closing:     make(chan struct{}),
-		didClose:    make(chan struct{}),
-		reconnected: make(chan ServerCodec),
-		readOp:      make(chan readOp),
-		readErr:     make(chan error),
-		reqInit:     make(chan *requestOp),
-		reqSent:     make(chan error, 1),
-		reqTimeout:  make(chan *requestOp),
+		isHTTP:            isHTTP,
+		idgen:             idgen,
+		services:          services,
+		reconnectFunc:     connect,
+		reconnectMinDelay: cfg.reconnectMinDelay,
+		reconnectMaxDelay: cfg.reconnectMaxDelay,
+		writeConn:         conn,
+		close:             make(chan struct{}),
+		closing:           make(chan struct{}),
+		didClose:          make(chan struct{}),
+		reconnected:       make(chan ServerCodec),
+		readOp:            make(chan readOp),
+		readErr:           make(chan error),
+		reqInit:           make(chan *requestOp),
+		reqSent:           make(chan error, 1),
+		reqTimeout:        make(chan *requestOp),
+		resubFailed:       make(chan *ClientSubscription),
 	}
 	if !isHTTP {
 		go c.dispatch(conn)
@@ -478,7 +490,7 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 	op := &requestOp{
 		ids:  []json.RawMessage{msg.ID},
 		resp: make(chan *jsonrpcMessage),
-		sub:  newClientSubscription(c, namespace, chanVal),
+		sub:  newClientSubscription(c, namespace, chanVal, msg.Params),
 	}
 
 	// Send the subscription request.
@@ -537,6 +549,102 @@ func (c *Client) write(ctx context.Context, msg interface{}, retry bool) error {
 	return err
 }
 
+// autoReconnect reports whether the client re-dials and resumes its subscriptions
+// when the connection is lost.
+func (c *Client) autoReconnect() bool {
+	return c.reconnectMinDelay > 0 && c.reconnectFunc != nil
+}
+
+// redial re-establishes the lost connection in the background, retrying with
+// exponential backoff until it succeeds or the client is closed.
+func (c *Client) redial(dead ServerCodec) {
+	delay := c.reconnectMinDelay
+	for {
+		// Take the write lock, so the connection can't be replaced concurrently.
+		op := new(requestOp)
+		select {
+		case c.reqInit <- op:
+		case <-c.closing:
+			return
+		}
+		// A caller may have reconnected already while writing a request.
+		var err error
+		if c.writeConn == nil || c.writeConn == dead {
+			err = c.reconnect(context.Background())
+		}
+		c.reqSent <- err
+		if err == nil || err == ErrClientQuit {
+			return
+		}
+		log.Debug("RPC client reconnect failed", "err", err, "delay", delay)
+
+		select {
+		case <-time.After(delay):
+		case <-c.closing:
+			return
+		}
+		if delay *= 2; delay > c.reconnectMaxDelay {
+			delay = c.reconnectMaxDelay
+		}
+	}
+}
+
+// resubscribe re-establishes the given subscriptions after the client reconnected.
+// Subscriptions which fail due to the connection being lost again are handed back
+// to dispatch, to be retried on the next connection.
+func (c *Client) resubscribe(subs []*ClientSubscription) {
+	for _, sub := range subs {
+		err := c.resubscribeOne(sub)
+		switch {
+		case err == nil:
+			sub.signalGap()
+		case errors.Is(err, ErrClientQuit):
+			sub.close(ErrClientQuit)
+		case isResubscribeFinal(err):
+			log.Debug("RPC client failed to resume subscription", "err", err)
+			sub.close(err)
+		default:
+			select {
+			case c.resubFailed <- sub:
+			case <-c.closing:
+				sub.close(ErrClientQuit)
+			}
+		}
+	}
+}
+
+// resubscribeOne sends the subscription request of sub again, resuming it with
+// the subscription ID assigned by the server.
+func (c *Client) resubscribeOne(sub *ClientSubscription) error {
+	// Skip the subscription if it was unsubscribed in the meantime.
+	select {
+	case <-sub.forwardDone:
+		return nil
+	default:
+	}
+	msg := &jsonrpcMessage{Version: vsn, ID: c.nextID(), Method: sub.namespace + subscribeMethodSuffix, Params: sub.params}
+	op := &requestOp{
+		ids:  []json.RawMessage{msg.ID},
+		resp: make(chan *jsonrpcMessage),
+		sub:  sub,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+	defer cancel()
+
+	if err := c.send(ctx, op, msg); err != nil {
+		return err
+	}
+	_, err := op.wait(ctx, c)
+	return err
+}
+
+// isResubscribeFinal reports whether a subscription failing to resume with the given
+// error should be ended instead of being retried on the next connection.
+func isResubscribeFinal(err error) bool {
+	var rpcErr Error
+	return errors.As(err, &rpcErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
 func (c *Client) reconnect(ctx context.Context) error {
 	if c.reconnectFunc == nil {
 		return errDead
@@ -571,6 +679,7 @@ func (c *Client) dispatch(codec ServerCodec) {
 		reqInitLock = c.reqInit // nil while the send lock is held
 		conn        = c.newClientConn(codec)
 		reading     = true
+		resume      []*ClientSubscription // subscriptions to re-establish after reconnecting
 	)
 	defer func() {
 		close(c.closing)
@@ -578,6 +687,9 @@ func (c *Client) dispatch(codec ServerCodec) {
 			conn.close(ErrClientQuit, nil)
 			c.drainRead()
 		}
+		for _, sub := range resume {
+			sub.close(ErrClientQuit)
+		}
 		close(c.didClose)
 	}()
 
@@ -599,6 +711,10 @@ func (c *Client) dispatch(codec ServerCodec) {
 
 		case err := <-c.readErr:
 			conn.handler.log.Debug("RPC connection read error", "err", err)
+			if c.autoReconnect() {
+				resume = append(resume, conn.handler.takeClientSubscriptions()...)
+				go c.redial(conn.codec)
+			}
 			conn.close(err, lastOp)
 			reading = false
 
@@ -611,6 +727,9 @@ func (c *Client) dispatch(codec ServerCodec) {
 				// In those cases the caller will notice first and reconnect. Closing the
 				// handler terminates all waiting requests (closing op.resp) except for
 				// lastOp, which will be transferred to the new handler.
+				if c.autoReconnect() {
+					resume = append(resume, conn.handler.takeClientSubscriptions()...)
+				}
 				conn.close(errClientReconnected, lastOp)
 				c.drainRead()
 			}
@@ -620,6 +739,19 @@ func (c *Client) dispatch(codec ServerCodec) {
 			// Re-register the in-flight request on the new handler
 			// because that's where it will be sent.
 			conn.handler.addRequestOp(lastOp)
+			if len(resume) > 0 {
+				go c.resubscribe(resume)
+				resume = nil
+			}
+
+		case sub := <-c.resubFailed:
+			// The connection was lost again while resuming the subscription. Retry
+			// right away if a new connection is up already.
+			if reading {
+				go c.resubscribe([]*ClientSubscription{sub})
+			} else {
+				resume = append(resume, sub)
+			}
 
 		// Send path:
 		case op := <-reqInitLock:
@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -33,6 +34,9 @@ type clientConfig struct {
 	httpAuth    HTTPAuth
 
 	wsDialer *websocket.Dialer
+
+	reconnectMinDelay time.Duration // zero if automatic reconnection is disabled
+	reconnectMaxDelay time.Duration
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -98,6 +102,33 @@ func WithHTTPAuth(a HTTPAuth) ClientOption {
 	})
 }
 
+// WithReconnect enables automatic reconnection of the client when the connection is
+// lost. The client re-dials in the background, waiting minDelay after the first failed
+// attempt and doubling the delay after each further failure, up to maxDelay. Zero
+// delays select the defaults.
+//
+// Once reconnected, all active subscriptions are re-established on the new connection
+// instead of failing. Notifications sent by the server while the connection was down
+// are lost, so a value is sent on ClientSubscription.Gap whenever a subscription is
+// resumed. Calls which are in flight when the connection is lost still fail.
+//
+// This option has no effect for HTTP clients.
+func WithReconnect(minDelay, maxDelay time.Duration) ClientOption {
+	if minDelay <= 0 {
+		minDelay = defaultReconnectMinDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultReconnectMaxDelay
+	}
+	if maxDelay < minDelay {
+		maxDelay = minDelay
+	}
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.reconnectMinDelay = minDelay
+		cfg.reconnectMaxDelay = maxDelay
+	})
+}
+
 // A HTTPAuth function is called by the client whenever a HTTP request is sent.
 // The function must be safe for concurrent use.
 //
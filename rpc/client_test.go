@@ -467,7 +467,7 @@ func TestClientSubscriptionUnsubscribeServer(t *testing.T) {
 	defer srv.Stop()
 
 	// Create the client on the other end of the pipe.
-	client, _ := newClient(context.Background(), func(context.Context) (ServerCodec, error) {
+	client, _ := newClient(context.Background(), new(clientConfig), func(context.Context) (ServerCodec, error) {
 		return NewCodec(p2), nil
 	})
 	defer client.Close()
@@ -718,6 +718,70 @@ func TestClientReconnect(t *testing.T) {
 	}
 }
 
+func TestClientReconnectResubscribe(t *testing.T) {
+	startServer := func(addr string) (*Server, net.Listener) {
+		srv := newTestServer()
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Fatal("can't listen:", err)
+		}
+		go http.Serve(l, srv.WebsocketHandler([]string{"*"}))
+		return srv, l
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 12*time.Second)
+	defer cancel()
+
+	// Start a server and a reconnecting client.
+	s1, l1 := startServer("127.0.0.1:0")
+	client, err := DialOptions(ctx, "ws://"+l1.Addr().String(), WithReconnect(10*time.Millisecond, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal("can't dial", err)
+	}
+	defer client.Close()
+
+	nc := make(chan int)
+	sub, err := client.Subscribe(ctx, "nftest", nc, "someSubscription", 1, 7)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	if val := <-nc; val != 7 {
+		t.Fatalf("value mismatch: got %d, want %d", val, 7)
+	}
+
+	// Shut down the server and start it up again on the same address. The client
+	// should reconnect on its own and resume the subscription.
+	l1.Close()
+	s1.Stop()
+
+	s2, l2 := startServer(l1.Addr().String())
+	defer l2.Close()
+	defer s2.Stop()
+
+	select {
+	case <-sub.Gap():
+	case err := <-sub.Err():
+		t.Fatal("subscription ended:", err)
+	case <-ctx.Done():
+		t.Fatal("subscription not resumed")
+	}
+	// The new server sends the initial notification again on the resumed subscription.
+	select {
+	case val := <-nc:
+		if val != 7 {
+			t.Fatalf("value mismatch after resuming: got %d, want %d", val, 7)
+		}
+	case err := <-sub.Err():
+		t.Fatal("subscription ended:", err)
+	case <-ctx.Done():
+		t.Fatal("no notification after resuming")
+	}
+	sub.Unsubscribe()
+	if err, ok := <-sub.Err(); ok {
+		t.Fatal("unexpected subscription error:", err)
+	}
+}
+
 func httpTestClient(srv *Server, transport string, fl *flakeyListener) (*Client, *httptest.Server) {
 	// Create the HTTP server.
 	var hs *httptest.Server
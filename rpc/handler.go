@@ -315,6 +315,17 @@ func (h *handler) cancelAllRequests(err error, inflightReq *requestOp) {
 	}
 }
 
+// takeClientSubscriptions removes all active subscriptions without ending them, so
+// they can be resumed on another connection.
+func (h *handler) takeClientSubscriptions() []*ClientSubscription {
+	subs := make([]*ClientSubscription, 0, len(h.clientSubs))
+	for id, sub := range h.clientSubs {
+		delete(h.clientSubs, id)
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
 func (h *handler) addSubscriptions(nn []*Notifier) {
 	h.subLock.Lock()
 	defer h.subLock.Unlock()
@@ -402,9 +413,13 @@ func (h *handler) handleResponse(msg *jsonrpcMessage) {
 		op.err = msg.Error
 		return
 	}
-	if op.err = json.Unmarshal(msg.Result, &op.sub.subid); op.err == nil {
-		go op.sub.run()
-		h.clientSubs[op.sub.subid] = op.sub
+	var subid string
+	if op.err = json.Unmarshal(msg.Result, &subid); op.err == nil {
+		// Resumed subscriptions are still running from the previous connection.
+		if !op.sub.setID(subid) {
+			go op.sub.run()
+		}
+		h.clientSubs[subid] = op.sub
 	}
 }
 
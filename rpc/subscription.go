@@ -209,8 +209,15 @@ type ClientSubscription struct {
 	etype     reflect.Type
 	channel   reflect.Value
 	namespace string
+	params    json.RawMessage // subscribe request parameters, for resuming
+
+	subidLock sync.Mutex
 	subid     string
 
+	// The gap channel receives a value when the subscription was resumed after the
+	// client reconnected.
+	gap chan struct{}
+
 	// The in channel receives notification values from client dispatcher.
 	in chan json.RawMessage
 
@@ -230,10 +237,11 @@ type ClientSubscription struct {
 // This is the sentinel value sent on sub.quit when Unsubscribe is called.
 var errUnsubscribed = errors.New("unsubscribed")
 
-func newClientSubscription(c *Client, namespace string, channel reflect.Value) *ClientSubscription {
+func newClientSubscription(c *Client, namespace string, channel reflect.Value, params json.RawMessage) *ClientSubscription {
 	sub := &ClientSubscription{
 		client:      c,
 		namespace:   namespace,
+		params:      params,
 		etype:       channel.Type().Elem(),
 		channel:     channel,
 		in:          make(chan json.RawMessage),
@@ -241,6 +249,7 @@ func newClientSubscription(c *Client, namespace string, channel reflect.Value) *
 		forwardDone: make(chan struct{}),
 		unsubDone:   make(chan struct{}),
 		err:         make(chan error, 1),
+		gap:         make(chan struct{}, 1),
 	}
 	return sub
 }
@@ -253,10 +262,50 @@ func newClientSubscription(c *Client, namespace string, channel reflect.Value) *
 // error has occurred.
 //
 // The error channel is closed when Unsubscribe is called on the subscription.
+//
+// If the client was created with WithReconnect, losing the connection doesn't end
+// the subscription. It is resumed once the client has reconnected, see Gap.
 func (sub *ClientSubscription) Err() <-chan error {
 	return sub.err
 }
 
+// Gap returns a channel which receives a value whenever the subscription is resumed
+// after the client reconnected. Notifications sent by the server while the connection
+// was down are lost, and subscribers may want to backfill them on receiving the value.
+// Multiple gaps which aren't received in time are reported as one.
+//
+// Subscriptions are only resumed if the client was created with WithReconnect.
+func (sub *ClientSubscription) Gap() <-chan struct{} {
+	return sub.gap
+}
+
+// signalGap is called by the client when the subscription has been resumed.
+func (sub *ClientSubscription) signalGap() {
+	select {
+	case sub.gap <- struct{}{}:
+	default:
+	}
+}
+
+// setID sets the subscription ID assigned by the server and reports whether the
+// subscription had an ID before, i.e. whether it is being resumed.
+func (sub *ClientSubscription) setID(id string) (resumed bool) {
+	sub.subidLock.Lock()
+	defer sub.subidLock.Unlock()
+
+	resumed = sub.subid != ""
+	sub.subid = id
+	return resumed
+}
+
+// id returns the current subscription ID assigned by the server.
+func (sub *ClientSubscription) id() string {
+	sub.subidLock.Lock()
+	defer sub.subidLock.Unlock()
+
+	return sub.subid
+}
+
 // Unsubscribe unsubscribes the notification and closes the error channel.
 // It can safely be called more than once.
 func (sub *ClientSubscription) Unsubscribe() {
@@ -373,5 +422,5 @@ func (sub *ClientSubscription) unmarshal(result json.RawMessage) (interface{}, e
 
 func (sub *ClientSubscription) requestUnsubscribe() error {
 	var result interface{}
-	return sub.client.Call(&result, sub.namespace+unsubscribeMethodSuffix, sub.subid)
+	return sub.client.Call(&result, sub.namespace+unsubscribeMethodSuffix, sub.id())
 }
@@ -28,6 +28,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/accounts/kms"
 	"github.com/ethereum/go-ethereum/accounts/scwallet"
 	"github.com/ethereum/go-ethereum/accounts/usbwallet"
 	"github.com/ethereum/go-ethereum/common"
@@ -46,7 +47,7 @@ const (
 	// ExternalAPIVersion -- see extapi_changelog.md
 	ExternalAPIVersion = "6.1.0"
 	// InternalAPIVersion -- see intapi_changelog.md
-	InternalAPIVersion = "7.0.1"
+	InternalAPIVersion = "7.1.0"
 )
 
 // ExternalAPI defines the external API through which signing requests are made.
@@ -243,6 +244,8 @@ type (
 		Callinfo    []apitypes.ValidationInfo `json:"call_info"`
 		Hash        hexutil.Bytes             `json:"hash"`
 		Meta        Metadata                  `json:"meta"`
+		TypedData   *apitypes.TypedData       `json:"typed_data,omitempty"`
+		Summary     []string                  `json:"summary,omitempty"`
 	}
 	SignDataResponse struct {
 		Approved bool `json:"approved"`
@@ -540,6 +543,13 @@ func (api *SignerAPI) lookupOrQueryPassword(address common.Address, title, promp
 	return pwResp.Text, nil
 }
 
+// needsPassword returns whether the user needs to be asked for a password to sign
+// with the given wallet. Key management services authenticate requests on their
+// own, so there is no point in prompting for one.
+func needsPassword(wallet accounts.Wallet) bool {
+	return wallet.URL().Scheme != kms.Scheme
+}
+
 // SignTransaction signs the given Transaction and returns it both as json and rlp-encoded form
 func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxArgs, methodSelector *string) (*ethapi.SignTransactionResult, error) {
 	var (
@@ -591,10 +601,13 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 	// Convert fields into a real transaction
 	var unsignedTx = result.Transaction.ToTransaction()
 	// Get the password for the transaction
-	pw, err := api.lookupOrQueryPassword(acc.Address, "Account password",
-		fmt.Sprintf("Please enter the password for account %s", acc.Address.String()))
-	if err != nil {
-		return nil, err
+	var pw string
+	if needsPassword(wallet) {
+		pw, err = api.lookupOrQueryPassword(acc.Address, "Account password",
+			fmt.Sprintf("Please enter the password for account %s", acc.Address.String()))
+		if err != nil {
+			return nil, err
+		}
 	}
 	// The one to sign is the one that was returned from the UI
 	signedTx, err := wallet.SignTxWithPassphrase(acc, pw, unsignedTx, api.chainID)
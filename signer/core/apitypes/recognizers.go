@@ -0,0 +1,270 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package apitypes
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// Canonical type encodings of the well-known typed data payloads.
+const (
+	permitEncoding = "Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"
+
+	daiPermitEncoding = "Permit(address holder,address spender,uint256 nonce,uint256 expiry,bool allowed)"
+
+	permit2DetailsEncoding           = "PermitDetails(address token,uint160 amount,uint48 expiration,uint48 nonce)"
+	permit2SingleEncoding            = "PermitSingle(PermitDetails details,address spender,uint256 sigDeadline)" + permit2DetailsEncoding
+	permit2BatchEncoding             = "PermitBatch(PermitDetails[] details,address spender,uint256 sigDeadline)" + permit2DetailsEncoding
+	permit2TokenPermissionsEncoding  = "TokenPermissions(address token,uint256 amount)"
+	permit2TransferFromEncoding      = "PermitTransferFrom(TokenPermissions permitted,address spender,uint256 nonce,uint256 deadline)" + permit2TokenPermissionsEncoding
+	permit2BatchTransferFromEncoding = "PermitBatchTransferFrom(TokenPermissions[] permitted,address spender,uint256 nonce,uint256 deadline)" + permit2TokenPermissionsEncoding
+
+	seaportOrderEncoding = "OrderComponents(address offerer,address zone,OfferItem[] offer,ConsiderationItem[] consideration,uint8 orderType,uint256 startTime,uint256 endTime,bytes32 zoneHash,uint256 salt,bytes32 conduitKey,uint256 counter)" +
+		"ConsiderationItem(uint8 itemType,address token,uint256 identifierOrCriteria,uint256 startAmount,uint256 endAmount,address recipient)" +
+		"OfferItem(uint8 itemType,address token,uint256 identifierOrCriteria,uint256 startAmount,uint256 endAmount)"
+)
+
+// permit2Address is the address of the canonical Permit2 deployment, which is
+// the same on all chains.
+var permit2Address = common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+
+// typedDataRecognizer renders a well-known typed data payload into a human
+// readable summary, or returns nil if the payload is not recognized.
+type typedDataRecognizer func(typedData *TypedData) []string
+
+// typedDataRecognizers are the recognizers tried in order when summarizing.
+var typedDataRecognizers = []typedDataRecognizer{
+	recognizePermit,
+	recognizeDaiPermit,
+	recognizePermit2,
+	recognizeSeaportOrder,
+}
+
+// Summary returns a human readable summary of well-known typed data payloads,
+// such as ERC-20 permits, Permit2 approvals and transfers, or Seaport orders.
+// Payloads are recognized by the canonical encoding of their primary type. If
+// the payload is not recognized, nil is returned. The summary is only meant for
+// display, so the typed data is expected to have been validated already.
+func (typedData *TypedData) Summary() []string {
+	for _, recognize := range typedDataRecognizers {
+		if summary := recognize(typedData); summary != nil {
+			return summary
+		}
+	}
+	return nil
+}
+
+// hasEncoding checks whether the primary type of the typed data is the given
+// one, with the given canonical type encoding.
+func (typedData *TypedData) hasEncoding(primaryType string, encoding string) bool {
+	return typedData.PrimaryType == primaryType && string(typedData.EncodeType(primaryType)) == encoding
+}
+
+// recognizePermit summarizes EIP-2612 permits.
+func recognizePermit(typedData *TypedData) []string {
+	if !typedData.hasEncoding("Permit", permitEncoding) {
+		return nil
+	}
+	msg := typedData.Message
+	return []string{
+		fmt.Sprintf("ERC-20 permit for token %s", typedData.domainContract()),
+		fmt.Sprintf("Owner %s allows spender %s", summaryAddress(msg, "owner"), summaryAddress(msg, "spender")),
+		fmt.Sprintf("to spend %s of the token", summaryAmount(msg, "value", math.MaxBig256)),
+		fmt.Sprintf("Nonce %s, valid until %s", summaryInteger(msg, "nonce"), summaryTime(msg, "deadline")),
+	}
+}
+
+// recognizeDaiPermit summarizes the DAI-style permits predating EIP-2612.
+func recognizeDaiPermit(typedData *TypedData) []string {
+	if !typedData.hasEncoding("Permit", daiPermitEncoding) {
+		return nil
+	}
+	msg := typedData.Message
+	action := "revokes the unlimited allowance of"
+	if allowed, _ := msg["allowed"].(bool); allowed {
+		action = "grants an unlimited allowance to"
+	}
+	return []string{
+		fmt.Sprintf("DAI-style permit for token %s", typedData.domainContract()),
+		fmt.Sprintf("Holder %s %s spender %s", summaryAddress(msg, "holder"), action, summaryAddress(msg, "spender")),
+		fmt.Sprintf("Nonce %s, valid until %s", summaryInteger(msg, "nonce"), summaryTime(msg, "expiry")),
+	}
+}
+
+// recognizePermit2 summarizes Uniswap Permit2 allowances and signature transfers.
+func recognizePermit2(typedData *TypedData) []string {
+	var (
+		msg     = typedData.Message
+		summary []string
+	)
+	switch {
+	case typedData.hasEncoding("PermitSingle", permit2SingleEncoding):
+		details, _ := msg["details"].(map[string]interface{})
+		summary = []string{
+			fmt.Sprintf("Permit2 allowance for spender %s", summaryAddress(msg, "spender")),
+			summaryPermit2Details(details),
+			fmt.Sprintf("Signature valid until %s", summaryTime(msg, "sigDeadline")),
+		}
+
+	case typedData.hasEncoding("PermitBatch", permit2BatchEncoding):
+		summary = []string{fmt.Sprintf("Permit2 batch allowance for spender %s", summaryAddress(msg, "spender"))}
+		for _, details := range summaryList(msg, "details") {
+			summary = append(summary, summaryPermit2Details(details))
+		}
+		summary = append(summary, fmt.Sprintf("Signature valid until %s", summaryTime(msg, "sigDeadline")))
+
+	case typedData.hasEncoding("PermitTransferFrom", permit2TransferFromEncoding):
+		permitted, _ := msg["permitted"].(map[string]interface{})
+		summary = []string{
+			fmt.Sprintf("Permit2 transfer by spender %s", summaryAddress(msg, "spender")),
+			fmt.Sprintf("Transfer up to %s of token %s", summaryAmount(permitted, "amount", math.MaxBig256), summaryAddress(permitted, "token")),
+			fmt.Sprintf("Nonce %s, valid until %s", summaryInteger(msg, "nonce"), summaryTime(msg, "deadline")),
+		}
+
+	case typedData.hasEncoding("PermitBatchTransferFrom", permit2BatchTransferFromEncoding):
+		summary = []string{fmt.Sprintf("Permit2 batch transfer by spender %s", summaryAddress(msg, "spender"))}
+		for _, permitted := range summaryList(msg, "permitted") {
+			summary = append(summary, fmt.Sprintf("Transfer up to %s of token %s", summaryAmount(permitted, "amount", math.MaxBig256), summaryAddress(permitted, "token")))
+		}
+		summary = append(summary, fmt.Sprintf("Nonce %s, valid until %s", summaryInteger(msg, "nonce"), summaryTime(msg, "deadline")))
+
+	default:
+		return nil
+	}
+	if !common.IsHexAddress(typedData.Domain.VerifyingContract) || common.HexToAddress(typedData.Domain.VerifyingContract) != permit2Address {
+		summary = append(summary, fmt.Sprintf("WARNING: verifying contract %s is not the canonical Permit2 deployment", typedData.domainContract()))
+	}
+	return summary
+}
+
+// summaryPermit2Details summarizes the token allowance of a Permit2 approval.
+func summaryPermit2Details(details map[string]interface{}) string {
+	maxUint160 := new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 160), common.Big1)
+	return fmt.Sprintf("Allow spending %s of token %s until %s (nonce %s)",
+		summaryAmount(details, "amount", maxUint160), summaryAddress(details, "token"),
+		summaryTime(details, "expiration"), summaryInteger(details, "nonce"))
+}
+
+// seaportItemTypes are the names of the Seaport item types, indexed by value.
+var seaportItemTypes = []string{"native", "ERC20", "ERC721", "ERC1155", "ERC721 (criteria)", "ERC1155 (criteria)"}
+
+// recognizeSeaportOrder summarizes Seaport marketplace orders.
+func recognizeSeaportOrder(typedData *TypedData) []string {
+	if !typedData.hasEncoding("OrderComponents", seaportOrderEncoding) {
+		return nil
+	}
+	msg := typedData.Message
+	summary := []string{fmt.Sprintf("Seaport order by %s on %s", summaryAddress(msg, "offerer"), typedData.domainContract())}
+	for _, item := range summaryList(msg, "offer") {
+		summary = append(summary, "Offering "+summarySeaportItem(item))
+	}
+	for _, item := range summaryList(msg, "consideration") {
+		summary = append(summary, fmt.Sprintf("In exchange for %s to %s", summarySeaportItem(item), summaryAddress(item, "recipient")))
+	}
+	summary = append(summary, fmt.Sprintf("Valid from %s until %s", summaryTime(msg, "startTime"), summaryTime(msg, "endTime")))
+	return summary
+}
+
+// summarySeaportItem summarizes a Seaport offer or consideration item.
+func summarySeaportItem(item map[string]interface{}) string {
+	kind := "unknown"
+	if itemType, err := parseInteger("uint8", item["itemType"]); err == nil && itemType.Int64() < int64(len(seaportItemTypes)) {
+		kind = seaportItemTypes[itemType.Int64()]
+	}
+	amount := summaryInteger(item, "startAmount")
+	if end := summaryInteger(item, "endAmount"); end != amount {
+		amount = fmt.Sprintf("%s to %s", amount, end)
+	}
+	switch kind {
+	case "native":
+		return fmt.Sprintf("%s wei", amount)
+	case "ERC20":
+		return fmt.Sprintf("%s of %s token %s", amount, kind, summaryAddress(item, "token"))
+	case "ERC721":
+		return fmt.Sprintf("%s token %s #%s", kind, summaryAddress(item, "token"), summaryInteger(item, "identifierOrCriteria"))
+	default:
+		return fmt.Sprintf("%s of %s token %s #%s", amount, kind, summaryAddress(item, "token"), summaryInteger(item, "identifierOrCriteria"))
+	}
+}
+
+// domainContract returns the verifying contract of the domain, along with its
+// name if available.
+func (typedData *TypedData) domainContract() string {
+	contract := typedData.Domain.VerifyingContract
+	if common.IsHexAddress(contract) {
+		contract = common.HexToAddress(contract).Hex()
+	}
+	if typedData.Domain.Name != "" {
+		return fmt.Sprintf("%s (%s)", contract, typedData.Domain.Name)
+	}
+	return contract
+}
+
+// summaryList returns the struct items of an array field.
+func summaryList(data map[string]interface{}, field string) []map[string]interface{} {
+	items, _ := convertDataToSlice(data[field])
+	list := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+// summaryAddress formats an address field in its checksummed form.
+func summaryAddress(data map[string]interface{}, field string) string {
+	if addr, ok := data[field].(string); ok && common.IsHexAddress(addr) {
+		return common.HexToAddress(addr).Hex()
+	}
+	return fmt.Sprintf("%v", data[field])
+}
+
+// summaryInteger formats an integer field in decimal.
+func summaryInteger(data map[string]interface{}, field string) string {
+	value, err := parseInteger("uint256", data[field])
+	if err != nil {
+		return fmt.Sprintf("%v", data[field])
+	}
+	return value.String()
+}
+
+// summaryAmount formats a token amount field, calling out unlimited amounts.
+func summaryAmount(data map[string]interface{}, field string, unlimited *big.Int) string {
+	if value, err := parseInteger("uint256", data[field]); err == nil && value.Cmp(unlimited) >= 0 {
+		return "an unlimited amount"
+	}
+	return summaryInteger(data, field)
+}
+
+// summaryTime formats a unix timestamp field. Timestamps beyond year 9999 are
+// commonly used to never expire.
+func summaryTime(data map[string]interface{}, field string) string {
+	value, err := parseInteger("uint256", data[field])
+	if err != nil {
+		return fmt.Sprintf("%v", data[field])
+	}
+	if !value.IsInt64() || value.Int64() > 253402300799 {
+		return "forever"
+	}
+	return time.Unix(value.Int64(), 0).UTC().Format(time.RFC3339)
+}
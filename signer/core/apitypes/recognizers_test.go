@@ -0,0 +1,207 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package apitypes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const permitTypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Permit": [
+      {"name": "owner", "type": "address"},
+      {"name": "spender", "type": "address"},
+      {"name": "value", "type": "uint256"},
+      {"name": "nonce", "type": "uint256"},
+      {"name": "deadline", "type": "uint256"}
+    ]
+  },
+  "primaryType": "Permit",
+  "domain": {"name": "USD Coin", "version": "2", "chainId": 1, "verifyingContract": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+  "message": {
+    "owner": "0x000000000000000000000000000000000000dead",
+    "spender": "0x000000000000000000000000000000000000beef",
+    "value": "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+    "nonce": 3,
+    "deadline": "1672531200"
+  }
+}`
+
+const permit2TypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "PermitSingle": [
+      {"name": "details", "type": "PermitDetails"},
+      {"name": "spender", "type": "address"},
+      {"name": "sigDeadline", "type": "uint256"}
+    ],
+    "PermitDetails": [
+      {"name": "token", "type": "address"},
+      {"name": "amount", "type": "uint160"},
+      {"name": "expiration", "type": "uint48"},
+      {"name": "nonce", "type": "uint48"}
+    ]
+  },
+  "primaryType": "PermitSingle",
+  "domain": {"name": "Permit2", "chainId": "1", "verifyingContract": "0x000000000022d473030f116ddee9f6b43ac78ba3"},
+  "message": {
+    "details": {
+      "token": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+      "amount": "1000000",
+      "expiration": "1672531200",
+      "nonce": "0"
+    },
+    "spender": "0x000000000000000000000000000000000000beef",
+    "sigDeadline": "1672534800"
+  }
+}`
+
+const seaportTypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "OrderComponents": [
+      {"name": "offerer", "type": "address"},
+      {"name": "zone", "type": "address"},
+      {"name": "offer", "type": "OfferItem[]"},
+      {"name": "consideration", "type": "ConsiderationItem[]"},
+      {"name": "orderType", "type": "uint8"},
+      {"name": "startTime", "type": "uint256"},
+      {"name": "endTime", "type": "uint256"},
+      {"name": "zoneHash", "type": "bytes32"},
+      {"name": "salt", "type": "uint256"},
+      {"name": "conduitKey", "type": "bytes32"},
+      {"name": "counter", "type": "uint256"}
+    ],
+    "OfferItem": [
+      {"name": "itemType", "type": "uint8"},
+      {"name": "token", "type": "address"},
+      {"name": "identifierOrCriteria", "type": "uint256"},
+      {"name": "startAmount", "type": "uint256"},
+      {"name": "endAmount", "type": "uint256"}
+    ],
+    "ConsiderationItem": [
+      {"name": "itemType", "type": "uint8"},
+      {"name": "token", "type": "address"},
+      {"name": "identifierOrCriteria", "type": "uint256"},
+      {"name": "startAmount", "type": "uint256"},
+      {"name": "endAmount", "type": "uint256"},
+      {"name": "recipient", "type": "address"}
+    ]
+  },
+  "primaryType": "OrderComponents",
+  "domain": {"name": "Seaport", "version": "1.1", "chainId": 1, "verifyingContract": "0x00000000006c3852cbef3e08e8df289169ede581"},
+  "message": {
+    "offerer": "0x000000000000000000000000000000000000dead",
+    "zone": "0x0000000000000000000000000000000000000000",
+    "offer": [
+      {"itemType": 2, "token": "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", "identifierOrCriteria": "1234", "startAmount": "1", "endAmount": "1"}
+    ],
+    "consideration": [
+      {"itemType": 0, "token": "0x0000000000000000000000000000000000000000", "identifierOrCriteria": "0", "startAmount": "975000000000000000", "endAmount": "975000000000000000", "recipient": "0x000000000000000000000000000000000000dead"},
+      {"itemType": 1, "token": "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", "identifierOrCriteria": "0", "startAmount": "25000000000000000", "endAmount": "25000000000000000", "recipient": "0x0000000000000000000000000000000000000fee"}
+    ],
+    "orderType": 0,
+    "startTime": "1672531200",
+    "endTime": "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+    "zoneHash": "0x0000000000000000000000000000000000000000000000000000000000000000",
+    "salt": "42",
+    "conduitKey": "0x0000000000000000000000000000000000000000000000000000000000000000",
+    "counter": "0"
+  }
+}`
+
+func TestTypedDataSummary(t *testing.T) {
+	tests := []struct {
+		data    string
+		summary []string
+	}{
+		{
+			data: permitTypedData,
+			summary: []string{
+				"ERC-20 permit for token 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48 (USD Coin)",
+				"Owner 0x000000000000000000000000000000000000dEaD allows spender 0x000000000000000000000000000000000000bEEF",
+				"to spend an unlimited amount of the token",
+				"Nonce 3, valid until 2023-01-01T00:00:00Z",
+			},
+		},
+		{
+			data: permit2TypedData,
+			summary: []string{
+				"Permit2 allowance for spender 0x000000000000000000000000000000000000bEEF",
+				"Allow spending 1000000 of token 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48 until 2023-01-01T00:00:00Z (nonce 0)",
+				"Signature valid until 2023-01-01T01:00:00Z",
+			},
+		},
+		{
+			data: seaportTypedData,
+			summary: []string{
+				"Seaport order by 0x000000000000000000000000000000000000dEaD on 0x00000000006c3852cbEf3e08E8dF289169EdE581 (Seaport)",
+				"Offering ERC721 token 0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D #1234",
+				"In exchange for 975000000000000000 wei to 0x000000000000000000000000000000000000dEaD",
+				"In exchange for 25000000000000000 of ERC20 token 0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2 to 0x0000000000000000000000000000000000000FEE",
+				"Valid from 2023-01-01T00:00:00Z until forever",
+			},
+		},
+	}
+	for i, tt := range tests {
+		var td TypedData
+		if err := json.Unmarshal([]byte(tt.data), &td); err != nil {
+			t.Fatalf("test %d: failed to unmarshal typed data: %v", i, err)
+		}
+		// Ensure the fixtures are valid typed data to begin with
+		if _, _, err := TypedDataAndHash(td); err != nil {
+			t.Fatalf("test %d: failed to hash typed data: %v", i, err)
+		}
+		if have := td.Summary(); !reflect.DeepEqual(have, tt.summary) {
+			t.Errorf("test %d: summary mismatch:\nhave %q\nwant %q", i, have, tt.summary)
+		}
+	}
+}
+
+func TestTypedDataSummaryUnrecognized(t *testing.T) {
+	var td TypedData
+	if err := json.Unmarshal([]byte(permit2TypedData), &td); err != nil {
+		t.Fatalf("failed to unmarshal typed data: %v", err)
+	}
+	// Permit2 payloads signed for other contracts should be called out
+	td.Domain.VerifyingContract = "0x000000000000000000000000000000000000dead"
+	if summary := td.Summary(); len(summary) != 4 {
+		t.Errorf("expected warning about non-canonical deployment, got %q", summary)
+	}
+	// Structurally different payloads should not be recognized
+	td.Types["PermitDetails"] = td.Types["PermitDetails"][:3]
+	if summary := td.Summary(); summary != nil {
+		t.Errorf("expected modified payload to not be recognized, got %q", summary)
+	}
+}
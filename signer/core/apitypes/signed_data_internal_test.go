@@ -180,6 +180,12 @@ func TestParseInteger(t *testing.T) {
 		{"int32", big.NewInt(-124), big.NewInt(-124)},
 		{"uint32", "0xff", big.NewInt(0xff)},
 		{"int8", "0xffff", nil},
+		{"int8", "127", big.NewInt(127)},
+		{"int8", "128", nil},
+		{"int8", "-128", big.NewInt(-128)},
+		{"int8", "-129", nil},
+		{"uint48", "0xffffffffffff", big.NewInt(0xffffffffffff)},
+		{"uint7", "1", nil},
 	} {
 		res, err := parseInteger(tt.t, tt.v)
 		if tt.exp == nil && res == nil {
@@ -233,3 +239,32 @@ func TestConvertAddressDataToSlice(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestPrimitiveTypeValidity(t *testing.T) {
+	for _, tt := range []struct {
+		t     string
+		valid bool
+	}{
+		{"uint160", true},
+		{"int24", true},
+		{"uint", true},
+		{"bytes1", true},
+		{"bytes32", true},
+		{"address[2][]", true},
+		{"bytes32[][3]", true},
+		{"uint7", false},
+		{"uint264", false},
+		{"uint08", false},
+		{"bytes0", false},
+		{"bytes33", false},
+		{"bytes04", false},
+		{"address[0]", false},
+		{"address[01]", false},
+		{"address[", false},
+		{"[]", false},
+	} {
+		if have := isPrimitiveTypeValid(tt.t); have != tt.valid {
+			t.Errorf("type %q: validity mismatch: have %v, want %v", tt.t, have, tt.valid)
+		}
+	}
+}
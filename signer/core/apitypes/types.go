@@ -38,7 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
-var typedDataReferenceTypeRegexp = regexp.MustCompile(`^[A-Z](\w*)(\[\])?$`)
+var typedDataReferenceTypeRegexp = regexp.MustCompile(`^[A-Z](\w*)(\[\d*\])*$`)
 
 type ValidationInfo struct {
 	Typ     string `json:"type"`
@@ -211,17 +211,38 @@ type Type struct {
 	Type string `json:"type"`
 }
 
-func (t *Type) isArray() bool {
-	return strings.HasSuffix(t.Type, "[]")
+// typeName returns the canonical name of the type. If the type is 'Person[]' or
+// 'Person[2][]', then this method returns 'Person'
+func (t *Type) typeName() string {
+	return baseType(t.Type)
 }
 
-// typeName returns the canonical name of the type. If the type is 'Person[]', then
-// this method returns 'Person'
-func (t *Type) typeName() string {
-	if strings.HasSuffix(t.Type, "[]") {
-		return strings.TrimSuffix(t.Type, "[]")
+// baseType strips all array dimensions from a type, returning the type of the
+// innermost elements.
+func baseType(typ string) string {
+	if i := strings.IndexByte(typ, '['); i >= 0 {
+		return typ[:i]
 	}
-	return t.Type
+	return typ
+}
+
+// parseArrayType splits the outermost dimension off an array type, returning the
+// type of the elements and the length of the array, or -1 if it is dynamic. For
+// example 'uint8[2][]' is a dynamic array of 'uint8[2]' elements.
+func parseArrayType(typ string) (string, int, error) {
+	i := strings.LastIndexByte(typ, '[')
+	if i <= 0 || !strings.HasSuffix(typ, "]") {
+		return "", 0, fmt.Errorf("invalid array type %q", typ)
+	}
+	size := typ[i+1 : len(typ)-1]
+	if size == "" {
+		return typ[:i], -1, nil
+	}
+	length, err := strconv.Atoi(size)
+	if err != nil || length <= 0 || size[0] == '0' {
+		return "", 0, fmt.Errorf("invalid array length in type %q", typ)
+	}
+	return typ[:i], length, nil
 }
 
 func (t *Type) isReferenceType() bool {
@@ -251,6 +272,35 @@ type TypedDataDomain struct {
 	Salt              string                `json:"salt"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler, additionally accepting the chain id
+// as a plain JSON number, as sent by most dapps.
+func (domain *TypedDataDomain) UnmarshalJSON(input []byte) error {
+	type typedDataDomain TypedDataDomain
+	var dec struct {
+		typedDataDomain
+		ChainId json.RawMessage `json:"chainId"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*domain = TypedDataDomain(dec.typedDataDomain)
+	domain.ChainId = nil
+
+	if len(dec.ChainId) == 0 || string(dec.ChainId) == "null" {
+		return nil
+	}
+	chainId := new(math.HexOrDecimal256)
+	if dec.ChainId[0] == '"' {
+		if err := json.Unmarshal(dec.ChainId, chainId); err != nil {
+			return err
+		}
+	} else if err := chainId.UnmarshalText(dec.ChainId); err != nil {
+		return err
+	}
+	domain.ChainId = chainId
+	return nil
+}
+
 // TypedDataAndHash is a helper function that calculates a hash for typed data conforming to EIP-712.
 // This hash can then be safely used to calculate a signature.
 //
@@ -281,7 +331,7 @@ func (typedData *TypedData) HashStruct(primaryType string, data TypedDataMessage
 
 // Dependencies returns an array of custom types ordered by their hierarchical reference tree
 func (typedData *TypedData) Dependencies(primaryType string, found []string) []string {
-	primaryType = strings.TrimSuffix(primaryType, "[]")
+	primaryType = baseType(primaryType)
 	includes := func(arr []string, str string) bool {
 		for _, obj := range arr {
 			if obj == str {
@@ -364,56 +414,53 @@ func (typedData *TypedData) EncodeData(primaryType string, data map[string]inter
 
 	// Add field contents. Structs and arrays have special handlers.
 	for _, field := range typedData.Types[primaryType] {
-		encType := field.Type
-		encValue := data[field.Name]
-		if encType[len(encType)-1:] == "]" {
-			arrayValue, err := convertDataToSlice(encValue)
-			if err != nil {
-				return nil, dataMismatchError(encType, encValue)
-			}
-
-			arrayBuffer := bytes.Buffer{}
-			parsedType := strings.Split(encType, "[")[0]
-			for _, item := range arrayValue {
-				if typedData.Types[parsedType] != nil {
-					mapValue, ok := item.(map[string]interface{})
-					if !ok {
-						return nil, dataMismatchError(parsedType, item)
-					}
-					encodedData, err := typedData.EncodeData(parsedType, mapValue, depth+1)
-					if err != nil {
-						return nil, err
-					}
-					arrayBuffer.Write(crypto.Keccak256(encodedData))
-				} else {
-					bytesValue, err := typedData.EncodePrimitiveValue(parsedType, item, depth)
-					if err != nil {
-						return nil, err
-					}
-					arrayBuffer.Write(bytesValue)
-				}
-			}
+		encValue, err := typedData.encodeValue(field.Type, data[field.Name], depth)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encValue)
+	}
+	return buffer.Bytes(), nil
+}
 
-			buffer.Write(crypto.Keccak256(arrayBuffer.Bytes()))
-		} else if typedData.Types[field.Type] != nil {
-			mapValue, ok := encValue.(map[string]interface{})
-			if !ok {
-				return nil, dataMismatchError(encType, encValue)
-			}
-			encodedData, err := typedData.EncodeData(field.Type, mapValue, depth+1)
-			if err != nil {
-				return nil, err
-			}
-			buffer.Write(crypto.Keccak256(encodedData))
-		} else {
-			byteValue, err := typedData.EncodePrimitiveValue(encType, encValue, depth)
+// encodeValue encodes a single member of a struct into 32 bytes. Structs are
+// encoded as their hashStruct and arrays as the keccak256 hash of the concatenated
+// encodings of their elements, recursing into nested arrays.
+func (typedData *TypedData) encodeValue(encType string, encValue interface{}, depth int) ([]byte, error) {
+	if strings.HasSuffix(encType, "]") {
+		elemType, length, err := parseArrayType(encType)
+		if err != nil {
+			return nil, err
+		}
+		arrayValue, err := convertDataToSlice(encValue)
+		if err != nil {
+			return nil, dataMismatchError(encType, encValue)
+		}
+		if length >= 0 && len(arrayValue) != length {
+			return nil, fmt.Errorf("provided array of length %d doesn't match type '%s'", len(arrayValue), encType)
+		}
+		arrayBuffer := bytes.Buffer{}
+		for _, item := range arrayValue {
+			encodedItem, err := typedData.encodeValue(elemType, item, depth+1)
 			if err != nil {
 				return nil, err
 			}
-			buffer.Write(byteValue)
+			arrayBuffer.Write(encodedItem)
 		}
+		return crypto.Keccak256(arrayBuffer.Bytes()), nil
 	}
-	return buffer.Bytes(), nil
+	if typedData.Types[encType] != nil {
+		mapValue, ok := encValue.(map[string]interface{})
+		if !ok {
+			return nil, dataMismatchError(encType, encValue)
+		}
+		encodedData, err := typedData.EncodeData(encType, mapValue, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(encodedData), nil
+	}
+	return typedData.EncodePrimitiveValue(encType, encValue, depth)
 }
 
 // Attempt to parse bytes in different formats: byte array, hex string, hexutil.Bytes.
@@ -458,7 +505,7 @@ func parseInteger(encType string, encValue interface{}) (*big.Int, error) {
 			lengthStr = strings.TrimPrefix(encType, "int")
 		}
 		atoiSize, err := strconv.Atoi(lengthStr)
-		if err != nil {
+		if err != nil || atoiSize < 8 || atoiSize > 256 || atoiSize%8 != 0 {
 			return nil, fmt.Errorf("invalid size on integer: %v", lengthStr)
 		}
 		length = atoiSize
@@ -489,6 +536,10 @@ func parseInteger(encType string, encValue interface{}) (*big.Int, error) {
 	if b.BitLen() > length {
 		return nil, fmt.Errorf("integer larger than '%v'", encType)
 	}
+	// Signed integers lose a bit to the sign, the range being [-2^(n-1), 2^(n-1))
+	if signed && ((b.Sign() >= 0 && b.BitLen() > length-1) || (b.Sign() < 0 && new(big.Int).Add(b, common.Big1).BitLen() > length-1)) {
+		return nil, fmt.Errorf("integer out of range for '%v'", encType)
+	}
 	if !signed && b.Sign() == -1 {
 		return nil, fmt.Errorf("invalid negative value for unsigned type %v", encType)
 	}
@@ -545,7 +596,7 @@ func (typedData *TypedData) EncodePrimitiveValue(encType string, encValue interf
 		if err != nil {
 			return nil, fmt.Errorf("invalid size on bytes: %v", lengthStr)
 		}
-		if length < 0 || length > 32 {
+		if length < 1 || length > 32 {
 			return nil, fmt.Errorf("invalid size on bytes: %d", length)
 		}
 		if byteValue, ok := parseBytes(encValue); !ok || len(byteValue) != length {
@@ -638,51 +689,50 @@ func (typedData *TypedData) formatData(primaryType string, data map[string]inter
 
 	// Add field contents. Structs and arrays have special handlers.
 	for _, field := range typedData.Types[primaryType] {
-		encName := field.Name
-		encValue := data[encName]
-		item := &NameValueType{
-			Name: encName,
-			Typ:  field.Type,
-		}
-		if field.isArray() {
-			arrayValue, _ := convertDataToSlice(encValue)
-			parsedType := field.typeName()
-			for _, v := range arrayValue {
-				if typedData.Types[parsedType] != nil {
-					mapValue, _ := v.(map[string]interface{})
-					mapOutput, err := typedData.formatData(parsedType, mapValue)
-					if err != nil {
-						return nil, err
-					}
-					item.Value = mapOutput
-				} else {
-					primitiveOutput, err := formatPrimitiveValue(field.Type, encValue)
-					if err != nil {
-						return nil, err
-					}
-					item.Value = primitiveOutput
-				}
-			}
-		} else if typedData.Types[field.Type] != nil {
-			if mapValue, ok := encValue.(map[string]interface{}); ok {
-				mapOutput, err := typedData.formatData(field.Type, mapValue)
-				if err != nil {
-					return nil, err
-				}
-				item.Value = mapOutput
-			} else {
-				item.Value = "<nil>"
-			}
-		} else {
-			primitiveOutput, err := formatPrimitiveValue(field.Type, encValue)
+		value, err := typedData.formatValue(field.Type, data[field.Name])
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, &NameValueType{
+			Name:  field.Name,
+			Value: value,
+			Typ:   field.Type,
+		})
+	}
+	return output, nil
+}
+
+// formatValue formats a single member of a struct. Structs and arrays are
+// formatted into nested lists, with array elements named by their index.
+func (typedData *TypedData) formatValue(encType string, encValue interface{}) (interface{}, error) {
+	if strings.HasSuffix(encType, "]") {
+		elemType, _, err := parseArrayType(encType)
+		if err != nil {
+			return nil, err
+		}
+		arrayValue, _ := convertDataToSlice(encValue)
+		items := make([]*NameValueType, 0, len(arrayValue))
+		for i, v := range arrayValue {
+			value, err := typedData.formatValue(elemType, v)
 			if err != nil {
 				return nil, err
 			}
-			item.Value = primitiveOutput
+			items = append(items, &NameValueType{
+				Name:  fmt.Sprintf("[%d]", i),
+				Value: value,
+				Typ:   elemType,
+			})
 		}
-		output = append(output, item)
+		return items, nil
 	}
-	return output, nil
+	if typedData.Types[encType] != nil {
+		mapValue, ok := encValue.(map[string]interface{})
+		if !ok {
+			return "<nil>", nil
+		}
+		return typedData.formatData(encType, mapValue)
+	}
+	return formatPrimitiveValue(encType, encValue)
 }
 
 func formatPrimitiveValue(encType string, encValue interface{}) (string, error) {
@@ -721,6 +771,9 @@ func (t Types) validate() error {
 		if len(typeKey) == 0 {
 			return fmt.Errorf("empty type key")
 		}
+		if strings.ContainsAny(typeKey, "[]") {
+			return fmt.Errorf("type key %q cannot be an array", typeKey)
+		}
 		for i, typeObj := range typeArr {
 			if len(typeObj.Type) == 0 {
 				return fmt.Errorf("type %q:%d: empty Type", typeKey, i)
@@ -748,121 +801,47 @@ func (t Types) validate() error {
 
 // Checks if the primitive value is valid
 func isPrimitiveTypeValid(primitiveType string) bool {
-	if primitiveType == "address" ||
-		primitiveType == "address[]" ||
-		primitiveType == "bool" ||
-		primitiveType == "bool[]" ||
-		primitiveType == "string" ||
-		primitiveType == "string[]" {
-		return true
+	// Strip off and validate any array dimensions
+	for strings.HasSuffix(primitiveType, "]") {
+		elemType, _, err := parseArrayType(primitiveType)
+		if err != nil {
+			return false
+		}
+		primitiveType = elemType
 	}
-	if primitiveType == "bytes" ||
-		primitiveType == "bytes[]" ||
-		primitiveType == "bytes1" ||
-		primitiveType == "bytes1[]" ||
-		primitiveType == "bytes2" ||
-		primitiveType == "bytes2[]" ||
-		primitiveType == "bytes3" ||
-		primitiveType == "bytes3[]" ||
-		primitiveType == "bytes4" ||
-		primitiveType == "bytes4[]" ||
-		primitiveType == "bytes5" ||
-		primitiveType == "bytes5[]" ||
-		primitiveType == "bytes6" ||
-		primitiveType == "bytes6[]" ||
-		primitiveType == "bytes7" ||
-		primitiveType == "bytes7[]" ||
-		primitiveType == "bytes8" ||
-		primitiveType == "bytes8[]" ||
-		primitiveType == "bytes9" ||
-		primitiveType == "bytes9[]" ||
-		primitiveType == "bytes10" ||
-		primitiveType == "bytes10[]" ||
-		primitiveType == "bytes11" ||
-		primitiveType == "bytes11[]" ||
-		primitiveType == "bytes12" ||
-		primitiveType == "bytes12[]" ||
-		primitiveType == "bytes13" ||
-		primitiveType == "bytes13[]" ||
-		primitiveType == "bytes14" ||
-		primitiveType == "bytes14[]" ||
-		primitiveType == "bytes15" ||
-		primitiveType == "bytes15[]" ||
-		primitiveType == "bytes16" ||
-		primitiveType == "bytes16[]" ||
-		primitiveType == "bytes17" ||
-		primitiveType == "bytes17[]" ||
-		primitiveType == "bytes18" ||
-		primitiveType == "bytes18[]" ||
-		primitiveType == "bytes19" ||
-		primitiveType == "bytes19[]" ||
-		primitiveType == "bytes20" ||
-		primitiveType == "bytes20[]" ||
-		primitiveType == "bytes21" ||
-		primitiveType == "bytes21[]" ||
-		primitiveType == "bytes22" ||
-		primitiveType == "bytes22[]" ||
-		primitiveType == "bytes23" ||
-		primitiveType == "bytes23[]" ||
-		primitiveType == "bytes24" ||
-		primitiveType == "bytes24[]" ||
-		primitiveType == "bytes25" ||
-		primitiveType == "bytes25[]" ||
-		primitiveType == "bytes26" ||
-		primitiveType == "bytes26[]" ||
-		primitiveType == "bytes27" ||
-		primitiveType == "bytes27[]" ||
-		primitiveType == "bytes28" ||
-		primitiveType == "bytes28[]" ||
-		primitiveType == "bytes29" ||
-		primitiveType == "bytes29[]" ||
-		primitiveType == "bytes30" ||
-		primitiveType == "bytes30[]" ||
-		primitiveType == "bytes31" ||
-		primitiveType == "bytes31[]" ||
-		primitiveType == "bytes32" ||
-		primitiveType == "bytes32[]" {
+	switch primitiveType {
+	case "address", "bool", "string", "bytes", "int", "uint":
 		return true
 	}
-	if primitiveType == "int" ||
-		primitiveType == "int[]" ||
-		primitiveType == "int8" ||
-		primitiveType == "int8[]" ||
-		primitiveType == "int16" ||
-		primitiveType == "int16[]" ||
-		primitiveType == "int32" ||
-		primitiveType == "int32[]" ||
-		primitiveType == "int64" ||
-		primitiveType == "int64[]" ||
-		primitiveType == "int96" ||
-		primitiveType == "int96[]" ||
-		primitiveType == "int128" ||
-		primitiveType == "int128[]" ||
-		primitiveType == "int256" ||
-		primitiveType == "int256[]" {
-		return true
+	if size, ok := typeSize(primitiveType, "bytes"); ok {
+		return size >= 1 && size <= 32
 	}
-	if primitiveType == "uint" ||
-		primitiveType == "uint[]" ||
-		primitiveType == "uint8" ||
-		primitiveType == "uint8[]" ||
-		primitiveType == "uint16" ||
-		primitiveType == "uint16[]" ||
-		primitiveType == "uint32" ||
-		primitiveType == "uint32[]" ||
-		primitiveType == "uint64" ||
-		primitiveType == "uint64[]" ||
-		primitiveType == "uint96" ||
-		primitiveType == "uint96[]" ||
-		primitiveType == "uint128" ||
-		primitiveType == "uint128[]" ||
-		primitiveType == "uint256" ||
-		primitiveType == "uint256[]" {
-		return true
+	if size, ok := typeSize(primitiveType, "int"); ok {
+		return size >= 8 && size <= 256 && size%8 == 0
+	}
+	if size, ok := typeSize(primitiveType, "uint"); ok {
+		return size >= 8 && size <= 256 && size%8 == 0
 	}
 	return false
 }
 
+// typeSize parses the size of a sized primitive type such as uint64 or bytes4,
+// rejecting non-canonical sizes with leading zeroes.
+func typeSize(typ string, prefix string) (int, bool) {
+	if !strings.HasPrefix(typ, prefix) {
+		return 0, false
+	}
+	suffix := typ[len(prefix):]
+	if len(suffix) == 0 || suffix[0] < '1' || suffix[0] > '9' {
+		return 0, false
+	}
+	size, err := strconv.Atoi(suffix)
+	if err != nil {
+		return 0, false
+	}
+	return size, true
+}
+
 // validate checks if the given domain is valid, i.e. contains at least
 // the minimum viable keys and values
 func (domain *TypedDataDomain) validate() error {
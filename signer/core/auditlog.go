@@ -113,6 +113,16 @@ func (l *AuditLogger) Version(ctx context.Context) (string, error) {
 }
 
 func NewAuditLogger(path string, api ExternalAPI) (*AuditLogger, error) {
+	l, err := NewAuditLog(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuditLoggerWithLog(l, api), nil
+}
+
+// NewAuditLog creates a logfmt formatted audit log writing into the given file,
+// which can be shared between the audited components.
+func NewAuditLog(path string) (log.Logger, error) {
 	l := log.New("api", "signer")
 	handler, err := log.FileHandler(path, log.LogfmtFormat())
 	if err != nil {
@@ -120,5 +130,17 @@ func NewAuditLogger(path string, api ExternalAPI) (*AuditLogger, error) {
 	}
 	l.SetHandler(handler)
 	l.Info("Configured", "audit log", path)
-	return &AuditLogger{l, api}, nil
+	return l, nil
+}
+
+// NewAuditLoggerWithLog wraps the external API, recording all requests and
+// responses into the given audit log.
+func NewAuditLoggerWithLog(l log.Logger, api ExternalAPI) *AuditLogger {
+	return &AuditLogger{l, api}
+}
+
+// auditQuorum records an event of the multi-operator approval of a transaction
+// signing request into the audit log.
+func auditQuorum(l log.Logger, event string, id uint64, hash common.Hash, ctx ...interface{}) {
+	l.Info(event, append([]interface{}{"type", "quorum", "id", id, "hash", hash}, ctx...)...)
 }
@@ -172,6 +172,13 @@ func (ui *CommandlineUI) ApproveSignData(request *SignDataRequest) (SignDataResp
 		}
 		fmt.Println()
 	}
+	if len(request.Summary) != 0 {
+		fmt.Printf("Summary:\n")
+		for _, line := range request.Summary {
+			fmt.Printf("  %s\n", line)
+		}
+		fmt.Println()
+	}
 	fmt.Printf("messages:\n")
 	for _, nvt := range request.Messages {
 		fmt.Printf("\u00a0\u00a0%v\n", strings.TrimSpace(nvt.Pprint(1)))
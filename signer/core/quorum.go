@@ -0,0 +1,343 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// QuorumApprove is the decision an operator signs to approve a request.
+	QuorumApprove = "approve"
+
+	// QuorumVeto is the decision an operator signs to veto a request.
+	QuorumVeto = "veto"
+)
+
+var (
+	// ErrUnknownRequest is returned if an operator decides on a request which is
+	// not (or no longer) pending.
+	ErrUnknownRequest = errors.New("unknown or expired request")
+
+	// ErrUnknownOperator is returned if a decision is not signed by any of the
+	// registered operators.
+	ErrUnknownOperator = errors.New("decision not signed by a registered operator")
+
+	// ErrQuorumModified is returned if the next handler modifies a transaction
+	// after it was approved by the quorum.
+	ErrQuorumModified = errors.New("transaction modified after quorum approval")
+)
+
+// QuorumUI is an implementation of UIClientAPI which holds transaction signing
+// requests pending until M of N registered operators approve them. Any single
+// operator can veto a request, and requests not reaching a quorum within the
+// timeout are rejected. Operators authenticate their decisions by signing them
+// with their own keys, via the QuorumAPI. Transactions reaching a quorum, and all
+// other requests, are forwarded to the next handler.
+type QuorumUI struct {
+	next      UIClientAPI                 // Handler to forward approved transactions and other requests to
+	session   common.Hash                 // Random nonce binding decisions to this process
+	operators map[common.Address]struct{} // Operators allowed to decide on requests
+	threshold int                         // Number of approvals required to accept a request
+	timeout   time.Duration               // Time after which a pending request is rejected
+	audit     log.Logger                  // Audit log to record the decisions into
+
+	pending map[uint64]*quorumRequest // Requests waiting for a decision
+	nextID  uint64                    // Identifier to assign to the next request
+	lock    sync.Mutex                // Protects the pending requests
+}
+
+// quorumRequest is a transaction signing request waiting for operator decisions.
+type quorumRequest struct {
+	id        uint64
+	hash      common.Hash
+	request   *SignTxRequest
+	expires   time.Time
+	approvals map[common.Address]struct{}
+	decided   chan bool // Receives the final decision, buffered
+}
+
+// PendingRequest is a transaction signing request waiting for operator decisions.
+type PendingRequest struct {
+	ID        uint64           `json:"id"`        // Identifier of the request
+	Session   common.Hash      `json:"session"`   // Nonce of the signer process, to be included in the decisions
+	Hash      common.Hash      `json:"hash"`      // Hash of the request, to be included in the decisions
+	Request   *SignTxRequest   `json:"request"`   // The request itself
+	Approvals []common.Address `json:"approvals"` // Operators having approved the request so far
+	Expires   time.Time        `json:"expires"`   // Time after which the request is rejected
+}
+
+// NewQuorumUI creates a multi-operator approval UI requiring threshold approvals
+// out of the given operators within the timeout. The audit log is optional.
+func NewQuorumUI(next UIClientAPI, operators []common.Address, threshold int, timeout time.Duration, audit log.Logger) (*QuorumUI, error) {
+	set := make(map[common.Address]struct{})
+	for _, operator := range operators {
+		set[operator] = struct{}{}
+	}
+	if threshold < 1 || threshold > len(set) {
+		return nil, fmt.Errorf("invalid threshold %d for %d operators", threshold, len(set))
+	}
+	// Request identifiers restart with every process, so bind the decisions to
+	// a random session nonce to prevent replaying them after a restart.
+	var session common.Hash
+	if _, err := rand.Read(session[:]); err != nil {
+		return nil, err
+	}
+	if audit == nil {
+		audit = log.New()
+		audit.SetHandler(log.DiscardHandler())
+	}
+	return &QuorumUI{
+		next:      next,
+		session:   session,
+		operators: set,
+		threshold: threshold,
+		timeout:   timeout,
+		audit:     audit,
+		pending:   make(map[uint64]*quorumRequest),
+	}, nil
+}
+
+// QuorumDecisionHash returns the hash an operator needs to sign to decide on a
+// pending request. The hash is calculated as
+//
+//	keccak256("\x19Ethereum Signed Message:\n"${message length}${message})
+//
+// where message is "clef quorum <decision> <session> <id> <request hash>", so
+// decisions can be signed with any tool supporting personal_sign.
+func QuorumDecisionHash(decision string, session common.Hash, id uint64, hash common.Hash) []byte {
+	return accounts.TextHash([]byte(fmt.Sprintf("clef quorum %s %s %d %s", decision, session.Hex(), id, hash.Hex())))
+}
+
+// ApproveTx holds the transaction pending until enough operators approve it, any
+// operator vetoes it or it times out. Once approved by the quorum, the decision
+// is left to the next handler, which may not modify the transaction anymore.
+func (q *QuorumUI) ApproveTx(request *SignTxRequest) (SignTxResponse, error) {
+	blob, err := json.Marshal(request.Transaction)
+	if err != nil {
+		return SignTxResponse{Approved: false}, err
+	}
+	q.lock.Lock()
+	req := &quorumRequest{
+		id:        q.nextID,
+		hash:      crypto.Keccak256Hash(blob),
+		request:   request,
+		expires:   time.Now().Add(q.timeout),
+		approvals: make(map[common.Address]struct{}),
+		decided:   make(chan bool, 1),
+	}
+	q.pending[req.id] = req
+	q.nextID++
+	q.lock.Unlock()
+
+	auditQuorum(q.audit, "QuorumRequest", req.id, req.hash, "tx", request.Transaction.String(), "metadata", request.Meta.String())
+	q.next.ShowInfo(fmt.Sprintf("Transaction %d pending approval by %d of %d operators", req.id, q.threshold, len(q.operators)))
+
+	timer := time.NewTimer(q.timeout)
+	defer timer.Stop()
+
+	var approved bool
+	select {
+	case approved = <-req.decided:
+	case <-timer.C:
+		q.lock.Lock()
+		// A decision may have raced with the timeout, honour it
+		select {
+		case approved = <-req.decided:
+		default:
+			delete(q.pending, req.id)
+			auditQuorum(q.audit, "QuorumTimeout", req.id, req.hash, "approvals", len(req.approvals))
+		}
+		q.lock.Unlock()
+	}
+	if !approved {
+		return SignTxResponse{Approved: false}, nil
+	}
+	resp, err := q.next.ApproveTx(request)
+	if err != nil || !resp.Approved {
+		return resp, err
+	}
+	// The signed transaction must be the one the operators approved
+	if blob, err = json.Marshal(resp.Transaction); err != nil {
+		return SignTxResponse{Approved: false}, err
+	}
+	if hash := crypto.Keccak256Hash(blob); hash != req.hash {
+		auditQuorum(q.audit, "QuorumModified", req.id, req.hash, "modified", hash, "tx", resp.Transaction.String())
+		return SignTxResponse{Approved: false}, ErrQuorumModified
+	}
+	return resp, nil
+}
+
+// Pending returns all the requests waiting for operator decisions.
+func (q *QuorumUI) Pending() []*PendingRequest {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	pending := make([]*PendingRequest, 0, len(q.pending))
+	for _, req := range q.pending {
+		approvals := make([]common.Address, 0, len(req.approvals))
+		for operator := range req.approvals {
+			approvals = append(approvals, operator)
+		}
+		pending = append(pending, &PendingRequest{
+			ID:        req.id,
+			Session:   q.session,
+			Hash:      req.hash,
+			Request:   req.request,
+			Approvals: approvals,
+			Expires:   req.expires,
+		})
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+	return pending
+}
+
+// Decide records a signed operator decision on a pending request. The request
+// is approved when the threshold is reached, and rejected on the first veto.
+func (q *QuorumUI) Decide(id uint64, decision string, signature []byte) error {
+	if decision != QuorumApprove && decision != QuorumVeto {
+		return fmt.Errorf("invalid decision %q", decision)
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	req, ok := q.pending[id]
+	if !ok {
+		return ErrUnknownRequest
+	}
+	operator, err := recoverOperator(QuorumDecisionHash(decision, q.session, id, req.hash), signature)
+	if err != nil {
+		return err
+	}
+	if _, ok := q.operators[operator]; !ok {
+		auditQuorum(q.audit, "QuorumUnauthorized", id, req.hash, "signer", operator, "decision", decision)
+		return ErrUnknownOperator
+	}
+	if decision == QuorumVeto {
+		delete(q.pending, id)
+		auditQuorum(q.audit, "QuorumVeto", id, req.hash, "operator", operator)
+		req.decided <- false
+		return nil
+	}
+	req.approvals[operator] = struct{}{}
+	auditQuorum(q.audit, "QuorumApproval", id, req.hash, "operator", operator, "approvals", len(req.approvals), "threshold", q.threshold)
+
+	if len(req.approvals) >= q.threshold {
+		delete(q.pending, id)
+		auditQuorum(q.audit, "QuorumReached", id, req.hash, "approvals", len(req.approvals))
+		req.decided <- true
+	}
+	return nil
+}
+
+// recoverOperator recovers the address of the operator having signed a decision.
+func recoverOperator(hash []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes long", crypto.SignatureLength)
+	}
+	sig := common.CopyBytes(signature)
+	if sig[crypto.RecoveryIDOffset] == 27 || sig[crypto.RecoveryIDOffset] == 28 {
+		sig[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V from 27/28 to 0/1
+	}
+	pubkey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+func (q *QuorumUI) ApproveSignData(request *SignDataRequest) (SignDataResponse, error) {
+	return q.next.ApproveSignData(request)
+}
+
+func (q *QuorumUI) ApproveListing(request *ListRequest) (ListResponse, error) {
+	return q.next.ApproveListing(request)
+}
+
+func (q *QuorumUI) ApproveNewAccount(request *NewAccountRequest) (NewAccountResponse, error) {
+	return q.next.ApproveNewAccount(request)
+}
+
+func (q *QuorumUI) ShowError(message string) {
+	q.next.ShowError(message)
+}
+
+func (q *QuorumUI) ShowInfo(message string) {
+	q.next.ShowInfo(message)
+}
+
+func (q *QuorumUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	q.next.OnApprovedTx(tx)
+}
+
+func (q *QuorumUI) OnSignerStartup(info StartupInfo) {
+	q.next.OnSignerStartup(info)
+}
+
+func (q *QuorumUI) OnInputRequired(info UserInputRequest) (UserInputResponse, error) {
+	return q.next.OnInputRequired(info)
+}
+
+func (q *QuorumUI) RegisterUIServer(api *UIServerAPI) {
+	q.next.RegisterUIServer(api)
+}
+
+// QuorumAPI is the API operators use to inspect and decide on pending requests.
+// Every decision needs to be signed by the operator's key, so the API is safe
+// to be exposed next to the external API.
+type QuorumAPI struct {
+	ui *QuorumUI
+}
+
+// NewQuorumAPI creates a new operator API for the given quorum UI.
+func NewQuorumAPI(ui *QuorumUI) *QuorumAPI {
+	return &QuorumAPI{ui}
+}
+
+// PendingRequests returns all the requests waiting for operator decisions.
+// Example call
+// {"jsonrpc":"2.0","method":"operator_pendingRequests","params":[], "id":1}
+func (api *QuorumAPI) PendingRequests() []*PendingRequest {
+	return api.ui.Pending()
+}
+
+// Approve records the signed approval of an operator on a pending request.
+// Example call
+// {"jsonrpc":"2.0","method":"operator_approve","params":[0, "0x..."], "id":2}
+func (api *QuorumAPI) Approve(id uint64, signature hexutil.Bytes) error {
+	return api.ui.Decide(id, QuorumApprove, signature)
+}
+
+// Veto records the signed veto of an operator on a pending request, rejecting it.
+// Example call
+// {"jsonrpc":"2.0","method":"operator_veto","params":[0, "0x..."], "id":3}
+func (api *QuorumAPI) Veto(id uint64, signature hexutil.Bytes) error {
+	return api.ui.Decide(id, QuorumVeto, signature)
+}
@@ -0,0 +1,228 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// quorumTester bundles a quorum UI with the keys of its operators.
+type quorumTester struct {
+	ui   *core.QuorumUI
+	next *headlessUi
+	keys []*ecdsa.PrivateKey
+}
+
+func newQuorumTester(t *testing.T, operators int, threshold int, timeout time.Duration) *quorumTester {
+	qt := &quorumTester{next: &headlessUi{approveCh: make(chan string, 1)}}
+	var addrs []common.Address
+	for i := 0; i < operators; i++ {
+		key, _ := crypto.GenerateKey()
+		qt.keys = append(qt.keys, key)
+		addrs = append(addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	ui, err := core.NewQuorumUI(qt.next, addrs, threshold, timeout, nil)
+	if err != nil {
+		t.Fatalf("failed to create quorum UI: %v", err)
+	}
+	qt.ui = ui
+	return qt
+}
+
+// request submits a transaction for approval in the background, returning the
+// channel delivering the outcome and the pending request once it is registered.
+func (qt *quorumTester) request(t *testing.T) (chan bool, *core.PendingRequest) {
+	from, _ := common.NewMixedcaseAddressFromString("0x000000000000000000000000000000000000dead")
+	result := make(chan bool, 1)
+	go func() {
+		resp, err := qt.ui.ApproveTx(&core.SignTxRequest{Transaction: apitypes.SendTxArgs{From: *from, To: from}})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		result <- resp.Approved
+	}()
+	for i := 0; i < 100; i++ {
+		if pending := qt.ui.Pending(); len(pending) > 0 {
+			return result, pending[len(pending)-1]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("request never became pending")
+	return nil, nil
+}
+
+// decide signs a decision with the given key and submits it.
+func (qt *quorumTester) decide(key *ecdsa.PrivateKey, decision string, req *core.PendingRequest) error {
+	sig, _ := crypto.Sign(core.QuorumDecisionHash(decision, req.Session, req.ID, req.Hash), key)
+	sig[crypto.RecoveryIDOffset] += 27
+	return qt.ui.Decide(req.ID, decision, sig)
+}
+
+func TestQuorumApproval(t *testing.T) {
+	qt := newQuorumTester(t, 3, 2, time.Minute)
+	result, req := qt.request(t)
+
+	// A single approval (even if repeated) should not suffice
+	for i := 0; i < 2; i++ {
+		if err := qt.decide(qt.keys[0], core.QuorumApprove, req); err != nil {
+			t.Fatalf("failed to approve: %v", err)
+		}
+	}
+	select {
+	case <-result:
+		t.Fatalf("request decided before reaching quorum")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// Approvals by non-operators should be refused
+	stranger, _ := crypto.GenerateKey()
+	if err := qt.decide(stranger, core.QuorumApprove, req); err != core.ErrUnknownOperator {
+		t.Fatalf("stranger approval error mismatch: have %v, want %v", err, core.ErrUnknownOperator)
+	}
+	// The second operator should push it over the threshold, handing the
+	// request to the next handler
+	qt.next.approveCh <- "Y"
+	if err := qt.decide(qt.keys[1], core.QuorumApprove, req); err != nil {
+		t.Fatalf("failed to approve: %v", err)
+	}
+	if !<-result {
+		t.Fatalf("expected request to be approved")
+	}
+	if err := qt.decide(qt.keys[2], core.QuorumApprove, req); err != core.ErrUnknownRequest {
+		t.Fatalf("late approval error mismatch: have %v, want %v", err, core.ErrUnknownRequest)
+	}
+}
+
+func TestQuorumVeto(t *testing.T) {
+	qt := newQuorumTester(t, 3, 2, time.Minute)
+	result, req := qt.request(t)
+
+	if err := qt.decide(qt.keys[0], core.QuorumApprove, req); err != nil {
+		t.Fatalf("failed to approve: %v", err)
+	}
+	// A signature for a different decision must not be accepted
+	sig, _ := crypto.Sign(core.QuorumDecisionHash(core.QuorumApprove, req.Session, req.ID, req.Hash), qt.keys[1])
+	if err := qt.ui.Decide(req.ID, core.QuorumVeto, sig); err != core.ErrUnknownOperator {
+		t.Fatalf("mismatched decision error mismatch: have %v, want %v", err, core.ErrUnknownOperator)
+	}
+	if err := qt.decide(qt.keys[1], core.QuorumVeto, req); err != nil {
+		t.Fatalf("failed to veto: %v", err)
+	}
+	if <-result {
+		t.Fatalf("expected request to be vetoed")
+	}
+	if len(qt.ui.Pending()) != 0 {
+		t.Fatalf("vetoed request still pending")
+	}
+}
+
+func TestQuorumTimeout(t *testing.T) {
+	qt := newQuorumTester(t, 2, 2, 200*time.Millisecond)
+	result, req := qt.request(t)
+
+	if err := qt.decide(qt.keys[0], core.QuorumApprove, req); err != nil {
+		t.Fatalf("failed to approve: %v", err)
+	}
+	if <-result {
+		t.Fatalf("expected request to time out")
+	}
+	if err := qt.decide(qt.keys[1], core.QuorumApprove, req); err != core.ErrUnknownRequest {
+		t.Fatalf("expired approval error mismatch: have %v, want %v", err, core.ErrUnknownRequest)
+	}
+}
+
+// Tests that requests approved by the quorum can still be rejected by the next
+// handler in the chain.
+func TestQuorumNextRejection(t *testing.T) {
+	qt := newQuorumTester(t, 1, 1, time.Minute)
+	result, req := qt.request(t)
+
+	qt.next.approveCh <- "N"
+	if err := qt.decide(qt.keys[0], core.QuorumApprove, req); err != nil {
+		t.Fatalf("failed to approve: %v", err)
+	}
+	if <-result {
+		t.Fatalf("expected request to be rejected by the next handler")
+	}
+}
+
+// Tests that the next handler cannot modify a transaction approved by the quorum.
+func TestQuorumNextModification(t *testing.T) {
+	qt := newQuorumTester(t, 1, 1, time.Minute)
+	from, _ := common.NewMixedcaseAddressFromString("0x000000000000000000000000000000000000dead")
+
+	errc := make(chan error, 1)
+	go func() {
+		resp, err := qt.ui.ApproveTx(&core.SignTxRequest{Transaction: apitypes.SendTxArgs{From: *from, To: from}})
+		if resp.Approved {
+			t.Errorf("modified transaction approved")
+		}
+		errc <- err
+	}()
+	var req *core.PendingRequest
+	for i := 0; i < 100 && req == nil; i++ {
+		if pending := qt.ui.Pending(); len(pending) > 0 {
+			req = pending[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if req == nil {
+		t.Fatalf("request not pending")
+	}
+	qt.next.approveCh <- "M"
+	if err := qt.decide(qt.keys[0], core.QuorumApprove, req); err != nil {
+		t.Fatalf("failed to approve: %v", err)
+	}
+	if err := <-errc; err != core.ErrQuorumModified {
+		t.Fatalf("modification error mismatch: have %v, want %v", err, core.ErrQuorumModified)
+	}
+}
+
+// Tests that decisions signed for a previous signer process are not accepted
+// after a restart, even though request identifiers start over.
+func TestQuorumReplay(t *testing.T) {
+	qt := newQuorumTester(t, 1, 1, 200*time.Millisecond)
+	_, req := qt.request(t)
+
+	sig, _ := crypto.Sign(core.QuorumDecisionHash(core.QuorumApprove, req.Session, req.ID, req.Hash), qt.keys[0])
+
+	// Restart the quorum UI with the same operator and request the same transaction
+	restarted, err := core.NewQuorumUI(qt.next, []common.Address{crypto.PubkeyToAddress(qt.keys[0].PublicKey)}, 1, 200*time.Millisecond, nil)
+	if err != nil {
+		t.Fatalf("failed to create quorum UI: %v", err)
+	}
+	qt.ui = restarted
+	result, replayed := qt.request(t)
+	if replayed.ID != req.ID || replayed.Hash != req.Hash {
+		t.Fatalf("restarted request mismatch: have %d/%x, want %d/%x", replayed.ID, replayed.Hash, req.ID, req.Hash)
+	}
+	if replayed.Session == req.Session {
+		t.Fatalf("session nonce reused across restarts")
+	}
+	if err := restarted.Decide(replayed.ID, core.QuorumApprove, sig); err != core.ErrUnknownOperator {
+		t.Fatalf("replayed approval error mismatch: have %v, want %v", err, core.ErrUnknownOperator)
+	}
+	if <-result {
+		t.Fatalf("expected replayed request to time out")
+	}
+}
@@ -53,11 +53,14 @@ func (api *SignerAPI) sign(req *SignDataRequest, legacyV bool) (hexutil.Bytes, e
 	if err != nil {
 		return nil, err
 	}
-	pw, err := api.lookupOrQueryPassword(account.Address,
-		"Password for signing",
-		fmt.Sprintf("Please enter password for signing data with account %s", account.Address.Hex()))
-	if err != nil {
-		return nil, err
+	var pw string
+	if needsPassword(wallet) {
+		pw, err = api.lookupOrQueryPassword(account.Address,
+			"Password for signing",
+			fmt.Sprintf("Please enter password for signing data with account %s", account.Address.Hex()))
+		if err != nil {
+			return nil, err
+		}
 	}
 	// Sign the data with the wallet
 	signature, err := wallet.SignDataWithPassphrase(account, pw, req.ContentType, req.Rawdata)
@@ -286,7 +289,9 @@ func typedDataRequest(data any) (*SignDataRequest, error) {
 		ContentType: apitypes.DataTyped.Mime,
 		Rawdata:     []byte(rawData),
 		Messages:    messages,
-		Hash:        sighash}, nil
+		Hash:        sighash,
+		TypedData:   &typedData,
+		Summary:     typedData.Summary()}, nil
 }
 
 // EcRecover recovers the address associated with the given sig.
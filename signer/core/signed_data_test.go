@@ -338,6 +338,103 @@ func TestEncodeData(t *testing.T) {
 	}
 }
 
+// loadTypedData loads a typed data message from the testdata folder.
+func loadTypedData(t *testing.T, file string) apitypes.TypedData {
+	t.Helper()
+
+	blob, err := os.ReadFile(path.Join("testdata", file))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", file, err)
+	}
+	var td apitypes.TypedData
+	if err := json.Unmarshal(blob, &td); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", file, err)
+	}
+	return td
+}
+
+// Tests arrays of structs and primitives against the reference vector used by
+// the signTypedData_v4 implementation of MetaMask's eth-sig-util.
+func TestTypedDataArraysReference(t *testing.T) {
+	td := loadTypedData(t, "arrays-2.json")
+
+	if have, want := string(td.EncodeType("Mail")), "Mail(Person from,Person[] to,string contents)Person(string name,address[] wallets)"; have != want {
+		t.Errorf("encodeType mismatch: have %s, want %s", have, want)
+	}
+	domain, err := td.HashStruct("EIP712Domain", td.Domain.Map())
+	if err != nil {
+		t.Fatalf("failed to hash domain: %v", err)
+	}
+	if have, want := domain.String(), "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"; have != want {
+		t.Errorf("domain separator mismatch: have %s, want %s", have, want)
+	}
+	message, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		t.Fatalf("failed to hash message: %v", err)
+	}
+	if have, want := message.String(), "0xeb4221181ff3f1a83ea7313993ca9218496e424604ba9492bb4052c03d5c3df8"; have != want {
+		t.Errorf("message hash mismatch: have %s, want %s", have, want)
+	}
+	digest, _, err := apitypes.TypedDataAndHash(td)
+	if err != nil {
+		t.Fatalf("failed to hash typed data: %v", err)
+	}
+	if have, want := hexutil.Encode(digest), "0xa85c2e2b118698e88db68a8105b794a8cc7cec074e89ef991cb4f5f533819cc2"; have != want {
+		t.Errorf("digest mismatch: have %s, want %s", have, want)
+	}
+}
+
+// Tests that nested and fixed size arrays are encoded recursively, each level
+// being the keccak256 hash of the concatenated encodings of its elements.
+func TestTypedDataNestedArrays(t *testing.T) {
+	td := loadTypedData(t, "nested_arrays.json")
+
+	word := func(v int64) []byte { return math.U256Bytes(big.NewInt(v)) }
+	concat := func(items ...[]byte) []byte { return bytes.Join(items, nil) }
+	point := func(x int64, tag string) []byte {
+		return crypto.Keccak256(concat(
+			crypto.Keccak256([]byte("Point(uint256 x,bytes4 tag)")),
+			word(x),
+			common.RightPadBytes(common.FromHex(tag), 32),
+		))
+	}
+	want := crypto.Keccak256(concat(
+		crypto.Keccak256([]byte("Grid(uint8[2][] cells,Point[][] points,string[3] labels)Point(uint256 x,bytes4 tag)")),
+		crypto.Keccak256(concat(
+			crypto.Keccak256(concat(word(1), word(2))),
+			crypto.Keccak256(concat(word(3), word(4))),
+		)),
+		crypto.Keccak256(concat(
+			crypto.Keccak256(concat(point(1, "0x01020304"), point(2, "0x05060708"))),
+			crypto.Keccak256(nil),
+		)),
+		crypto.Keccak256(concat(
+			crypto.Keccak256([]byte("a")),
+			crypto.Keccak256([]byte("b")),
+			crypto.Keccak256([]byte("c")),
+		)),
+	))
+	have, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		t.Fatalf("failed to hash message: %v", err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Errorf("message hash mismatch: have %x, want %x", have, want)
+	}
+	// Ensure the nested arrays are formatted element by element
+	formatted, err := td.Format()
+	if err != nil {
+		t.Fatalf("failed to format message: %v", err)
+	}
+	cells := formatted[1].Value.([]*apitypes.NameValueType)[0].Value.([]*apitypes.NameValueType)
+	if len(cells) != 2 || cells[1].Name != "[1]" || cells[1].Typ != "uint8[2]" {
+		t.Fatalf("unexpected formatting of nested array: %v", cells)
+	}
+	if inner := cells[1].Value.([]*apitypes.NameValueType); len(inner) != 2 || inner[0].Value != "3 (0x3)" {
+		t.Fatalf("unexpected formatting of inner array: %v", inner)
+	}
+}
+
 func TestFormatter(t *testing.T) {
 	var d apitypes.TypedData
 	err := json.Unmarshal([]byte(jsonTypedData), &d)
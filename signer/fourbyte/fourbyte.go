@@ -114,6 +114,13 @@ func (db *Database) Selector(id []byte) (string, error) {
 	return "", fmt.Errorf("signature %v not found", sig)
 }
 
+// VerifySelector checks whether the ABI encoded call data matches the requested
+// method signature, without stuffing any extra data into the arguments.
+func (db *Database) VerifySelector(selector string, calldata []byte) error {
+	_, err := verifySelector(selector, calldata)
+	return err
+}
+
 // AddSelector inserts a new 4byte entry into the database. If custom database
 // saving is enabled, the new dataset is also persisted to disk.
 //
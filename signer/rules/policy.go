@@ -0,0 +1,454 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/signer/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// Actions a policy can take on requests not covered by it.
+const (
+	PolicyReject = "reject" // Request is rejected without user interaction
+	PolicyManual = "manual" // Request is forwarded to the user for manual processing
+)
+
+// Policy is a declarative ruleset for automatically approving requests. Every
+// request covered by the policy is approved, everything else is handled via the
+// default action.
+type Policy struct {
+	Default      string          `json:"default"`      // Action for requests not covered by the policy (manual or reject)
+	Listing      bool            `json:"listing"`      // Whether account listing requests are approved
+	Transactions *TxPolicy       `json:"transactions"` // Rules for approving transactions
+	SignData     *SignDataPolicy `json:"signData"`     // Rules for approving data signing
+}
+
+// TxPolicy contains the rules for automatically approving transactions.
+type TxPolicy struct {
+	Senders    []common.Address `json:"senders"`    // Accounts allowed to send (empty = any)
+	Recipients []*Recipient     `json:"recipients"` // Allow-listed recipients
+}
+
+// Recipient is an allow-listed transaction recipient, along with the limits on
+// the transactions sent to it.
+type Recipient struct {
+	Address    common.Address        `json:"address"`    // Address of the allowed recipient
+	DailyLimit *math.HexOrDecimal256 `json:"dailyLimit"` // Total value allowed per UTC day in wei (nil = unlimited)
+	Methods    []string              `json:"methods"`    // Allowed method signatures (plain transfers are always allowed)
+
+	selectors map[[4]byte]string // Method IDs of the allowed methods, mapped to their signatures
+}
+
+// SignDataPolicy contains the rules for automatically approving data signing.
+type SignDataPolicy struct {
+	TypedDataDomains []*TypedDataDomain `json:"typedDataDomains"` // Allowed EIP-712 domains
+}
+
+// TypedDataDomain is an allow-listed EIP-712 domain. Empty fields match any value.
+type TypedDataDomain struct {
+	Name              string                `json:"name"`
+	Version           string                `json:"version"`
+	ChainId           *math.HexOrDecimal256 `json:"chainId"`
+	VerifyingContract *common.Address       `json:"verifyingContract"`
+	PrimaryTypes      []string              `json:"primaryTypes"`
+}
+
+// CalldataVerifier checks whether the ABI encoded call data matches a textual
+// method signature. fourbyte.Database implements it.
+type CalldataVerifier interface {
+	VerifySelector(selector string, calldata []byte) error
+}
+
+// ParsePolicy parses a policy from its YAML or JSON representation (the latter
+// being a subset of the former) and validates it.
+func ParsePolicy(data []byte) (*Policy, error) {
+	// Decode the YAML into a generic form and reencode it as JSON to reuse all
+	// the JSON unmarshalling logic of the common types
+	var generic interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	blob, err := json.Marshal(generic)
+	if err != nil {
+		return nil, err
+	}
+	policy := new(Policy)
+	if err := json.Unmarshal(blob, policy); err != nil {
+		return nil, err
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// validate checks the policy for errors and precomputes the method selectors.
+func (p *Policy) validate() error {
+	switch p.Default {
+	case "":
+		p.Default = PolicyManual
+	case PolicyManual, PolicyReject:
+	default:
+		return fmt.Errorf("invalid default action %q", p.Default)
+	}
+	if p.Transactions != nil {
+		seen := make(map[common.Address]bool)
+		for _, recipient := range p.Transactions.Recipients {
+			if seen[recipient.Address] {
+				return fmt.Errorf("duplicate recipient %v", recipient.Address)
+			}
+			seen[recipient.Address] = true
+
+			recipient.selectors = make(map[[4]byte]string)
+			for _, method := range recipient.Methods {
+				method = strings.ReplaceAll(method, " ", "")
+				if _, err := abi.ParseSelector(method); err != nil {
+					return fmt.Errorf("invalid method %q for recipient %v: %v", method, recipient.Address, err)
+				}
+				var id [4]byte
+				copy(id[:], crypto.Keccak256([]byte(method))[:4])
+				recipient.selectors[id] = method
+			}
+		}
+	}
+	return nil
+}
+
+// policyUI provides an implementation of UIClientAPI that evaluates a declarative
+// policy for each approval request, forwarding to the next handler for requests
+// not covered by it.
+type policyUI struct {
+	next     core.UIClientAPI // The next handler, for manual processing
+	storage  storage.Storage  // Persistent storage for the spend counters
+	verifier CalldataVerifier // Optional verifier for the call data of method calls
+	policy   *Policy          // The policy to use
+
+	pending []*reservation   // Spends of approved transactions, awaiting their signing
+	now     func() time.Time // Clock to use for the daily limits, overridable for testing
+	lock    sync.Mutex       // Protects the spend counters from concurrent approvals
+}
+
+// reservationTimeout is the time after which the spend of an approved, but not
+// signed transaction is released (e.g. if signing failed or was rejected later).
+const reservationTimeout = 10 * time.Minute
+
+// reservation is the value of an approved transaction, counted against the
+// daily limit of its recipient until the transaction is signed.
+type reservation struct {
+	key     string         // Storage key of the spend counter to record into
+	to      common.Address // Recipient of the approved transaction
+	nonce   uint64         // Nonce of the approved transaction
+	value   *big.Int       // Value of the approved transaction
+	expires time.Time      // Time after which the reservation is dropped
+}
+
+// NewPolicyEvaluator creates a policy based rule engine, storing the spend counters
+// in the given storage and verifying call data with the optional verifier.
+func NewPolicyEvaluator(next core.UIClientAPI, counters storage.Storage, verifier CalldataVerifier) (*policyUI, error) {
+	return &policyUI{
+		next:     next,
+		storage:  counters,
+		verifier: verifier,
+		now:      time.Now,
+	}, nil
+}
+
+// Init parses the policy to evaluate requests against.
+func (p *policyUI) Init(policy []byte) error {
+	parsed, err := ParsePolicy(policy)
+	if err != nil {
+		return err
+	}
+	p.policy = parsed
+	return nil
+}
+
+func (p *policyUI) RegisterUIServer(api *core.UIServerAPI) {
+	p.next.RegisterUIServer(api)
+}
+
+// spentKey returns the storage key of the spend counter for a recipient on the
+// current day.
+func (p *policyUI) spentKey(recipient common.Address) string {
+	return fmt.Sprintf("spent/%x/%s", recipient, p.now().UTC().Format("2006-01-02"))
+}
+
+// checkTx evaluates a transaction against the policy, reserving its value in the
+// daily spend of the recipient if approved. The spend is only recorded in the
+// persistent counter once the transaction is signed.
+func (p *policyUI) checkTx(tx *apitypes.SendTxArgs) error {
+	rules := p.policy.Transactions
+	if rules == nil {
+		return errors.New("transactions not covered")
+	}
+	if len(rules.Senders) > 0 {
+		var allowed bool
+		for _, sender := range rules.Senders {
+			if sender == tx.From.Address() {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("sender %v not allowed", tx.From.Address())
+		}
+	}
+	if tx.To == nil {
+		return errors.New("contract creation not allowed")
+	}
+	var recipient *Recipient
+	for _, r := range rules.Recipients {
+		if r.Address == tx.To.Address() {
+			recipient = r
+			break
+		}
+	}
+	if recipient == nil {
+		return fmt.Errorf("recipient %v not allowed", tx.To.Address())
+	}
+	var data []byte
+	if tx.Input != nil {
+		data = *tx.Input
+	} else if tx.Data != nil {
+		data = *tx.Data
+	}
+	if len(data) > 0 {
+		if len(data) < 4 {
+			return errors.New("invalid call data")
+		}
+		var id [4]byte
+		copy(id[:], data[:4])
+
+		method, ok := recipient.selectors[id]
+		if !ok {
+			return fmt.Errorf("method %#x not allowed", id)
+		}
+		if p.verifier != nil {
+			if err := p.verifier.VerifySelector(method, data); err != nil {
+				return err
+			}
+		}
+	}
+	if recipient.DailyLimit == nil {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	key := p.spentKey(recipient.Address)
+	spent, err := p.spent(key)
+	if err != nil {
+		return err
+	}
+	// Count the spends of transactions approved but not yet signed too
+	now := p.now()
+	pending := p.pending[:0]
+	for _, r := range p.pending {
+		if now.After(r.expires) {
+			continue
+		}
+		if r.key == key {
+			spent.Add(spent, r.value)
+		}
+		pending = append(pending, r)
+	}
+	p.pending = pending
+
+	spent.Add(spent, tx.Value.ToInt())
+	if spent.Cmp((*big.Int)(recipient.DailyLimit)) > 0 {
+		return fmt.Errorf("daily limit of %v exceeded", recipient.Address)
+	}
+	p.pending = append(p.pending, &reservation{
+		key:     key,
+		to:      recipient.Address,
+		nonce:   uint64(tx.Nonce),
+		value:   new(big.Int).Set(tx.Value.ToInt()),
+		expires: now.Add(reservationTimeout),
+	})
+	return nil
+}
+
+// spent retrieves the recorded spend counter stored under the given key. Missing
+// counters count as nothing spent, while failing to read the storage is an error
+// so the daily limits cannot be bypassed.
+func (p *policyUI) spent(key string) (*big.Int, error) {
+	stored, err := p.storage.Get(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return new(big.Int), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spend counter: %v", err)
+	}
+	spent, ok := new(big.Int).SetString(stored, 10)
+	if !ok {
+		return nil, fmt.Errorf("corrupt spend counter %q", stored)
+	}
+	return spent, nil
+}
+
+// recordTx records the spend of a signed transaction in the persistent counter,
+// if its value was reserved during approval.
+func (p *policyUI) recordTx(tx *types.Transaction) {
+	if tx == nil || tx.To() == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for i, r := range p.pending {
+		if r.to != *tx.To() || r.nonce != tx.Nonce() || r.value.Cmp(tx.Value()) != 0 {
+			continue
+		}
+		p.pending = append(p.pending[:i], p.pending[i+1:]...)
+
+		spent, err := p.spent(r.key)
+		if err != nil {
+			log.Error("Failed to record transaction spend", "err", err)
+			return
+		}
+		p.storage.Put(r.key, spent.Add(spent, r.value).String())
+		return
+	}
+}
+
+// checkSignData evaluates a data signing request against the policy.
+func (p *policyUI) checkSignData(request *core.SignDataRequest) error {
+	rules := p.policy.SignData
+	if rules == nil {
+		return errors.New("data signing not covered")
+	}
+	if request.ContentType != apitypes.DataTyped.Mime || request.TypedData == nil {
+		return fmt.Errorf("content type %q not allowed", request.ContentType)
+	}
+	domain := request.TypedData.Domain
+	for _, allowed := range rules.TypedDataDomains {
+		if allowed.matches(&domain, request.TypedData.PrimaryType) {
+			return nil
+		}
+	}
+	return fmt.Errorf("typed data domain %q not allowed", domain.Name)
+}
+
+// matches checks whether the EIP-712 domain and primary type of a typed data
+// request match the allowed domain.
+func (d *TypedDataDomain) matches(domain *apitypes.TypedDataDomain, primaryType string) bool {
+	if d.Name != "" && d.Name != domain.Name {
+		return false
+	}
+	if d.Version != "" && d.Version != domain.Version {
+		return false
+	}
+	if d.ChainId != nil && (domain.ChainId == nil || (*big.Int)(d.ChainId).Cmp((*big.Int)(domain.ChainId)) != 0) {
+		return false
+	}
+	if d.VerifyingContract != nil {
+		if !common.IsHexAddress(domain.VerifyingContract) || common.HexToAddress(domain.VerifyingContract) != *d.VerifyingContract {
+			return false
+		}
+	}
+	if len(d.PrimaryTypes) > 0 {
+		for _, typ := range d.PrimaryTypes {
+			if typ == primaryType {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (p *policyUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	if err := p.checkTx(&request.Transaction); err != nil {
+		if p.policy.Default == PolicyReject {
+			log.Info("Policy rejected transaction", "reason", err)
+			return core.SignTxResponse{Approved: false}, nil
+		}
+		log.Info("Transaction not covered by policy, going to manual", "reason", err)
+		return p.next.ApproveTx(request)
+	}
+	log.Info("Policy approved transaction")
+	return core.SignTxResponse{Transaction: request.Transaction, Approved: true}, nil
+}
+
+func (p *policyUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	if err := p.checkSignData(request); err != nil {
+		if p.policy.Default == PolicyReject {
+			log.Info("Policy rejected data signing", "reason", err)
+			return core.SignDataResponse{Approved: false}, nil
+		}
+		log.Info("Data signing not covered by policy, going to manual", "reason", err)
+		return p.next.ApproveSignData(request)
+	}
+	log.Info("Policy approved data signing")
+	return core.SignDataResponse{Approved: true}, nil
+}
+
+func (p *policyUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	if p.policy.Listing {
+		return core.ListResponse{Accounts: request.Accounts}, nil
+	}
+	if p.policy.Default == PolicyReject {
+		return core.ListResponse{}, nil
+	}
+	return p.next.ApproveListing(request)
+}
+
+func (p *policyUI) ApproveNewAccount(request *core.NewAccountRequest) (core.NewAccountResponse, error) {
+	// This cannot be handled by policies, requires setting a password
+	return p.next.ApproveNewAccount(request)
+}
+
+// OnInputRequired not handled by policies
+func (p *policyUI) OnInputRequired(info core.UserInputRequest) (core.UserInputResponse, error) {
+	return p.next.OnInputRequired(info)
+}
+
+func (p *policyUI) ShowError(message string) {
+	log.Error(message)
+	p.next.ShowError(message)
+}
+
+func (p *policyUI) ShowInfo(message string) {
+	log.Info(message)
+	p.next.ShowInfo(message)
+}
+
+func (p *policyUI) OnSignerStartup(info core.StartupInfo) {
+	p.next.OnSignerStartup(info)
+}
+
+func (p *policyUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	p.recordTx(tx.Tx)
+	p.next.OnApprovedTx(tx)
+}
@@ -0,0 +1,252 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rules
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/signer/storage"
+)
+
+const testPolicy = `
+default: manual
+listing: true
+transactions:
+  recipients:
+    - address: "0x000000000000000000000000000000000000dead"
+      dailyLimit: "1000000000000000000"
+    - address: "0x000000000000000000000000000000000000beef"
+      methods:
+        - "transfer(address, uint256)"
+signData:
+  typedDataDomains:
+    - name: "Ether Mail"
+      chainId: "1"
+      verifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+`
+
+func initPolicyEngine(t *testing.T, policy string, next core.UIClientAPI) *policyUI {
+	t.Helper()
+
+	p, err := NewPolicyEvaluator(next, storage.NewEphemeralStorage(), nil)
+	if err != nil {
+		t.Fatalf("failed to create policy engine: %v", err)
+	}
+	if err := p.Init([]byte(policy)); err != nil {
+		t.Fatalf("failed to parse policy: %v", err)
+	}
+	return p
+}
+
+func TestPolicyParsing(t *testing.T) {
+	tests := []struct {
+		policy string
+		fail   bool
+	}{
+		{policy: `{"default": "reject", "listing": true}`},
+		{policy: testPolicy},
+		{policy: `default: approve`, fail: true},
+		{policy: `transactions: {recipients: [{address: "0xdead", methods: ["transfer(address"]}]}`, fail: true},
+		{policy: `transactions: {recipients: [{address: "0x01"}, {address: "0x01"}]}`, fail: true},
+	}
+	for i, tt := range tests {
+		_, err := ParsePolicy([]byte(tt.policy))
+		if tt.fail && err == nil {
+			t.Errorf("test %d: expected failure", i)
+		}
+		if !tt.fail && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestPolicyListing(t *testing.T) {
+	p := initPolicyEngine(t, testPolicy, &dontCallMe{t})
+
+	accs := make([]accounts.Account, 2)
+	resp, err := p.ApproveListing(&core.ListRequest{Accounts: accs})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Accounts) != len(accs) {
+		t.Errorf("accounts mismatch: have %d, want %d", len(resp.Accounts), len(accs))
+	}
+}
+
+func TestPolicyTxLimits(t *testing.T) {
+	p := initPolicyEngine(t, testPolicy, &dontCallMe{t})
+
+	day := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return day }
+
+	// 0.3 ether transfers should be approved until the daily limit is exhausted
+	v := new(big.Int).SetBytes(common.Hex2Bytes("0429D069189E0000"))
+	for i := 0; i < 3; i++ {
+		resp, err := p.ApproveTx(dummyTx(hexutil.Big(*v)))
+		if err != nil {
+			t.Fatalf("transfer %d: unexpected error: %v", i, err)
+		}
+		if !resp.Approved {
+			t.Fatalf("transfer %d: expected approval", i)
+		}
+	}
+	// The fourth transfer exceeds the limit and should go to manual processing
+	ui := &dummyUI{make([]string, 0)}
+	p.next = ui
+	if resp, _ := p.ApproveTx(dummyTx(hexutil.Big(*v))); resp.Approved {
+		t.Fatalf("expected limit to be exceeded")
+	}
+	if len(ui.calls) != 1 || ui.calls[0] != "ApproveTx" {
+		t.Fatalf("expected manual processing, got calls %v", ui.calls)
+	}
+	// On the next day, the limit should be reset
+	p.next = &dontCallMe{t}
+	p.now = func() time.Time { return day.Add(24 * time.Hour) }
+	if resp, _ := p.ApproveTx(dummyTx(hexutil.Big(*v))); !resp.Approved {
+		t.Fatalf("expected limit to be reset")
+	}
+}
+
+func TestPolicyTxSpendRecording(t *testing.T) {
+	p := initPolicyEngine(t, testPolicy, &dontCallMe{t})
+
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	// An approved transaction that never gets signed only holds its value until
+	// the reservation expires
+	v := new(big.Int).SetBytes(common.Hex2Bytes("0853A0D2313C0000")) // 0.6 ether
+	if resp, _ := p.ApproveTx(dummyTx(hexutil.Big(*v))); !resp.Approved {
+		t.Fatalf("expected approval")
+	}
+	now = now.Add(reservationTimeout + time.Second)
+	if resp, _ := p.ApproveTx(dummyTx(hexutil.Big(*v))); !resp.Approved {
+		t.Fatalf("expected approval after unsigned reservation expired")
+	}
+	// Once signed, the spend is recorded and counts against the limit for good
+	ui := &dummyUI{make([]string, 0)}
+	p.next = ui
+	p.OnApprovedTx(ethapi.SignTransactionResult{Tx: dummySigned(v)})
+	if spent, _ := p.spent(p.spentKey(common.HexToAddress("0xdead"))); spent.Cmp(v) != 0 {
+		t.Fatalf("spend not recorded: have %v, want %v", spent, v)
+	}
+	now = now.Add(reservationTimeout + time.Second)
+	if resp, _ := p.ApproveTx(dummyTx(hexutil.Big(*v))); resp.Approved {
+		t.Fatalf("expected limit to be exceeded")
+	}
+}
+
+// failingStorage is a storage which cannot be read.
+type failingStorage struct{}
+
+func (failingStorage) Put(key, value string)          {}
+func (failingStorage) Get(key string) (string, error) { return "", errors.New("storage failure") }
+func (failingStorage) Del(key string)                 {}
+
+func TestPolicyTxSpendStorageFailure(t *testing.T) {
+	p := initPolicyEngine(t, testPolicy, &dontCallMe{t})
+	p.storage = failingStorage{}
+
+	// Unreadable spend counters must not be mistaken for nothing spent
+	ui := &dummyUI{make([]string, 0)}
+	p.next = ui
+	if resp, _ := p.ApproveTx(dummyTx(hexutil.Big(*big.NewInt(1)))); resp.Approved {
+		t.Fatalf("transaction approved with unreadable spend counter")
+	}
+	if len(ui.calls) != 1 || ui.calls[0] != "ApproveTx" {
+		t.Fatalf("expected manual processing, got calls %v", ui.calls)
+	}
+}
+
+func TestPolicyTxMethods(t *testing.T) {
+	p := initPolicyEngine(t, `{"default": "reject", "transactions": {"recipients": [{"address": "0x000000000000000000000000000000000000dead", "methods": ["transfer(address,uint256)"]}]}}`, &dontCallMe{t})
+
+	transfer := hexutil.Bytes(common.FromHex("0xa9059cbb000000000000000000000000000000000000000000000000000000000000dead0000000000000000000000000000000000000000000000000000000000000001"))
+	approve := hexutil.Bytes(common.FromHex("0x095ea7b3000000000000000000000000000000000000000000000000000000000000dead0000000000000000000000000000000000000000000000000000000000000001"))
+
+	tests := []struct {
+		data     *hexutil.Bytes
+		approved bool
+	}{
+		{data: nil, approved: true},
+		{data: &transfer, approved: true},
+		{data: &approve, approved: false},
+	}
+	for i, tt := range tests {
+		req := dummyTxWithV(0)
+		req.Transaction.Data = tt.data
+
+		resp, err := p.ApproveTx(req)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %v", i, err)
+		}
+		if resp.Approved != tt.approved {
+			t.Errorf("test %d: approval mismatch: have %v, want %v", i, resp.Approved, tt.approved)
+		}
+	}
+	// Transactions to other recipients should be rejected
+	req := dummyTxWithV(0)
+	req.Transaction.To, _ = mixAddr("0x000000000000000000000000000000000000beef")
+	if resp, _ := p.ApproveTx(req); resp.Approved {
+		t.Errorf("expected unknown recipient to be rejected")
+	}
+}
+
+func TestPolicyTypedData(t *testing.T) {
+	p := initPolicyEngine(t, testPolicy, &dontCallMe{t})
+
+	newRequest := func(name string, chainId int64) *core.SignDataRequest {
+		return &core.SignDataRequest{
+			ContentType: apitypes.DataTyped.Mime,
+			TypedData: &apitypes.TypedData{
+				PrimaryType: "Mail",
+				Domain: apitypes.TypedDataDomain{
+					Name:              name,
+					ChainId:           math.NewHexOrDecimal256(chainId),
+					VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+				},
+			},
+		}
+	}
+	resp, err := p.ApproveSignData(newRequest("Ether Mail", 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Approved {
+		t.Fatalf("expected allowed domain to be approved")
+	}
+	for _, req := range []*core.SignDataRequest{newRequest("Other Mail", 1), newRequest("Ether Mail", 5), {ContentType: apitypes.TextPlain.Mime}} {
+		ui := &dummyUI{make([]string, 0)}
+		p.next = ui
+		if resp, _ := p.ApproveSignData(req); resp.Approved {
+			t.Errorf("expected request to not be approved")
+		}
+		if len(ui.calls) != 1 || ui.calls[0] != "ApproveSignData" {
+			t.Errorf("expected manual processing, got calls %v", ui.calls)
+		}
+	}
+}
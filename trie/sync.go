@@ -369,6 +369,20 @@ func (s *Sync) Pending() int {
 	return len(s.nodeReqs) + len(s.codeReqs)
 }
 
+// Retrieved returns the trie nodes which were already retrieved, but can't be
+// committed yet as they are waiting for their children to complete. Feeding them
+// into a new scheduler for the same trie restores the progress without having
+// to retrieve them again.
+func (s *Sync) Retrieved() []NodeSyncResult {
+	var nodes []NodeSyncResult
+	for path, req := range s.nodeReqs {
+		if req.data != nil {
+			nodes = append(nodes, NodeSyncResult{Path: path, Data: req.data})
+		}
+	}
+	return nodes
+}
+
 // schedule inserts a new state retrieval request into the fetch queue. If there
 // is already a pending request for this node, the new request will be discarded
 // and only a parent reference added to the old one.